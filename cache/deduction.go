@@ -0,0 +1,147 @@
+// Package cache keep deduction config in memory, it is invalidated by NOTIFY from trigger on deductions table
+// and fall back to TTL while listener connection is down. Active rule set is reloaded by NOTIFY from trigger on rule_sets table.
+package cache
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/baronight/assessment-tax/db"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/lib/pq"
+)
+
+// DeductionChannel is channel that trigger on deductions table notify on every change
+const DeductionChannel = "deductions_changed"
+
+type DeductionStorer interface {
+	GetDeductions(ctx context.Context) ([]models.Deduction, error)
+}
+
+type Deductions struct {
+	store DeductionStorer
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu         sync.RWMutex
+	deductions []models.Deduction
+	loadedAt   time.Time
+	loaded     bool
+	// generation increase on every invalidate, so load that started before it is not kept
+	generation uint64
+
+	listening atomic.Bool
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+}
+
+func NewDeductions(store DeductionStorer, ttl time.Duration) *Deductions {
+	return &Deductions{store: store, ttl: ttl, now: time.Now}
+}
+
+// GetDeductions return cached deductions, they are expired by TTL only while listener is not listening
+func (c *Deductions) GetDeductions(ctx context.Context) ([]models.Deduction, error) {
+	c.mu.RLock()
+	fresh := c.loaded && (c.listening.Load() || c.now().Sub(c.loadedAt) < c.ttl)
+	deductions, generation := c.deductions, c.generation
+	c.mu.RUnlock()
+	if fresh {
+		c.hits.Add(1)
+		return slices.Clone(deductions), nil
+	}
+
+	c.misses.Add(1)
+	deductions, err := c.store.GetDeductions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.generation == generation {
+		c.deductions, c.loadedAt, c.loaded = deductions, c.now(), true
+	}
+	c.mu.Unlock()
+	return slices.Clone(deductions), nil
+}
+
+// Invalidate drop cached deductions, next GetDeductions load them from store
+func (c *Deductions) Invalidate() {
+	c.mu.Lock()
+	c.loaded = false
+	c.generation++
+	c.mu.Unlock()
+}
+
+// SetListening switch between invalidate by notification and by TTL,
+// cache is invalidated when listening start again because notification may be missed while it was down
+func (c *Deductions) SetListening(listening bool) {
+	if c.listening.Swap(listening) != listening && listening {
+		c.Invalidate()
+	}
+}
+
+func (c *Deductions) Stats() models.CacheStats {
+	return models.CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Listening: c.listening.Load(),
+	}
+}
+
+// Listen invalidate cache on every notification until ctx is done or notify is closed,
+// pq send nil notification after reconnect
+func (c *Deductions) Listen(ctx context.Context, notify <-chan *pq.Notification) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-notify:
+			if !ok {
+				c.SetListening(false)
+				return
+			}
+			c.Invalidate()
+		}
+	}
+}
+
+// ListenDeductions open listener connection on DeductionChannel that keep cache up to date until ctx is done
+func ListenDeductions(ctx context.Context, databaseSource string, c *Deductions) *pq.Listener {
+	listener := pq.NewListener(databaseSource, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		// first connection has no channel yet, cache is listening only after Listen below return,
+		// reconnected connection already listen again on every channel
+		switch event {
+		case pq.ListenerEventReconnected:
+			c.SetListening(true)
+		case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+			c.SetListening(false)
+		}
+	})
+	go func() {
+		if err := listener.Listen(DeductionChannel); err != nil {
+			c.SetListening(false)
+			return
+		}
+		c.SetListening(true)
+		c.Listen(ctx, listener.NotificationChannel())
+	}()
+	return listener
+}
+
+// Store serve deductions from cache and everything else from database,
+// so it can be used in place of db.Store by every service
+type Store struct {
+	*db.Store
+	Deductions *Deductions
+}
+
+func NewStore(s *db.Store, ttl time.Duration) *Store {
+	return &Store{Store: s, Deductions: NewDeductions(s, ttl)}
+}
+
+// GetDeductions implements services.TaxStorer.
+func (s *Store) GetDeductions(ctx context.Context) ([]models.Deduction, error) {
+	return s.Deductions.GetDeductions(ctx)
+}
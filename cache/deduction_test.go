@@ -0,0 +1,173 @@
+//go:build !integration
+// +build !integration
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/lib/pq"
+)
+
+type stubDeductionStore struct {
+	deductions []models.Deduction
+	err        error
+	calls      int
+	// onGet run while store is loading, to simulate change in between
+	onGet func()
+}
+
+func (s *stubDeductionStore) GetDeductions(ctx context.Context) ([]models.Deduction, error) {
+	s.calls++
+	if s.onGet != nil {
+		s.onGet()
+	}
+	return s.deductions, s.err
+}
+
+func setupCache(ttl time.Duration) (*Deductions, *stubDeductionStore, *time.Time) {
+	stub := &stubDeductionStore{deductions: []models.Deduction{{Slug: models.PersonalSlug, Amount: 60_000}}}
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	c := NewDeductions(stub, ttl)
+	c.now = func() time.Time { return now }
+	return c, stub, &now
+}
+
+func assertStats(t *testing.T, c *Deductions, hits, misses uint64) {
+	t.Helper()
+	if got := c.Stats(); got.Hits != hits || got.Misses != misses {
+		t.Errorf("expect %d hits and %d misses but got %#v", hits, misses, got)
+	}
+}
+
+func TestDeductionsCache(t *testing.T) {
+	t.Run("given cached deductions should not load from store again", func(t *testing.T) {
+		c, stub, _ := setupCache(time.Minute)
+
+		c.GetDeductions(context.Background())
+		got, err := c.GetDeductions(context.Background())
+
+		if err != nil || len(got) != 1 || got[0].Amount != 60_000 {
+			t.Errorf("expect cached deductions but got %#v, %v", got, err)
+		}
+		if stub.calls != 1 {
+			t.Errorf("expect store was called once but got %d", stub.calls)
+		}
+		assertStats(t, c, 1, 1)
+	})
+	t.Run("given not listening and ttl passed should load from store again", func(t *testing.T) {
+		c, stub, now := setupCache(time.Minute)
+
+		c.GetDeductions(context.Background())
+		*now = now.Add(2 * time.Minute)
+		c.GetDeductions(context.Background())
+
+		if stub.calls != 2 {
+			t.Errorf("expect store was called twice but got %d", stub.calls)
+		}
+		assertStats(t, c, 0, 2)
+	})
+	t.Run("given listening should keep deductions after ttl until notified", func(t *testing.T) {
+		c, stub, now := setupCache(time.Minute)
+		c.SetListening(true)
+
+		c.GetDeductions(context.Background())
+		*now = now.Add(2 * time.Minute)
+		c.GetDeductions(context.Background())
+		c.Invalidate()
+		c.GetDeductions(context.Background())
+
+		if stub.calls != 2 {
+			t.Errorf("expect store was called twice but got %d", stub.calls)
+		}
+		assertStats(t, c, 1, 2)
+	})
+	t.Run("given error from store should not cache it", func(t *testing.T) {
+		c, stub, _ := setupCache(time.Minute)
+		stub.err = errors.New("error 'xxx' occured")
+
+		_, err := c.GetDeductions(context.Background())
+		stub.err = nil
+		c.GetDeductions(context.Background())
+
+		if err == nil {
+			t.Error("expect error from store")
+		}
+		if stub.calls != 2 {
+			t.Errorf("expect store was called twice but got %d", stub.calls)
+		}
+	})
+	t.Run("given invalidate while loading should not keep loaded deductions", func(t *testing.T) {
+		c, stub, _ := setupCache(time.Minute)
+		stub.onGet = func() { c.Invalidate() }
+
+		c.GetDeductions(context.Background())
+		stub.onGet = nil
+		c.GetDeductions(context.Background())
+
+		if stub.calls != 2 {
+			t.Errorf("expect store was called twice but got %d", stub.calls)
+		}
+	})
+	t.Run("given listening start again should drop deductions that may miss notification", func(t *testing.T) {
+		c, stub, _ := setupCache(time.Minute)
+		c.SetListening(true)
+		c.GetDeductions(context.Background())
+
+		c.SetListening(false)
+		c.SetListening(true)
+		c.GetDeductions(context.Background())
+
+		if stub.calls != 2 {
+			t.Errorf("expect store was called twice but got %d", stub.calls)
+		}
+	})
+	t.Run("given returned deductions is changed should not change cache", func(t *testing.T) {
+		c, _, _ := setupCache(time.Minute)
+
+		got, _ := c.GetDeductions(context.Background())
+		got[0].Amount = 0
+		got, _ = c.GetDeductions(context.Background())
+
+		if got[0].Amount != 60_000 {
+			t.Errorf("expect cached amount 60,000 but got %v", got[0].Amount)
+		}
+	})
+}
+
+func TestDeductionsListen(t *testing.T) {
+	t.Run("given notification should invalidate cache and stop listening when channel is closed", func(t *testing.T) {
+		c, stub, _ := setupCache(time.Minute)
+		c.SetListening(true)
+		c.GetDeductions(context.Background())
+		notify := make(chan *pq.Notification)
+		done := make(chan struct{})
+		go func() {
+			c.Listen(context.Background(), notify)
+			close(done)
+		}()
+
+		notify <- &pq.Notification{Channel: DeductionChannel, Extra: "UPDATE"}
+		close(notify)
+		<-done
+		c.GetDeductions(context.Background())
+
+		if stub.calls != 2 {
+			t.Errorf("expect store was called twice but got %d", stub.calls)
+		}
+		if c.Stats().Listening {
+			t.Error("expect cache is not listening after channel is closed")
+		}
+	})
+	t.Run("given context is done should stop listening", func(t *testing.T) {
+		c, _, _ := setupCache(time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		c.Listen(ctx, make(chan *pq.Notification))
+	})
+}
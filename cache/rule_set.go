@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/lib/pq"
+)
+
+// RuleSetChannel is channel that trigger on rule_sets table notify on every change
+const RuleSetChannel = "rule_sets_changed"
+
+type RuleSetLoader interface {
+	LoadActiveRuleSet(ctx context.Context) (models.RuleSet, error)
+}
+
+// ReloadRuleSets load active rule set on every notification until ctx is done or notify is closed,
+// pq send nil notification after reconnect and it is reloaded too because notification may be missed while it was down
+func ReloadRuleSets(ctx context.Context, notify <-chan *pq.Notification, loader RuleSetLoader) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-notify:
+			if !ok {
+				return
+			}
+			if _, err := loader.LoadActiveRuleSet(ctx); err != nil {
+				log.Printf("cannot reload active rule set: %v", err)
+			}
+		}
+	}
+}
+
+// ListenRuleSets open listener connection on RuleSetChannel that keep active rule set of this instance
+// same as the one that other instance activated, until ctx is done
+func ListenRuleSets(ctx context.Context, databaseSource string, loader RuleSetLoader) *pq.Listener {
+	listener := pq.NewListener(databaseSource, time.Second, time.Minute, nil)
+	go func() {
+		if err := listener.Listen(RuleSetChannel); err != nil {
+			log.Printf("cannot listen %s: %v", RuleSetChannel, err)
+			return
+		}
+		ReloadRuleSets(ctx, listener.NotificationChannel(), loader)
+	}()
+	return listener
+}
@@ -0,0 +1,68 @@
+//go:build !integration
+// +build !integration
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/lib/pq"
+)
+
+type stubRuleSetLoader struct {
+	err   error
+	calls int
+}
+
+func (s *stubRuleSetLoader) LoadActiveRuleSet(ctx context.Context) (models.RuleSet, error) {
+	s.calls++
+	return models.RuleSet{}, s.err
+}
+
+func TestReloadRuleSets(t *testing.T) {
+	t.Run("given notification and reconnect should reload active rule set until channel is closed", func(t *testing.T) {
+		loader := &stubRuleSetLoader{}
+		notify := make(chan *pq.Notification)
+		done := make(chan struct{})
+		go func() {
+			ReloadRuleSets(context.Background(), notify, loader)
+			close(done)
+		}()
+
+		notify <- &pq.Notification{Channel: RuleSetChannel, Extra: "UPDATE"}
+		notify <- nil
+		close(notify)
+		<-done
+
+		if loader.calls != 2 {
+			t.Errorf("expect rule set was loaded twice but got %d", loader.calls)
+		}
+	})
+	t.Run("given error on load should keep listening", func(t *testing.T) {
+		loader := &stubRuleSetLoader{err: errors.New("error 'xxx' occured")}
+		notify := make(chan *pq.Notification)
+		done := make(chan struct{})
+		go func() {
+			ReloadRuleSets(context.Background(), notify, loader)
+			close(done)
+		}()
+
+		notify <- &pq.Notification{Channel: RuleSetChannel}
+		notify <- &pq.Notification{Channel: RuleSetChannel}
+		close(notify)
+		<-done
+
+		if loader.calls != 2 {
+			t.Errorf("expect rule set was loaded twice but got %d", loader.calls)
+		}
+	})
+	t.Run("given context is done should stop listening", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		ReloadRuleSets(ctx, make(chan *pq.Notification), &stubRuleSetLoader{})
+	})
+}
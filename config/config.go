@@ -0,0 +1,285 @@
+// Package config load every setting of the api once on startup, from optional yaml file in CONFIG_FILE
+// and then from environment variables that override the file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StorageDatabase = "database"
+	StorageMemory   = "memory"
+
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+)
+
+var LogLevels = []string{"debug", "info", "warn", "error", "off"}
+
+type Config struct {
+	Port int `yaml:"port"`
+	// Storage is StorageMemory to run without database
+	Storage  string   `yaml:"storage"`
+	Database Database `yaml:"database"`
+	// DeductionCacheTTL expire cached deductions while they are not invalidated by database notification
+	DeductionCacheTTL time.Duration `yaml:"deductionCacheTTL"`
+	// ShutdownTimeout is how long server wait for running requests on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// ShutdownDrainDelay is how long /readyz report not ready before server is shut down, so load balancers stop sending requests
+	ShutdownDrainDelay time.Duration `yaml:"shutdownDrainDelay"`
+	CORS               CORS          `yaml:"cors"`
+	Auth               Auth          `yaml:"auth"`
+	LogLevel           string        `yaml:"logLevel"`
+}
+
+type Database struct {
+	Driver string `yaml:"driver"`
+	// URL is connection string of postgres or file path of sqlite
+	URL string `yaml:"url"`
+	// MaxOpenConns is 0 for no limit
+	MaxOpenConns int `yaml:"maxOpenConns"`
+	MaxIdleConns int `yaml:"maxIdleConns"`
+	// ConnMaxLifetime and ConnMaxIdleTime close connection that is older or idle longer than them, they are not limited when 0
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime"`
+	// ConnectRetries is how many times to ping database again when it is not ready on startup,
+	// wait ConnectBackoff before first retry and double it after every retry
+	ConnectRetries int           `yaml:"connectRetries"`
+	ConnectBackoff time.Duration `yaml:"connectBackoff"`
+	// QueryTimeout limit every query, no limit other than request when it is 0
+	QueryTimeout time.Duration `yaml:"queryTimeout"`
+}
+
+type CORS struct {
+	AllowOrigins []string `yaml:"allowOrigins"`
+}
+
+type Auth struct {
+	// Admins is password of every admin username
+	Admins map[string]string `yaml:"admins"`
+}
+
+// Default return settings that are used when they are not set in file or env
+func Default() Config {
+	return Config{
+		Port:    1323,
+		Storage: StorageDatabase,
+		Database: Database{
+			Driver:          DriverPostgres,
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectRetries:  8,
+			ConnectBackoff:  500 * time.Millisecond,
+			QueryTimeout:    5 * time.Second,
+		},
+		DeductionCacheTTL:  time.Minute,
+		ShutdownTimeout:    10 * time.Second,
+		ShutdownDrainDelay: 5 * time.Second,
+		CORS:               CORS{AllowOrigins: []string{"*"}},
+		Auth:               Auth{Admins: map[string]string{}},
+		LogLevel:           "info",
+	}
+}
+
+// Load return validated config from CONFIG_FILE and environment variables
+func Load() (Config, error) {
+	return load(os.LookupEnv)
+}
+
+func load(lookup func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+	if path, ok := lookup("CONFIG_FILE"); ok && path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return cfg, err
+		}
+	}
+	env := envReader{lookup: lookup}
+	env.int("PORT", &cfg.Port)
+	env.string("STORAGE", &cfg.Storage)
+	env.string("DATABASE_DRIVER", &cfg.Database.Driver)
+	env.string("DATABASE_URL", &cfg.Database.URL)
+	env.int("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	env.int("DB_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns)
+	env.duration("DB_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime)
+	env.duration("DB_CONN_MAX_IDLE_TIME", &cfg.Database.ConnMaxIdleTime)
+	env.int("DB_CONNECT_RETRIES", &cfg.Database.ConnectRetries)
+	env.duration("DB_CONNECT_BACKOFF", &cfg.Database.ConnectBackoff)
+	env.duration("DB_QUERY_TIMEOUT", &cfg.Database.QueryTimeout)
+	env.duration("DEDUCTION_CACHE_TTL", &cfg.DeductionCacheTTL)
+	env.duration("SHUTDOWN_TIMEOUT", &cfg.ShutdownTimeout)
+	env.duration("SHUTDOWN_DRAIN_DELAY", &cfg.ShutdownDrainDelay)
+	env.list("CORS_ALLOW_ORIGINS", &cfg.CORS.AllowOrigins)
+	env.admins(&cfg.Auth)
+	env.string("LOG_LEVEL", &cfg.LogLevel)
+	return cfg, errors.Join(append(env.errs, cfg.Validate())...)
+}
+
+func (cfg *Config) readFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("CONFIG_FILE: %w", err)
+	}
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return fmt.Errorf("CONFIG_FILE %s: %w", path, err)
+	}
+	if cfg.Auth.Admins == nil {
+		cfg.Auth.Admins = map[string]string{}
+	}
+	return nil
+}
+
+// Validate return every invalid setting in one error
+func (cfg Config) Validate() error {
+	var errs []error
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port (PORT) should be between 1 and 65535 but got %d", cfg.Port))
+	}
+	if cfg.Storage != StorageDatabase && cfg.Storage != StorageMemory {
+		errs = append(errs, fmt.Errorf("storage (STORAGE) should be %q or %q but got %q", StorageDatabase, StorageMemory, cfg.Storage))
+	}
+	if cfg.Storage == StorageDatabase {
+		errs = append(errs, cfg.Database.validate()...)
+	}
+	if cfg.DeductionCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("deduction cache ttl (DEDUCTION_CACHE_TTL) should not be negative but got %s", cfg.DeductionCacheTTL))
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("shutdown timeout (SHUTDOWN_TIMEOUT) should be positive but got %s", cfg.ShutdownTimeout))
+	}
+	if cfg.ShutdownDrainDelay < 0 {
+		errs = append(errs, fmt.Errorf("shutdown drain delay (SHUTDOWN_DRAIN_DELAY) should not be negative but got %s", cfg.ShutdownDrainDelay))
+	}
+	if len(cfg.CORS.AllowOrigins) == 0 {
+		errs = append(errs, errors.New("cors allow origins (CORS_ALLOW_ORIGINS) should have at least one origin"))
+	}
+	for user, pass := range cfg.Auth.Admins {
+		if user == "" || pass == "" {
+			errs = append(errs, fmt.Errorf("admin %q (ADMIN_USERNAME, ADMIN_PASSWORD, ADMIN_USERS) should have username and password", user))
+		}
+	}
+	if !slices.Contains(LogLevels, cfg.LogLevel) {
+		errs = append(errs, fmt.Errorf("log level (LOG_LEVEL) should be one of %s but got %q", strings.Join(LogLevels, ", "), cfg.LogLevel))
+	}
+	return errors.Join(errs...)
+}
+
+func (db Database) validate() []error {
+	var errs []error
+	if db.Driver != DriverPostgres && db.Driver != DriverSqlite {
+		errs = append(errs, fmt.Errorf("database driver (DATABASE_DRIVER) should be %q or %q but got %q", DriverPostgres, DriverSqlite, db.Driver))
+	}
+	if db.URL == "" {
+		errs = append(errs, errors.New("database url (DATABASE_URL) is required"))
+	}
+	if db.MaxOpenConns < 0 {
+		errs = append(errs, fmt.Errorf("database max open conns (DB_MAX_OPEN_CONNS) should not be negative but got %d", db.MaxOpenConns))
+	}
+	if db.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("database max idle conns (DB_MAX_IDLE_CONNS) should not be negative but got %d", db.MaxIdleConns))
+	}
+	if db.ConnMaxLifetime < 0 {
+		errs = append(errs, fmt.Errorf("database conn max lifetime (DB_CONN_MAX_LIFETIME) should not be negative but got %s", db.ConnMaxLifetime))
+	}
+	if db.ConnMaxIdleTime < 0 {
+		errs = append(errs, fmt.Errorf("database conn max idle time (DB_CONN_MAX_IDLE_TIME) should not be negative but got %s", db.ConnMaxIdleTime))
+	}
+	if db.ConnectRetries < 0 {
+		errs = append(errs, fmt.Errorf("database connect retries (DB_CONNECT_RETRIES) should not be negative but got %d", db.ConnectRetries))
+	}
+	if db.ConnectRetries > 0 && db.ConnectBackoff <= 0 {
+		errs = append(errs, fmt.Errorf("database connect backoff (DB_CONNECT_BACKOFF) should be positive but got %s", db.ConnectBackoff))
+	}
+	if db.QueryTimeout < 0 {
+		errs = append(errs, fmt.Errorf("database query timeout (DB_QUERY_TIMEOUT) should not be negative but got %s", db.QueryTimeout))
+	}
+	return errs
+}
+
+// envReader set value of env that is set, and keep error of env that can not be parsed
+type envReader struct {
+	lookup func(string) (string, bool)
+	errs   []error
+}
+
+func (r *envReader) get(key string) (string, bool) {
+	v, ok := r.lookup(key)
+	return strings.TrimSpace(v), ok && strings.TrimSpace(v) != ""
+}
+
+func (r *envReader) string(key string, dest *string) {
+	if v, ok := r.get(key); ok {
+		*dest = v
+	}
+}
+
+func (r *envReader) int(key string, dest *int) {
+	v, ok := r.get(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s should be number but got %q", key, v))
+		return
+	}
+	*dest = n
+}
+
+func (r *envReader) duration(key string, dest *time.Duration) {
+	v, ok := r.get(key)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s should be duration e.g. 5s but got %q", key, v))
+		return
+	}
+	*dest = d
+}
+
+// list read comma separated values
+func (r *envReader) list(key string, dest *[]string) {
+	v, ok := r.get(key)
+	if !ok {
+		return
+	}
+	var values []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			values = append(values, s)
+		}
+	}
+	*dest = values
+}
+
+// admins add admin from ADMIN_USERNAME and ADMIN_PASSWORD,
+// and other admins from ADMIN_USERS in format "user1:pass1,user2:pass2"
+func (r *envReader) admins(auth *Auth) {
+	if user, ok := r.get("ADMIN_USERNAME"); ok {
+		pass, _ := r.lookup("ADMIN_PASSWORD")
+		auth.Admins[user] = pass
+	}
+	users, ok := r.get("ADMIN_USERS")
+	if !ok {
+		return
+	}
+	for _, v := range strings.Split(users, ",") {
+		user, pass, found := strings.Cut(strings.TrimSpace(v), ":")
+		if !found || user == "" || pass == "" {
+			r.errs = append(r.errs, fmt.Errorf("ADMIN_USERS should be in format user1:pass1,user2:pass2 but got %q", v))
+			continue
+		}
+		auth.Admins[user] = pass
+	}
+}
@@ -0,0 +1,185 @@
+//go:build !integration
+// +build !integration
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func lookupEnv(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func assertErrorContains(t *testing.T, err error, messages ...string) {
+	t.Helper()
+	if err == nil {
+		t.Fatal("expect error was not nil")
+	}
+	for _, m := range messages {
+		if !strings.Contains(err.Error(), m) {
+			t.Errorf("expect error contains %q but got %q", m, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("given only database url should return default config", func(t *testing.T) {
+		got, err := load(lookupEnv(map[string]string{"DATABASE_URL": "host=localhost"}))
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		want := Default()
+		want.Database.URL = "host=localhost"
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("expect %#v but got %#v", want, got)
+		}
+	})
+	t.Run("given env should return typed settings", func(t *testing.T) {
+		got, err := load(lookupEnv(map[string]string{
+			"PORT":                  "8080",
+			"DATABASE_DRIVER":       "sqlite",
+			"DATABASE_URL":          "ktax.db",
+			"DB_MAX_OPEN_CONNS":     "20",
+			"DB_MAX_IDLE_CONNS":     "5",
+			"DB_QUERY_TIMEOUT":      "3s",
+			"DB_CONN_MAX_LIFETIME":  "1h",
+			"DB_CONN_MAX_IDLE_TIME": "10m",
+			"DB_CONNECT_RETRIES":    "3",
+			"DB_CONNECT_BACKOFF":    "2s",
+			"DEDUCTION_CACHE_TTL":   "30s",
+			"SHUTDOWN_TIMEOUT":      "15s",
+			"SHUTDOWN_DRAIN_DELAY":  "1s",
+			"CORS_ALLOW_ORIGINS":    "https://ktax.example, https://admin.ktax.example",
+			"ADMIN_USERNAME":        "adminTax",
+			"ADMIN_PASSWORD":        "admin!",
+			"ADMIN_USERS":           "approverTax:approver!",
+			"LOG_LEVEL":             "debug",
+		}))
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		want := Config{
+			Port:    8080,
+			Storage: StorageDatabase,
+			Database: Database{
+				Driver: DriverSqlite, URL: "ktax.db",
+				MaxOpenConns: 20, MaxIdleConns: 5, QueryTimeout: 3 * time.Second,
+				ConnMaxLifetime: time.Hour, ConnMaxIdleTime: 10 * time.Minute,
+				ConnectRetries: 3, ConnectBackoff: 2 * time.Second,
+			},
+			DeductionCacheTTL:  30 * time.Second,
+			ShutdownTimeout:    15 * time.Second,
+			ShutdownDrainDelay: time.Second,
+			CORS:               CORS{AllowOrigins: []string{"https://ktax.example", "https://admin.ktax.example"}},
+			Auth:               Auth{Admins: map[string]string{"adminTax": "admin!", "approverTax": "approver!"}},
+			LogLevel:           "debug",
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("expect %#v but got %#v", want, got)
+		}
+	})
+	t.Run("given memory storage should not require database url", func(t *testing.T) {
+		got, err := load(lookupEnv(map[string]string{"STORAGE": "memory"}))
+
+		if err != nil || got.Storage != StorageMemory {
+			t.Errorf("expect memory storage but got %#v, %v", got, err)
+		}
+	})
+	t.Run("given env that can not be parsed should return every invalid env", func(t *testing.T) {
+		_, err := load(lookupEnv(map[string]string{
+			"PORT":             "http",
+			"DB_QUERY_TIMEOUT": "5",
+			"ADMIN_USERS":      "approverTax",
+		}))
+
+		assertErrorContains(t, err,
+			`PORT should be number but got "http"`,
+			`DB_QUERY_TIMEOUT should be duration e.g. 5s but got "5"`,
+			`ADMIN_USERS should be in format user1:pass1,user2:pass2 but got "approverTax"`,
+		)
+	})
+	t.Run("given invalid settings should return every invalid setting", func(t *testing.T) {
+		_, err := load(lookupEnv(map[string]string{
+			"PORT":               "70000",
+			"DATABASE_DRIVER":    "mysql",
+			"DB_MAX_OPEN_CONNS":  "-1",
+			"DB_CONNECT_RETRIES": "-2",
+			"ADMIN_USERNAME":     "adminTax",
+			"LOG_LEVEL":          "verbose",
+		}))
+
+		assertErrorContains(t, err,
+			"port (PORT) should be between 1 and 65535 but got 70000",
+			`database driver (DATABASE_DRIVER) should be "postgres" or "sqlite" but got "mysql"`,
+			"database url (DATABASE_URL) is required",
+			"database max open conns (DB_MAX_OPEN_CONNS) should not be negative but got -1",
+			"database connect retries (DB_CONNECT_RETRIES) should not be negative but got -2",
+			`admin "adminTax" (ADMIN_USERNAME, ADMIN_PASSWORD, ADMIN_USERS) should have username and password`,
+			`log level (LOG_LEVEL) should be one of debug, info, warn, error, off but got "verbose"`,
+		)
+	})
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte(`
+port: 8080
+database:
+  url: host=db
+  maxOpenConns: 10
+  queryTimeout: 2s
+cors:
+  allowOrigins: [https://ktax.example]
+auth:
+  admins:
+    adminTax: admin!
+logLevel: warn
+`), 0o600)
+
+	t.Run("given config file should use its settings", func(t *testing.T) {
+		got, err := load(lookupEnv(map[string]string{"CONFIG_FILE": path}))
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if got.Port != 8080 || got.Database.URL != "host=db" || got.Database.MaxOpenConns != 10 ||
+			got.Database.QueryTimeout != 2*time.Second || got.Database.Driver != DriverPostgres ||
+			got.CORS.AllowOrigins[0] != "https://ktax.example" || got.Auth.Admins["adminTax"] != "admin!" || got.LogLevel != "warn" {
+			t.Errorf("expect settings of file with default of others but got %#v", got)
+		}
+	})
+	t.Run("given env should override config file", func(t *testing.T) {
+		got, err := load(lookupEnv(map[string]string{"CONFIG_FILE": path, "PORT": "9090", "ADMIN_USERS": "approverTax:approver!"}))
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if got.Port != 9090 || len(got.Auth.Admins) != 2 {
+			t.Errorf("expect env override file but got %#v", got)
+		}
+	})
+	t.Run("given missing config file should return error", func(t *testing.T) {
+		_, err := load(lookupEnv(map[string]string{"CONFIG_FILE": filepath.Join(t.TempDir(), "missing.yaml")}))
+
+		assertErrorContains(t, err, "CONFIG_FILE")
+	})
+	t.Run("given invalid yaml should return error", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+		os.WriteFile(invalid, []byte("port: [8080"), 0o600)
+
+		_, err := load(lookupEnv(map[string]string{"CONFIG_FILE": invalid}))
+
+		assertErrorContains(t, err, "CONFIG_FILE "+invalid)
+	})
+}
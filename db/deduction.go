@@ -1,22 +1,39 @@
 package db
 
-import "github.com/baronight/assessment-tax/models"
+import (
+	"context"
+	"database/sql"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+const deductionColumns = "id, slug, \"name\", amount, \"minAmount\", \"maxAmount\", version"
+
+func scanDeduction(row rowScanner) (models.Deduction, error) {
+	var d models.Deduction
+	err := row.Scan(
+		&d.Id, &d.Slug,
+		&d.Name, &d.Amount,
+		&d.MinAmount, &d.MaxAmount,
+		&d.Version,
+	)
+	return d, err
+}
 
 // getDeductions implements services.TaxStorer.
-func (p *Postgres) GetDeductions() ([]models.Deduction, error) {
-	rows, err := p.Db.Query("SELECT id, slug, \"name\", amount, \"minAmount\", \"maxAmount\" FROM deductions")
+func (s *Store) GetDeductions(ctx context.Context) ([]models.Deduction, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetDeductions")
+	defer cancel()
+	rows, err := s.Db.QueryContext(ctx, "SELECT "+deductionColumns+" FROM deductions")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var deductions []models.Deduction
 	for rows.Next() {
-		var d models.Deduction
-		if err := rows.Scan(
-			&d.Id, &d.Slug,
-			&d.Name, &d.Amount,
-			&d.MinAmount, &d.MaxAmount,
-		); err != nil {
+		d, err := scanDeduction(rows)
+		if err != nil {
 			return nil, err
 		}
 		deductions = append(deductions, d)
@@ -25,31 +42,22 @@ func (p *Postgres) GetDeductions() ([]models.Deduction, error) {
 }
 
 // GetDeduction implements services.AdminStorer.
-func (p *Postgres) GetDeduction(slug string) (models.Deduction, error) {
-	row := p.Db.QueryRow("SELECT id, slug, \"name\", amount, \"minAmount\", \"maxAmount\" FROM deductions WHERE slug = $1", slug)
-	var deduction models.Deduction
-	if err := row.Scan(
-		&deduction.Id, &deduction.Slug,
-		&deduction.Name, &deduction.Amount,
-		&deduction.MinAmount, &deduction.MaxAmount,
-	); err != nil {
-		return deduction, err
-	}
-	return deduction, nil
+func (s *Store) GetDeduction(ctx context.Context, slug string) (models.Deduction, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetDeduction")
+	defer cancel()
+	row := s.Db.QueryRowContext(ctx, "SELECT "+deductionColumns+" FROM deductions WHERE slug = $1", slug)
+	return scanDeduction(row)
 }
 
-// UpdateDeduction implements services.AdminStorer.
-func (p *Postgres) UpdateDeduction(slug string, amount float64) (models.Deduction, error) {
-	row := p.Db.QueryRow("UPDATE deductions SET amount = $1 WHERE slug = $2"+
-		" RETURNING id, slug, \"name\", amount, \"minAmount\", \"maxAmount\"",
-		amount, slug)
-	var deduction models.Deduction
-	if err := row.Scan(
-		&deduction.Id, &deduction.Slug,
-		&deduction.Name, &deduction.Amount,
-		&deduction.MinAmount, &deduction.MaxAmount,
-	); err != nil {
-		return deduction, err
+// updateDeduction set amount of deduction when it is still in version,
+// it return utils.ErrVersionMismatch when deduction was changed or is not found.
+func updateDeduction(ctx context.Context, q queryRower, slug string, amount float64, version int) (models.Deduction, error) {
+	row := q.QueryRowContext(ctx, "UPDATE deductions SET amount = $1, version = version + 1 WHERE slug = $2 AND version = $3"+
+		" RETURNING "+deductionColumns,
+		amount, slug, version)
+	deduction, err := scanDeduction(row)
+	if err == sql.ErrNoRows {
+		return deduction, utils.ErrVersionMismatch
 	}
-	return deduction, nil
+	return deduction, err
 }
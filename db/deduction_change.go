@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+const deductionChangeColumns = "id, slug, amount, version, status, \"proposedBy\", \"reviewedBy\", reason, \"createdAt\", \"reviewedAt\""
+
+func scanDeductionChange(row rowScanner) (models.DeductionChange, error) {
+	var v models.DeductionChange
+	var reviewedBy, reason sql.NullString
+	var createdAt time.Time
+	var reviewedAt sql.NullTime
+	if err := row.Scan(&v.Id, &v.Slug, &v.Amount, &v.Version, &v.Status, &v.ProposedBy, &reviewedBy, &reason, &createdAt, &reviewedAt); err != nil {
+		return v, err
+	}
+	v.ReviewedBy = reviewedBy.String
+	v.Reason = reason.String
+	v.CreatedAt = createdAt.Format(time.RFC3339)
+	if reviewedAt.Valid {
+		v.ReviewedAt = reviewedAt.Time.Format(time.RFC3339)
+	}
+	return v, nil
+}
+
+// CreateDeductionChange implements services.AdminStorer.
+func (s *Store) CreateDeductionChange(ctx context.Context, change models.DeductionChange) (models.DeductionChange, error) {
+	ctx, cancel := s.withTimeout(ctx, "CreateDeductionChange")
+	defer cancel()
+	return insertDeductionChange(ctx, s.Db, change)
+}
+
+func insertDeductionChange(ctx context.Context, q queryRower, change models.DeductionChange) (models.DeductionChange, error) {
+	row := q.QueryRowContext(ctx, "INSERT INTO deduction_changes (slug, amount, version, status, \"proposedBy\") VALUES ($1, $2, $3, $4, $5)"+
+		" RETURNING "+deductionChangeColumns,
+		change.Slug, change.Amount, change.Version, change.Status, change.ProposedBy)
+	return scanDeductionChange(row)
+}
+
+// CreateDeductionChanges implements services.AdminStorer.
+func (s *Store) CreateDeductionChanges(ctx context.Context, changes []models.DeductionChange) ([]models.DeductionChange, error) {
+	ctx, cancel := s.withTimeout(ctx, "CreateDeductionChanges")
+	defer cancel()
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	created, err := insertDeductionChanges(ctx, tx, changes)
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+// GetDeductionChanges implements services.AdminStorer.
+func (s *Store) GetDeductionChanges(ctx context.Context, status string) ([]models.DeductionChange, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetDeductionChanges")
+	defer cancel()
+	rows, err := s.Db.QueryContext(ctx, "SELECT "+deductionChangeColumns+" FROM deduction_changes WHERE status = $1 ORDER BY id", status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var changes []models.DeductionChange
+	for rows.Next() {
+		v, err := scanDeductionChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, v)
+	}
+	return changes, nil
+}
+
+// GetDeductionChange implements services.AdminStorer.
+func (s *Store) GetDeductionChange(ctx context.Context, id uint) (models.DeductionChange, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetDeductionChange")
+	defer cancel()
+	row := s.Db.QueryRowContext(ctx, "SELECT "+deductionChangeColumns+" FROM deduction_changes WHERE id = $1", id)
+	return scanDeductionChange(row)
+}
+
+// insertDeductionChanges insert every change with q, it is used with transaction so none is saved when one fail
+func insertDeductionChanges(ctx context.Context, q queryRower, changes []models.DeductionChange) ([]models.DeductionChange, error) {
+	created := make([]models.DeductionChange, 0, len(changes))
+	for _, change := range changes {
+		v, err := insertDeductionChange(ctx, q, change)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, v)
+	}
+	return created, nil
+}
+
+// queryRower is *sql.DB or *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// reviewDeductionChange update only pending change, so it return sql.ErrNoRows when change was already reviewed
+func reviewDeductionChange(ctx context.Context, q queryRower, id uint, status, reviewer, reason string) (models.DeductionChange, error) {
+	row := q.QueryRowContext(ctx, "UPDATE deduction_changes SET status = $2, \"reviewedBy\" = $3, reason = NULLIF($4, ''), \"reviewedAt\" = CURRENT_TIMESTAMP"+
+		" WHERE id = $1 AND status = 'pending' RETURNING "+deductionChangeColumns,
+		id, status, reviewer, reason)
+	return scanDeductionChange(row)
+}
+
+// ApproveDeductionChange implements services.AdminStorer.
+// It return utils.ErrVersionMismatch when deduction was changed after the change was proposed.
+func (s *Store) ApproveDeductionChange(ctx context.Context, id uint, reviewer string) (models.DeductionChange, error) {
+	ctx, cancel := s.withTimeout(ctx, "ApproveDeductionChange")
+	defer cancel()
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.DeductionChange{}, err
+	}
+	defer tx.Rollback()
+	change, err := reviewDeductionChange(ctx, tx, id, models.ApprovedChange, reviewer, "")
+	if err != nil {
+		return change, err
+	}
+	if _, err := updateDeduction(ctx, tx, change.Slug, change.Amount, change.Version); err != nil {
+		return change, err
+	}
+	return change, tx.Commit()
+}
+
+// RejectDeductionChange implements services.AdminStorer.
+func (s *Store) RejectDeductionChange(ctx context.Context, id uint, reviewer, reason string) (models.DeductionChange, error) {
+	ctx, cancel := s.withTimeout(ctx, "RejectDeductionChange")
+	defer cancel()
+	return reviewDeductionChange(ctx, s.Db, id, models.RejectedChange, reviewer, reason)
+}
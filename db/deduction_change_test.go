@@ -0,0 +1,239 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+var deductionChangeRowColumns = []string{"id", "slug", "amount", "version", "status", "proposedBy", "reviewedBy", "reason", "createdAt", "reviewedAt"}
+
+var reviewDeductionChangeQry = regexp.QuoteMeta("UPDATE deduction_changes SET status = $2, \"reviewedBy\" = $3, reason = NULLIF($4, ''), \"reviewedAt\" = CURRENT_TIMESTAMP" +
+	" WHERE id = $1 AND status = 'pending' RETURNING " + deductionChangeColumns)
+
+var createDeductionChangeQry = regexp.QuoteMeta("INSERT INTO deduction_changes (slug, amount, version, status, \"proposedBy\") VALUES ($1, $2, $3, $4, $5)" +
+	" RETURNING " + deductionChangeColumns)
+
+func TestCreateDeductionChange(t *testing.T) {
+	qry := createDeductionChangeQry
+	t.Run("given change should insert pending row", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(qry).
+			WithArgs("personal", 70_000.0, 1, "pending", "editor").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(1, "personal", 70_000.0, 1, "pending", "editor", nil, nil, at, nil))
+
+		got, err := p.CreateDeductionChange(context.Background(), models.DeductionChange{Slug: "personal", Amount: 70_000, Version: 1, Status: "pending", ProposedBy: "editor"})
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		want := models.DeductionChange{Id: 1, Slug: "personal", Amount: 70_000, Version: 1, Status: "pending", ProposedBy: "editor", CreatedAt: "2025-01-15T10:00:00Z"}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("expect %#v but got %#v", want, got)
+		}
+	})
+}
+
+func TestCreateDeductionChanges(t *testing.T) {
+	changes := []models.DeductionChange{
+		{Slug: "personal", Amount: 70_000, Version: 1, Status: "pending", ProposedBy: "editor"},
+		{Slug: "k-receipt", Amount: 60_000, Version: 2, Status: "pending", ProposedBy: "editor"},
+	}
+	at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	t.Run("given changes should insert all of them in transaction", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectQuery(createDeductionChangeQry).WithArgs("personal", 70_000.0, 1, "pending", "editor").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(1, "personal", 70_000.0, 1, "pending", "editor", nil, nil, at, nil))
+		mock.ExpectQuery(createDeductionChangeQry).WithArgs("k-receipt", 60_000.0, 2, "pending", "editor").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(2, "k-receipt", 60_000.0, 2, "pending", "editor", nil, nil, at, nil))
+		mock.ExpectCommit()
+
+		got, err := p.CreateDeductionChanges(context.Background(), changes)
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		want := []models.DeductionChange{
+			{Id: 1, Slug: "personal", Amount: 70_000, Version: 1, Status: "pending", ProposedBy: "editor", CreatedAt: "2025-01-15T10:00:00Z"},
+			{Id: 2, Slug: "k-receipt", Amount: 60_000, Version: 2, Status: "pending", ProposedBy: "editor", CreatedAt: "2025-01-15T10:00:00Z"},
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("expect %#v but got %#v", want, got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("given error on second insert should rollback first insert", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectQuery(createDeductionChangeQry).WithArgs("personal", 70_000.0, 1, "pending", "editor").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(1, "personal", 70_000.0, 1, "pending", "editor", nil, nil, at, nil))
+		mock.ExpectQuery(createDeductionChangeQry).WithArgs("k-receipt", 60_000.0, 2, "pending", "editor").WillReturnError(errors.New("error"))
+		mock.ExpectRollback()
+
+		got, err := p.CreateDeductionChanges(context.Background(), changes)
+
+		if err == nil {
+			t.Error("expect error should not be nil")
+		}
+		if got != nil {
+			t.Errorf("expect no change but got %#v", got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestApproveDeductionChange(t *testing.T) {
+	deductionQry := regexp.QuoteMeta("UPDATE deductions SET amount = $1, version = version + 1 WHERE slug = $2 AND version = $3" +
+		" RETURNING " + deductionColumns)
+	deductionRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "slug", "name", "amount", "minAmount", "maxAmount", "version"}).
+			AddRow(2, "personal", "personalDeduction", 70_000.0, 10_000.0, 100_000.0, 2)
+	}
+	at := time.Date(2025, 1, 16, 10, 0, 0, 0, time.UTC)
+	t.Run("given pending change should approve it and update deduction in transaction", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectQuery(reviewDeductionChangeQry).
+			WithArgs(1, "approved", "approver", "").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(1, "personal", 70_000.0, 1, "approved", "editor", "approver", nil, at, at))
+		mock.ExpectQuery(deductionQry).WithArgs(70_000.0, "personal", 1).WillReturnRows(deductionRow())
+		mock.ExpectCommit()
+
+		got, err := p.ApproveDeductionChange(context.Background(), 1, "approver")
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if got.ReviewedBy != "approver" || got.ReviewedAt != "2025-01-16T10:00:00Z" {
+			t.Errorf("expect reviewed change but got %#v", got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("given change that is not pending should rollback with no row error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectQuery(reviewDeductionChangeQry).WithArgs(1, "approved", "approver", "").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := p.ApproveDeductionChange(context.Background(), 1, "approver")
+
+		if err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("given error on update deduction should rollback", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectQuery(reviewDeductionChangeQry).
+			WithArgs(1, "approved", "approver", "").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(1, "personal", 70_000.0, 1, "approved", "editor", "approver", nil, at, at))
+		mock.ExpectQuery(deductionQry).WithArgs(70_000.0, "personal", 1).WillReturnError(errors.New("error"))
+		mock.ExpectRollback()
+
+		_, err := p.ApproveDeductionChange(context.Background(), 1, "approver")
+
+		if err == nil {
+			t.Error("expect error should not be nil")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("given deduction was changed after proposal should rollback with version mismatch error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectQuery(reviewDeductionChangeQry).
+			WithArgs(1, "approved", "approver", "").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(1, "personal", 70_000.0, 1, "approved", "editor", "approver", nil, at, at))
+		mock.ExpectQuery(deductionQry).WithArgs(70_000.0, "personal", 1).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := p.ApproveDeductionChange(context.Background(), 1, "approver")
+
+		if err != utils.ErrVersionMismatch {
+			t.Errorf("expect %q but got %q", utils.ErrVersionMismatch, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestRejectDeductionChange(t *testing.T) {
+	t.Run("given pending change should reject it with reason", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		at := time.Date(2025, 1, 16, 10, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(reviewDeductionChangeQry).
+			WithArgs(1, "rejected", "approver", "not announced").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(1, "personal", 70_000.0, 1, "rejected", "editor", "approver", "not announced", at, at))
+
+		got, err := p.RejectDeductionChange(context.Background(), 1, "approver", "not announced")
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if got.Status != "rejected" || got.Reason != "not announced" {
+			t.Errorf("expect rejected change but got %#v", got)
+		}
+	})
+}
+
+func TestGetDeductionChanges(t *testing.T) {
+	qry := regexp.QuoteMeta("SELECT " + deductionChangeColumns + " FROM deduction_changes WHERE status = $1 ORDER BY id")
+	t.Run("given status should return changes in that status", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(qry).WithArgs("pending").WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).
+			AddRow(1, "personal", 70_000.0, 1, "pending", "editor", nil, nil, at, nil).
+			AddRow(2, "k-receipt", 80_000.0, 1, "pending", "editor", nil, nil, at, nil))
+
+		got, err := p.GetDeductionChanges(context.Background(), "pending")
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if len(got) != 2 {
+			t.Errorf("expect 2 changes but got %d", len(got))
+		}
+	})
+}
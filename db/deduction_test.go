@@ -4,6 +4,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"reflect"
@@ -22,14 +23,14 @@ func NewMock() (*sql.DB, sqlmock.Sqlmock) {
 }
 
 func TestGetDeductions(t *testing.T) {
-	initMock := func() (p Postgres, mock sqlmock.Sqlmock, qry string, rows *sqlmock.Rows) {
+	initMock := func() (p Store, mock sqlmock.Sqlmock, qry string, rows *sqlmock.Rows) {
 		db, mock := NewMock()
-		p = Postgres{Db: db}
+		p = Store{Db: db}
 
-		qry = "SELECT id, slug, \"name\", amount, \"minAmount\", \"maxAmount\" FROM deductions"
+		qry = "SELECT id, slug, \"name\", amount, \"minAmount\", \"maxAmount\", version FROM deductions"
 
 		rows = sqlmock.
-			NewRows([]string{"id", "slug", "name", "amount", "minAmount", "maxAmount"})
+			NewRows([]string{"id", "slug", "name", "amount", "minAmount", "maxAmount", "version"})
 		return
 	}
 
@@ -38,20 +39,20 @@ func TestGetDeductions(t *testing.T) {
 		defer p.Db.Close()
 
 		rows = rows.
-			AddRow(1, "k-receipt", "kReceipt", 50000, 0, 100000).
-			AddRow(2, "personal", "personalDeduction", 60000, 10000, 100000).
-			AddRow(3, "donation", "Donation", 0, 0, 0)
+			AddRow(1, "k-receipt", "kReceipt", 50000, 0, 100000, 1).
+			AddRow(2, "personal", "personalDeduction", 60000, 10000, 100000, 2).
+			AddRow(3, "donation", "Donation", 0, 0, 0, 1)
 		mock.ExpectQuery(qry).WillReturnRows(rows)
 
-		deductions, err := p.GetDeductions()
+		deductions, err := p.GetDeductions(context.Background())
 
 		if err != nil {
 			t.Errorf("expect no error found but got %q", err)
 		}
 		want := []models.Deduction{
-			{Id: 1, Slug: "k-receipt", Name: "kReceipt", Amount: 50_000, MinAmount: 0, MaxAmount: 100_000},
-			{Id: 2, Slug: "personal", Name: "personalDeduction", Amount: 60_000, MinAmount: 10_000, MaxAmount: 100_000},
-			{Id: 3, Slug: "donation", Name: "Donation", Amount: 0, MinAmount: 0, MaxAmount: 0},
+			{Id: 1, Slug: "k-receipt", Name: "kReceipt", Amount: 50_000, MinAmount: 0, MaxAmount: 100_000, Version: 1},
+			{Id: 2, Slug: "personal", Name: "personalDeduction", Amount: 60_000, MinAmount: 10_000, MaxAmount: 100_000, Version: 2},
+			{Id: 3, Slug: "donation", Name: "Donation", Amount: 0, MinAmount: 0, MaxAmount: 0, Version: 1},
 		}
 		if len(want) != len(deductions) {
 			t.Errorf("expect deductions have %d rows but got %d rows", len(want), len(deductions))
@@ -65,7 +66,7 @@ func TestGetDeductions(t *testing.T) {
 		defer p.Db.Close()
 		mock.ExpectQuery(qry).WillReturnError(sql.ErrNoRows)
 
-		deductions, err := p.GetDeductions()
+		deductions, err := p.GetDeductions(context.Background())
 
 		if err != sql.ErrNoRows {
 			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
@@ -78,10 +79,10 @@ func TestGetDeductions(t *testing.T) {
 	t.Run("given invalid data should return error with null deduction", func(t *testing.T) {
 		p, mock, qry, rows := initMock()
 		defer p.Db.Close()
-		rows = rows.AddRow("1", "slug", "name", "amount", "minAmount", "maxAmount")
+		rows = rows.AddRow("1", "slug", "name", "amount", "minAmount", "maxAmount", "version")
 		mock.ExpectQuery(qry).WillReturnRows(rows)
 
-		deductions, err := p.GetDeductions()
+		deductions, err := p.GetDeductions(context.Background())
 
 		if err == nil {
 			t.Error("expect error is not nill")
@@ -93,14 +94,14 @@ func TestGetDeductions(t *testing.T) {
 }
 
 func TestGetDeduction(t *testing.T) {
-	initMock := func() (p Postgres, mock sqlmock.Sqlmock, qry string, rows *sqlmock.Rows) {
+	initMock := func() (p Store, mock sqlmock.Sqlmock, qry string, rows *sqlmock.Rows) {
 		db, mock := NewMock()
-		p = Postgres{Db: db}
+		p = Store{Db: db}
 
-		qry = "SELECT id, slug, \"name\", amount, \"minAmount\", \"maxAmount\" FROM deductions WHERE slug = \\$1"
+		qry = "SELECT id, slug, \"name\", amount, \"minAmount\", \"maxAmount\", version FROM deductions WHERE slug = \\$1"
 
 		rows = sqlmock.
-			NewRows([]string{"id", "slug", "name", "amount", "minAmount", "maxAmount"})
+			NewRows([]string{"id", "slug", "name", "amount", "minAmount", "maxAmount", "version"})
 		return
 	}
 
@@ -109,11 +110,11 @@ func TestGetDeduction(t *testing.T) {
 		defer p.Db.Close()
 
 		rows = rows.
-			AddRow(2, "personal", "personalDeduction", 60000, 10000, 100000)
+			AddRow(2, "personal", "personalDeduction", 60000, 10000, 100000, 3)
 
 		mock.ExpectQuery(qry).WithArgs("personal").WillReturnRows(rows)
 
-		deductions, err := p.GetDeduction("personal")
+		deductions, err := p.GetDeduction(context.Background(), "personal")
 
 		if err != nil {
 			t.Errorf("expect no error found but got %q", err)
@@ -125,6 +126,7 @@ func TestGetDeduction(t *testing.T) {
 			Amount:    60_000,
 			MinAmount: 10_000,
 			MaxAmount: 100_000,
+			Version:   3,
 		}
 
 		if !reflect.DeepEqual(want, deductions) {
@@ -136,59 +138,7 @@ func TestGetDeduction(t *testing.T) {
 		defer p.Db.Close()
 		mock.ExpectQuery(qry).WithArgs("personal").WillReturnRows(rows)
 
-		_, err := p.GetDeduction("personal")
-
-		if err == nil {
-			t.Errorf("expect error return")
-		}
-	})
-}
-
-func TestUpdateDeduction(t *testing.T) {
-	initMock := func() (p Postgres, mock sqlmock.Sqlmock, qry string, rows *sqlmock.Rows) {
-		db, mock := NewMock()
-		p = Postgres{Db: db}
-
-		qry = "UPDATE deductions SET amount = \\$1 WHERE slug = \\$2" +
-			" RETURNING id, slug, \"name\", amount, \"minAmount\", \"maxAmount\""
-
-		rows = sqlmock.
-			NewRows([]string{"id", "slug", "name", "amount", "minAmount", "maxAmount"})
-		return
-	}
-	t.Run("given success query should return updated deduction", func(t *testing.T) {
-		p, mock, qry, rows := initMock()
-		defer p.Db.Close()
-
-		rows = rows.
-			AddRow(2, "personal", "personalDeduction", 50000, 10000, 100000)
-
-		mock.ExpectQuery(qry).WithArgs(50000.0, "personal").WillReturnRows(rows)
-
-		deductions, err := p.UpdateDeduction("personal", 50000)
-
-		if err != nil {
-			t.Errorf("expect no error found but got %q", err)
-		}
-		want := models.Deduction{
-			Id:        2,
-			Slug:      "personal",
-			Name:      "personalDeduction",
-			Amount:    50_000,
-			MinAmount: 10_000,
-			MaxAmount: 100_000,
-		}
-
-		if !reflect.DeepEqual(want, deductions) {
-			t.Errorf("expect %#v but got %#v", want, deductions)
-		}
-	})
-	t.Run("given error on query should return error", func(t *testing.T) {
-		p, mock, qry, rows := initMock()
-		defer p.Db.Close()
-		mock.ExpectQuery(qry).WithArgs(50000, "personal").WillReturnRows(rows)
-
-		_, err := p.UpdateDeduction("personal", 50000)
+		_, err := p.GetDeduction(context.Background(), "personal")
 
 		if err == nil {
 			t.Errorf("expect error return")
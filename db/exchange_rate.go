@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+// GetExchangeRate implements services.TaxStorer.
+// It return latest rate of currency on or before date.
+func (s *Store) GetExchangeRate(ctx context.Context, currency string, date string) (models.ExchangeRate, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetExchangeRate")
+	defer cancel()
+	row := s.Db.QueryRowContext(ctx, "SELECT id, currency, rate, \"rateDate\" FROM exchange_rates"+
+		" WHERE currency = $1 AND \"rateDate\" <= $2 ORDER BY \"rateDate\" DESC LIMIT 1",
+		currency, date)
+	var rate models.ExchangeRate
+	var rateDate time.Time
+	if err := row.Scan(&rate.Id, &rate.Currency, &rate.Rate, &rateDate); err != nil {
+		return rate, err
+	}
+	rate.RateDate = rateDate.Format(models.DateLayout)
+	return rate, nil
+}
+
+// SaveExchangeRates implements services.ExchangeRateStorer.
+// It insert all rates in one transaction and replace rate of same currency and date.
+func (s *Store) SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) error {
+	ctx, cancel := s.withTimeout(ctx, "SaveExchangeRates")
+	defer cancel()
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, v := range rates {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO exchange_rates (currency, rate, \"rateDate\") VALUES ($1, $2, $3)"+
+			" ON CONFLICT (currency, \"rateDate\") DO UPDATE SET rate = EXCLUDED.rate",
+			v.Currency, v.Rate, v.RateDate); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
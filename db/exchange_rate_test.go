@@ -0,0 +1,95 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestGetExchangeRate(t *testing.T) {
+	qry := "SELECT id, currency, rate, \"rateDate\" FROM exchange_rates" +
+		" WHERE currency = \\$1 AND \"rateDate\" <= \\$2 ORDER BY \"rateDate\" DESC LIMIT 1"
+	t.Run("given success query should return latest rate", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		rows := sqlmock.NewRows([]string{"id", "currency", "rate", "rateDate"}).
+			AddRow(1, "USD", 34.5, time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC))
+		mock.ExpectQuery(qry).WithArgs("USD", "2024-12-31").WillReturnRows(rows)
+
+		rate, err := p.GetExchangeRate(context.Background(), "USD", "2024-12-31")
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		want := models.ExchangeRate{Id: 1, Currency: "USD", Rate: 34.5, RateDate: "2024-12-30"}
+		if !reflect.DeepEqual(want, rate) {
+			t.Errorf("expect %#v but got %#v", want, rate)
+		}
+	})
+	t.Run("given no rate should return no row error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WithArgs("USD", "2024-12-31").WillReturnError(sql.ErrNoRows)
+
+		_, err := p.GetExchangeRate(context.Background(), "USD", "2024-12-31")
+
+		if err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+	})
+}
+
+func TestSaveExchangeRates(t *testing.T) {
+	qry := "INSERT INTO exchange_rates \\(currency, rate, \"rateDate\"\\) VALUES \\(\\$1, \\$2, \\$3\\)" +
+		" ON CONFLICT \\(currency, \"rateDate\"\\) DO UPDATE SET rate = EXCLUDED.rate"
+	rates := []models.ExchangeRate{
+		{Currency: "USD", Rate: 34.5, RateDate: "2024-12-30"},
+		{Currency: "JPY", Rate: 0.22, RateDate: "2024-12-30"},
+	}
+	t.Run("given rates should insert all in one transaction", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectExec(qry).WithArgs("USD", 34.5, "2024-12-30").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(qry).WithArgs("JPY", 0.22, "2024-12-30").WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		err := p.SaveExchangeRates(context.Background(), rates)
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("given error on insert should rollback", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectExec(qry).WithArgs("USD", 34.5, "2024-12-30").WillReturnError(errors.New("error 'xxx' occured"))
+		mock.ExpectRollback()
+
+		err := p.SaveExchangeRates(context.Background(), rates)
+
+		if err == nil {
+			t.Error("expect error return")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+// GetInstallmentConfigs implements services.TaxStorer.
+func (s *Store) GetInstallmentConfigs(ctx context.Context) ([]models.InstallmentConfig, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetInstallmentConfigs")
+	defer cancel()
+	rows, err := s.Db.QueryContext(ctx, "SELECT id, slug, \"name\", amount FROM installment_configs")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var configs []models.InstallmentConfig
+	for rows.Next() {
+		var v models.InstallmentConfig
+		if err := rows.Scan(&v.Id, &v.Slug, &v.Name, &v.Amount); err != nil {
+			return nil, err
+		}
+		configs = append(configs, v)
+	}
+	return configs, nil
+}
@@ -0,0 +1,55 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestGetInstallmentConfigs(t *testing.T) {
+	qry := "SELECT id, slug, \"name\", amount FROM installment_configs"
+	t.Run("given success query should return installment configs data", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		rows := sqlmock.NewRows([]string{"id", "slug", "name", "amount"}).
+			AddRow(1, "installment-threshold", "Installment Threshold", 3000).
+			AddRow(2, "installment-count", "Installment Count", 3)
+		mock.ExpectQuery(qry).WillReturnRows(rows)
+
+		configs, err := p.GetInstallmentConfigs(context.Background())
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		want := []models.InstallmentConfig{
+			{Id: 1, Slug: "installment-threshold", Name: "Installment Threshold", Amount: 3_000},
+			{Id: 2, Slug: "installment-count", Name: "Installment Count", Amount: 3},
+		}
+		if !reflect.DeepEqual(want, configs) {
+			t.Errorf("expect %#v but got %#v", want, configs)
+		}
+	})
+	t.Run("given error on query should return error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WillReturnError(sql.ErrNoRows)
+
+		configs, err := p.GetInstallmentConfigs(context.Background())
+
+		if err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+		if configs != nil {
+			t.Errorf("expect configs should be null, but got %#v", configs)
+		}
+	})
+}
@@ -0,0 +1,263 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationLockId is key of advisory lock that prevent instances from applying same migration together
+const migrationLockId = 20240401
+
+var ErrMigrationInvalid = errors.New("migration file name should be <version>_<name>.up.sql or <version>_<name>.down.sql")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt string
+}
+
+// LoadMigrations read up and down sql of every migration in fsys and sort them by version
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, file := range files {
+		name, direction := strings.TrimSuffix(file, ".sql"), ""
+		switch {
+		case strings.HasSuffix(name, ".up"):
+			name, direction = strings.TrimSuffix(name, ".up"), "up"
+		case strings.HasSuffix(name, ".down"):
+			name, direction = strings.TrimSuffix(name, ".down"), "down"
+		default:
+			return nil, fmt.Errorf("%s: %w", file, ErrMigrationInvalid)
+		}
+		versionText, label, ok := strings.Cut(name, "_")
+		version, err := strconv.Atoi(versionText)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("%s: %w", file, ErrMigrationInvalid)
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		}
+		if m.Name != label {
+			return nil, fmt.Errorf("%s: name should be %q as other file of version %d", file, m.Name, version)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d: up sql is missing", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// lockMigration hold advisory lock until tx is done, so only one instance create migration table or apply migration at a time,
+// sqlite has no advisory lock, its database is locked by the first write of transaction instead
+func (s *Store) lockMigration(tx *sql.Tx) error {
+	if s.isSqlite() {
+		return nil
+	}
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", migrationLockId)
+	return err
+}
+
+// ensureMigrationTable create migration table under migration lock, concurrent CREATE TABLE IF NOT EXISTS can fail on postgres
+func (s *Store) ensureMigrationTable() error {
+	appliedAt := "\"appliedAt\" TIMESTAMPTZ NOT NULL DEFAULT now()"
+	if s.isSqlite() {
+		appliedAt = "\"appliedAt\" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP"
+	}
+	tx, err := s.Db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := s.lockMigration(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (" +
+		"version INTEGER NOT NULL PRIMARY KEY, \"name\" VARCHAR NOT NULL, " + appliedAt + ")"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AppliedMigrations return version of migrations that was applied
+func (s *Store) AppliedMigrations() (map[int]string, error) {
+	if err := s.ensureMigrationTable(); err != nil {
+		return nil, err
+	}
+	rows, err := s.Db.Query("SELECT version, \"appliedAt\" FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[int]string{}
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at.Format(time.RFC3339)
+	}
+	return applied, rows.Err()
+}
+
+// migrateOne run sql of migration and record it in one transaction, it is skipped when other instance did it first
+func (s *Store) migrateOne(m Migration, up bool) (bool, error) {
+	tx, err := s.Db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	if err := s.lockMigration(tx); err != nil {
+		return false, err
+	}
+	var applied bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", m.Version).Scan(&applied); err != nil {
+		return false, err
+	}
+	if applied == up {
+		return false, nil
+	}
+
+	if up {
+		if _, err := tx.Exec(m.Up); err != nil {
+			return false, fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		_, err = tx.Exec("INSERT INTO schema_migrations (version, \"name\") VALUES ($1, $2)", m.Version, m.Name)
+	} else {
+		if _, err := tx.Exec(m.Down); err != nil {
+			return false, fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		_, err = tx.Exec("DELETE FROM schema_migrations WHERE version = $1", m.Version)
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// MigrateUp apply every migration that is not applied yet in version order and return applied versions
+func (s *Store) MigrateUp(migrations []Migration) ([]int, error) {
+	if err := s.ensureMigrationTable(); err != nil {
+		return nil, err
+	}
+	var done []int
+	for _, m := range migrations {
+		ok, err := s.migrateOne(m, true)
+		if err != nil {
+			return done, err
+		}
+		if ok {
+			done = append(done, m.Version)
+		}
+	}
+	return done, nil
+}
+
+// MigrateDown roll back latest steps applied migrations and return rolled back versions
+func (s *Store) MigrateDown(migrations []Migration, steps int) ([]int, error) {
+	applied, err := s.AppliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var done []int
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return done, fmt.Errorf("migration %d_%s: down sql is missing", m.Version, m.Name)
+		}
+		ok, err := s.migrateOne(m, false)
+		if err != nil {
+			return done, err
+		}
+		if ok {
+			done = append(done, m.Version)
+		}
+	}
+	return done, nil
+}
+
+// MigrationStatuses return every migration with time that it was applied, applied at is empty for pending one
+func (s *Store) MigrationStatuses(migrations []Migration) ([]MigrationStatus, error) {
+	applied, err := s.AppliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		statuses = append(statuses, MigrationStatus{Version: m.Version, Name: m.Name, AppliedAt: applied[m.Version]})
+	}
+	return statuses, nil
+}
+
+// PendingMigrations return version of embedded migrations of database driver that are not applied yet,
+// it does not create migration table so database that was never migrated return error
+func (s *Store) PendingMigrations(ctx context.Context) ([]int, error) {
+	migrations, err := s.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.withTimeout(ctx, "PendingMigrations")
+	defer cancel()
+	rows, err := s.Db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var pending []int
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m.Version)
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,252 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/baronight/assessment-tax/migrations"
+	sqlitemigrations "github.com/baronight/assessment-tax/migrations/sqlite"
+)
+
+var (
+	ensureMigrationTableQry = regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")
+	migrationLockQry        = regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")
+	migrationAppliedQry     = regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)")
+	appliedMigrationsQry    = regexp.QuoteMeta("SELECT version, \"appliedAt\" FROM schema_migrations")
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("given up and down files should return migrations sorted by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"000002_add_index.up.sql":   {Data: []byte("CREATE INDEX")},
+			"000002_add_index.down.sql": {Data: []byte("DROP INDEX")},
+			"000001_init.up.sql":        {Data: []byte("CREATE TABLE")},
+		}
+
+		got, err := LoadMigrations(fsys)
+
+		if err != nil {
+			t.Fatalf("expect no error found but got %q", err)
+		}
+		if len(got) != 2 || got[0].Version != 1 || got[1].Version != 2 {
+			t.Fatalf("expect migration 1 and 2 but got %#v", got)
+		}
+		if got[1].Name != "add_index" || got[1].Up != "CREATE INDEX" || got[1].Down != "DROP INDEX" {
+			t.Errorf("expect add_index migration with up and down sql but got %#v", got[1])
+		}
+	})
+	testSuites := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"given file without direction should return error", fstest.MapFS{"000001_init.sql": {}}},
+		{"given file without version should return error", fstest.MapFS{"init.up.sql": {}}},
+		{"given only down file should return error", fstest.MapFS{"000001_init.down.sql": {Data: []byte("DROP TABLE")}}},
+		{"given different name on same version should return error", fstest.MapFS{
+			"000001_init.up.sql":  {Data: []byte("CREATE TABLE")},
+			"000001_other.up.sql": {Data: []byte("CREATE TABLE")},
+		}},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := LoadMigrations(tc.fsys); err == nil {
+				t.Error("expect error should not be nil")
+			}
+		})
+	}
+	t.Run("embedded migrations of postgres and sqlite should have same versions", func(t *testing.T) {
+		pg, err := LoadMigrations(migrations.FS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lite, err := LoadMigrations(sqlitemigrations.FS)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(pg) != len(lite) {
+			t.Fatalf("expect %d sqlite migrations but got %d", len(pg), len(lite))
+		}
+		for i := range pg {
+			if pg[i].Version != lite[i].Version || pg[i].Name != lite[i].Name {
+				t.Errorf("expect sqlite migration %d_%s but got %d_%s", pg[i].Version, pg[i].Name, lite[i].Version, lite[i].Name)
+			}
+		}
+	})
+	t.Run("embedded migrations should have up and down sql", func(t *testing.T) {
+		got, err := LoadMigrations(migrations.FS)
+
+		if err != nil {
+			t.Fatalf("expect no error found but got %q", err)
+		}
+		for _, m := range got {
+			if m.Down == "" {
+				t.Errorf("expect migration %d has down sql", m.Version)
+			}
+		}
+	})
+}
+
+// expectEnsureMigrationTable expect migration table is created after migration lock is taken
+func expectEnsureMigrationTable(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectExec(migrationLockQry).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(ensureMigrationTableQry).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+}
+
+func TestMigrateUp(t *testing.T) {
+	ms := []Migration{
+		{Version: 1, Name: "init", Up: "CREATE TABLE a (id INT)", Down: "DROP TABLE a"},
+		{Version: 2, Name: "add_b", Up: "CREATE TABLE b (id INT)", Down: "DROP TABLE b"},
+	}
+	t.Run("given pending migration should apply it and skip applied one", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		expectEnsureMigrationTable(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec(migrationLockQry).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(migrationAppliedQry).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec(migrationLockQry).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(migrationAppliedQry).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE b (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, \"name\") VALUES ($1, $2)")).
+			WithArgs(2, "add_b").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		got, err := p.MigrateUp(ms)
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if len(got) != 1 || got[0] != 2 {
+			t.Errorf("expect only migration 2 was applied but got %v", got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("given error on migration sql should rollback and stop", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		expectEnsureMigrationTable(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec(migrationLockQry).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(migrationAppliedQry).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE a (id INT)")).WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+
+		got, err := p.MigrateUp(ms)
+
+		if err == nil {
+			t.Error("expect error should not be nil")
+		}
+		if len(got) != 0 {
+			t.Errorf("expect no migration was applied but got %v", got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestMigrateDown(t *testing.T) {
+	ms := []Migration{
+		{Version: 1, Name: "init", Up: "CREATE TABLE a (id INT)", Down: "DROP TABLE a"},
+		{Version: 2, Name: "add_b", Up: "CREATE TABLE b (id INT)", Down: "DROP TABLE b"},
+	}
+	t.Run("given one step should roll back only latest applied migration", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+		expectEnsureMigrationTable(mock)
+		mock.ExpectQuery(appliedMigrationsQry).WillReturnRows(sqlmock.NewRows([]string{"version", "appliedAt"}).AddRow(1, at).AddRow(2, at))
+		mock.ExpectBegin()
+		mock.ExpectExec(migrationLockQry).WithArgs(migrationLockId).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(migrationAppliedQry).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(regexp.QuoteMeta("DROP TABLE b")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = $1")).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		got, err := p.MigrateDown(ms, 1)
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if len(got) != 1 || got[0] != 2 {
+			t.Errorf("expect migration 2 was rolled back but got %v", got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestMigrationStatuses(t *testing.T) {
+	t.Run("should return applied time of applied migration and empty for pending one", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+		expectEnsureMigrationTable(mock)
+		mock.ExpectQuery(appliedMigrationsQry).WillReturnRows(sqlmock.NewRows([]string{"version", "appliedAt"}).AddRow(1, at))
+
+		got, err := p.MigrationStatuses([]Migration{{Version: 1, Name: "init"}, {Version: 2, Name: "add_b"}})
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		want := []MigrationStatus{{Version: 1, Name: "init", AppliedAt: "2025-01-15T10:00:00Z"}, {Version: 2, Name: "add_b"}}
+		if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("expect %#v but got %#v", want, got)
+		}
+	})
+}
+
+func TestPendingMigrations(t *testing.T) {
+	qry := regexp.QuoteMeta("SELECT version FROM schema_migrations")
+	t.Run("given first migration is applied should return the others", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+
+		migrations, _ := p.Migrations()
+		var want []int
+		for _, m := range migrations[1:] {
+			want = append(want, m.Version)
+		}
+
+		got, err := p.PendingMigrations(context.Background())
+
+		if err != nil || len(want) == 0 || !reflect.DeepEqual(want, got) {
+			t.Errorf("expect migrations %v are pending but got %v, %v", want, got, err)
+		}
+	})
+	t.Run("given database was never migrated should return error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WillReturnError(errors.New(`relation "schema_migrations" does not exist`))
+
+		_, err := p.PendingMigrations(context.Background())
+
+		if err == nil {
+			t.Error("expect error was not nil")
+		}
+	})
+}
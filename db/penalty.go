@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+// GetPenalties implements services.TaxStorer.
+func (s *Store) GetPenalties(ctx context.Context) ([]models.Penalty, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetPenalties")
+	defer cancel()
+	rows, err := s.Db.QueryContext(ctx, "SELECT id, slug, \"name\", amount FROM penalties")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var penalties []models.Penalty
+	for rows.Next() {
+		var v models.Penalty
+		if err := rows.Scan(&v.Id, &v.Slug, &v.Name, &v.Amount); err != nil {
+			return nil, err
+		}
+		penalties = append(penalties, v)
+	}
+	return penalties, nil
+}
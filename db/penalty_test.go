@@ -0,0 +1,55 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestGetPenalties(t *testing.T) {
+	qry := "SELECT id, slug, \"name\", amount FROM penalties"
+	t.Run("given success query should return penalties data", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		rows := sqlmock.NewRows([]string{"id", "slug", "name", "amount"}).
+			AddRow(1, "surcharge", "Surcharge", 1.5).
+			AddRow(2, "late-filing", "Late Filing Penalty", 200)
+		mock.ExpectQuery(qry).WillReturnRows(rows)
+
+		penalties, err := p.GetPenalties(context.Background())
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		want := []models.Penalty{
+			{Id: 1, Slug: "surcharge", Name: "Surcharge", Amount: 1.5},
+			{Id: 2, Slug: "late-filing", Name: "Late Filing Penalty", Amount: 200},
+		}
+		if !reflect.DeepEqual(want, penalties) {
+			t.Errorf("expect %#v but got %#v", want, penalties)
+		}
+	})
+	t.Run("given error on query should return error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WillReturnError(sql.ErrNoRows)
+
+		penalties, err := p.GetPenalties(context.Background())
+
+		if err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+		if penalties != nil {
+			t.Errorf("expect penalties should be null, but got %#v", penalties)
+		}
+	})
+}
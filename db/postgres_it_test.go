@@ -0,0 +1,102 @@
+//go:build integration
+// +build integration
+
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/baronight/assessment-tax/config"
+	"github.com/baronight/assessment-tax/models"
+)
+
+// baselineSchema is init.sql that created database before versioned migrations
+const baselineSchema = `
+CREATE TABLE IF NOT EXISTS deductions (
+  id SERIAL NOT NULL,
+  slug VARCHAR NOT NULL,
+	"name" VARCHAR NOT NULL,
+  amount DECIMAL(10,2) NOT NULL,
+  "minAmount" DECIMAL(10,2) NOT NULL DEFAULT 0,
+  "maxAmount" DECIMAL(10,2) NOT NULL DEFAULT 0,
+	CONSTRAINT deductions_pk PRIMARY KEY (id),
+	CONSTRAINT deductions_slug_unique UNIQUE (slug)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS 
+  deductions_slug_idx 
+ON deductions (slug);
+
+INSERT INTO 
+  deductions (slug, "name", amount, "minAmount", "maxAmount")
+VALUES
+  ('k-receipt', 'kReceipt', 50000, 0, 100000),
+  ('personal','personalDeduction', 60000, 10000, 100000),
+  ('donation', 'Donation', 100000, 0, 100000);
+`
+
+// openPostgres return TEST_DATABASE_URL database after roll back and apply every migration again,
+// so it wipe every data of that database.
+func openPostgres(t *testing.T) *Store {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	p, err := Open(config.Database{Driver: config.DriverPostgres, URL: url})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { p.Db.Close() })
+	ms, err := p.Migrations()
+	if err == nil {
+		_, err = p.MigrateDown(ms, len(ms))
+	}
+	if err == nil {
+		_, err = p.MigrateUp(ms)
+	}
+	if err != nil {
+		t.Fatalf("expect postgres database was migrated again but got %q", err)
+	}
+	return p
+}
+
+func TestPostgresStorer(t *testing.T) {
+	testStorer(t, openPostgres)
+}
+
+func TestPostgresMigrationFromBaseline(t *testing.T) {
+	ctx := context.Background()
+	p := openPostgres(t)
+	ms, err := p.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.MigrateDown(ms, len(ms)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Db.Exec("DROP TABLE schema_migrations"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Db.Exec(baselineSchema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Db.Exec("UPDATE deductions SET amount = 70000 WHERE slug = 'k-receipt'"); err != nil {
+		t.Fatal(err)
+	}
+
+	up, err := p.MigrateUp(ms)
+	if err != nil || len(up) != len(ms) {
+		t.Fatalf("expect every migration was applied on baseline schema but got %v, %v", up, err)
+	}
+
+	got, err := p.GetDeduction(ctx, models.KReceiptSlug)
+	if err != nil || got.Amount != 70_000 || got.Version != 1 {
+		t.Errorf("expect baseline row was kept with version 1 but got %#v, %v", got, err)
+	}
+	if got, _ := p.GetDeductions(ctx); len(got) != 6 {
+		t.Errorf("expect family deductions were added to baseline rows but got %#v", got)
+	}
+}
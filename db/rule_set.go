@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+const ruleSetColumns = "id, content, active, \"createdAt\""
+
+func scanRuleSet(row rowScanner) (models.RuleSet, error) {
+	var v models.RuleSet
+	var id uint
+	var content []byte
+	var active bool
+	var createdAt time.Time
+	if err := row.Scan(&id, &content, &active, &createdAt); err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(content, &v); err != nil {
+		return v, err
+	}
+	v.Id = id
+	v.Active = active
+	v.CreatedAt = createdAt.Format(time.RFC3339)
+	return v, nil
+}
+
+// CreateRuleSet implements services.RuleSetStorer.
+// It keep whole rule set as json content, name and tax year are copied to column for listing.
+func (s *Store) CreateRuleSet(ctx context.Context, ruleSet models.RuleSet) (models.RuleSet, error) {
+	ctx, cancel := s.withTimeout(ctx, "CreateRuleSet")
+	defer cancel()
+	content, err := json.Marshal(ruleSet)
+	if err != nil {
+		return ruleSet, err
+	}
+	row := s.Db.QueryRowContext(ctx, "INSERT INTO rule_sets (\"name\", \"taxYear\", content) VALUES ($1, $2, $3) RETURNING "+ruleSetColumns,
+		ruleSet.Name, ruleSet.TaxYear, content)
+	return scanRuleSet(row)
+}
+
+// GetRuleSets implements services.RuleSetStorer.
+func (s *Store) GetRuleSets(ctx context.Context) ([]models.RuleSet, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetRuleSets")
+	defer cancel()
+	rows, err := s.Db.QueryContext(ctx, "SELECT "+ruleSetColumns+" FROM rule_sets ORDER BY id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ruleSets []models.RuleSet
+	for rows.Next() {
+		v, err := scanRuleSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		ruleSets = append(ruleSets, v)
+	}
+	return ruleSets, nil
+}
+
+// GetRuleSet implements services.RuleSetStorer.
+func (s *Store) GetRuleSet(ctx context.Context, id uint) (models.RuleSet, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetRuleSet")
+	defer cancel()
+	row := s.Db.QueryRowContext(ctx, "SELECT "+ruleSetColumns+" FROM rule_sets WHERE id = $1", id)
+	return scanRuleSet(row)
+}
+
+// GetActiveRuleSet implements services.RuleSetStorer.
+func (s *Store) GetActiveRuleSet(ctx context.Context) (models.RuleSet, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetActiveRuleSet")
+	defer cancel()
+	row := s.Db.QueryRowContext(ctx, "SELECT "+ruleSetColumns+" FROM rule_sets WHERE active LIMIT 1")
+	return scanRuleSet(row)
+}
+
+// ActivateRuleSet implements services.RuleSetStorer.
+// It mark rule set as the only active one and insert pending changes in the same transaction,
+// deduction amounts are changed by approval of deduction changes.
+func (s *Store) ActivateRuleSet(ctx context.Context, ruleSet models.RuleSet, changes []models.DeductionChange) ([]models.DeductionChange, error) {
+	ctx, cancel := s.withTimeout(ctx, "ActivateRuleSet")
+	defer cancel()
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "UPDATE rule_sets SET active = (id = $1)", ruleSet.Id); err != nil {
+		return nil, err
+	}
+	created, err := insertDeductionChanges(ctx, tx, changes)
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
@@ -0,0 +1,170 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/baronight/assessment-tax/models"
+)
+
+var ruleSetRowColumns = []string{"id", "content", "active", "createdAt"}
+
+func ruleSetRow(rows *sqlmock.Rows, id uint, active bool) *sqlmock.Rows {
+	return rows.AddRow(id,
+		[]byte(`{"name":"default","taxYear":2567,"brackets":[{"minIncome":0,"maxIncome":0,"rate":0.1}],"deductions":[{"slug":"personal","type":"fixed","amount":60000}]}`),
+		active, time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+}
+
+func wantRuleSet(id uint, active bool) models.RuleSet {
+	return models.RuleSet{
+		Id:         id,
+		Name:       "default",
+		TaxYear:    2567,
+		Brackets:   []models.TaxStep{{MinIncome: 0, MaxIncome: 0, Rate: 0.1}},
+		Deductions: []models.RuleDeduction{{Slug: "personal", Type: "fixed", Amount: 60_000}},
+		Active:     active,
+		CreatedAt:  "2025-01-15T10:00:00Z",
+	}
+}
+
+func TestCreateRuleSet(t *testing.T) {
+	qry := regexp.QuoteMeta("INSERT INTO rule_sets (\"name\", \"taxYear\", content) VALUES ($1, $2, $3) RETURNING " + ruleSetColumns)
+	t.Run("given rule set should insert json content and return saved row", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).
+			WithArgs("default", 2567, sqlmock.AnyArg()).
+			WillReturnRows(ruleSetRow(sqlmock.NewRows(ruleSetRowColumns), 1, false))
+
+		got, err := p.CreateRuleSet(context.Background(), models.RuleSet{Name: "default", TaxYear: 2567})
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if want := wantRuleSet(1, false); !reflect.DeepEqual(want, got) {
+			t.Errorf("expect %#v but got %#v", want, got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestGetRuleSets(t *testing.T) {
+	qry := regexp.QuoteMeta("SELECT " + ruleSetColumns + " FROM rule_sets ORDER BY id DESC")
+	t.Run("should return all rows", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		rows := sqlmock.NewRows(ruleSetRowColumns)
+		ruleSetRow(rows, 2, true)
+		ruleSetRow(rows, 1, false)
+		mock.ExpectQuery(qry).WillReturnRows(rows)
+
+		got, err := p.GetRuleSets(context.Background())
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if want := []models.RuleSet{wantRuleSet(2, true), wantRuleSet(1, false)}; !reflect.DeepEqual(want, got) {
+			t.Errorf("expect %#v but got %#v", want, got)
+		}
+	})
+}
+
+func TestGetActiveRuleSet(t *testing.T) {
+	qry := regexp.QuoteMeta("SELECT " + ruleSetColumns + " FROM rule_sets WHERE active LIMIT 1")
+	t.Run("given no active rule set should return no row error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WillReturnError(sql.ErrNoRows)
+
+		_, err := p.GetActiveRuleSet(context.Background())
+
+		if err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+	})
+}
+
+func TestActivateRuleSet(t *testing.T) {
+	activateQry := regexp.QuoteMeta("UPDATE rule_sets SET active = (id = $1)")
+	changeQry := createDeductionChangeQry
+	changes := []models.DeductionChange{
+		{Slug: "personal", Amount: 70_000, Version: 1, Status: "pending", ProposedBy: "editor"},
+		{Slug: "donation", Amount: 80_000, Version: 2, Status: "pending", ProposedBy: "editor"},
+	}
+	at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	t.Run("given rule set should activate it and propose changes in one transaction", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectExec(activateQry).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery(changeQry).WithArgs("personal", 70_000.0, 1, "pending", "editor").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(1, "personal", 70_000.0, 1, "pending", "editor", nil, nil, at, nil))
+		mock.ExpectQuery(changeQry).WithArgs("donation", 80_000.0, 2, "pending", "editor").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(2, "donation", 80_000.0, 2, "pending", "editor", nil, nil, at, nil))
+		mock.ExpectCommit()
+
+		got, err := p.ActivateRuleSet(context.Background(), wantRuleSet(1, false), changes)
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if len(got) != 2 || got[0].Id != 1 || got[1].Id != 2 {
+			t.Errorf("expect 2 pending changes but got %#v", got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("given error on update should rollback", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectExec(activateQry).WithArgs(1).WillReturnError(errors.New("error"))
+		mock.ExpectRollback()
+
+		_, err := p.ActivateRuleSet(context.Background(), wantRuleSet(1, false), changes)
+
+		if err == nil {
+			t.Error("expect error should not be nil")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("given error on propose change should rollback activation", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectBegin()
+		mock.ExpectExec(activateQry).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery(changeQry).WithArgs("personal", 70_000.0, 1, "pending", "editor").
+			WillReturnRows(sqlmock.NewRows(deductionChangeRowColumns).AddRow(1, "personal", 70_000.0, 1, "pending", "editor", nil, nil, at, nil))
+		mock.ExpectQuery(changeQry).WithArgs("donation", 80_000.0, 2, "pending", "editor").WillReturnError(errors.New("error"))
+		mock.ExpectRollback()
+
+		_, err := p.ActivateRuleSet(context.Background(), wantRuleSet(1, false), changes)
+
+		if err == nil {
+			t.Error("expect error should not be nil")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/baronight/assessment-tax/config"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+// openSqlite return in memory sqlite database that is migrated by New
+func openSqlite(t *testing.T) *Store {
+	t.Helper()
+	p, err := New(config.Database{Driver: config.DriverSqlite, URL: ":memory:"})
+	if err != nil {
+		t.Fatalf("expect sqlite database was migrated but got %q", err)
+	}
+	t.Cleanup(func() { p.Db.Close() })
+	return p
+}
+
+func TestSqliteStorer(t *testing.T) {
+	testStorer(t, openSqlite)
+}
+
+func TestSqliteMigration(t *testing.T) {
+	p := openSqlite(t)
+	ms, err := p.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	down, err := p.MigrateDown(ms, len(ms))
+	if err != nil || len(down) != len(ms) {
+		t.Errorf("expect every migration was rolled back but got %v, %v", down, err)
+	}
+	up, err := p.MigrateUp(ms)
+	if err != nil || len(up) != len(ms) {
+		t.Errorf("expect every migration was applied again but got %v, %v", up, err)
+	}
+	statuses, err := p.MigrationStatuses(ms)
+	if err != nil || len(statuses) != len(ms) || statuses[0].AppliedAt == "" {
+		t.Errorf("expect applied statuses but got %#v, %v", statuses, err)
+	}
+}
+
+func TestSqliteMigrationFromBaseline(t *testing.T) {
+	ctx := context.Background()
+	p, err := Open(config.Database{Driver: config.DriverSqlite, URL: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { p.Db.Close() })
+	ms, err := p.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.MigrateUp(ms[:1]); err != nil {
+		t.Fatalf("expect baseline migration was applied but got %q", err)
+	}
+	if _, err := p.Db.Exec("UPDATE deductions SET amount = 70000 WHERE slug = 'k-receipt'"); err != nil {
+		t.Fatal(err)
+	}
+
+	up, err := p.MigrateUp(ms)
+	if err != nil || len(up) != len(ms)-1 {
+		t.Fatalf("expect every migration after baseline was applied but got %v, %v", up, err)
+	}
+
+	got, err := p.GetDeduction(ctx, models.KReceiptSlug)
+	if err != nil || got.Amount != 70_000 || got.Version != 1 {
+		t.Errorf("expect baseline row was kept with version 1 but got %#v, %v", got, err)
+	}
+	if got, _ := p.GetDeductions(ctx); len(got) != 6 {
+		t.Errorf("expect family deductions were added to baseline rows but got %#v", got)
+	}
+}
+
+func TestSqliteFamilyDeductionsMigration(t *testing.T) {
+	ctx := context.Background()
+	p := openSqlite(t)
+	ms, err := p.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// migrations after family deductions are rolled back with it
+	steps := len(ms) - slices.IndexFunc(ms, func(m Migration) bool { return m.Name == "rule_sets_family_deductions" })
+	if _, err := p.MigrateDown(ms, steps); err != nil {
+		t.Fatal(err)
+	}
+	old, _ := p.CreateRuleSet(ctx, models.RuleSet{Name: "2567", TaxYear: 2567,
+		Deductions: []models.RuleDeduction{{Slug: models.PersonalSlug, Type: models.FixedRuleType, Amount: 60_000}}})
+	empty, _ := p.CreateRuleSet(ctx, models.RuleSet{Name: "empty", TaxYear: 2567})
+	taxReturnId := insertTaxReturn(t, p, 2567, 1, `{"name":"2567","brackets":[{"rate":0.1}]}`)
+
+	if _, err := p.MigrateUp(ms); err != nil {
+		t.Fatalf("expect migration was applied but got %q", err)
+	}
+
+	family := []models.RuleDeduction{
+		{Slug: models.SpouseSlug, Type: models.FamilyRuleType, Member: models.SpouseMember, Amount: 60_000, HalfOnHalfYear: true},
+		{Slug: models.ChildSlug, Type: models.FamilyRuleType, Member: models.ChildMember, Amount: 30_000, HalfOnHalfYear: true},
+		{Slug: models.ParentSlug, Type: models.FamilyRuleType, Member: models.ParentMember, Amount: 30_000, HalfOnHalfYear: true},
+	}
+	got, _ := p.GetRuleSet(ctx, old.Id)
+	if want := append(old.Deductions, family...); !reflect.DeepEqual(want, got.Deductions) {
+		t.Errorf("expect family deductions were added after %#v but got %#v", old.Deductions, got.Deductions)
+	}
+	if got, _ := p.GetRuleSet(ctx, empty.Id); !reflect.DeepEqual(family, got.Deductions) {
+		t.Errorf("expect family deductions were added to rule set without deductions but got %#v", got.Deductions)
+	}
+	if got, _ := p.GetTaxReturn(ctx, taxReturnId); len(got.RuleSet.Deductions) != 0 {
+		t.Errorf("expect rule set snapshot of tax return was kept as it was saved but got %#v", got.RuleSet.Deductions)
+	}
+
+	if _, err := p.MigrateDown(ms, steps); err != nil {
+		t.Fatalf("expect migration was rolled back but got %q", err)
+	}
+	if got, _ := p.GetRuleSet(ctx, old.Id); !reflect.DeepEqual(old.Deductions, got.Deductions) {
+		t.Errorf("expect family deductions were removed but got %#v", got.Deductions)
+	}
+}
+
+func TestSqliteTaxReturnRevisionMigration(t *testing.T) {
+	ctx := context.Background()
+	p := openSqlite(t)
+	ms, err := p.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := len(ms) - slices.IndexFunc(ms, func(m Migration) bool { return m.Name == "tax_returns_revision_unique" })
+	if _, err := p.MigrateDown(ms, steps); err != nil {
+		t.Fatal(err)
+	}
+	var ids []uint
+	for _, revision := range []int{1, 1, 2} {
+		ids = append(ids, insertTaxReturn(t, p, 2567, revision, "{}"))
+	}
+	otherId := insertTaxReturn(t, p, 2566, 1, "{}")
+
+	if _, err := p.MigrateUp(ms); err != nil {
+		t.Fatalf("expect migration was applied but got %q", err)
+	}
+
+	for i, id := range ids {
+		if got, _ := p.GetTaxReturn(ctx, id); got.Revision != i+1 {
+			t.Errorf("expect return %d was numbered as revision %d but got %d", id, i+1, got.Revision)
+		}
+	}
+	if got, _ := p.GetTaxReturn(ctx, otherId); got.Revision != 1 {
+		t.Errorf("expect return without duplicate keep its revision but got %d", got.Revision)
+	}
+	if _, err := p.CreateTaxReturn(ctx, models.TaxReturn{TaxpayerId: "1234567890121", TaxYear: 2567, Revision: 1}); err != utils.ErrTaxReturnExists {
+		t.Errorf("expect %q but got %q", utils.ErrTaxReturnExists, err)
+	}
+}
+
+// insertTaxReturn insert row with columns of tax returns before snapshot of request was kept,
+// so it can be used on schema that is rolled back to earlier version
+func insertTaxReturn(t *testing.T, p *Store, taxYear, revision int, ruleSet string) uint {
+	t.Helper()
+	var id uint
+	err := p.Db.QueryRow("INSERT INTO tax_returns (\"taxpayerId\", \"taxYear\", revision, request, response, deductions, \"ruleSet\")"+
+		" VALUES ('1234567890121', $1, $2, '{}', '{}', '[]', $3) RETURNING id", taxYear, revision, ruleSet).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
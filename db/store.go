@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/baronight/assessment-tax/config"
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/baronight/assessment-tax/migrations"
+	sqlitemigrations "github.com/baronight/assessment-tax/migrations/sqlite"
+	"github.com/baronight/assessment-tax/models"
+	_ "github.com/lib/pq"
+)
+
+// maxConnectBackoff limit backoff between connect retries that is doubled after every retry
+const maxConnectBackoff = 30 * time.Second
+
+// sleep wait between connect retries, it is replaced in tests
+var sleep = time.Sleep
+
+// Store is storer of sql database of Driver, it serve postgres and sqlite
+// since its queries are written in sql that both of them support.
+type Store struct {
+	Db *sql.DB
+	// QueryTimeout limit every query, no limit other than context of caller when it is 0
+	QueryTimeout time.Duration
+	// Driver is config.DriverPostgres when it is empty
+	Driver string
+}
+
+func (s *Store) isSqlite() bool {
+	return s.Driver == config.DriverSqlite
+}
+
+// Migrations return embedded migrations of database driver
+func (s *Store) Migrations() ([]Migration, error) {
+	if s.isSqlite() {
+		return LoadMigrations(sqlitemigrations.FS)
+	}
+	return LoadMigrations(migrations.FS)
+}
+
+// withTimeout return ctx that is cancelled when caller cancel it or query timeout is passed,
+// its cancel also record latency of storer method since withTimeout is called
+func (s *Store) withTimeout(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	start := time.Now()
+	var cancel context.CancelFunc
+	if s.QueryTimeout <= 0 {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, s.QueryTimeout)
+	}
+	return ctx, func() {
+		cancel()
+		metrics.ObserveQuery(method, start)
+	}
+}
+
+// New connect to database and apply migrations that are not applied yet
+func New(cfg config.Database) (*Store, error) {
+	s, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ms, err := s.Migrations()
+	if err == nil {
+		_, err = s.MigrateUp(ms)
+	}
+	if err != nil {
+		defer s.Db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Open connect to database without applying migration, it retry when database is not ready yet
+// e.g. postgres container is still starting
+func Open(cfg config.Database) (*Store, error) {
+	db, err := sql.Open(cfg.Driver, cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	if cfg.Driver == config.DriverSqlite {
+		// sqlite allow one writer at a time, and every connection of :memory: is a new database
+		// so the only connection is never closed
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+	}
+	if err := ping(db, cfg.ConnectRetries, cfg.ConnectBackoff); err != nil {
+		defer db.Close()
+		return nil, err
+	}
+	return &Store{Db: db, QueryTimeout: cfg.QueryTimeout, Driver: cfg.Driver}, nil
+}
+
+// ping check connection to database, it ping again up to retries times with backoff that is doubled after every retry
+func ping(db *sql.DB, retries int, backoff time.Duration) error {
+	err := db.Ping()
+	for retry := 1; err != nil && retry <= retries; retry++ {
+		log.Printf("database is not ready (%v), retry %d/%d in %s", err, retry, retries, backoff)
+		sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+		err = db.Ping()
+	}
+	return err
+}
+
+// Ping check that database is reachable
+func (s *Store) Ping(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx, "Ping")
+	defer cancel()
+	return s.Db.PingContext(ctx)
+}
+
+// PoolStats return connection pool statistics of database
+func (s *Store) PoolStats() models.PoolStats {
+	stats := s.Db.Stats()
+	return models.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
@@ -0,0 +1,177 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/baronight/assessment-tax/config"
+)
+
+const (
+	VALID_DB_ENV   string = "host=localhost port=5432 user=postgres password=postgres dbname=ktaxes sslmode=disable"
+	INVALID_DB_ENV string = "helloworld"
+)
+
+func TestDB(t *testing.T) {
+	t.Run("given wrong env should return error", func(t *testing.T) {
+		_, err := New(config.Database{Driver: config.DriverPostgres, URL: INVALID_DB_ENV})
+
+		if err == nil {
+			t.Errorf("expect error was not nil")
+		}
+	})
+
+	t.Run("given valid env should return Store which can execution sql", func(t *testing.T) {
+		pg, err := New(config.Database{Driver: config.DriverPostgres, URL: VALID_DB_ENV})
+
+		if err != nil {
+			t.Fatal("expect error was nil")
+		}
+		defer pg.Db.Close()
+
+		row := pg.Db.QueryRow("SELECT 1 + 1")
+		var expect int
+		if err := row.Scan(&expect); err != nil {
+			t.Error("expect error was nil")
+		}
+		if expect != 2 {
+			t.Errorf("expect result should be 2 but got %d", expect)
+		}
+	})
+}
+
+func TestQueryContext(t *testing.T) {
+	qry := "SELECT id, slug, \"name\", amount, \"minAmount\", \"maxAmount\", version FROM deductions"
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "slug", "name", "amount", "minAmount", "maxAmount", "version"})
+	}
+	t.Run("given cancelled request should abort the query", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WillDelayFor(time.Second).WillReturnRows(rows())
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := p.GetDeductions(ctx)
+
+		if !errors.Is(err, sqlmock.ErrCancelled) {
+			t.Errorf("expect %q but got %q", sqlmock.ErrCancelled, err)
+		}
+		if elapsed := time.Since(start); elapsed >= time.Second {
+			t.Errorf("expect query was aborted before it finished but it took %s", elapsed)
+		}
+	})
+	t.Run("given query slower than query timeout should abort the query", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db, QueryTimeout: 10 * time.Millisecond}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WillDelayFor(time.Second).WillReturnRows(rows())
+
+		start := time.Now()
+		_, err := p.GetDeductions(context.Background())
+
+		if !errors.Is(err, sqlmock.ErrCancelled) {
+			t.Errorf("expect %q but got %q", sqlmock.ErrCancelled, err)
+		}
+		if elapsed := time.Since(start); elapsed >= time.Second {
+			t.Errorf("expect query was aborted by timeout but it took %s", elapsed)
+		}
+	})
+	t.Run("given query faster than query timeout should return result", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db, QueryTimeout: time.Second}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WillReturnRows(rows().AddRow(1, "k-receipt", "kReceipt", 50000, 0, 100000, 1))
+
+		got, err := p.GetDeductions(context.Background())
+
+		if err != nil || len(got) != 1 {
+			t.Errorf("expect one deduction but got %#v, %v", got, err)
+		}
+	})
+}
+
+func TestPing(t *testing.T) {
+	setup := func(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *[]time.Duration) {
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		if err != nil {
+			t.Fatalf("an error %q was not expected when opening a stub database connection", err)
+		}
+		waits := []time.Duration{}
+		sleep = func(d time.Duration) { waits = append(waits, d) }
+		t.Cleanup(func() {
+			sleep = time.Sleep
+			db.Close()
+		})
+		return db, mock, &waits
+	}
+	t.Run("given database is ready later should retry with doubled backoff", func(t *testing.T) {
+		db, mock, waits := setup(t)
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		mock.ExpectPing()
+
+		err := ping(db, 5, time.Second)
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(want, *waits) {
+			t.Errorf("expect wait %v but got %v", want, *waits)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("given database is never ready should return error after every retry", func(t *testing.T) {
+		db, mock, waits := setup(t)
+		for i := 0; i < 4; i++ {
+			mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		}
+
+		err := ping(db, 3, 20*time.Second)
+
+		if err == nil || err.Error() != "connection refused" {
+			t.Errorf("expect connection refused but got %v", err)
+		}
+		if want := []time.Duration{20 * time.Second, maxConnectBackoff, maxConnectBackoff}; !reflect.DeepEqual(want, *waits) {
+			t.Errorf("expect backoff is limited to %s but got %v", maxConnectBackoff, *waits)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("given no retry should return first error", func(t *testing.T) {
+		db, mock, waits := setup(t)
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+		err := ping(db, 0, time.Second)
+
+		if err == nil || len(*waits) != 0 {
+			t.Errorf("expect error without retry but got %v, %v", err, *waits)
+		}
+	})
+}
+
+func TestPoolStats(t *testing.T) {
+	db, _ := NewMock()
+	p := Store{Db: db}
+	defer p.Db.Close()
+	db.SetMaxOpenConns(10)
+
+	got := p.PoolStats()
+
+	if got.MaxOpenConnections != 10 || got.InUse != 0 || got.WaitCount != 0 {
+		t.Errorf("expect stats of pool with 10 max open connections but got %#v", got)
+	}
+}
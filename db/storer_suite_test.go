@@ -0,0 +1,254 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+// testStorer run storer methods against real database that open return, every open should return database that is just migrated,
+// so postgres and sqlite are checked by the same cases.
+func testStorer(t *testing.T, open func(t *testing.T) *Store) {
+	ctx := context.Background()
+
+	t.Run("given migrated database should have seeded configs", func(t *testing.T) {
+		p := open(t)
+
+		deductions, err := p.GetDeductions(ctx)
+		if err != nil || len(deductions) != 6 {
+			t.Errorf("expect 6 deductions but got %#v, %v", deductions, err)
+		}
+		personal, err := p.GetDeduction(ctx, models.PersonalSlug)
+		if err != nil || personal.Amount != 60_000 || personal.MinAmount != 10_000 || personal.MaxAmount != 100_000 || personal.Version != 1 {
+			t.Errorf("expect seeded personal deduction but got %#v, %v", personal, err)
+		}
+		penalties, err := p.GetPenalties(ctx)
+		if err != nil || len(penalties) != 4 {
+			t.Errorf("expect 4 penalties but got %#v, %v", penalties, err)
+		}
+		configs, err := p.GetInstallmentConfigs(ctx)
+		if err != nil || len(configs) != 2 {
+			t.Errorf("expect 2 installment configs but got %#v, %v", configs, err)
+		}
+		if _, err := p.GetDeduction(ctx, "xxx"); err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+		if pending, err := p.PendingMigrations(ctx); err != nil || len(pending) != 0 {
+			t.Errorf("expect no pending migration but got %v, %v", pending, err)
+		}
+		if err := p.Ping(ctx); err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+	})
+
+	t.Run("given many deduction changes should create all of them as pending", func(t *testing.T) {
+		p := open(t)
+
+		got, err := p.CreateDeductionChanges(ctx, []models.DeductionChange{
+			{Slug: models.PersonalSlug, Amount: 70_000, Version: 1, Status: models.PendingChange, ProposedBy: "editor"},
+			{Slug: models.KReceiptSlug, Amount: 60_000, Version: 1, Status: models.PendingChange, ProposedBy: "editor"},
+		})
+
+		if pending, _ := p.GetDeductionChanges(ctx, models.PendingChange); err != nil || len(got) != 2 || !reflect.DeepEqual(got, pending) {
+			t.Errorf("expect 2 pending changes but got %#v, %#v, %v", got, pending, err)
+		}
+	})
+
+	t.Run("given deduction changes should approve and reject only pending one", func(t *testing.T) {
+		p := open(t)
+		propose := func(amount float64) models.DeductionChange {
+			t.Helper()
+			change, err := p.CreateDeductionChange(ctx, models.DeductionChange{
+				Slug: models.PersonalSlug, Amount: amount, Version: 1, Status: models.PendingChange, ProposedBy: "editor"})
+			if err != nil || change.Id == 0 || change.CreatedAt == "" {
+				t.Fatalf("expect created change but got %#v, %v", change, err)
+			}
+			return change
+		}
+		first, second, third := propose(70_000), propose(80_000), propose(90_000)
+
+		approved, err := p.ApproveDeductionChange(ctx, first.Id, "approver")
+		if err != nil || approved.Status != models.ApprovedChange || approved.ReviewedBy != "approver" || approved.ReviewedAt == "" {
+			t.Errorf("expect approved change but got %#v, %v", approved, err)
+		}
+		if d, _ := p.GetDeduction(ctx, models.PersonalSlug); d.Amount != 70_000 || d.Version != 2 {
+			t.Errorf("expect deduction was updated by approved change but got %#v", d)
+		}
+		if _, err := p.ApproveDeductionChange(ctx, first.Id, "approver"); err != sql.ErrNoRows {
+			t.Errorf("expect reviewed change return %q but got %q", sql.ErrNoRows, err)
+		}
+		if _, err := p.ApproveDeductionChange(ctx, second.Id, "approver"); err != utils.ErrVersionMismatch {
+			t.Errorf("expect outdated change return %q but got %q", utils.ErrVersionMismatch, err)
+		}
+		rejected, err := p.RejectDeductionChange(ctx, third.Id, "approver", "not announced")
+		if err != nil || rejected.Status != models.RejectedChange || rejected.Reason != "not announced" {
+			t.Errorf("expect rejected change but got %#v, %v", rejected, err)
+		}
+		pending, err := p.GetDeductionChanges(ctx, models.PendingChange)
+		if err != nil || len(pending) != 1 || pending[0].Id != second.Id {
+			t.Errorf("expect only outdated change is still pending but got %#v, %v", pending, err)
+		}
+		if got, err := p.GetDeductionChange(ctx, third.Id); err != nil || got.Status != models.RejectedChange {
+			t.Errorf("expect rejected change but got %#v, %v", got, err)
+		}
+	})
+
+	t.Run("given exchange rates should return latest rate on or before date", func(t *testing.T) {
+		p := open(t)
+		err := p.SaveExchangeRates(ctx, []models.ExchangeRate{
+			{Currency: "USD", Rate: 36, RateDate: "2024-12-01"},
+			{Currency: "USD", Rate: 37, RateDate: "2024-12-30"},
+			{Currency: "USD", Rate: 38, RateDate: "2025-01-10"},
+		})
+		if err == nil {
+			err = p.SaveExchangeRates(ctx, []models.ExchangeRate{{Currency: "USD", Rate: 36.5, RateDate: "2024-12-30"}})
+		}
+		if err != nil {
+			t.Fatalf("expect no error found but got %q", err)
+		}
+
+		got, err := p.GetExchangeRate(ctx, "USD", "2025-01-01")
+
+		if err != nil || got.Rate != 36.5 || got.RateDate != "2024-12-30" {
+			t.Errorf("expect replaced rate of 2024-12-30 but got %#v, %v", got, err)
+		}
+		if _, err := p.GetExchangeRate(ctx, "USD", "2024-11-30"); err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+	})
+
+	t.Run("given taxpayer should create, update and delete it", func(t *testing.T) {
+		p := open(t)
+		taxpayer := models.Taxpayer{NationalId: "1101700203000", Name: "Somchai Jaidee", MaritalStatus: "single"}
+
+		created, err := p.CreateTaxpayer(ctx, taxpayer)
+		if err != nil || created.CreatedAt == "" {
+			t.Errorf("expect created taxpayer but got %#v, %v", created, err)
+		}
+		if _, err := p.CreateTaxpayer(ctx, taxpayer); err != utils.ErrTaxpayerExists {
+			t.Errorf("expect %q but got %q", utils.ErrTaxpayerExists, err)
+		}
+		taxpayer.MaritalStatus, taxpayer.SpouseHasIncome, taxpayer.Children = "married", true, 2
+		updated, err := p.UpdateTaxpayer(ctx, taxpayer)
+		if err != nil || !updated.SpouseHasIncome || updated.Children != 2 {
+			t.Errorf("expect updated taxpayer but got %#v, %v", updated, err)
+		}
+		if err := p.DeleteTaxpayer(ctx, taxpayer.NationalId); err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if _, err := p.GetTaxpayer(ctx, taxpayer.NationalId); err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+		if err := p.DeleteTaxpayer(ctx, taxpayer.NationalId); err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+	})
+
+	t.Run("given tax returns should list them by tax year and id descending", func(t *testing.T) {
+		p := open(t)
+		for _, year := range []int{2566, 2567, 2567} {
+			_, err := p.CreateTaxReturn(ctx, models.TaxReturn{
+				TaxpayerId: "1101700203000", TaxYear: year,
+				Request:    models.TaxRequest{TotalIncome: 500_000},
+				Response:   models.TaxResponse{Tax: 29_000},
+				Deductions: []models.Deduction{{Slug: models.PersonalSlug, Amount: 60_000}},
+			})
+			if err != nil {
+				t.Fatalf("expect no error found but got %q", err)
+			}
+		}
+
+		got, err := p.GetTaxReturns(ctx, "1101700203000", 0)
+		if err != nil || len(got) != 3 || got[0].Id != 3 || got[1].Id != 2 || got[2].Id != 1 {
+			t.Errorf("expect returns order by tax year and id descending but got %#v, %v", got, err)
+		}
+		if got, _ := p.GetTaxReturns(ctx, "1101700203000", 2566); len(got) != 1 {
+			t.Errorf("expect one return of 2566 but got %#v", got)
+		}
+
+		if got[0].Revision != 2 || got[1].Revision != 1 || got[2].Revision != 1 {
+			t.Errorf("expect revision 0 was saved after the latest one of tax year but got %#v", got)
+		}
+
+		amended := got[0]
+		amended.Revision, amended.Response.Tax = 0, 30_000
+		amended.RuleSet = models.RuleSet{Name: "default", TaxYear: 2567}
+		created, err := p.CreateTaxReturn(ctx, amended)
+		if err != nil || created.Id != 4 || created.Revision != 3 || created.Response.Tax != 30_000 || created.RuleSet.Name != "default" {
+			t.Errorf("expect amended return as new row but got %#v, %v", created, err)
+		}
+		if latest, _ := p.GetTaxReturn(ctx, amended.Id); latest.Revision != 2 || latest.Response.Tax != 29_000 {
+			t.Errorf("expect earlier revision is kept but got %#v", latest)
+		}
+		amended.Revision = 3
+		if _, err := p.CreateTaxReturn(ctx, amended); err != utils.ErrTaxReturnExists {
+			t.Errorf("expect %q but got %q", utils.ErrTaxReturnExists, err)
+		}
+		if created.RequestThb != nil || created.Taxpayer != nil {
+			t.Errorf("expect no snapshot of request and taxpayer but got %#v, %#v", created.RequestThb, created.Taxpayer)
+		}
+		snapshot := models.TaxReturn{
+			TaxpayerId: "1101700203000", TaxYear: 2568,
+			RequestThb: &models.TaxRequest{TotalIncome: 700_000, Allowances: []models.Allowance{{Type: models.DonationSlug, Amount: 3_500}}},
+			Taxpayer:   &models.Taxpayer{NationalId: "1101700203000", MaritalStatus: "married", Children: 2},
+		}
+		created, err = p.CreateTaxReturn(ctx, snapshot)
+		if got, _ := p.GetTaxReturn(ctx, created.Id); err != nil || !reflect.DeepEqual(snapshot.RequestThb, got.RequestThb) || !reflect.DeepEqual(snapshot.Taxpayer, got.Taxpayer) {
+			t.Errorf("expect snapshot of request and taxpayer was kept but got %#v, %#v, %v", got.RequestThb, got.Taxpayer, err)
+		}
+		if _, err := p.GetTaxReturn(ctx, 99); err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+	})
+
+	t.Run("given rule set should activate it without changing deductions", func(t *testing.T) {
+		p := open(t)
+		if _, err := p.GetActiveRuleSet(ctx); err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+		first, err := p.CreateRuleSet(ctx, models.RuleSet{Name: "2567", TaxYear: 2567})
+		if err != nil {
+			t.Fatalf("expect no error found but got %q", err)
+		}
+		second, _ := p.CreateRuleSet(ctx, models.RuleSet{Name: "2568", TaxYear: 2568,
+			Deductions: []models.RuleDeduction{{Slug: "donation", Amount: 80_000}}})
+		p.ActivateRuleSet(ctx, first, nil)
+
+		changes, err := p.ActivateRuleSet(ctx, second, []models.DeductionChange{
+			{Slug: "donation", Amount: 80_000, Version: 1, Status: models.PendingChange, ProposedBy: "editor"},
+		})
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if pending, _ := p.GetDeductionChanges(ctx, models.PendingChange); len(changes) != 1 || !reflect.DeepEqual(changes, pending) {
+			t.Errorf("expect donation change is pending but got %#v, %#v", changes, pending)
+		}
+		if active, err := p.GetActiveRuleSet(ctx); err != nil || active.Id != second.Id || active.Name != "2568" {
+			t.Errorf("expect second rule set is active but got %#v, %v", active, err)
+		}
+		if ruleSets, _ := p.GetRuleSets(ctx); len(ruleSets) != 2 || ruleSets[0].Id != second.Id || ruleSets[1].Active {
+			t.Errorf("expect only latest rule set is active but got %#v", ruleSets)
+		}
+		if d, _ := p.GetDeduction(ctx, "donation"); d.Amount == 80_000 || d.Version != 1 {
+			t.Errorf("expect donation deduction is not changed before approval but got %#v", d)
+		}
+	})
+
+	t.Run("given cancelled context should not query", func(t *testing.T) {
+		p := open(t)
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := p.GetDeductions(ctx)
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expect %q but got %q", context.Canceled, err)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+const taxReturnColumns = "id, \"taxpayerId\", \"taxYear\", revision, request, response, deductions, \"ruleSet\", \"requestThb\", taxpayer, \"createdAt\", \"updatedAt\""
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTaxReturn(row rowScanner) (models.TaxReturn, error) {
+	var v models.TaxReturn
+	var request, response, deductions, ruleSet, requestThb, taxpayer []byte
+	var createdAt, updatedAt time.Time
+	if err := row.Scan(&v.Id, &v.TaxpayerId, &v.TaxYear, &v.Revision, &request, &response, &deductions, &ruleSet, &requestThb, &taxpayer, &createdAt, &updatedAt); err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(request, &v.Request); err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(response, &v.Response); err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(deductions, &v.Deductions); err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(ruleSet, &v.RuleSet); err != nil {
+		return v, err
+	}
+	// snapshots are null on return that was saved before they were kept
+	if requestThb != nil {
+		if err := json.Unmarshal(requestThb, &v.RequestThb); err != nil {
+			return v, err
+		}
+	}
+	if taxpayer != nil {
+		if err := json.Unmarshal(taxpayer, &v.Taxpayer); err != nil {
+			return v, err
+		}
+	}
+	v.CreatedAt = createdAt.Format(time.RFC3339)
+	v.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return v, nil
+}
+
+// nullableJson marshal v to json, nil pointer is saved as null column
+func nullableJson[T any](v *T) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func marshalTaxReturn(taxReturn models.TaxReturn) (request, response, deductions, ruleSet []byte, requestThb, taxpayer any, err error) {
+	if request, err = json.Marshal(taxReturn.Request); err != nil {
+		return
+	}
+	if response, err = json.Marshal(taxReturn.Response); err != nil {
+		return
+	}
+	if deductions, err = json.Marshal(taxReturn.Deductions); err != nil {
+		return
+	}
+	if ruleSet, err = json.Marshal(taxReturn.RuleSet); err != nil {
+		return
+	}
+	if requestThb, err = nullableJson(taxReturn.RequestThb); err != nil {
+		return
+	}
+	taxpayer, err = nullableJson(taxReturn.Taxpayer)
+	return
+}
+
+// CreateTaxReturn implements services.TaxReturnStorer.
+// Every revision of tax return is inserted as new row, so earlier revisions are kept. Revision 0 is counted
+// after the latest one in the insert itself, and revision that already exists is rejected by unique index.
+func (s *Store) CreateTaxReturn(ctx context.Context, taxReturn models.TaxReturn) (models.TaxReturn, error) {
+	ctx, cancel := s.withTimeout(ctx, "CreateTaxReturn")
+	defer cancel()
+	request, response, deductions, ruleSet, requestThb, taxpayer, err := marshalTaxReturn(taxReturn)
+	if err != nil {
+		return taxReturn, err
+	}
+	row := s.Db.QueryRowContext(ctx, "INSERT INTO tax_returns (\"taxpayerId\", \"taxYear\", revision, request, response, deductions, \"ruleSet\", \"requestThb\", taxpayer)"+
+		" VALUES ($1, $2, COALESCE(NULLIF($3, 0), (SELECT COALESCE(MAX(revision), 0) + 1 FROM tax_returns WHERE \"taxpayerId\" = $1 AND \"taxYear\" = $2)),"+
+		" $4, $5, $6, $7, $8, $9) RETURNING "+taxReturnColumns,
+		taxReturn.TaxpayerId, taxReturn.TaxYear, taxReturn.Revision, request, response, deductions, ruleSet, requestThb, taxpayer)
+	result, err := scanTaxReturn(row)
+	if isUniqueViolation(err) {
+		return result, utils.ErrTaxReturnExists
+	}
+	return result, err
+}
+
+// GetTaxReturns implements services.TaxReturnStorer.
+// It return all returns of taxpayer when tax year is 0.
+func (s *Store) GetTaxReturns(ctx context.Context, taxpayerId string, taxYear int) ([]models.TaxReturn, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetTaxReturns")
+	defer cancel()
+	rows, err := s.Db.QueryContext(ctx, "SELECT "+taxReturnColumns+" FROM tax_returns"+
+		" WHERE \"taxpayerId\" = $1 AND ($2 = 0 OR \"taxYear\" = $2) ORDER BY \"taxYear\" DESC, id DESC",
+		taxpayerId, taxYear)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var taxReturns []models.TaxReturn
+	for rows.Next() {
+		v, err := scanTaxReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		taxReturns = append(taxReturns, v)
+	}
+	return taxReturns, nil
+}
+
+// GetTaxReturn implements services.TaxReturnStorer.
+func (s *Store) GetTaxReturn(ctx context.Context, id uint) (models.TaxReturn, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetTaxReturn")
+	defer cancel()
+	row := s.Db.QueryRowContext(ctx, "SELECT "+taxReturnColumns+" FROM tax_returns WHERE id = $1", id)
+	return scanTaxReturn(row)
+}
@@ -0,0 +1,156 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/lib/pq"
+)
+
+var taxReturnRowColumns = []string{"id", "taxpayerId", "taxYear", "revision", "request", "response", "deductions", "ruleSet", "requestThb", "taxpayer", "createdAt", "updatedAt"}
+
+func taxReturnRow(rows *sqlmock.Rows, id uint, year int) *sqlmock.Rows {
+	at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	return rows.AddRow(id, "A", year, 1,
+		[]byte(`{"totalIncome":500000}`),
+		[]byte(`{"tax":29000,"taxLevel":[]}`),
+		[]byte(`[{"slug":"personal","name":"Personal","amount":60000}]`),
+		[]byte(`{"name":"default","taxYear":2567,"brackets":null,"deductions":null,"active":true}`),
+		[]byte(`{"totalIncome":500000}`),
+		[]byte(`{"nationalId":"A","children":1}`),
+		at, at)
+}
+
+func wantTaxReturn(id uint, year int) models.TaxReturn {
+	return models.TaxReturn{
+		Id:         id,
+		TaxpayerId: "A",
+		TaxYear:    year,
+		Revision:   1,
+		Request:    models.TaxRequest{TotalIncome: 500_000},
+		Response:   models.TaxResponse{Tax: 29_000, TaxLevel: []models.TaxLevel{}},
+		Deductions: []models.Deduction{{Slug: "personal", Name: "Personal", Amount: 60_000}},
+		RuleSet:    models.RuleSet{Name: "default", TaxYear: 2567, Active: true},
+		RequestThb: &models.TaxRequest{TotalIncome: 500_000},
+		Taxpayer:   &models.Taxpayer{NationalId: "A", Children: 1},
+		CreatedAt:  "2025-01-15T10:00:00Z",
+		UpdatedAt:  "2025-01-15T10:00:00Z",
+	}
+}
+
+func TestCreateTaxReturn(t *testing.T) {
+	qry := regexp.QuoteMeta("INSERT INTO tax_returns (\"taxpayerId\", \"taxYear\", revision, request, response, deductions, \"ruleSet\", \"requestThb\", taxpayer)" +
+		" VALUES ($1, $2, COALESCE(NULLIF($3, 0), (SELECT COALESCE(MAX(revision), 0) + 1 FROM tax_returns WHERE \"taxpayerId\" = $1 AND \"taxYear\" = $2))," +
+		" $4, $5, $6, $7, $8, $9) RETURNING " + taxReturnColumns)
+	t.Run("given tax return should insert json columns and return saved row", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		want := wantTaxReturn(1, 2567)
+		requestThb, _ := json.Marshal(want.RequestThb)
+		taxpayer, _ := json.Marshal(want.Taxpayer)
+		mock.ExpectQuery(qry).
+			WithArgs("A", 2567, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), requestThb, taxpayer).
+			WillReturnRows(taxReturnRow(sqlmock.NewRows(taxReturnRowColumns), 1, 2567))
+
+		got, err := p.CreateTaxReturn(context.Background(), models.TaxReturn{
+			TaxpayerId: "A",
+			TaxYear:    2567,
+			Revision:   1,
+			Request:    want.Request,
+			Response:   want.Response,
+			Deductions: want.Deductions,
+			RuleSet:    want.RuleSet,
+			RequestThb: want.RequestThb,
+			Taxpayer:   want.Taxpayer,
+		})
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("expect %#v but got %#v", want, got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("given return without snapshot of request should insert null columns", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).
+			WithArgs("A", 2567, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+			WillReturnRows(taxReturnRow(sqlmock.NewRows(taxReturnRowColumns), 1, 2567))
+
+		if _, err := p.CreateTaxReturn(context.Background(), models.TaxReturn{TaxpayerId: "A", TaxYear: 2567, Revision: 1}); err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("given revision that already exists should return ErrTaxReturnExists", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WillReturnError(&pq.Error{Code: uniqueViolation})
+
+		_, err := p.CreateTaxReturn(context.Background(), models.TaxReturn{TaxpayerId: "A", TaxYear: 2567, Revision: 1})
+
+		if err != utils.ErrTaxReturnExists {
+			t.Errorf("expect %q but got %q", utils.ErrTaxReturnExists, err)
+		}
+	})
+}
+
+func TestGetTaxReturns(t *testing.T) {
+	qry := regexp.QuoteMeta("SELECT " + taxReturnColumns + " FROM tax_returns" +
+		" WHERE \"taxpayerId\" = $1 AND ($2 = 0 OR \"taxYear\" = $2) ORDER BY \"taxYear\" DESC, id DESC")
+	t.Run("given taxpayer should return all rows", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		rows := sqlmock.NewRows(taxReturnRowColumns)
+		taxReturnRow(rows, 2, 2567)
+		taxReturnRow(rows, 1, 2566)
+		mock.ExpectQuery(qry).WithArgs("A", 0).WillReturnRows(rows)
+
+		got, err := p.GetTaxReturns(context.Background(), "A", 0)
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		want := []models.TaxReturn{wantTaxReturn(2, 2567), wantTaxReturn(1, 2566)}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("expect %#v but got %#v", want, got)
+		}
+	})
+}
+
+func TestGetTaxReturn(t *testing.T) {
+	qry := regexp.QuoteMeta("SELECT " + taxReturnColumns + " FROM tax_returns WHERE id = $1")
+	t.Run("given no row should return no row error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WithArgs(9).WillReturnError(sql.ErrNoRows)
+
+		_, err := p.GetTaxReturn(context.Background(), 9)
+
+		if err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+	})
+}
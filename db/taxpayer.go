@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const taxpayerColumns = "\"nationalId\", \"name\", \"maritalStatus\", \"spouseHasIncome\", children, parents, \"createdAt\", \"updatedAt\""
+
+// uniqueViolation is postgres error code when insert duplicate key
+const uniqueViolation = "23505"
+
+// isUniqueViolation report whether err is duplicate key error of postgres or sqlite
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == uniqueViolation
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code()
+		return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
+
+func scanTaxpayer(row rowScanner) (models.Taxpayer, error) {
+	var v models.Taxpayer
+	var createdAt, updatedAt time.Time
+	if err := row.Scan(&v.NationalId, &v.Name, &v.MaritalStatus, &v.SpouseHasIncome, &v.Children, &v.Parents, &createdAt, &updatedAt); err != nil {
+		return v, err
+	}
+	v.CreatedAt = createdAt.Format(time.RFC3339)
+	v.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return v, nil
+}
+
+// CreateTaxpayer implements services.TaxpayerStorer.
+func (s *Store) CreateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error) {
+	ctx, cancel := s.withTimeout(ctx, "CreateTaxpayer")
+	defer cancel()
+	row := s.Db.QueryRowContext(ctx, "INSERT INTO taxpayers (\"nationalId\", \"name\", \"maritalStatus\", \"spouseHasIncome\", children, parents)"+
+		" VALUES ($1, $2, $3, $4, $5, $6) RETURNING "+taxpayerColumns,
+		taxpayer.NationalId, taxpayer.Name, taxpayer.MaritalStatus, taxpayer.SpouseHasIncome, taxpayer.Children, taxpayer.Parents)
+	result, err := scanTaxpayer(row)
+	if isUniqueViolation(err) {
+		return result, utils.ErrTaxpayerExists
+	}
+	return result, err
+}
+
+// GetTaxpayer implements services.TaxpayerStorer and services.TaxStorer.
+func (s *Store) GetTaxpayer(ctx context.Context, nationalId string) (models.Taxpayer, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetTaxpayer")
+	defer cancel()
+	row := s.Db.QueryRowContext(ctx, "SELECT "+taxpayerColumns+" FROM taxpayers WHERE \"nationalId\" = $1", nationalId)
+	return scanTaxpayer(row)
+}
+
+// UpdateTaxpayer implements services.TaxpayerStorer.
+func (s *Store) UpdateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error) {
+	ctx, cancel := s.withTimeout(ctx, "UpdateTaxpayer")
+	defer cancel()
+	row := s.Db.QueryRowContext(ctx, "UPDATE taxpayers SET \"name\" = $2, \"maritalStatus\" = $3, \"spouseHasIncome\" = $4, children = $5, parents = $6,"+
+		" \"updatedAt\" = CURRENT_TIMESTAMP WHERE \"nationalId\" = $1 RETURNING "+taxpayerColumns,
+		taxpayer.NationalId, taxpayer.Name, taxpayer.MaritalStatus, taxpayer.SpouseHasIncome, taxpayer.Children, taxpayer.Parents)
+	return scanTaxpayer(row)
+}
+
+// DeleteTaxpayer implements services.TaxpayerStorer.
+// It return sql.ErrNoRows when there is no taxpayer to delete.
+func (s *Store) DeleteTaxpayer(ctx context.Context, nationalId string) error {
+	ctx, cancel := s.withTimeout(ctx, "DeleteTaxpayer")
+	defer cancel()
+	result, err := s.Db.ExecContext(ctx, "DELETE FROM taxpayers WHERE \"nationalId\" = $1", nationalId)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
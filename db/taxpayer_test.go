@@ -0,0 +1,100 @@
+//go:build !integration
+// +build !integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/lib/pq"
+)
+
+var taxpayerRowColumns = []string{"nationalId", "name", "maritalStatus", "spouseHasIncome", "children", "parents", "createdAt", "updatedAt"}
+
+func TestCreateTaxpayer(t *testing.T) {
+	qry := regexp.QuoteMeta("INSERT INTO taxpayers (\"nationalId\", \"name\", \"maritalStatus\", \"spouseHasIncome\", children, parents)" +
+		" VALUES ($1, $2, $3, $4, $5, $6) RETURNING " + taxpayerColumns)
+	taxpayer := models.Taxpayer{NationalId: "1234567890121", Name: "Somchai", MaritalStatus: "married", Children: 2}
+	t.Run("given taxpayer should insert and return saved row", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(qry).
+			WithArgs("1234567890121", "Somchai", "married", false, 2, 0).
+			WillReturnRows(sqlmock.NewRows(taxpayerRowColumns).AddRow("1234567890121", "Somchai", "married", false, 2, 0, at, at))
+
+		got, err := p.CreateTaxpayer(context.Background(), taxpayer)
+
+		if err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+		want := taxpayer
+		want.CreatedAt, want.UpdatedAt = "2025-01-15T10:00:00Z", "2025-01-15T10:00:00Z"
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("expect %#v but got %#v", want, got)
+		}
+	})
+	t.Run("given duplicate national id should return taxpayer exists error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WillReturnError(&pq.Error{Code: uniqueViolation})
+
+		_, err := p.CreateTaxpayer(context.Background(), taxpayer)
+
+		if err != utils.ErrTaxpayerExists {
+			t.Errorf("expect %q but got %q", utils.ErrTaxpayerExists, err)
+		}
+	})
+}
+
+func TestGetTaxpayer(t *testing.T) {
+	qry := regexp.QuoteMeta("SELECT " + taxpayerColumns + " FROM taxpayers WHERE \"nationalId\" = $1")
+	t.Run("given no row should return no row error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectQuery(qry).WithArgs("1234567890121").WillReturnError(sql.ErrNoRows)
+
+		_, err := p.GetTaxpayer(context.Background(), "1234567890121")
+
+		if err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+	})
+}
+
+func TestDeleteTaxpayer(t *testing.T) {
+	qry := regexp.QuoteMeta("DELETE FROM taxpayers WHERE \"nationalId\" = $1")
+	t.Run("given no deleted row should return no row error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectExec(qry).WithArgs("1234567890121").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := p.DeleteTaxpayer(context.Background(), "1234567890121")
+
+		if err != sql.ErrNoRows {
+			t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+		}
+	})
+	t.Run("given deleted row should return no error", func(t *testing.T) {
+		db, mock := NewMock()
+		p := Store{Db: db}
+		defer p.Db.Close()
+		mock.ExpectExec(qry).WithArgs("1234567890121").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		if err := p.DeleteTaxpayer(context.Background(), "1234567890121"); err != nil {
+			t.Errorf("expect no error found but got %q", err)
+		}
+	})
+}
@@ -15,14 +15,84 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/deductions/k-receipt": {
+        "/admin/cache/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To get hit and miss count of deduction cache since start, and whether it is invalidated by database notification",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "cache"
+                ],
+                "summary": "Cache Stats API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CacheStats"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/config/export": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To get all deductions with their min and max amount, that can be imported to other environment",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "config"
+                ],
+                "summary": "Export Config API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ConfigExport"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/config/import": {
             "post": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "To setting k-receipt deduction amount for use in tax calculate",
+                "description": "To propose amount of deductions from exported config as pending deduction changes that another admin has to approve,\ndeduction that is not in config is not changed. Amount is validated with current limit and min and max should be the same as current limit.\nUse dryRun to see what will be proposed first",
                 "consumes": [
                     "application/json"
                 ],
@@ -31,25 +101,31 @@ const docTemplate = `{
                 ],
                 "tags": [
                     "admin",
-                    "deduction"
+                    "config"
                 ],
-                "summary": "K-Receipt Deduction Config API",
+                "summary": "Import Config API",
                 "parameters": [
                     {
-                        "description": "new amount that you want to set",
-                        "name": "tax",
+                        "description": "config from export API",
+                        "name": "config",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/DeductionRequest"
+                            "$ref": "#/definitions/ConfigExport"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only return changes without saving",
+                        "name": "dryRun",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/kReceiptResponse"
+                            "$ref": "#/definitions/ConfigImportResponse"
                         }
                     },
                     "400": {
@@ -64,8 +140,83 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "data not found",
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/db/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To get open, in use and idle connections of database pool, and how many times and how long requests waited for connection since start",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "database"
+                ],
+                "summary": "Database Pool Stats API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/PoolStats"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/deduction-changes": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To list deduction changes by status, pending changes by default",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "deduction"
+                ],
+                "summary": "Deduction Change List API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, approved or rejected",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/DeductionChange"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -79,17 +230,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/deductions/personal": {
+        "/admin/deduction-changes/{id}/approve": {
             "post": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "To setting personal deduction amount for use in tax calculate",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "To approve pending deduction change that proposed by other admin and write new amount to deduction",
                 "produces": [
                     "application/json"
                 ],
@@ -97,27 +245,31 @@ const docTemplate = `{
                     "admin",
                     "deduction"
                 ],
-                "summary": "Personal Deduction Config API",
+                "summary": "Approve Deduction Change API",
                 "parameters": [
                     {
-                        "description": "new amount that you want to set",
-                        "name": "tax",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/DeductionRequest"
-                        }
+                        "type": "integer",
+                        "description": "deduction change id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/PersonalResponse"
+                            "$ref": "#/definitions/DeductionChange"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "new version of deduction"
+                            }
                         }
                     },
                     "400": {
-                        "description": "validate error or cannot get body",
+                        "description": "invalid id or amount is out of limit",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -128,8 +280,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "change was proposed by same admin",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "data not found",
+                        "description": "deduction change not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "change was already reviewed",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "deduction was changed after the change was proposed",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -143,9 +313,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/tax/calculations": {
+        "/admin/deduction-changes/{id}/reject": {
             "post": {
-                "description": "To calculate personal tax and return how much addition pay tax / refund tax",
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To reject pending deduction change that proposed by other admin, deduction is not changed",
                 "consumes": [
                     "application/json"
                 ],
@@ -153,17 +328,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tax"
+                    "admin",
+                    "deduction"
                 ],
-                "summary": "Tax Calculate API",
+                "summary": "Reject Deduction Change API",
                 "parameters": [
                     {
-                        "description": "tax data that want to calculate",
-                        "name": "tax",
+                        "type": "integer",
+                        "description": "deduction change id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "reason of rejection",
+                        "name": "review",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/TaxRequest"
+                            "$ref": "#/definitions/DeductionChangeReview"
                         }
                     }
                 ],
@@ -171,11 +353,35 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/TaxResponse"
+                            "$ref": "#/definitions/DeductionChange"
                         }
                     },
                     "400": {
-                        "description": "validate error or cannot get body",
+                        "description": "invalid id or cannot get body",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "change was proposed by same admin",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "deduction change not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "change was already reviewed",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -189,37 +395,76 @@ const docTemplate = `{
                 }
             }
         },
-        "/tax/calculations/upload-csv": {
+        "/admin/deductions/k-receipt": {
             "post": {
-                "description": "To calculate personal tax from csv file and return list of total income, tax and tax refund of each row data",
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To propose k-receipt deduction amount for use in tax calculate, it is applied when other admin approve",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tax"
+                    "admin",
+                    "deduction"
                 ],
-                "summary": "Tax Calculate From CSV file API",
+                "summary": "K-Receipt Deduction Config API",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "csv tax file",
-                        "name": "taxFile",
-                        "in": "formData",
+                        "description": "new amount that you want to set",
+                        "name": "tax",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/DeductionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of deduction from get deduction API",
+                        "name": "If-Match",
+                        "in": "header",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/TaxCsvResponse"
+                            "$ref": "#/definitions/DeductionChange"
                         }
                     },
                     "400": {
-                        "description": "validate error or cannot get file",
+                        "description": "validate error or cannot get body",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "data not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "deduction was changed after it was read",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -232,63 +477,2362 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "Allowance": {
-            "type": "object",
-            "required": [
-                "allowanceType"
-            ],
-            "properties": {
-                "allowanceType": {
+        },
+        "/admin/deductions/personal": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To propose personal deduction amount for use in tax calculate, it is applied when other admin approve",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "deduction"
+                ],
+                "summary": "Personal Deduction Config API",
+                "parameters": [
+                    {
+                        "description": "new amount that you want to set",
+                        "name": "tax",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/DeductionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of deduction from get deduction API",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/DeductionChange"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error or cannot get body",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "data not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "deduction was changed after it was read",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/deductions/{slug}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To get current deduction amount with its version in ETag header, that is required in If-Match header to change it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "deduction"
+                ],
+                "summary": "Deduction Config API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "deduction slug e.g. personal, k-receipt",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Deduction"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of deduction"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "data not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exchange-rates": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To load daily exchange rates (THB per 1 unit) for convert foreign income and allowances in tax calculate",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "exchange-rate"
+                ],
+                "summary": "Exchange Rate Config API",
+                "parameters": [
+                    {
+                        "description": "list of exchange rates",
+                        "name": "rates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ExchangeRateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ExchangeRateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error or cannot get body",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exchange-rates/upload-csv": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To load daily exchange rates from csv file of Bank of Thailand reference rates with column date, currency and rate",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "exchange-rate"
+                ],
+                "summary": "Exchange Rate From CSV file API",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "csv exchange rate file",
+                        "name": "rateFile",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ExchangeRateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error or cannot get file",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rule-sets": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To list saved tax rule sets, latest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "rule-set"
+                ],
+                "summary": "Rule Set List API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/RuleSet"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To save tax rule set (brackets, deductions, caps and order) from json or yaml body by content type, and activate it when activate is true.\nCaps that differ from deduction amounts are proposed as pending deduction changes for another admin to approve",
+                "consumes": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "rule-set"
+                ],
+                "summary": "Create Rule Set API",
+                "parameters": [
+                    {
+                        "description": "rule set in json or yaml",
+                        "name": "ruleSet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/RuleSet"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "activate rule set after save",
+                        "name": "activate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/RuleSet"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error, cap out of deduction limit or cannot get body",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rule-sets/active": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To get tax rule set that tax is calculated with, it is default rule set when no rule set is activated",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "rule-set"
+                ],
+                "summary": "Active Rule Set API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/RuleSet"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rule-sets/upload": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To save tax rule set from .json, .yaml or .yml file, and activate it when activate is true.\nCaps that differ from deduction amounts are proposed as pending deduction changes for another admin to approve",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "rule-set"
+                ],
+                "summary": "Rule Set From File API",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "rule set file",
+                        "name": "ruleSetFile",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "activate rule set after save",
+                        "name": "activate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/RuleSet"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error, cap out of deduction limit or cannot get file",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rule-sets/{id}/activate": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To use saved rule set for tax calculate, caps that differ from deduction amounts are proposed as pending deduction changes\nand deduction amounts are changed only when another admin approve them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "rule-set"
+                ],
+                "summary": "Activate Rule Set API",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "rule set id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/RuleSet"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id or cap out of deduction limit",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "rule set not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "To check that api process is alive, it does not check any dependency",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Liveness"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "To check that api can serve requests: database is reachable, migrations are applied and deduction config is loadable.\nIt is not ready while server is shutting down so load balancers can drain traffic.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Readiness"
+                        }
+                    },
+                    "503": {
+                        "description": "some components are down",
+                        "schema": {
+                            "$ref": "#/definitions/Readiness"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/calculations": {
+            "post": {
+                "description": "To calculate personal tax and return how much addition pay tax / refund tax",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Tax Calculate API",
+                "parameters": [
+                    {
+                        "description": "tax data that want to calculate",
+                        "name": "tax",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TaxRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TaxResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error, cannot get body, exchange rate or taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "taxpayer id is sent without authentication",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/calculations/certificates": {
+            "post": {
+                "description": "To calculate personal tax from withholding tax certificates (50 Tawi), income is aggregated by type and tax withheld is summed to wht",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "certificate"
+                ],
+                "summary": "Tax Calculate From Withholding Certificates API",
+                "parameters": [
+                    {
+                        "description": "withholding certificates and allowances",
+                        "name": "certificates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CertificateTaxRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CertificateTaxResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error, cannot get body or taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "taxpayer id is sent without authentication",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/calculations/certificates/upload-csv": {
+            "post": {
+                "description": "To calculate personal tax from csv file of withholding tax certificates with column payerTaxId, incomeType, amountPaid and taxWithheld",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "certificate"
+                ],
+                "summary": "Tax Calculate From Withholding Certificates CSV file API",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "csv certificate file",
+                        "name": "certificateFile",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "taxpayer id for family allowances",
+                        "name": "taxpayerId",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CertificateTaxResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error, cannot get file or taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "taxpayer id is sent without authentication",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/calculations/pdf": {
+            "post": {
+                "description": "To calculate personal tax and return printable pdf summary of inputs, allowances with caps, tax by bracket and final tax or refund",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Tax Calculate PDF Summary API",
+                "parameters": [
+                    {
+                        "description": "tax data that want to calculate",
+                        "name": "tax",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TaxRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error, cannot get body, exchange rate or taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "taxpayer id is sent without authentication",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/calculations/spouse": {
+            "post": {
+                "description": "To calculate tax of both spouses by joint filing and separate filing and recommend the option that has less total tax",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "spouse"
+                ],
+                "summary": "Spouse Tax Calculate API",
+                "parameters": [
+                    {
+                        "description": "tax data of taxpayer and spouse",
+                        "name": "tax",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/SpouseTaxRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SpouseTaxResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error, cannot get body, exchange rate or taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "taxpayer id is sent without authentication",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/calculations/spouse/upload-csv": {
+            "post": {
+                "description": "To calculate joint filing and separate filing tax from csv file and return recommend option of each row data",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "spouse"
+                ],
+                "summary": "Spouse Tax Calculate From CSV file API",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "csv tax file with spouse columns",
+                        "name": "taxFile",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/SpouseTaxCsvResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error or cannot get file",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/calculations/upload-csv": {
+            "post": {
+                "description": "To calculate personal tax from csv file and return list of total income, tax and tax refund of each row data",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Tax Calculate From CSV file API",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "csv tax file",
+                        "name": "taxFile",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TaxCsvResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error or cannot get file",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/e-filing": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To calculate tax returns and export them as PND 90/91 in fixed-width (txt in TIS-620, width in bytes) or xml import layout of e-filing, taxpayer profile is required",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain",
+                    "text/xml"
+                ],
+                "tags": [
+                    "tax",
+                    "e-filing"
+                ],
+                "summary": "Revenue Department E-Filing Export API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "txt (default) or xml",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "description": "tax returns that want to export",
+                        "name": "returns",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/EFilingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error, cannot get body, missing required field, exchange rate or taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/e-filing/upload-csv": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To calculate tax csv file that has taxpayerId and taxYear column and export them in e-filing import layout",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "text/plain",
+                    "text/xml"
+                ],
+                "tags": [
+                    "tax",
+                    "e-filing"
+                ],
+                "summary": "Revenue Department E-Filing Export From CSV file API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "txt (default) or xml",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "csv tax file",
+                        "name": "taxFile",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error, cannot get file, missing required field, exchange rate or taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/payroll/withholdings": {
+            "post": {
+                "description": "To calculate tax that employer should withhold from salary and bonus of this month",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "payroll"
+                ],
+                "summary": "Monthly Payroll Withholding API (PND 1)",
+                "parameters": [
+                    {
+                        "description": "payroll data of this month",
+                        "name": "payroll",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/PayrollRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/PayrollResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error or cannot get body",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/payroll/withholdings/upload-csv": {
+            "post": {
+                "description": "To calculate withholding tax of whole payroll from csv file and return withholding of each employee",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "payroll"
+                ],
+                "summary": "Monthly Payroll Withholding From CSV file API",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "csv payroll file",
+                        "name": "payrollFile",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/PayrollCsvResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error or cannot get file",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/returns": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To list tax returns of taxpayer, filter by tax year when send",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "tax-return"
+                ],
+                "summary": "Tax Return History API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "taxpayer id",
+                        "name": "taxpayerId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tax year in Buddhist Era",
+                        "name": "taxYear",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/TaxReturn"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "validate error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To calculate personal tax and save it as tax return of taxpayer with deduction config that used",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "tax-return"
+                ],
+                "summary": "Save Tax Return API",
+                "parameters": [
+                    {
+                        "description": "taxpayer, tax year and tax data that want to calculate",
+                        "name": "taxReturn",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TaxReturnRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/TaxReturn"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error, cannot get body or exchange rate or taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "tax return of taxpayer and tax year is already saved",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/returns/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To get saved tax return with its request, response and deduction config that used",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "tax-return"
+                ],
+                "summary": "Tax Return API",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "tax return id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TaxReturn"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "tax return not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To recalculate saved tax return with new tax data and current deduction config and save it as next revision, earlier revisions are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "tax-return"
+                ],
+                "summary": "Amend Tax Return API",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "tax return id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "tax data that want to amend",
+                        "name": "tax",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TaxRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TaxReturn"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id, validate error, taxpayer id is not of tax return, cannot get body or exchange rate or taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "tax return not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "tax return is amended by other request at the same time",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/returns/{id}/pdf": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To get printable pdf summary of saved tax return only from its snapshot of deductions, rule set, request in THB and taxpayer profile",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "tax",
+                    "tax-return"
+                ],
+                "summary": "Tax Return PDF Summary API",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "tax return id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "tax return not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "tax return was saved without taxpayer profile snapshot",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/taxpayers": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To create taxpayer profile, number of children and parents is used as family allowance when calculation send taxpayer id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "taxpayer"
+                ],
+                "summary": "Create Taxpayer API",
+                "parameters": [
+                    {
+                        "description": "taxpayer profile",
+                        "name": "taxpayer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Taxpayer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/Taxpayer"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error or cannot get body",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "taxpayer already exists",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax/taxpayers/{nationalId}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To get taxpayer profile by national id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "taxpayer"
+                ],
+                "summary": "Taxpayer API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "13 digits national id",
+                        "name": "nationalId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Taxpayer"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid national id",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To update taxpayer profile, national id in path is used instead of one in body",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax",
+                    "taxpayer"
+                ],
+                "summary": "Update Taxpayer API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "13 digits national id",
+                        "name": "nationalId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "taxpayer profile",
+                        "name": "taxpayer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Taxpayer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Taxpayer"
+                        }
+                    },
+                    "400": {
+                        "description": "validate error or cannot get body",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "To delete taxpayer profile by national id",
+                "tags": [
+                    "tax",
+                    "taxpayer"
+                ],
+                "summary": "Delete Taxpayer API",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "13 digits national id",
+                        "name": "nationalId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid national id",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "taxpayer not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "Allowance": {
+            "type": "object",
+            "required": [
+                "allowanceType"
+            ],
+            "properties": {
+                "allowanceType": {
+                    "description": "Type is slug of deduction rule in active rule set that allowance is claimed for",
                     "type": "string",
-                    "enum": [
-                        "donation",
-                        "k-receipt"
-                    ]
+                    "example": "donation"
+                },
+                "amount": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "CacheStats": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "listening": {
+                    "description": "Listening is false when listener connection is down and cache is expired by TTL",
+                    "type": "boolean",
+                    "example": true
+                },
+                "misses": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "CertificateTaxRequest": {
+            "type": "object",
+            "properties": {
+                "allowances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Allowance"
+                    }
+                },
+                "certificates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Certificate"
+                    }
+                },
+                "taxpayerId": {
+                    "type": "string",
+                    "example": "1234567890121"
+                }
+            }
+        },
+        "CertificateTaxResponse": {
+            "type": "object",
+            "properties": {
+                "certificates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CertificateResult"
+                    }
+                },
+                "incomeByType": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.IncomeByType"
+                    }
+                },
+                "input": {
+                    "$ref": "#/definitions/TaxRequest"
+                },
+                "result": {
+                    "$ref": "#/definitions/TaxResponse"
+                }
+            }
+        },
+        "ComponentHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error is why component is down",
+                    "type": "string",
+                    "example": ""
+                },
+                "status": {
+                    "type": "string",
+                    "example": "up"
+                }
+            }
+        },
+        "ConfigExport": {
+            "type": "object",
+            "properties": {
+                "deductions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DeductionConfig"
+                    }
+                }
+            }
+        },
+        "ConfigImportResponse": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DeductionConfigDiff"
+                    }
+                },
+                "dryRun": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "pendingChanges": {
+                    "description": "PendingChanges are proposed changes of amount that wait for other admin to approve, it is empty on dry run",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DeductionChange"
+                    }
+                }
+            }
+        },
+        "ConvertedAmount": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "amountThb": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "totalIncome"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "rateDate": {
+                    "type": "string"
+                }
+            }
+        },
+        "CsvCalculateResult": {
+            "type": "object",
+            "properties": {
+                "tax": {
+                    "type": "number"
+                },
+                "taxRefund": {
+                    "type": "number"
+                },
+                "totalIncome": {
+                    "type": "number"
+                }
+            }
+        },
+        "Deduction": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version increase on every update, it is sent as ETag to prevent lost update",
+                    "type": "integer"
+                }
+            }
+        },
+        "DeductionChange": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 70000
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-01-15T10:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "proposedBy": {
+                    "type": "string",
+                    "example": "editor"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "amount is not announced yet"
+                },
+                "reviewedAt": {
+                    "type": "string",
+                    "example": "2025-01-16T10:00:00Z"
+                },
+                "reviewedBy": {
+                    "type": "string",
+                    "example": "approver"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "personal"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "version": {
+                    "description": "Version of deduction that change is proposed on, change can be approved only when deduction is still in this version",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "DeductionChangeReview": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "amount is not announced yet"
+                }
+            }
+        },
+        "DeductionConfig": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 60000
+                },
+                "maxAmount": {
+                    "type": "number",
+                    "example": 100000
+                },
+                "minAmount": {
+                    "type": "number",
+                    "example": 10000
+                },
+                "name": {
+                    "type": "string",
+                    "example": "personalDeduction"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "personal"
+                }
+            }
+        },
+        "DeductionConfigDiff": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/DeductionConfig"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "personal"
+                },
+                "to": {
+                    "$ref": "#/definitions/DeductionConfig"
+                }
+            }
+        },
+        "DeductionRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "EFilingRequest": {
+            "type": "object",
+            "properties": {
+                "returns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/TaxReturnRequest"
+                    }
+                }
+            }
+        },
+        "ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 36.5
+                },
+                "rateDate": {
+                    "type": "string",
+                    "example": "2024-12-30"
+                }
+            }
+        },
+        "ExchangeRateRequest": {
+            "type": "object",
+            "properties": {
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ExchangeRate"
+                    }
+                }
+            }
+        },
+        "ExchangeRateResponse": {
+            "type": "object",
+            "properties": {
+                "saved": {
+                    "type": "integer"
+                }
+            }
+        },
+        "Installment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "dueDate": {
+                    "type": "string"
+                },
+                "no": {
+                    "type": "integer"
+                }
+            }
+        },
+        "Liveness": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "alive"
+                }
+            }
+        },
+        "PayrollCsvResponse": {
+            "type": "object",
+            "properties": {
+                "payroll": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/PayrollCsvResult"
+                    }
+                }
+            }
+        },
+        "PayrollCsvResult": {
+            "type": "object",
+            "properties": {
+                "employeeId": {
+                    "type": "string"
                 },
+                "monthlySalary": {
+                    "type": "number"
+                },
+                "withholding": {
+                    "type": "number"
+                }
+            }
+        },
+        "PayrollRequest": {
+            "type": "object",
+            "properties": {
+                "allowances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Allowance"
+                    }
+                },
+                "bonusMonths": {
+                    "type": "number"
+                },
+                "month": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "monthlySalary": {
+                    "type": "number",
+                    "example": 50000
+                },
+                "ytdIncome": {
+                    "type": "number"
+                },
+                "ytdWht": {
+                    "type": "number"
+                }
+            }
+        },
+        "PayrollResponse": {
+            "type": "object",
+            "properties": {
+                "annualIncome": {
+                    "type": "number"
+                },
+                "annualTax": {
+                    "type": "number"
+                },
+                "bonus": {
+                    "type": "number"
+                },
+                "bonusWithholding": {
+                    "type": "number"
+                },
+                "regularWithholding": {
+                    "type": "number"
+                },
+                "withholding": {
+                    "type": "number"
+                }
+            }
+        },
+        "PoolStats": {
+            "type": "object",
+            "properties": {
+                "idle": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "inUse": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "maxIdleClosed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "maxIdleTimeClosed": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "maxLifetimeClosed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "maxOpenConnections": {
+                    "description": "MaxOpenConnections is 0 when open connections are not limited",
+                    "type": "integer",
+                    "example": 10
+                },
+                "openConnections": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "waitCount": {
+                    "description": "WaitCount and WaitDurationMs are how many times and how long requests waited for free connection since start",
+                    "type": "integer",
+                    "example": 4
+                },
+                "waitDurationMs": {
+                    "type": "integer",
+                    "example": 35
+                }
+            }
+        },
+        "Readiness": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/ComponentHealth"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ready"
+                }
+            }
+        },
+        "RuleDeduction": {
+            "type": "object",
+            "properties": {
                 "amount": {
+                    "description": "Amount is cap of deduction, 0 mean no limit",
                     "type": "number",
-                    "minimum": 0
+                    "example": 100000
+                },
+                "halfOnHalfYear": {
+                    "description": "HalfOnHalfYear allow only half of deduction on half-year return (PND 94)",
+                    "type": "boolean"
+                },
+                "member": {
+                    "description": "Member is family member that family deduction amount is given for each of them",
+                    "type": "string",
+                    "example": "child"
+                },
+                "percentLimit": {
+                    "description": "PercentLimit cap deduction to percent of total income, 0 mean no limit",
+                    "type": "number",
+                    "example": 10
+                },
+                "periods": {
+                    "description": "Periods that deduction is eligible, empty mean every period",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "donation"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "allowance"
                 }
             }
         },
-        "CsvCalculateResult": {
+        "RuleSet": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "brackets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/TaxStep"
+                    }
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-01-15T10:00:00Z"
+                },
+                "deductions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/RuleDeduction"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "taxYear": {
+                    "type": "integer",
+                    "example": 2567
+                }
+            }
+        },
+        "SeparateFilingResponse": {
             "type": "object",
             "properties": {
+                "spouse": {
+                    "$ref": "#/definitions/TaxResponse"
+                },
                 "tax": {
                     "type": "number"
                 },
                 "taxRefund": {
                     "type": "number"
                 },
+                "taxpayer": {
+                    "$ref": "#/definitions/TaxResponse"
+                }
+            }
+        },
+        "SpouseCsvCalculateResult": {
+            "type": "object",
+            "properties": {
+                "jointTax": {
+                    "type": "number"
+                },
+                "jointTaxRefund": {
+                    "type": "number"
+                },
+                "recommendation": {
+                    "type": "string"
+                },
+                "separateTax": {
+                    "type": "number"
+                },
+                "separateTaxRefund": {
+                    "type": "number"
+                },
+                "spouseTotalIncome": {
+                    "type": "number"
+                },
                 "totalIncome": {
                     "type": "number"
                 }
             }
         },
-        "DeductionRequest": {
+        "SpouseTaxCsvResponse": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "number"
+                "taxes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/SpouseCsvCalculateResult"
+                    }
                 }
             }
         },
-        "ErrorResponse": {
+        "SpouseTaxRequest": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "spouse": {
+                    "$ref": "#/definitions/TaxRequest"
+                },
+                "taxpayer": {
+                    "$ref": "#/definitions/TaxRequest"
                 }
             }
         },
-        "PersonalResponse": {
+        "SpouseTaxResponse": {
             "type": "object",
             "properties": {
-                "personalDeduction": {
-                    "type": "number"
+                "joint": {
+                    "$ref": "#/definitions/TaxResponse"
+                },
+                "recommendation": {
+                    "type": "string",
+                    "example": "separate"
+                },
+                "separate": {
+                    "$ref": "#/definitions/SeparateFilingResponse"
                 }
             }
         },
@@ -323,6 +2867,41 @@ const docTemplate = `{
                         "$ref": "#/definitions/Allowance"
                     }
                 },
+                "currency": {
+                    "type": "string",
+                    "example": "THB"
+                },
+                "dueDate": {
+                    "type": "string",
+                    "example": "2025-03-31"
+                },
+                "filingDate": {
+                    "type": "string",
+                    "example": "2025-04-30"
+                },
+                "incomeType": {
+                    "type": "string",
+                    "example": "salary"
+                },
+                "period": {
+                    "type": "string",
+                    "example": "full-year"
+                },
+                "prepaidTax": {
+                    "type": "number"
+                },
+                "rateDate": {
+                    "type": "string",
+                    "example": "2024-12-30"
+                },
+                "refundDate": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                },
+                "taxpayerId": {
+                    "type": "string",
+                    "example": "1234567890121"
+                },
                 "totalIncome": {
                     "type": "number",
                     "minimum": 0,
@@ -337,6 +2916,27 @@ const docTemplate = `{
         "TaxResponse": {
             "type": "object",
             "properties": {
+                "exchangeRates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ConvertedAmount"
+                    }
+                },
+                "installments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Installment"
+                    }
+                },
+                "penalty": {
+                    "type": "number"
+                },
+                "refundInterest": {
+                    "type": "number"
+                },
+                "surcharge": {
+                    "type": "number"
+                },
                 "tax": {
                     "type": "number"
                 },
@@ -351,10 +2951,179 @@ const docTemplate = `{
                 }
             }
         },
-        "kReceiptResponse": {
+        "TaxReturn": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deductions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Deduction"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "request": {
+                    "$ref": "#/definitions/TaxRequest"
+                },
+                "requestThb": {
+                    "description": "RequestThb and Taxpayer are request in THB and profile that result is calculated with, they are empty\non return that was saved before they were kept",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/TaxRequest"
+                        }
+                    ]
+                },
+                "response": {
+                    "$ref": "#/definitions/TaxResponse"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "ruleSet": {
+                    "$ref": "#/definitions/RuleSet"
+                },
+                "taxYear": {
+                    "type": "integer"
+                },
+                "taxpayer": {
+                    "$ref": "#/definitions/Taxpayer"
+                },
+                "taxpayerId": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "TaxReturnRequest": {
+            "type": "object",
+            "properties": {
+                "tax": {
+                    "$ref": "#/definitions/TaxRequest"
+                },
+                "taxYear": {
+                    "type": "integer",
+                    "example": 2567
+                },
+                "taxpayerId": {
+                    "type": "string",
+                    "example": "1234567890121"
+                }
+            }
+        },
+        "TaxStep": {
+            "type": "object",
+            "properties": {
+                "maxIncome": {
+                    "description": "MaxIncome 0 mean no ceiling, allow only on last step",
+                    "type": "number",
+                    "example": 500000
+                },
+                "minIncome": {
+                    "type": "number",
+                    "example": 150000
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 0.1
+                }
+            }
+        },
+        "Taxpayer": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "maritalStatus": {
+                    "type": "string",
+                    "example": "married"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Somchai Jaidee"
+                },
+                "nationalId": {
+                    "type": "string",
+                    "example": "1234567890121"
+                },
+                "parents": {
+                    "description": "Parents that taxpayer can claim allowance, up to 2 or 4 with parents of spouse who has no income",
+                    "type": "integer",
+                    "example": 0
+                },
+                "spouseHasIncome": {
+                    "type": "boolean"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Certificate": {
+            "type": "object",
+            "properties": {
+                "amountPaid": {
+                    "type": "number",
+                    "example": 500000
+                },
+                "incomeType": {
+                    "type": "string",
+                    "example": "salary"
+                },
+                "payerTaxId": {
+                    "type": "string",
+                    "example": "0105556123453"
+                },
+                "taxWithheld": {
+                    "type": "number",
+                    "example": 25000
+                }
+            }
+        },
+        "models.CertificateResult": {
+            "type": "object",
+            "properties": {
+                "amountPaid": {
+                    "type": "number",
+                    "example": 500000
+                },
+                "incomeType": {
+                    "type": "string",
+                    "example": "salary"
+                },
+                "no": {
+                    "type": "integer"
+                },
+                "payerTaxId": {
+                    "type": "string",
+                    "example": "0105556123453"
+                },
+                "taxWithheld": {
+                    "type": "number",
+                    "example": 25000
+                }
+            }
+        },
+        "models.IncomeByType": {
             "type": "object",
             "properties": {
-                "kReceipt": {
+                "amountPaid": {
+                    "type": "number"
+                },
+                "incomeType": {
+                    "type": "string"
+                },
+                "taxWithheld": {
                     "type": "number"
                 }
             }
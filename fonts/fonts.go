@@ -0,0 +1,29 @@
+// Package fonts embed TrueType fonts that used to render pdf, so it work offline.
+//
+// Thai text need Thai glyphs, Sarabun-Regular.ttf and Sarabun-Bold.ttf (SIL Open Font License, see OFL.txt)
+// are committed in this folder and they are used instead of DejaVu Sans, which has no Thai glyph.
+// TestThaiGlyphs fail when one of them is missing or does not render every Thai character.
+package fonts
+
+import "embed"
+
+//go:embed *.ttf
+var files embed.FS
+
+var (
+	// Regular and Bold are font files in priority order
+	Regular = []string{"Sarabun-Regular.ttf", "DejaVuSans.ttf"}
+	Bold    = []string{"Sarabun-Bold.ttf", "DejaVuSans-Bold.ttf"}
+)
+
+// Load return content of first embedded font that exist in names
+func Load(names []string) ([]byte, error) {
+	var err error
+	for _, name := range names {
+		var b []byte
+		if b, err = files.ReadFile(name); err == nil {
+			return b, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,115 @@
+//go:build !integration
+// +build !integration
+
+package fonts
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+)
+
+// thaiText is every Thai character that a font should render, tax level of summary is e.g. "1 ขึ้นไป"
+const thaiText = "กขฃคฅฆงจฉชซฌญฎฏฐฑฒณดตถทธนบปผฝพฟภมยรฤลฦวศษสหฬอฮฯะัาำิีึืฺุู฿เแโใไๅๆ็่้๊๋์ํ๎๏๐๑๒๓๔๕๖๗๘๙๚๛"
+
+// glyphIndex return glyph of r in cmap table of TrueType font, it is 0 when font has no glyph of r
+func glyphIndex(font []byte, r rune) (int, error) {
+	errInvalid := errors.New("font has no unicode cmap table")
+	u16 := func(at int) int { return int(binary.BigEndian.Uint16(font[at:])) }
+	u32 := func(at int) int { return int(binary.BigEndian.Uint32(font[at:])) }
+	if len(font) < 12 {
+		return 0, errInvalid
+	}
+
+	cmap := -1
+	for i := 0; i < u16(4); i++ {
+		record := 12 + i*16
+		if string(font[record:record+4]) == "cmap" {
+			cmap = u32(record + 8)
+		}
+	}
+	if cmap < 0 {
+		return 0, errInvalid
+	}
+	for i := 0; i < u16(cmap+2); i++ {
+		record := cmap + 4 + i*8
+		platform, encoding, table := u16(record), u16(record+2), cmap+u32(record+4)
+		if platform != 0 && !(platform == 3 && (encoding == 1 || encoding == 10)) {
+			continue
+		}
+		switch u16(table) {
+		case 4:
+			segments := u16(table+6) / 2
+			ends, starts := table+14, table+16+segments*2
+			deltas, offsets := starts+segments*2, starts+segments*4
+			for s := 0; s < segments; s++ {
+				start, end := u16(starts+s*2), u16(ends+s*2)
+				if int(r) < start || int(r) > end {
+					continue
+				}
+				delta, offset := u16(deltas+s*2), u16(offsets+s*2)
+				if offset == 0 {
+					return (int(r) + delta) & 0xFFFF, nil
+				}
+				glyph := u16(offsets + s*2 + offset + (int(r)-start)*2)
+				if glyph == 0 {
+					return 0, nil
+				}
+				return (glyph + delta) & 0xFFFF, nil
+			}
+			return 0, nil
+		case 12:
+			for g := 0; g < u32(table+12); g++ {
+				group := table + 16 + g*12
+				if start, end := u32(group), u32(group+4); int(r) >= start && int(r) <= end {
+					return u32(group+8) + int(r) - start, nil
+				}
+			}
+			return 0, nil
+		}
+	}
+	return 0, errInvalid
+}
+
+func TestGlyphIndex(t *testing.T) {
+	t.Run("given DejaVu Sans should find latin glyph and no thai glyph", func(t *testing.T) {
+		font, err := files.ReadFile("DejaVuSans.ttf")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := glyphIndex(font, 'A'); err != nil || got == 0 {
+			t.Errorf("expect glyph of 'A' but got %d, %v", got, err)
+		}
+		if got, err := glyphIndex(font, 'ก'); err != nil || got != 0 {
+			t.Errorf("expect no glyph of 'ก' but got %d, %v", got, err)
+		}
+	})
+}
+
+func TestThaiGlyphs(t *testing.T) {
+	for _, names := range [][]string{Regular, Bold} {
+		t.Run("given "+names[0]+" should have glyph of every thai character", func(t *testing.T) {
+			if _, err := files.ReadFile(names[0]); err != nil {
+				t.Fatalf("%s is not in fonts folder, commit it with OFL.txt from google/fonts ofl/sarabun", names[0])
+			}
+			font, err := Load(names)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, r := range thaiText + "Personal Income Tax Summary 1,234.00" {
+				if got, err := glyphIndex(font, r); err != nil || got == 0 {
+					t.Errorf("expect glyph of %q but got %d, %v", r, got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFontLicense(t *testing.T) {
+	if _, err := os.Stat("OFL.txt"); err != nil {
+		t.Fatalf("expect OFL.txt of Sarabun in fonts folder but got %v", err)
+	}
+}
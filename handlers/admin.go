@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/baronight/assessment-tax/middlewares"
 	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/services"
 	"github.com/baronight/assessment-tax/utils"
 	"github.com/labstack/echo/v4"
 )
@@ -14,92 +21,251 @@ type AdminHandlers struct {
 }
 
 type AdminServicer interface {
-	ValidateDeductionRequest(slug string, amount float64) error
-	UpdateDeductionConfig(slug string, deduction models.DeductionRequest) (models.Deduction, error)
+	ValidateDeductionRequest(ctx context.Context, slug string, amount float64) error
+	GetDeductionConfig(ctx context.Context, slug string) (models.Deduction, error)
+	ProposeDeductionChange(ctx context.Context, slug string, deduction models.DeductionRequest, version int, editor string) (models.DeductionChange, error)
+	GetDeductionChanges(ctx context.Context, status string) ([]models.DeductionChange, error)
+	ApproveDeductionChange(ctx context.Context, id uint, reviewer string) (models.DeductionChange, error)
+	RejectDeductionChange(ctx context.Context, id uint, reviewer string, review models.DeductionChangeReview) (models.DeductionChange, error)
+	ExportConfig(ctx context.Context) (models.ConfigExport, error)
+	ImportConfig(ctx context.Context, config models.ConfigExport, dryRun bool, editor string) (models.ConfigImportResponse, error)
 }
 
 func NewAdminHandlers(service AdminServicer) *AdminHandlers {
 	return &AdminHandlers{Service: service}
 }
 
-// PersonalDeductionConfigHandler
+// setETag send version of data as ETag
+func setETag(c echo.Context, version int) {
+	c.Response().Header().Set("ETag", fmt.Sprintf("%q", strconv.Itoa(version)))
+}
+
+// ifMatchVersion return version in If-Match header, it accept both strong and weak ETag
+func ifMatchVersion(c echo.Context) (int, error) {
+	ifMatch := c.Request().Header.Get("If-Match")
+	if ifMatch == "" {
+		return 0, utils.ErrVersionRequired
+	}
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+	if err != nil {
+		return 0, utils.ErrVersionMismatch
+	}
+	return version, nil
+}
+
+// GetDeductionConfigHandler
 //
-// @Summary Personal Deduction Config API
-// @Description To setting personal deduction amount for use in tax calculate
+// @Summary Deduction Config API
+// @Description To get current deduction amount with its version in ETag header, that is required in If-Match header to change it
 // @Tags admin, deduction
-// @Accept json
 // @Produce json
 // @Security BasicAuth
-// @Param tax body DeductionRequest true "new amount that you want to set"
-// @Success 200 {object} PersonalResponse
-// @Router /admin/deductions/personal [post]
-// @Failure 400 {object} ErrorResponse "validate error or cannot get body"
+// @Param slug path string true "deduction slug e.g. personal, k-receipt"
+// @Success 200 {object} Deduction
+// @Header 200 {string} ETag "version of deduction"
+// @Router /admin/deductions/{slug} [get]
 // @Failure 401 {object} ErrorResponse "unauthorized"
 // @Failure 404 {object} ErrorResponse "data not found"
 // @Failure 500 {object} ErrorResponse "internal server error"
-func (h *AdminHandlers) PersonalDeductionConfigHandler(c echo.Context) error {
+func (h *AdminHandlers) GetDeductionConfigHandler(c echo.Context) error {
+	deduction, err := h.Service.GetDeductionConfig(c.Request().Context(), c.Param("slug"))
+	if err != nil {
+		c.Logger().Error(err)
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: "data not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	setETag(c, deduction.Version)
+	return c.JSON(http.StatusOK, deduction)
+}
+
+func (h *AdminHandlers) proposeDeductionChange(c echo.Context, slug string) error {
+	version, err := ifMatchVersion(c)
+	if errors.Is(err, utils.ErrVersionRequired) {
+		return c.JSON(http.StatusPreconditionRequired, models.ErrorResponse{Message: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{Message: err.Error()})
+	}
 	body := new(models.DeductionRequest)
 	if err := c.Bind(body); err != nil {
 		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
 	}
 
-	if err := h.Service.ValidateDeductionRequest(models.PersonalSlug, body.Amount); err != nil {
+	if err := h.Service.ValidateDeductionRequest(c.Request().Context(), slug, body.Amount); err != nil {
 		c.Logger().Error(err)
 		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
 	}
 
-	deduction, err := h.Service.UpdateDeductionConfig(models.PersonalSlug, *body)
+	change, err := h.Service.ProposeDeductionChange(c.Request().Context(), slug, *body, version, middlewares.AdminUser(c))
 
 	if err != nil {
 		c.Logger().Error(err)
+		if errors.Is(err, utils.ErrVersionMismatch) {
+			return c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{Message: err.Error()})
+		}
 		if err == sql.ErrNoRows {
 			return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: "data not found"})
 		}
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
 	}
-	result := models.PersonalResponse{
-		Amount: deduction.Amount,
-	}
-	return c.JSON(http.StatusOK, result)
+	setETag(c, change.Version)
+	return c.JSON(http.StatusAccepted, change)
+}
+
+// PersonalDeductionConfigHandler
+//
+// @Summary Personal Deduction Config API
+// @Description To propose personal deduction amount for use in tax calculate, it is applied when other admin approve
+// @Tags admin, deduction
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param tax body DeductionRequest true "new amount that you want to set"
+// @Param If-Match header string true "ETag of deduction from get deduction API"
+// @Success 202 {object} DeductionChange
+// @Router /admin/deductions/personal [post]
+// @Failure 400 {object} ErrorResponse "validate error or cannot get body"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 404 {object} ErrorResponse "data not found"
+// @Failure 412 {object} ErrorResponse "deduction was changed after it was read"
+// @Failure 428 {object} ErrorResponse "If-Match header is missing"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *AdminHandlers) PersonalDeductionConfigHandler(c echo.Context) error {
+	return h.proposeDeductionChange(c, models.PersonalSlug)
 }
 
 // KReceiptDeductionConfigHandler
 //
 // @Summary K-Receipt Deduction Config API
-// @Description To setting k-receipt deduction amount for use in tax calculate
+// @Description To propose k-receipt deduction amount for use in tax calculate, it is applied when other admin approve
 // @Tags admin, deduction
 // @Accept json
 // @Produce json
 // @Security BasicAuth
 // @Param tax body DeductionRequest true "new amount that you want to set"
-// @Success 200 {object} kReceiptResponse
+// @Param If-Match header string true "ETag of deduction from get deduction API"
+// @Success 202 {object} DeductionChange
 // @Router /admin/deductions/k-receipt [post]
 // @Failure 400 {object} ErrorResponse "validate error or cannot get body"
 // @Failure 401 {object} ErrorResponse "unauthorized"
 // @Failure 404 {object} ErrorResponse "data not found"
+// @Failure 412 {object} ErrorResponse "deduction was changed after it was read"
+// @Failure 428 {object} ErrorResponse "If-Match header is missing"
 // @Failure 500 {object} ErrorResponse "internal server error"
 func (h *AdminHandlers) KReceiptDeductionConfigHandler(c echo.Context) error {
-	body := new(models.DeductionRequest)
-	if err := c.Bind(body); err != nil {
+	return h.proposeDeductionChange(c, models.KReceiptSlug)
+}
+
+func deductionChangeErrorResponse(c echo.Context, err error) error {
+	c.Logger().Error(err)
+	switch {
+	case errors.Is(err, utils.ErrDeductionChangeNotFound):
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, utils.ErrDeductionChangeReviewed):
+		return c.JSON(http.StatusConflict, models.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, utils.ErrSelfReview):
+		return c.JSON(http.StatusForbidden, models.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, utils.ErrVersionMismatch):
+		return c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, services.ErrDeductionInvalid), errors.Is(err, services.ErrDeductionAmountInvalid):
 		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
 	}
+	return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+}
 
-	if err := h.Service.ValidateDeductionRequest(models.KReceiptSlug, body.Amount); err != nil {
-		c.Logger().Error(err)
+func deductionChangeId(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return 0, errors.New("deduction change id should be number")
+	}
+	return uint(id), nil
+}
+
+// GetDeductionChangesHandler
+//
+// @Summary Deduction Change List API
+// @Description To list deduction changes by status, pending changes by default
+// @Tags admin, deduction
+// @Produce json
+// @Security BasicAuth
+// @Param status query string false "pending, approved or rejected"
+// @Success 200 {array} DeductionChange
+// @Router /admin/deduction-changes [get]
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *AdminHandlers) GetDeductionChangesHandler(c echo.Context) error {
+	result, err := h.Service.GetDeductionChanges(c.Request().Context(), c.QueryParam("status"))
+	if err != nil {
+		return deductionChangeErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// ApproveDeductionChangeHandler
+//
+// @Summary Approve Deduction Change API
+// @Description To approve pending deduction change that proposed by other admin and write new amount to deduction
+// @Tags admin, deduction
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "deduction change id"
+// @Success 200 {object} DeductionChange
+// @Header 200 {string} ETag "new version of deduction"
+// @Router /admin/deduction-changes/{id}/approve [post]
+// @Failure 400 {object} ErrorResponse "invalid id or amount is out of limit"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 403 {object} ErrorResponse "change was proposed by same admin"
+// @Failure 404 {object} ErrorResponse "deduction change not found"
+// @Failure 409 {object} ErrorResponse "change was already reviewed"
+// @Failure 412 {object} ErrorResponse "deduction was changed after the change was proposed"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *AdminHandlers) ApproveDeductionChangeHandler(c echo.Context) error {
+	id, err := deductionChangeId(c)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
 	}
 
-	deduction, err := h.Service.UpdateDeductionConfig(models.KReceiptSlug, *body)
+	result, err := h.Service.ApproveDeductionChange(c.Request().Context(), id, middlewares.AdminUser(c))
+	if err != nil {
+		return deductionChangeErrorResponse(c, err)
+	}
+	setETag(c, result.Version+1)
+	return c.JSON(http.StatusOK, result)
+}
 
+// RejectDeductionChangeHandler
+//
+// @Summary Reject Deduction Change API
+// @Description To reject pending deduction change that proposed by other admin, deduction is not changed
+// @Tags admin, deduction
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "deduction change id"
+// @Param review body DeductionChangeReview false "reason of rejection"
+// @Success 200 {object} DeductionChange
+// @Router /admin/deduction-changes/{id}/reject [post]
+// @Failure 400 {object} ErrorResponse "invalid id or cannot get body"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 403 {object} ErrorResponse "change was proposed by same admin"
+// @Failure 404 {object} ErrorResponse "deduction change not found"
+// @Failure 409 {object} ErrorResponse "change was already reviewed"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *AdminHandlers) RejectDeductionChangeHandler(c echo.Context) error {
+	id, err := deductionChangeId(c)
 	if err != nil {
-		c.Logger().Error(err)
-		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: "data not found"})
-		}
-		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
 	}
-	result := models.KReceiptResponse{
-		Amount: deduction.Amount,
+	body := new(models.DeductionChangeReview)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.RejectDeductionChange(c.Request().Context(), id, middlewares.AdminUser(c), *body)
+	if err != nil {
+		return deductionChangeErrorResponse(c, err)
 	}
 	return c.JSON(http.StatusOK, result)
 }
@@ -4,67 +4,123 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
-	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/baronight/assessment-tax/models"
 )
 
-func TestITPersonalDeduction(t *testing.T) {
-	var got models.PersonalResponse
+func proposeITRequest(url, amount, ifMatch string) *Response {
+	req, _ := http.NewRequest(http.MethodPost, os.Getenv("API_URL")+url, strings.NewReader(`{"amount": `+amount+`}`))
+	req.SetBasicAuth("adminTax", "admin!")
+	req.Header.Add("Content-Type", "application/json;charset=UTF-8")
+	req.Header.Add("If-Match", ifMatch)
+	client := http.Client{}
+	res, err := client.Do(req)
+	return &Response{res, err}
+}
 
-	res := clientITRequest(
+func deductionITETag(t *testing.T, url string) string {
+	t.Helper()
+	res := clientITRequest(http.MethodGet, os.Getenv("API_URL")+url, nil, "application/json;charset=UTF-8", "adminTax", "admin!")
+	assertHttpCode(t, http.StatusOK, res.StatusCode)
+	etag := res.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expect deduction response has ETag header")
+	}
+	return etag
+}
+
+func proposeITDeduction(t *testing.T, url, amount string) models.DeductionChange {
+	t.Helper()
+	var got models.DeductionChange
+	res := proposeITRequest(url, amount, deductionITETag(t, url))
+	if err := res.Decode(&got); err != nil {
+		t.Errorf("expect response body to be valid json but got %q", err)
+	}
+	assertHttpCode(t, http.StatusAccepted, res.StatusCode)
+	if got.Status != models.PendingChange || got.ProposedBy != "adminTax" {
+		t.Errorf("expect pending change proposed by adminTax but got %#v", got)
+	}
+	return got
+}
+
+func reviewITDeductionChange(id uint, action, user, pass string) *Response {
+	return clientITRequest(
 		http.MethodPost,
-		os.Getenv("API_URL")+"/admin/deductions/personal",
-		strings.NewReader(
-			`{
-			"amount": 60000.0
-		}`),
+		fmt.Sprintf("%s/admin/deduction-changes/%d/%s", os.Getenv("API_URL"), id, action),
+		strings.NewReader(`{}`),
 		"application/json;charset=UTF-8",
-		"adminTax",
-		"admin!",
+		user,
+		pass,
 	)
+}
+
+func TestITPersonalDeduction(t *testing.T) {
+	change := proposeITDeduction(t, "/admin/deductions/personal", "60000.0")
+
+	res := reviewITDeductionChange(change.Id, "approve", "adminTax", "admin!")
+	assertHttpCode(t, http.StatusForbidden, res.StatusCode)
 
-	err := res.Decode(&got)
-	if err != nil {
+	var got models.DeductionChange
+	res = reviewITDeductionChange(change.Id, "approve", "approverTax", "approver!")
+	if err := res.Decode(&got); err != nil {
 		t.Errorf("expect response body to be valid json but got %q", err)
 	}
 	assertHttpCode(t, http.StatusOK, res.StatusCode)
-
-	var want = models.PersonalResponse{Amount: 60_000}
-
-	if !reflect.DeepEqual(want, got) {
-		t.Errorf("expect %#v but got %#v", want, got)
+	if got.Status != models.ApprovedChange || got.Amount != 60_000 {
+		t.Errorf("expect approved change of 60,000 but got %#v", got)
 	}
+	if want := fmt.Sprintf(`"%d"`, change.Version+1); res.Header.Get("ETag") != want {
+		t.Errorf("expect ETag %s after approve but got %s", want, res.Header.Get("ETag"))
+	}
+
+	// version that editor read before approval is out of date
+	res = proposeITRequest("/admin/deductions/personal", "70000.0", fmt.Sprintf(`"%d"`, change.Version))
+	assertHttpCode(t, http.StatusPreconditionFailed, res.StatusCode)
 }
 
 func TestITKReceiptDeduction(t *testing.T) {
-	var got models.KReceiptResponse
-
-	res := clientITRequest(
-		http.MethodPost,
-		os.Getenv("API_URL")+"/admin/deductions/k-receipt",
-		strings.NewReader(
-			`{
-			"amount": 70000.0
-		}`),
-		"application/json;charset=UTF-8",
-		"adminTax",
-		"admin!",
-	)
+	change := proposeITDeduction(t, "/admin/deductions/k-receipt", "70000.0")
 
-	err := res.Decode(&got)
-	if err != nil {
+	var got models.DeductionChange
+	res := reviewITDeductionChange(change.Id, "reject", "approverTax", "approver!")
+	if err := res.Decode(&got); err != nil {
 		t.Errorf("expect response body to be valid json but got %q", err)
 	}
 	assertHttpCode(t, http.StatusOK, res.StatusCode)
+	if got.Status != models.RejectedChange {
+		t.Errorf("expect rejected change but got %#v", got)
+	}
+
+	res = reviewITDeductionChange(change.Id, "approve", "approverTax", "approver!")
+	assertHttpCode(t, http.StatusConflict, res.StatusCode)
+}
 
-	var want = models.KReceiptResponse{Amount: 70_000}
+func TestITConfigExportImport(t *testing.T) {
+	var export models.ConfigExport
+	res := clientITRequest(http.MethodGet, os.Getenv("API_URL")+"/admin/config/export", nil, "application/json;charset=UTF-8", "adminTax", "admin!")
+	if err := res.Decode(&export); err != nil {
+		t.Errorf("expect response body to be valid json but got %q", err)
+	}
+	assertHttpCode(t, http.StatusOK, res.StatusCode)
+	if len(export.Deductions) == 0 {
+		t.Fatal("expect exported deductions")
+	}
 
-	if !reflect.DeepEqual(want, got) {
-		t.Errorf("expect %#v but got %#v", want, got)
+	body, _ := json.Marshal(export)
+	var got models.ConfigImportResponse
+	res = clientITRequest(http.MethodPost, os.Getenv("API_URL")+"/admin/config/import?dryRun=true", bytes.NewReader(body), "application/json;charset=UTF-8", "adminTax", "admin!")
+	if err := res.Decode(&got); err != nil {
+		t.Errorf("expect response body to be valid json but got %q", err)
+	}
+	assertHttpCode(t, http.StatusOK, res.StatusCode)
+	if !got.DryRun || len(got.Changes) != 0 {
+		t.Errorf("expect importing exported config has no change but got %#v", got)
 	}
 }
@@ -4,49 +4,86 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/baronight/assessment-tax/config"
 	"github.com/baronight/assessment-tax/middlewares"
 	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/services"
 	"github.com/baronight/assessment-tax/utils"
 	"github.com/labstack/echo/v4"
 )
 
 type AdminRequestConfig struct {
-	method string
-	url    string
-	user   string
-	pass   string
-	body   io.Reader
+	method  string
+	url     string
+	user    string
+	pass    string
+	ifMatch string
+	body    io.Reader
 }
 
 type StubAdminServicer struct {
 	expectToCall    map[string]bool
 	expectCallTimes map[string]int
 	err             error
-	deduction       models.Deduction
+	change          models.DeductionChange
 	errValidate     error
+	user            string
+	version         int
+	deduction       models.Deduction
+	dryRun          bool
 }
 
-func (s *StubAdminServicer) ValidateDeductionRequest(slug string, amount float64) error {
+func (s *StubAdminServicer) ValidateDeductionRequest(ctx context.Context, slug string, amount float64) error {
 	s.expectToCall["ValidateDeductionRequest"] = true
 	s.expectCallTimes["ValidateDeductionRequest"]++
 	return s.errValidate
 }
-func (s *StubAdminServicer) UpdateDeductionConfig(slug string, deduction models.DeductionRequest) (models.Deduction, error) {
-	s.expectToCall["UpdateDeductionConfig"] = true
-	s.expectCallTimes["UpdateDeductionConfig"]++
+func (s *StubAdminServicer) GetDeductionConfig(ctx context.Context, slug string) (models.Deduction, error) {
+	s.expectToCall["GetDeductionConfig"] = true
 	return s.deduction, s.err
 }
+func (s *StubAdminServicer) ProposeDeductionChange(ctx context.Context, slug string, deduction models.DeductionRequest, version int, editor string) (models.DeductionChange, error) {
+	s.expectToCall["ProposeDeductionChange"] = true
+	s.expectCallTimes["ProposeDeductionChange"]++
+	s.user = editor
+	s.version = version
+	return s.change, s.err
+}
+func (s *StubAdminServicer) GetDeductionChanges(ctx context.Context, status string) ([]models.DeductionChange, error) {
+	s.expectToCall["GetDeductionChanges"] = true
+	return []models.DeductionChange{s.change}, s.err
+}
+func (s *StubAdminServicer) ApproveDeductionChange(ctx context.Context, id uint, reviewer string) (models.DeductionChange, error) {
+	s.expectToCall["ApproveDeductionChange"] = true
+	s.user = reviewer
+	return s.change, s.err
+}
+func (s *StubAdminServicer) RejectDeductionChange(ctx context.Context, id uint, reviewer string, review models.DeductionChangeReview) (models.DeductionChange, error) {
+	s.expectToCall["RejectDeductionChange"] = true
+	s.user = reviewer
+	return s.change, s.err
+}
+
+func (s *StubAdminServicer) ExportConfig(ctx context.Context) (models.ConfigExport, error) {
+	s.expectToCall["ExportConfig"] = true
+	return models.ConfigExport{Deductions: []models.DeductionConfig{{Slug: s.deduction.Slug, Amount: s.deduction.Amount}}}, s.err
+}
+func (s *StubAdminServicer) ImportConfig(ctx context.Context, config models.ConfigExport, dryRun bool, editor string) (models.ConfigImportResponse, error) {
+	s.expectToCall["ImportConfig"] = true
+	s.dryRun, s.user = dryRun, editor
+	return models.ConfigImportResponse{DryRun: dryRun, Changes: []models.DeductionConfigDiff{}}, s.err
+}
 
 func (s *StubAdminServicer) assertMethodWasCalled(t *testing.T, methodName string) {
 	t.Helper()
@@ -67,15 +104,21 @@ func (s *StubAdminServicer) assertMethodCalledTime(t *testing.T, methodName stri
 	}
 }
 
+// testAuth is admin that can call admin handlers in tests
+var testAuth = config.Auth{Admins: map[string]string{"adminTax": "admin!", "approver": "approve!"}}
+
 func setupAdminHandler(config AdminRequestConfig) (res *httptest.ResponseRecorder, c echo.Context, h *AdminHandlers, stub *StubAdminServicer, mw echo.MiddlewareFunc) {
 	e := echo.New()
 	// e.Validator = &models.CustomValidator{Validator: validator.New()}
 	req := httptest.NewRequest(config.method, config.url, config.body)
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	req.SetBasicAuth(config.user, config.pass)
+	if config.ifMatch != "" {
+		req.Header.Set("If-Match", config.ifMatch)
+	}
 
 	res = httptest.NewRecorder()
-	mw = middlewares.BasicAuthMiddleware()
+	mw = middlewares.BasicAuthMiddleware(testAuth)
 	e.Use(mw)
 	c = e.NewContext(req, res)
 	stub = &StubAdminServicer{
@@ -87,8 +130,6 @@ func setupAdminHandler(config AdminRequestConfig) (res *httptest.ResponseRecorde
 }
 
 func TestPersonalDeductionConfigHandler(t *testing.T) {
-	os.Setenv("ADMIN_USERNAME", "adminTax")
-	os.Setenv("ADMIN_PASSWORD", "admin!")
 	url := "/admin/deductions/personal"
 	t.Run("given invalid authentication should return status 401", func(t *testing.T) {
 		body, _ := json.Marshal(models.DeductionRequest{Amount: 60_000})
@@ -112,18 +153,19 @@ func TestPersonalDeductionConfigHandler(t *testing.T) {
 			statusCode = err.(*echo.HTTPError).Code
 		}
 		stub.assertMethodWasNotCalled(t, "ValidateDeductionRequest")
-		stub.assertMethodWasNotCalled(t, "UpdateDeductionConfig")
+		stub.assertMethodWasNotCalled(t, "ProposeDeductionChange")
 		assertHttpCode(t, http.StatusUnauthorized, statusCode)
 	})
 	t.Run("given amount is invalid should return 400 with error message from validate function", func(t *testing.T) {
 		body, _ := json.Marshal(models.DeductionRequest{Amount: 9_000})
 		res, c, h, stub, mw := setupAdminHandler(
 			AdminRequestConfig{
-				method: http.MethodPost,
-				url:    url,
-				user:   "adminTax",
-				pass:   "admin!",
-				body:   strings.NewReader(string(body)),
+				method:  http.MethodPost,
+				url:     url,
+				user:    "adminTax",
+				pass:    "admin!",
+				ifMatch: `"1"`,
+				body:    strings.NewReader(string(body)),
 			},
 		)
 		stub.errValidate = errors.New("error 'xxx' occured")
@@ -139,7 +181,7 @@ func TestPersonalDeductionConfigHandler(t *testing.T) {
 		}
 		stub.assertMethodWasCalled(t, "ValidateDeductionRequest")
 		stub.assertMethodCalledTime(t, "ValidateDeductionRequest", 1)
-		stub.assertMethodWasNotCalled(t, "UpdateDeductionConfig")
+		stub.assertMethodWasNotCalled(t, "ProposeDeductionChange")
 		assertHttpCode(t, http.StatusBadRequest, statusCode)
 		got := decodeErrorResponse(t, res)
 		assertErrorMessage(t, stub.errValidate.Error(), got.Message)
@@ -148,11 +190,12 @@ func TestPersonalDeductionConfigHandler(t *testing.T) {
 		body, _ := json.Marshal(models.DeductionRequest{Amount: 60_000})
 		res, c, h, stub, mw := setupAdminHandler(
 			AdminRequestConfig{
-				method: http.MethodPost,
-				url:    url,
-				user:   "adminTax",
-				pass:   "admin!",
-				body:   strings.NewReader(string(body)),
+				method:  http.MethodPost,
+				url:     url,
+				user:    "adminTax",
+				pass:    "admin!",
+				ifMatch: `"1"`,
+				body:    strings.NewReader(string(body)),
 			},
 		)
 		stub.err = sql.ErrNoRows
@@ -167,21 +210,22 @@ func TestPersonalDeductionConfigHandler(t *testing.T) {
 			statusCode = err.(*echo.HTTPError).Code
 		}
 		stub.assertMethodWasCalled(t, "ValidateDeductionRequest")
-		stub.assertMethodWasCalled(t, "UpdateDeductionConfig")
-		stub.assertMethodCalledTime(t, "UpdateDeductionConfig", 1)
+		stub.assertMethodWasCalled(t, "ProposeDeductionChange")
+		stub.assertMethodCalledTime(t, "ProposeDeductionChange", 1)
 		assertHttpCode(t, http.StatusNotFound, statusCode)
 		got := decodeErrorResponse(t, res)
 		assertErrorMessage(t, "data not found", got.Message)
 	})
-	t.Run("given error on call 'UpdateDeductionConfig' should return status 500 with message 'internal server error'", func(t *testing.T) {
+	t.Run("given error on call 'ProposeDeductionChange' should return status 500 with message 'internal server error'", func(t *testing.T) {
 		body, _ := json.Marshal(models.DeductionRequest{Amount: 60_000})
 		res, c, h, stub, mw := setupAdminHandler(
 			AdminRequestConfig{
-				method: http.MethodPost,
-				url:    url,
-				user:   "adminTax",
-				pass:   "admin!",
-				body:   strings.NewReader(string(body)),
+				method:  http.MethodPost,
+				url:     url,
+				user:    "adminTax",
+				pass:    "admin!",
+				ifMatch: `"1"`,
+				body:    strings.NewReader(string(body)),
 			},
 		)
 		stub.err = errors.New("error 'xxx' occured")
@@ -196,24 +240,25 @@ func TestPersonalDeductionConfigHandler(t *testing.T) {
 			statusCode = err.(*echo.HTTPError).Code
 		}
 		stub.assertMethodWasCalled(t, "ValidateDeductionRequest")
-		stub.assertMethodWasCalled(t, "UpdateDeductionConfig")
-		stub.assertMethodCalledTime(t, "UpdateDeductionConfig", 1)
+		stub.assertMethodWasCalled(t, "ProposeDeductionChange")
+		stub.assertMethodCalledTime(t, "ProposeDeductionChange", 1)
 		assertHttpCode(t, http.StatusInternalServerError, statusCode)
 		got := decodeErrorResponse(t, res)
 		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
 	})
-	t.Run("given valid amount should return 200 with updated personal deduction amount", func(t *testing.T) {
+	t.Run("given valid amount should return 202 with pending personal deduction change", func(t *testing.T) {
 		body, _ := json.Marshal(models.DeductionRequest{Amount: 60_000})
 		res, c, h, stub, mw := setupAdminHandler(
 			AdminRequestConfig{
-				method: http.MethodPost,
-				url:    url,
-				user:   "adminTax",
-				pass:   "admin!",
-				body:   strings.NewReader(string(body)),
+				method:  http.MethodPost,
+				url:     url,
+				user:    "adminTax",
+				pass:    "admin!",
+				ifMatch: `"1"`,
+				body:    strings.NewReader(string(body)),
 			},
 		)
-		stub.deduction = models.Deduction{Amount: 60_000}
+		stub.change = models.DeductionChange{Id: 1, Amount: 60_000, Status: models.PendingChange, ProposedBy: "adminTax"}
 
 		err := mw(func(c echo.Context) error {
 			return h.PersonalDeductionConfigHandler(c)
@@ -225,11 +270,14 @@ func TestPersonalDeductionConfigHandler(t *testing.T) {
 			statusCode = err.(*echo.HTTPError).Code
 		}
 		stub.assertMethodWasCalled(t, "ValidateDeductionRequest")
-		stub.assertMethodWasCalled(t, "UpdateDeductionConfig")
-		stub.assertMethodCalledTime(t, "UpdateDeductionConfig", 1)
-		assertHttpCode(t, http.StatusOK, statusCode)
-		want := models.PersonalResponse{Amount: 60_000}
-		var got models.PersonalResponse
+		stub.assertMethodWasCalled(t, "ProposeDeductionChange")
+		stub.assertMethodCalledTime(t, "ProposeDeductionChange", 1)
+		assertHttpCode(t, http.StatusAccepted, statusCode)
+		if stub.user != "adminTax" {
+			t.Errorf("expect change is proposed by adminTax but got %q", stub.user)
+		}
+		want := stub.change
+		var got models.DeductionChange
 		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
 			t.Errorf("expect response body to be valid json but got %s", res.Body.String())
 		}
@@ -240,8 +288,6 @@ func TestPersonalDeductionConfigHandler(t *testing.T) {
 }
 
 func TestKReceiptDeductionConfigHandler(t *testing.T) {
-	os.Setenv("ADMIN_USERNAME", "adminTax")
-	os.Setenv("ADMIN_PASSWORD", "admin!")
 	url := "/admin/deductions/k-receipt"
 	t.Run("given invalid authentication should return status 401", func(t *testing.T) {
 		body, _ := json.Marshal(models.DeductionRequest{Amount: 60_000})
@@ -265,18 +311,19 @@ func TestKReceiptDeductionConfigHandler(t *testing.T) {
 			statusCode = err.(*echo.HTTPError).Code
 		}
 		stub.assertMethodWasNotCalled(t, "ValidateDeductionRequest")
-		stub.assertMethodWasNotCalled(t, "UpdateDeductionConfig")
+		stub.assertMethodWasNotCalled(t, "ProposeDeductionChange")
 		assertHttpCode(t, http.StatusUnauthorized, statusCode)
 	})
 	t.Run("given amount is invalid should return 400 with error message from validate function", func(t *testing.T) {
 		body, _ := json.Marshal(models.DeductionRequest{Amount: 900_000})
 		res, c, h, stub, mw := setupAdminHandler(
 			AdminRequestConfig{
-				method: http.MethodPost,
-				url:    url,
-				user:   "adminTax",
-				pass:   "admin!",
-				body:   strings.NewReader(string(body)),
+				method:  http.MethodPost,
+				url:     url,
+				user:    "adminTax",
+				pass:    "admin!",
+				ifMatch: `"1"`,
+				body:    strings.NewReader(string(body)),
 			},
 		)
 		stub.errValidate = errors.New("error 'xxx' occured")
@@ -292,7 +339,7 @@ func TestKReceiptDeductionConfigHandler(t *testing.T) {
 		}
 		stub.assertMethodWasCalled(t, "ValidateDeductionRequest")
 		stub.assertMethodCalledTime(t, "ValidateDeductionRequest", 1)
-		stub.assertMethodWasNotCalled(t, "UpdateDeductionConfig")
+		stub.assertMethodWasNotCalled(t, "ProposeDeductionChange")
 		assertHttpCode(t, http.StatusBadRequest, statusCode)
 		got := decodeErrorResponse(t, res)
 		assertErrorMessage(t, stub.errValidate.Error(), got.Message)
@@ -301,11 +348,12 @@ func TestKReceiptDeductionConfigHandler(t *testing.T) {
 		body, _ := json.Marshal(models.DeductionRequest{Amount: 60_000})
 		res, c, h, stub, mw := setupAdminHandler(
 			AdminRequestConfig{
-				method: http.MethodPost,
-				url:    url,
-				user:   "adminTax",
-				pass:   "admin!",
-				body:   strings.NewReader(string(body)),
+				method:  http.MethodPost,
+				url:     url,
+				user:    "adminTax",
+				pass:    "admin!",
+				ifMatch: `"1"`,
+				body:    strings.NewReader(string(body)),
 			},
 		)
 		stub.err = sql.ErrNoRows
@@ -320,21 +368,22 @@ func TestKReceiptDeductionConfigHandler(t *testing.T) {
 			statusCode = err.(*echo.HTTPError).Code
 		}
 		stub.assertMethodWasCalled(t, "ValidateDeductionRequest")
-		stub.assertMethodWasCalled(t, "UpdateDeductionConfig")
-		stub.assertMethodCalledTime(t, "UpdateDeductionConfig", 1)
+		stub.assertMethodWasCalled(t, "ProposeDeductionChange")
+		stub.assertMethodCalledTime(t, "ProposeDeductionChange", 1)
 		assertHttpCode(t, http.StatusNotFound, statusCode)
 		got := decodeErrorResponse(t, res)
 		assertErrorMessage(t, "data not found", got.Message)
 	})
-	t.Run("given error on call 'UpdateDeductionConfig' should return status 500 with message 'internal server error'", func(t *testing.T) {
+	t.Run("given error on call 'ProposeDeductionChange' should return status 500 with message 'internal server error'", func(t *testing.T) {
 		body, _ := json.Marshal(models.DeductionRequest{Amount: 60_000})
 		res, c, h, stub, mw := setupAdminHandler(
 			AdminRequestConfig{
-				method: http.MethodPost,
-				url:    url,
-				user:   "adminTax",
-				pass:   "admin!",
-				body:   strings.NewReader(string(body)),
+				method:  http.MethodPost,
+				url:     url,
+				user:    "adminTax",
+				pass:    "admin!",
+				ifMatch: `"1"`,
+				body:    strings.NewReader(string(body)),
 			},
 		)
 		stub.err = errors.New("error 'xxx' occured")
@@ -349,24 +398,25 @@ func TestKReceiptDeductionConfigHandler(t *testing.T) {
 			statusCode = err.(*echo.HTTPError).Code
 		}
 		stub.assertMethodWasCalled(t, "ValidateDeductionRequest")
-		stub.assertMethodWasCalled(t, "UpdateDeductionConfig")
-		stub.assertMethodCalledTime(t, "UpdateDeductionConfig", 1)
+		stub.assertMethodWasCalled(t, "ProposeDeductionChange")
+		stub.assertMethodCalledTime(t, "ProposeDeductionChange", 1)
 		assertHttpCode(t, http.StatusInternalServerError, statusCode)
 		got := decodeErrorResponse(t, res)
 		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
 	})
-	t.Run("given valid amount should return 200 with updated k-receipt deduction amount", func(t *testing.T) {
+	t.Run("given valid amount should return 202 with pending k-receipt deduction change", func(t *testing.T) {
 		body, _ := json.Marshal(models.DeductionRequest{Amount: 60_000})
 		res, c, h, stub, mw := setupAdminHandler(
 			AdminRequestConfig{
-				method: http.MethodPost,
-				url:    url,
-				user:   "adminTax",
-				pass:   "admin!",
-				body:   strings.NewReader(string(body)),
+				method:  http.MethodPost,
+				url:     url,
+				user:    "adminTax",
+				pass:    "admin!",
+				ifMatch: `"1"`,
+				body:    strings.NewReader(string(body)),
 			},
 		)
-		stub.deduction = models.Deduction{Amount: 60_000}
+		stub.change = models.DeductionChange{Id: 1, Amount: 60_000, Status: models.PendingChange, ProposedBy: "adminTax"}
 
 		err := mw(func(c echo.Context) error {
 			return h.KReceiptDeductionConfigHandler(c)
@@ -378,11 +428,14 @@ func TestKReceiptDeductionConfigHandler(t *testing.T) {
 			statusCode = err.(*echo.HTTPError).Code
 		}
 		stub.assertMethodWasCalled(t, "ValidateDeductionRequest")
-		stub.assertMethodWasCalled(t, "UpdateDeductionConfig")
-		stub.assertMethodCalledTime(t, "UpdateDeductionConfig", 1)
-		assertHttpCode(t, http.StatusOK, statusCode)
-		want := models.KReceiptResponse{Amount: 60_000}
-		var got models.KReceiptResponse
+		stub.assertMethodWasCalled(t, "ProposeDeductionChange")
+		stub.assertMethodCalledTime(t, "ProposeDeductionChange", 1)
+		assertHttpCode(t, http.StatusAccepted, statusCode)
+		if stub.user != "adminTax" {
+			t.Errorf("expect change is proposed by adminTax but got %q", stub.user)
+		}
+		want := stub.change
+		var got models.DeductionChange
 		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
 			t.Errorf("expect response body to be valid json but got %s", res.Body.String())
 		}
@@ -391,3 +444,151 @@ func TestKReceiptDeductionConfigHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestDeductionConfigETag(t *testing.T) {
+	setup := func(method, ifMatch string) (*httptest.ResponseRecorder, echo.Context, *AdminHandlers, *StubAdminServicer, echo.MiddlewareFunc) {
+		body, _ := json.Marshal(models.DeductionRequest{Amount: 60_000})
+		return setupAdminHandler(AdminRequestConfig{
+			method:  method,
+			url:     "/admin/deductions/personal",
+			user:    "adminTax",
+			pass:    "admin!",
+			ifMatch: ifMatch,
+			body:    strings.NewReader(string(body)),
+		})
+	}
+	t.Run("given exist deduction should return 200 with version in ETag header", func(t *testing.T) {
+		res, c, h, stub, mw := setup(http.MethodGet, "")
+		c.SetParamNames("slug")
+		c.SetParamValues("personal")
+		stub.deduction = models.Deduction{Slug: "personal", Amount: 60_000, Version: 3}
+
+		mw(h.GetDeductionConfigHandler)(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if got := res.Header().Get("ETag"); got != `"3"` {
+			t.Errorf("expect ETag %q but got %q", `"3"`, got)
+		}
+	})
+	t.Run("given not exist deduction should return 404", func(t *testing.T) {
+		res, c, h, stub, mw := setup(http.MethodGet, "")
+		stub.err = sql.ErrNoRows
+
+		mw(h.GetDeductionConfigHandler)(c)
+
+		assertHttpCode(t, http.StatusNotFound, res.Code)
+	})
+	t.Run("given no If-Match header should return 428", func(t *testing.T) {
+		res, c, h, stub, mw := setup(http.MethodPost, "")
+
+		mw(h.PersonalDeductionConfigHandler)(c)
+
+		stub.assertMethodWasNotCalled(t, "ProposeDeductionChange")
+		assertHttpCode(t, http.StatusPreconditionRequired, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrVersionRequired.Error(), got.Message)
+	})
+	t.Run("given If-Match that is not version should return 412", func(t *testing.T) {
+		res, c, h, stub, mw := setup(http.MethodPost, `"abc"`)
+
+		mw(h.PersonalDeductionConfigHandler)(c)
+
+		stub.assertMethodWasNotCalled(t, "ProposeDeductionChange")
+		assertHttpCode(t, http.StatusPreconditionFailed, res.Code)
+	})
+	t.Run("given weak ETag in If-Match should propose change on that version", func(t *testing.T) {
+		res, c, h, stub, mw := setup(http.MethodPost, `W/"2"`)
+		stub.change = models.DeductionChange{Id: 1, Amount: 60_000, Version: 2, Status: models.PendingChange}
+
+		mw(h.PersonalDeductionConfigHandler)(c)
+
+		assertHttpCode(t, http.StatusAccepted, res.Code)
+		if stub.version != 2 {
+			t.Errorf("expect change is proposed on version 2 but got %d", stub.version)
+		}
+		if got := res.Header().Get("ETag"); got != `"2"` {
+			t.Errorf("expect ETag %q but got %q", `"2"`, got)
+		}
+	})
+	t.Run("given outdated version should return 412", func(t *testing.T) {
+		res, c, h, stub, mw := setup(http.MethodPost, `"1"`)
+		stub.err = utils.ErrVersionMismatch
+
+		mw(h.PersonalDeductionConfigHandler)(c)
+
+		assertHttpCode(t, http.StatusPreconditionFailed, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrVersionMismatch.Error(), got.Message)
+	})
+}
+
+func TestDeductionChangeHandlers(t *testing.T) {
+	setup := func(method, url, id, user, pass string, body io.Reader) (*httptest.ResponseRecorder, echo.Context, *AdminHandlers, *StubAdminServicer, echo.MiddlewareFunc) {
+		res, c, h, stub, mw := setupAdminHandler(AdminRequestConfig{method: method, url: url, user: user, pass: pass, body: body})
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return res, c, h, stub, mw
+	}
+	t.Run("given user in ADMIN_USERS should approve change as that user", func(t *testing.T) {
+		res, c, h, stub, mw := setup(http.MethodPost, "/admin/deduction-changes/1/approve", "1", "approver", "approve!", nil)
+		stub.change = models.DeductionChange{Id: 1, Version: 1, Status: models.ApprovedChange, ReviewedBy: "approver"}
+
+		mw(h.ApproveDeductionChangeHandler)(c)
+
+		stub.assertMethodWasCalled(t, "ApproveDeductionChange")
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if got := res.Header().Get("ETag"); got != `"2"` {
+			t.Errorf("expect ETag of updated deduction %q but got %q", `"2"`, got)
+		}
+		if stub.user != "approver" {
+			t.Errorf("expect change is reviewed by approver but got %q", stub.user)
+		}
+	})
+	t.Run("given id that is not number should return 400", func(t *testing.T) {
+		res, c, h, stub, mw := setup(http.MethodPost, "/admin/deduction-changes/x/approve", "x", "approver", "approve!", nil)
+
+		mw(h.ApproveDeductionChangeHandler)(c)
+
+		stub.assertMethodWasNotCalled(t, "ApproveDeductionChange")
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+	testSuites := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"given change proposed by same admin should return 403", utils.ErrSelfReview, http.StatusForbidden},
+		{"given not exist change should return 404", utils.ErrDeductionChangeNotFound, http.StatusNotFound},
+		{"given reviewed change should return 409", utils.ErrDeductionChangeReviewed, http.StatusConflict},
+		{"given deduction was changed after proposal should return 412", utils.ErrVersionMismatch, http.StatusPreconditionFailed},
+		{"given amount out of limit at approval should return 400", services.ErrDeductionAmountInvalid, http.StatusBadRequest},
+		{"given error from service should return 500", errors.New("error 'xxx' occured"), http.StatusInternalServerError},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			res, c, h, stub, mw := setup(http.MethodPost, "/admin/deduction-changes/1/approve", "1", "adminTax", "admin!", nil)
+			stub.err = tc.err
+
+			mw(h.ApproveDeductionChangeHandler)(c)
+
+			assertHttpCode(t, tc.want, res.Code)
+		})
+	}
+	t.Run("given reason should reject change", func(t *testing.T) {
+		res, c, h, stub, mw := setup(http.MethodPost, "/admin/deduction-changes/1/reject", "1", "approver", "approve!", strings.NewReader(`{"reason":"not announced"}`))
+		stub.change = models.DeductionChange{Id: 1, Status: models.RejectedChange, Reason: "not announced"}
+
+		mw(h.RejectDeductionChangeHandler)(c)
+
+		stub.assertMethodWasCalled(t, "RejectDeductionChange")
+		assertHttpCode(t, http.StatusOK, res.Code)
+	})
+	t.Run("should list deduction changes", func(t *testing.T) {
+		res, c, h, stub, mw := setup(http.MethodGet, "/admin/deduction-changes", "", "adminTax", "admin!", nil)
+
+		mw(h.GetDeductionChangesHandler)(c)
+
+		stub.assertMethodWasCalled(t, "GetDeductionChanges")
+		assertHttpCode(t, http.StatusOK, res.Code)
+	})
+}
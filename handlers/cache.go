@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/labstack/echo/v4"
+)
+
+type CacheHandlers struct {
+	Service CacheServicer
+}
+
+type CacheServicer interface {
+	Stats() models.CacheStats
+}
+
+func NewCacheHandlers(service CacheServicer) *CacheHandlers {
+	return &CacheHandlers{Service: service}
+}
+
+// CacheStatsHandler
+//
+// @Summary Cache Stats API
+// @Description To get hit and miss count of deduction cache since start, and whether it is invalidated by database notification
+// @Tags admin, cache
+// @Produce json
+// @Security BasicAuth
+// @Success 200 {object} CacheStats
+// @Router /admin/cache/stats [get]
+// @Failure 401 {object} ErrorResponse "unauthorized"
+func (h *CacheHandlers) CacheStatsHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.Service.Stats())
+}
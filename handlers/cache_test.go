@@ -0,0 +1,41 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/labstack/echo/v4"
+)
+
+type stubCacheServicer struct {
+	stats models.CacheStats
+}
+
+func (s *stubCacheServicer) Stats() models.CacheStats {
+	return s.stats
+}
+
+func TestCacheStatsHandler(t *testing.T) {
+	t.Run("should return 200 with cache stats", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil)
+		res := httptest.NewRecorder()
+		c := e.NewContext(req, res)
+		stub := &stubCacheServicer{stats: models.CacheStats{Hits: 10, Misses: 2, Listening: true}}
+		h := NewCacheHandlers(stub)
+
+		h.CacheStatsHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got models.CacheStats
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || got != stub.stats {
+			t.Errorf("expect %#v but got %s", stub.stats, res.Body.String())
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type CertificateHandlers struct {
+	Service CertificateServicer
+}
+
+type CertificateServicer interface {
+	CertificateTaxCalculate(ctx context.Context, req models.CertificateTaxRequest) (models.CertificateTaxResponse, error)
+	ExtractCertificateCsv(reader io.Reader) ([]models.Certificate, error)
+}
+
+func NewCertificateHandlers(service CertificateServicer) *CertificateHandlers {
+	return &CertificateHandlers{Service: service}
+}
+
+func (h *CertificateHandlers) certificateTaxCalculate(c echo.Context, req models.CertificateTaxRequest) error {
+	if !taxpayerAuthorized(c, req.TaxpayerId) {
+		return taxpayerUnauthorized(c)
+	}
+	if err := validators.ValidateCertificateTaxRequest(req); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.CertificateTaxCalculate(c.Request().Context(), req)
+	if err != nil {
+		c.Logger().Error(err)
+		if errors.Is(err, utils.ErrTaxpayerNotFound) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// CertificateTaxCalculateHandler
+//
+// @Summary Tax Calculate From Withholding Certificates API
+// @Description To calculate personal tax from withholding tax certificates (50 Tawi), income is aggregated by type and tax withheld is summed to wht
+// @Tags tax, certificate
+// @Accept json
+// @Produce json
+// @Param certificates body CertificateTaxRequest true "withholding certificates and allowances"
+// @Success 200 {object} CertificateTaxResponse
+// @Router /tax/calculations/certificates [post]
+// @Failure 400 {object} ErrorResponse "validate error, cannot get body or taxpayer not found"
+// @Failure 401 {object} ErrorResponse "taxpayer id is sent without authentication"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *CertificateHandlers) CertificateTaxCalculateHandler(c echo.Context) error {
+	body := new(models.CertificateTaxRequest)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+	return h.certificateTaxCalculate(c, *body)
+}
+
+// CertificateUploadCsvHandler
+//
+// @Summary Tax Calculate From Withholding Certificates CSV file API
+// @Description To calculate personal tax from csv file of withholding tax certificates with column payerTaxId, incomeType, amountPaid and taxWithheld
+// @Tags tax, certificate
+// @Accept mpfd
+// @Produce json
+// @Param certificateFile formData file true "csv certificate file"
+// @Param taxpayerId formData string false "taxpayer id for family allowances"
+// @Success 200 {object} CertificateTaxResponse
+// @Router /tax/calculations/certificates/upload-csv [post]
+// @Failure 400 {object} ErrorResponse "validate error, cannot get file or taxpayer not found"
+// @Failure 401 {object} ErrorResponse "taxpayer id is sent without authentication"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *CertificateHandlers) CertificateUploadCsvHandler(c echo.Context) error {
+	file, err := c.FormFile("certificateFile")
+	if err != nil {
+		return rejectCsv(c, "certificate", csvMissingFile, err.Error())
+	}
+	if fileType := file.Header.Get("Content-Type"); fileType != "text/csv" {
+		return rejectCsv(c, "certificate", csvNotCsv, "support only csv file")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return rejectCsv(c, "certificate", csvUnreadable, err.Error())
+	}
+	defer src.Close()
+
+	certificates, err := h.Service.ExtractCertificateCsv(src)
+	if err != nil {
+		return rejectCsv(c, "certificate", csvRejectReason(err), err.Error())
+	}
+	metrics.CsvRows.WithLabelValues("certificate").Add(float64(len(certificates)))
+
+	return h.certificateTaxCalculate(c, models.CertificateTaxRequest{
+		TaxpayerId:   c.FormValue("taxpayerId"),
+		Certificates: certificates,
+	})
+}
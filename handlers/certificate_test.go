@@ -0,0 +1,154 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/middlewares"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type stubCertificateServicer struct {
+	expectToCall map[string]bool
+	err          error
+	request      models.CertificateTaxRequest
+	certificates []models.Certificate
+}
+
+func (s *stubCertificateServicer) CertificateTaxCalculate(ctx context.Context, req models.CertificateTaxRequest) (models.CertificateTaxResponse, error) {
+	s.expectToCall["CertificateTaxCalculate"] = true
+	s.request = req
+	return models.CertificateTaxResponse{Result: models.TaxResponse{Tax: 16_000}}, s.err
+}
+func (s *stubCertificateServicer) ExtractCertificateCsv(reader io.Reader) ([]models.Certificate, error) {
+	s.expectToCall["ExtractCertificateCsv"] = true
+	return s.certificates, nil
+}
+
+func setupCertificateHandler(target string, body io.Reader, contentType string) (res *httptest.ResponseRecorder, c echo.Context, h *CertificateHandlers, stub *stubCertificateServicer) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, target, body)
+	req.Header.Set(echo.HeaderContentType, contentType)
+	res = httptest.NewRecorder()
+	c = e.NewContext(req, res)
+	stub = &stubCertificateServicer{expectToCall: make(map[string]bool)}
+	h = NewCertificateHandlers(stub)
+	return
+}
+
+func TestCertificateTaxCalculateHandler(t *testing.T) {
+	url := "/tax/calculations/certificates"
+	t.Run("given invalid certificate should return 400 with validate message", func(t *testing.T) {
+		res, c, h, stub := setupCertificateHandler(url, strings.NewReader(`{"certificates":[{"payerTaxId":"123","incomeType":"salary","amountPaid":1000}]}`), echo.MIMEApplicationJSON)
+
+		h.CertificateTaxCalculateHandler(c)
+
+		if stub.expectToCall["CertificateTaxCalculate"] {
+			t.Error("expect CertificateTaxCalculate was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, "certificate 1: "+validators.ErrPayerTaxIdInvalid.Error(), got.Message)
+	})
+	t.Run("given valid certificates should return 200 with result", func(t *testing.T) {
+		res, c, h, _ := setupCertificateHandler(url, strings.NewReader(`{"certificates":[{"payerTaxId":"0105556123453","incomeType":"salary","amountPaid":500000,"taxWithheld":25000}]}`), echo.MIMEApplicationJSON)
+
+		h.CertificateTaxCalculateHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got models.CertificateTaxResponse
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || got.Result.Tax != 16_000 {
+			t.Errorf("expect certificate tax response but got %s", res.Body.String())
+		}
+	})
+	t.Run("given error from service should return 500 with error message", func(t *testing.T) {
+		res, c, h, stub := setupCertificateHandler(url, strings.NewReader(`{"certificates":[{"payerTaxId":"0105556123453","incomeType":"salary","amountPaid":500000}]}`), echo.MIMEApplicationJSON)
+		stub.err = errors.New("error 'xxx' occured")
+
+		h.CertificateTaxCalculateHandler(c)
+
+		assertHttpCode(t, http.StatusInternalServerError, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
+	})
+}
+
+func TestCertificateUploadCsvHandler(t *testing.T) {
+	url := "/tax/calculations/certificates/upload-csv"
+	initBody := func(t *testing.T, filePath, contentType string) (*bytes.Buffer, *multipart.Writer) {
+		body := new(bytes.Buffer)
+		dir, _ := os.Getwd()
+		fileData, err := os.Open(filepath.Join(dir, filePath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fileData.Close()
+
+		writer := multipart.NewWriter(body)
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", `form-data; name="certificateFile"; filename="certificates.csv"`)
+		h.Set("Content-Type", contentType)
+		part, err := writer.CreatePart(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(part, fileData); err != nil {
+			t.Fatal(err)
+		}
+		writer.WriteField("taxpayerId", "1234567890121")
+		writer.Close()
+		return body, writer
+	}
+	t.Run("given csv file and taxpayer id should calculate all certificates", func(t *testing.T) {
+		body, writer := initBody(t, "../testdata/valid-certificates.csv", "text/csv")
+		res, c, h, stub := setupCertificateHandler(url, body, writer.FormDataContentType())
+		c.Set(middlewares.AdminUserKey, "adminTax")
+		stub.certificates = []models.Certificate{{PayerTaxId: "0105556123453", IncomeType: models.SalaryIncome, AmountPaid: 400_000}}
+
+		h.CertificateUploadCsvHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if stub.request.TaxpayerId != "1234567890121" || len(stub.request.Certificates) != 1 {
+			t.Errorf("expect request with taxpayer and certificates but got %#v", stub.request)
+		}
+	})
+	t.Run("given taxpayer id without authentication should return 401", func(t *testing.T) {
+		body, writer := initBody(t, "../testdata/valid-certificates.csv", "text/csv")
+		res, c, h, stub := setupCertificateHandler(url, body, writer.FormDataContentType())
+
+		h.CertificateUploadCsvHandler(c)
+
+		assertHttpCode(t, http.StatusUnauthorized, res.Code)
+		if stub.expectToCall["CertificateTaxCalculate"] {
+			t.Error("expect CertificateTaxCalculate was not called")
+		}
+	})
+	t.Run("given upload non csv file should return 400 with error message 'support only csv file'", func(t *testing.T) {
+		body, writer := initBody(t, "../testdata/taxes.txt", "text/plain")
+		res, c, h, stub := setupCertificateHandler(url, body, writer.FormDataContentType())
+
+		h.CertificateUploadCsvHandler(c)
+
+		if stub.expectToCall["ExtractCertificateCsv"] {
+			t.Error("expect ExtractCertificateCsv was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/baronight/assessment-tax/middlewares"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/services"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// ExportConfigHandler
+//
+// @Summary Export Config API
+// @Description To get all deductions with their min and max amount, that can be imported to other environment
+// @Tags admin, config
+// @Produce json
+// @Security BasicAuth
+// @Success 200 {object} ConfigExport
+// @Router /admin/config/export [get]
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *AdminHandlers) ExportConfigHandler(c echo.Context) error {
+	result, err := h.Service.ExportConfig(c.Request().Context())
+	if err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// ImportConfigHandler
+//
+// @Summary Import Config API
+// @Description To propose amount of deductions from exported config as pending deduction changes that another admin has to approve,
+// @Description deduction that is not in config is not changed. Amount is validated with current limit and min and max should be the same as current limit.
+// @Description Use dryRun to see what will be proposed first
+// @Tags admin, config
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param config body ConfigExport true "config from export API"
+// @Param dryRun query bool false "only return changes without saving"
+// @Success 200 {object} ConfigImportResponse
+// @Router /admin/config/import [post]
+// @Failure 400 {object} ErrorResponse "validate error or cannot get body"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *AdminHandlers) ImportConfigHandler(c echo.Context) error {
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dryRun"))
+	body := new(models.ConfigExport)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.ImportConfig(c.Request().Context(), *body, dryRun, middlewares.AdminUser(c))
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, result)
+	case errors.Is(err, services.ErrDeductionInvalid),
+		errors.Is(err, services.ErrDeductionAmountInvalid),
+		errors.Is(err, services.ErrDeductionLimitChanged),
+		errors.Is(err, services.ErrDeductionConfigDuplicated):
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	default:
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+}
@@ -0,0 +1,102 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/services"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+func TestExportConfigHandler(t *testing.T) {
+	config := AdminRequestConfig{method: http.MethodGet, url: "/admin/config/export", user: "adminTax", pass: "admin!"}
+	t.Run("should return 200 with deductions config", func(t *testing.T) {
+		res, c, h, stub, mw := setupAdminHandler(config)
+		stub.deduction = models.Deduction{Slug: "personal", Amount: 60_000}
+
+		mw(h.ExportConfigHandler)(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got models.ConfigExport
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || len(got.Deductions) != 1 || got.Deductions[0].Slug != "personal" {
+			t.Errorf("expect exported personal deduction but got %s", res.Body.String())
+		}
+	})
+	t.Run("given error from service should return 500", func(t *testing.T) {
+		res, c, h, stub, mw := setupAdminHandler(config)
+		stub.err = errors.New("error 'xxx' occured")
+
+		mw(h.ExportConfigHandler)(c)
+
+		assertHttpCode(t, http.StatusInternalServerError, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
+	})
+}
+
+func TestImportConfigHandler(t *testing.T) {
+	body := `{"deductions":[{"slug":"personal","amount":60000,"minAmount":10000,"maxAmount":100000}]}`
+	setup := func(url, body string) AdminRequestConfig {
+		return AdminRequestConfig{method: http.MethodPost, url: url, user: "adminTax", pass: "admin!", body: strings.NewReader(body)}
+	}
+	t.Run("given dryRun query should import config as dry run", func(t *testing.T) {
+		res, c, h, stub, mw := setupAdminHandler(setup("/admin/config/import?dryRun=true", body))
+
+		mw(h.ImportConfigHandler)(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if !stub.dryRun {
+			t.Error("expect config is imported as dry run")
+		}
+	})
+	t.Run("given no dryRun query should import config as changes proposed by admin", func(t *testing.T) {
+		res, c, h, stub, mw := setupAdminHandler(setup("/admin/config/import", body))
+
+		mw(h.ImportConfigHandler)(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if stub.dryRun {
+			t.Error("expect config is not imported as dry run")
+		}
+		if stub.user != "adminTax" {
+			t.Errorf("expect changes are proposed by adminTax but got %q", stub.user)
+		}
+	})
+	t.Run("given invalid body should return 400", func(t *testing.T) {
+		res, c, h, stub, mw := setupAdminHandler(setup("/admin/config/import", `{"deductions":"x"}`))
+
+		mw(h.ImportConfigHandler)(c)
+
+		stub.assertMethodWasNotCalled(t, "ImportConfig")
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+	testSuites := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"given not exist deduction should return 400", fmt.Errorf("deduction 1: %w", services.ErrDeductionInvalid), http.StatusBadRequest},
+		{"given amount out of limit should return 400", fmt.Errorf("deduction 1: %w", services.ErrDeductionAmountInvalid), http.StatusBadRequest},
+		{"given changed limit should return 400", fmt.Errorf("deduction 1: %w", services.ErrDeductionLimitChanged), http.StatusBadRequest},
+		{"given duplicated deduction should return 400", fmt.Errorf("deduction 2: %w", services.ErrDeductionConfigDuplicated), http.StatusBadRequest},
+		{"given error from service should return 500", errors.New("error 'xxx' occured"), http.StatusInternalServerError},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			res, c, h, stub, mw := setupAdminHandler(setup("/admin/config/import", body))
+			stub.err = tc.err
+
+			mw(h.ImportConfigHandler)(c)
+
+			assertHttpCode(t, tc.want, res.Code)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// reasons that csv upload is rejected, they are label of metrics.CsvRejections
+const (
+	csvMissingFile   = "missing_file"
+	csvNotCsv        = "not_csv"
+	csvUnreadable    = "unreadable"
+	csvMalformed     = "malformed"
+	csvMissingHeader = "missing_header"
+	csvEmptyValue    = "empty_value"
+	csvNotNumber     = "not_number"
+	csvInvalidValue  = "invalid_value"
+)
+
+// csvRejectReason return reason of error that is returned by extracting csv file
+func csvRejectReason(err error) string {
+	var parseErr *csv.ParseError
+	var numErr *strconv.NumError
+	switch {
+	case errors.As(err, &parseErr):
+		return csvMalformed
+	case errors.Is(err, utils.ErrCsvHeaderMissing):
+		return csvMissingHeader
+	case errors.Is(err, utils.ErrCsvValueEmpty):
+		return csvEmptyValue
+	case errors.As(err, &numErr):
+		return csvNotNumber
+	default:
+		return csvInvalidValue
+	}
+}
+
+// rejectCsv count rejected csv upload by reason and response message as bad request
+func rejectCsv(c echo.Context, upload, reason, message string) error {
+	metrics.CsvRejections.WithLabelValues(upload, reason).Inc()
+	return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: message})
+}
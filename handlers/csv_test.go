@@ -0,0 +1,36 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/baronight/assessment-tax/utils"
+)
+
+func TestCsvRejectReason(t *testing.T) {
+	_, numErr := strconv.ParseFloat("abc", 64)
+	testCases := []struct {
+		err  error
+		want string
+	}{
+		{err: &csv.ParseError{Line: 2, Err: csv.ErrFieldCount}, want: csvMalformed},
+		{err: utils.ErrCsvHeaderMissing, want: csvMissingHeader},
+		{err: fmt.Errorf("row 2: %w", utils.ErrCsvValueEmpty), want: csvEmptyValue},
+		{err: numErr, want: csvNotNumber},
+		{err: errors.New("totalIncome should be greater than or equal to 0"), want: csvInvalidValue},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("given %q should return %s", tc.err, tc.want), func(t *testing.T) {
+			if got := csvRejectReason(tc.err); got != tc.want {
+				t.Errorf("expect %q but got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type EFilingHandlers struct {
+	Service EFilingServicer
+}
+
+type EFilingServicer interface {
+	EFilingRecords(ctx context.Context, returns []models.TaxReturnRequest) ([]models.EFilingRecord, error)
+	WriteEFiling(w io.Writer, records []models.EFilingRecord, format string) error
+	ExtractEFilingCsv(reader io.Reader) ([]models.TaxReturnRequest, error)
+}
+
+func NewEFilingHandlers(service EFilingServicer) *EFilingHandlers {
+	return &EFilingHandlers{Service: service}
+}
+
+func efilingFormat(c echo.Context) (string, error) {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = models.EFilingFixedWidth
+	}
+	return format, validators.ValidateEFilingFormat(format)
+}
+
+// exportEFiling build e-filing records of returns and send them as attachment file
+func (h *EFilingHandlers) exportEFiling(c echo.Context, returns []models.TaxReturnRequest, format string) error {
+	records, err := h.Service.EFilingRecords(c.Request().Context(), returns)
+	if err != nil {
+		c.Logger().Error(err)
+		if errors.Is(err, utils.ErrExchangeRateNotFound) || errors.Is(err, utils.ErrTaxpayerNotFound) ||
+			errors.Is(err, utils.ErrEFilingRecordInvalid) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+
+	var buf bytes.Buffer
+	if err := h.Service.WriteEFiling(&buf, records, format); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	contentType := echo.MIMETextPlain + "; charset=" + models.EFilingCharset
+	if format == models.EFilingXml {
+		contentType = echo.MIMEApplicationXMLCharsetUTF8
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="e-filing.%s"`, format))
+	return c.Blob(http.StatusOK, contentType, buf.Bytes())
+}
+
+// EFilingExportHandler
+//
+// @Summary Revenue Department E-Filing Export API
+// @Description To calculate tax returns and export them as PND 90/91 in fixed-width (txt in TIS-620, width in bytes) or xml import layout of e-filing, taxpayer profile is required
+// @Tags tax, e-filing
+// @Accept json
+// @Produce plain,xml
+// @Security BasicAuth
+// @Param format query string false "txt (default) or xml"
+// @Param returns body EFilingRequest true "tax returns that want to export"
+// @Success 200 {file} file
+// @Router /tax/e-filing [post]
+// @Failure 400 {object} ErrorResponse "validate error, cannot get body, missing required field, exchange rate or taxpayer not found"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *EFilingHandlers) EFilingExportHandler(c echo.Context) error {
+	format, err := efilingFormat(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+	body := new(models.EFilingRequest)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	if err := validators.ValidateEFilingRequest(*body); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	return h.exportEFiling(c, body.Returns, format)
+}
+
+// EFilingUploadCsvHandler
+//
+// @Summary Revenue Department E-Filing Export From CSV file API
+// @Description To calculate tax csv file that has taxpayerId and taxYear column and export them in e-filing import layout
+// @Tags tax, e-filing
+// @Accept mpfd
+// @Produce plain,xml
+// @Security BasicAuth
+// @Param format query string false "txt (default) or xml"
+// @Param taxFile formData file true "csv tax file"
+// @Success 200 {file} file
+// @Router /tax/e-filing/upload-csv [post]
+// @Failure 400 {object} ErrorResponse "validate error, cannot get file, missing required field, exchange rate or taxpayer not found"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *EFilingHandlers) EFilingUploadCsvHandler(c echo.Context) error {
+	format, err := efilingFormat(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+	file, err := c.FormFile("taxFile")
+	if err != nil {
+		return rejectCsv(c, "efiling", csvMissingFile, err.Error())
+	}
+	if fileType := file.Header.Get("Content-Type"); fileType != "text/csv" {
+		return rejectCsv(c, "efiling", csvNotCsv, "support only csv file")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return rejectCsv(c, "efiling", csvUnreadable, err.Error())
+	}
+	defer src.Close()
+
+	returns, err := h.Service.ExtractEFilingCsv(src)
+	if err != nil {
+		return rejectCsv(c, "efiling", csvRejectReason(err), err.Error())
+	}
+	if err := validators.ValidateEFilingRequest(models.EFilingRequest{Returns: returns}); err != nil {
+		c.Logger().Error(err)
+		return rejectCsv(c, "efiling", csvInvalidValue, err.Error())
+	}
+	metrics.CsvRows.WithLabelValues("efiling").Add(float64(len(returns)))
+
+	return h.exportEFiling(c, returns, format)
+}
@@ -0,0 +1,112 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type stubEFilingServicer struct {
+	expectToCall map[string]bool
+	err          error
+	format       string
+}
+
+func (s *stubEFilingServicer) EFilingRecords(ctx context.Context, returns []models.TaxReturnRequest) ([]models.EFilingRecord, error) {
+	s.expectToCall["EFilingRecords"] = true
+	return []models.EFilingRecord{}, s.err
+}
+func (s *stubEFilingServicer) WriteEFiling(w io.Writer, records []models.EFilingRecord, format string) error {
+	s.expectToCall["WriteEFiling"] = true
+	s.format = format
+	_, err := io.WriteString(w, "content")
+	return err
+}
+func (s *stubEFilingServicer) ExtractEFilingCsv(reader io.Reader) ([]models.TaxReturnRequest, error) {
+	s.expectToCall["ExtractEFilingCsv"] = true
+	return nil, nil
+}
+
+func setupEFilingHandler(target string, body io.Reader) (res *httptest.ResponseRecorder, c echo.Context, h *EFilingHandlers, stub *stubEFilingServicer) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, target, body)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	res = httptest.NewRecorder()
+	c = e.NewContext(req, res)
+	stub = &stubEFilingServicer{expectToCall: make(map[string]bool)}
+	h = NewEFilingHandlers(stub)
+	return
+}
+
+const validEFilingBody = `{"returns":[{"taxpayerId":"1234567890121","taxYear":2567,"tax":{"totalIncome":500000}}]}`
+
+func TestEFilingExportHandler(t *testing.T) {
+	t.Run("given unknown format should return 400", func(t *testing.T) {
+		res, c, h, _ := setupEFilingHandler("/tax/e-filing?format=pdf", strings.NewReader(validEFilingBody))
+
+		h.EFilingExportHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrEFilingFormatInvalid.Error(), got.Message)
+	})
+	t.Run("given return missing taxpayer id should return 400", func(t *testing.T) {
+		res, c, h, stub := setupEFilingHandler("/tax/e-filing", strings.NewReader(`{"returns":[{"taxYear":2567}]}`))
+
+		h.EFilingExportHandler(c)
+
+		if stub.expectToCall["EFilingRecords"] {
+			t.Error("expect EFilingRecords was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+	t.Run("given record missing required field should return 400", func(t *testing.T) {
+		res, c, h, stub := setupEFilingHandler("/tax/e-filing", strings.NewReader(validEFilingBody))
+		stub.err = fmt.Errorf("return 1: %w: %w", utils.ErrEFilingRecordInvalid, validators.ErrTaxpayerNameRequired)
+
+		h.EFilingExportHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+	t.Run("given xml format should return xml attachment", func(t *testing.T) {
+		res, c, h, stub := setupEFilingHandler("/tax/e-filing?format=xml", strings.NewReader(validEFilingBody))
+
+		h.EFilingExportHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if stub.format != models.EFilingXml {
+			t.Errorf("expect format %q but got %q", models.EFilingXml, stub.format)
+		}
+		if got := res.Header().Get(echo.HeaderContentType); got != echo.MIMEApplicationXMLCharsetUTF8 {
+			t.Errorf("expect content type %q but got %q", echo.MIMEApplicationXMLCharsetUTF8, got)
+		}
+		if got := res.Header().Get(echo.HeaderContentDisposition); got != `attachment; filename="e-filing.xml"` {
+			t.Errorf("expect xml attachment but got %q", got)
+		}
+	})
+	t.Run("given no format should return fixed-width text", func(t *testing.T) {
+		res, c, h, stub := setupEFilingHandler("/tax/e-filing", strings.NewReader(validEFilingBody))
+
+		h.EFilingExportHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if stub.format != models.EFilingFixedWidth {
+			t.Errorf("expect format %q but got %q", models.EFilingFixedWidth, stub.format)
+		}
+		if res.Body.String() != "content" {
+			t.Errorf("expect file content but got %q", res.Body.String())
+		}
+	})
+}
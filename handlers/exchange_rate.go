@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type ExchangeRateHandlers struct {
+	Service ExchangeRateServicer
+}
+
+type ExchangeRateServicer interface {
+	SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) (models.ExchangeRateResponse, error)
+	ExtractExchangeRateCsv(reader io.Reader) ([]models.ExchangeRate, error)
+}
+
+func NewExchangeRateHandlers(service ExchangeRateServicer) *ExchangeRateHandlers {
+	return &ExchangeRateHandlers{Service: service}
+}
+
+// SaveExchangeRatesHandler
+//
+// @Summary Exchange Rate Config API
+// @Description To load daily exchange rates (THB per 1 unit) for convert foreign income and allowances in tax calculate
+// @Tags admin, exchange-rate
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param rates body ExchangeRateRequest true "list of exchange rates"
+// @Success 200 {object} ExchangeRateResponse
+// @Router /admin/exchange-rates [post]
+// @Failure 400 {object} ErrorResponse "validate error or cannot get body"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *ExchangeRateHandlers) SaveExchangeRatesHandler(c echo.Context) error {
+	body := new(models.ExchangeRateRequest)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	for _, v := range body.Rates {
+		if err := validators.ValidateExchangeRate(v); err != nil {
+			c.Logger().Error(err)
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+		}
+	}
+
+	result, err := h.Service.SaveExchangeRates(c.Request().Context(), body.Rates)
+	if err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// ExchangeRateUploadCsvHandler
+//
+// @Summary Exchange Rate From CSV file API
+// @Description To load daily exchange rates from csv file of Bank of Thailand reference rates with column date, currency and rate
+// @Tags admin, exchange-rate
+// @Accept mpfd
+// @Produce json
+// @Security BasicAuth
+// @Param rateFile formData file true "csv exchange rate file"
+// @Success 200 {object} ExchangeRateResponse
+// @Router /admin/exchange-rates/upload-csv [post]
+// @Failure 400 {object} ErrorResponse "validate error or cannot get file"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *ExchangeRateHandlers) ExchangeRateUploadCsvHandler(c echo.Context) error {
+	file, err := c.FormFile("rateFile")
+	if err != nil {
+		return rejectCsv(c, "exchange_rate", csvMissingFile, err.Error())
+	}
+	if fileType := file.Header.Get("Content-Type"); fileType != "text/csv" {
+		return rejectCsv(c, "exchange_rate", csvNotCsv, "support only csv file")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return rejectCsv(c, "exchange_rate", csvUnreadable, err.Error())
+	}
+	defer src.Close()
+
+	rates, err := h.Service.ExtractExchangeRateCsv(src)
+	if err != nil {
+		return rejectCsv(c, "exchange_rate", csvRejectReason(err), err.Error())
+	}
+	metrics.CsvRows.WithLabelValues("exchange_rate").Add(float64(len(rates)))
+
+	result, err := h.Service.SaveExchangeRates(c.Request().Context(), rates)
+	if err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
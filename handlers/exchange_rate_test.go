@@ -0,0 +1,88 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type stubExchangeRateServicer struct {
+	expectToCall map[string]bool
+	err          error
+	saved        []models.ExchangeRate
+}
+
+func (s *stubExchangeRateServicer) SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) (models.ExchangeRateResponse, error) {
+	s.expectToCall["SaveExchangeRates"] = true
+	s.saved = rates
+	return models.ExchangeRateResponse{Saved: len(rates)}, s.err
+}
+func (s *stubExchangeRateServicer) ExtractExchangeRateCsv(reader io.Reader) ([]models.ExchangeRate, error) {
+	s.expectToCall["ExtractExchangeRateCsv"] = true
+	return nil, nil
+}
+
+func setupExchangeRateHandler(body io.Reader) (res *httptest.ResponseRecorder, c echo.Context, h *ExchangeRateHandlers, stub *stubExchangeRateServicer) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/admin/exchange-rates", body)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	res = httptest.NewRecorder()
+	c = e.NewContext(req, res)
+	stub = &stubExchangeRateServicer{expectToCall: make(map[string]bool)}
+	h = NewExchangeRateHandlers(stub)
+	return
+}
+
+func TestSaveExchangeRatesHandler(t *testing.T) {
+	t.Run("given invalid rate should return 400 with validate message", func(t *testing.T) {
+		res, c, h, stub := setupExchangeRateHandler(strings.NewReader(`{"rates":[{"currency":"USD","rate":0,"rateDate":"2024-12-30"}]}`))
+
+		h.SaveExchangeRatesHandler(c)
+
+		if stub.expectToCall["SaveExchangeRates"] {
+			t.Error("expect SaveExchangeRates was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrExchangeRateInvalid.Error(), got.Message)
+	})
+	t.Run("given valid rates should return 200 with number of saved rates", func(t *testing.T) {
+		res, c, h, stub := setupExchangeRateHandler(strings.NewReader(`{"rates":[{"currency":"USD","rate":34.5,"rateDate":"2024-12-30"}]}`))
+
+		h.SaveExchangeRatesHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		want := []models.ExchangeRate{{Currency: "USD", Rate: 34.5, RateDate: "2024-12-30"}}
+		if !reflect.DeepEqual(want, stub.saved) {
+			t.Errorf("expect %#v but got %#v", want, stub.saved)
+		}
+		var got models.ExchangeRateResponse
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || got.Saved != 1 {
+			t.Errorf("expect saved 1 rate but got %s", res.Body.String())
+		}
+	})
+	t.Run("given error from service should return 500 with error message", func(t *testing.T) {
+		res, c, h, stub := setupExchangeRateHandler(strings.NewReader(`{"rates":[{"currency":"USD","rate":34.5,"rateDate":"2024-12-30"}]}`))
+		stub.err = errors.New("error 'xxx' occured")
+
+		h.SaveExchangeRatesHandler(c)
+
+		assertHttpCode(t, http.StatusInternalServerError, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
+	})
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/labstack/echo/v4"
+)
+
+type HealthHandlers struct {
+	Service HealthServicer
+}
+
+type HealthServicer interface {
+	Readiness(ctx context.Context) models.Readiness
+}
+
+func NewHealthHandlers(service HealthServicer) *HealthHandlers {
+	return &HealthHandlers{Service: service}
+}
+
+// LivenessHandler
+//
+// @Summary Liveness API
+// @Description To check that api process is alive, it does not check any dependency
+// @Tags health
+// @Produce json
+// @Success 200 {object} Liveness
+// @Router /healthz [get]
+func (h *HealthHandlers) LivenessHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.Liveness{Status: models.StatusAlive})
+}
+
+// ReadinessHandler
+//
+// @Summary Readiness API
+// @Description To check that api can serve requests: database is reachable, migrations are applied and deduction config is loadable.
+// @Description It is not ready while server is shutting down so load balancers can drain traffic.
+// @Tags health
+// @Produce json
+// @Success 200 {object} Readiness
+// @Failure 503 {object} Readiness "some components are down"
+// @Router /readyz [get]
+func (h *HealthHandlers) ReadinessHandler(c echo.Context) error {
+	readiness := h.Service.Readiness(c.Request().Context())
+	if readiness.Status != models.StatusReady {
+		return c.JSON(http.StatusServiceUnavailable, readiness)
+	}
+	return c.JSON(http.StatusOK, readiness)
+}
@@ -0,0 +1,82 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/labstack/echo/v4"
+)
+
+type stubHealthServicer struct {
+	readiness models.Readiness
+}
+
+func (s *stubHealthServicer) Readiness(ctx context.Context) models.Readiness {
+	return s.readiness
+}
+
+func TestLivenessHandler(t *testing.T) {
+	t.Run("should return 200 with alive status", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		res := httptest.NewRecorder()
+		c := e.NewContext(req, res)
+		h := NewHealthHandlers(&stubHealthServicer{})
+
+		h.LivenessHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got models.Liveness
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || got.Status != models.StatusAlive {
+			t.Errorf("expect alive status but got %s", res.Body.String())
+		}
+	})
+}
+
+func TestReadinessHandler(t *testing.T) {
+	testCases := []struct {
+		name      string
+		readiness models.Readiness
+		wantCode  int
+	}{
+		{
+			name: "given every component is up should return 200",
+			readiness: models.Readiness{Status: models.StatusReady, Components: map[string]models.ComponentHealth{
+				"database": {Status: models.ComponentUp},
+			}},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "given some component is down should return 503 with its error",
+			readiness: models.Readiness{Status: models.StatusNotReady, Components: map[string]models.ComponentHealth{
+				"database": {Status: models.ComponentDown, Error: "unavailable"},
+			}},
+			wantCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			res := httptest.NewRecorder()
+			c := e.NewContext(req, res)
+			h := NewHealthHandlers(&stubHealthServicer{readiness: tc.readiness})
+
+			h.ReadinessHandler(c)
+
+			assertHttpCode(t, tc.wantCode, res.Code)
+			var got models.Readiness
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || got.Status != tc.readiness.Status || got.Components["database"] != tc.readiness.Components["database"] {
+				t.Errorf("expect %#v but got %s", tc.readiness, res.Body.String())
+			}
+		})
+	}
+}
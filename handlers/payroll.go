@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type PayrollHandlers struct {
+	Service PayrollServicer
+}
+
+type PayrollServicer interface {
+	PayrollCalculate(ctx context.Context, payroll models.PayrollRequest) (models.PayrollResponse, error)
+	ExtractPayrollCsv(reader io.Reader) ([]models.PayrollCsv, error)
+	CalculatePayrollCsv(ctx context.Context, payroll []models.PayrollCsv) (models.PayrollCsvResponse, error)
+}
+
+func NewPayrollHandlers(service PayrollServicer) *PayrollHandlers {
+	return &PayrollHandlers{Service: service}
+}
+
+// PayrollCalculateHandler
+//
+// @Summary Monthly Payroll Withholding API (PND 1)
+// @Description To calculate tax that employer should withhold from salary and bonus of this month
+// @Tags tax, payroll
+// @Accept json
+// @Produce json
+// @Param payroll body PayrollRequest true "payroll data of this month"
+// @Success 200 {object} PayrollResponse
+// @Router /tax/payroll/withholdings [post]
+// @Failure 400 {object} ErrorResponse "validate error or cannot get body"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *PayrollHandlers) PayrollCalculateHandler(c echo.Context) error {
+	body := new(models.PayrollRequest)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	if err := validators.ValidatePayrollRequest(*body); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.PayrollCalculate(c.Request().Context(), *body)
+
+	if err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// PayrollUploadCsvHandler
+//
+// @Summary Monthly Payroll Withholding From CSV file API
+// @Description To calculate withholding tax of whole payroll from csv file and return withholding of each employee
+// @Tags tax, payroll
+// @Accept mpfd
+// @Produce json
+// @Param payrollFile formData file true "csv payroll file"
+// @Success 200 {object} PayrollCsvResponse
+// @Router /tax/payroll/withholdings/upload-csv [post]
+// @Failure 400 {object} ErrorResponse "validate error or cannot get file"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *PayrollHandlers) PayrollUploadCsvHandler(c echo.Context) error {
+	file, err := c.FormFile("payrollFile")
+	if err != nil {
+		return rejectCsv(c, "payroll", csvMissingFile, err.Error())
+	}
+	if fileType := file.Header.Get("Content-Type"); fileType != "text/csv" {
+		return rejectCsv(c, "payroll", csvNotCsv, "support only csv file")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return rejectCsv(c, "payroll", csvUnreadable, err.Error())
+	}
+	defer src.Close()
+
+	csv, err := h.Service.ExtractPayrollCsv(src)
+	if err != nil {
+		return rejectCsv(c, "payroll", csvRejectReason(err), err.Error())
+	}
+	metrics.CsvRows.WithLabelValues("payroll").Add(float64(len(csv)))
+
+	result, err := h.Service.CalculatePayrollCsv(c.Request().Context(), csv)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
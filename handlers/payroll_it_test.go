@@ -0,0 +1,51 @@
+//go:build integration
+// +build integration
+
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestITPayrollWithholding(t *testing.T) {
+	var got models.PayrollResponse
+
+	res := clientITRequest(
+		http.MethodPost,
+		os.Getenv("API_URL")+"/tax/payroll/withholdings",
+		strings.NewReader(
+			`{
+			"monthlySalary": 50000.0,
+			"month": 1,
+			"bonusMonths": 2
+		}`),
+		"application/json;charset=UTF-8",
+		"",
+		"",
+	)
+
+	err := res.Decode(&got)
+	if err != nil {
+		t.Errorf("expect response body to be valid json but got %q", err)
+	}
+	assertHttpCode(t, http.StatusOK, res.StatusCode)
+
+	var want = models.PayrollResponse{
+		AnnualIncome:       700_000,
+		AnnualTax:          56_000,
+		Bonus:              100_000,
+		RegularWithholding: 3_416.67,
+		BonusWithholding:   15_000,
+		Withholding:        18_416.67,
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expect %#v but got %#v", want, got)
+	}
+}
@@ -0,0 +1,205 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type stubPayrollServicer struct {
+	expectToCall    map[string]bool
+	expectCallTimes map[string]int
+	err             error
+	response        models.PayrollResponse
+	extractResult   []models.PayrollCsv
+	extractErr      error
+	csvResponse     models.PayrollCsvResponse
+}
+
+func (s *stubPayrollServicer) PayrollCalculate(ctx context.Context, payroll models.PayrollRequest) (models.PayrollResponse, error) {
+	s.expectToCall["PayrollCalculate"] = true
+	s.expectCallTimes["PayrollCalculate"]++
+	return s.response, s.err
+}
+func (s *stubPayrollServicer) ExtractPayrollCsv(reader io.Reader) ([]models.PayrollCsv, error) {
+	s.expectToCall["ExtractPayrollCsv"] = true
+	s.expectCallTimes["ExtractPayrollCsv"]++
+	return s.extractResult, s.extractErr
+}
+func (s *stubPayrollServicer) CalculatePayrollCsv(ctx context.Context, payroll []models.PayrollCsv) (models.PayrollCsvResponse, error) {
+	s.expectToCall["CalculatePayrollCsv"] = true
+	s.expectCallTimes["CalculatePayrollCsv"]++
+	return s.csvResponse, s.err
+}
+
+func (s *stubPayrollServicer) assertMethodWasCalled(t *testing.T, methodName string) {
+	t.Helper()
+	if !s.expectToCall[methodName] {
+		t.Errorf("expect %s was called", methodName)
+	}
+}
+func (s *stubPayrollServicer) assertMethodWasNotCalled(t *testing.T, methodName string) {
+	t.Helper()
+	if s.expectToCall[methodName] {
+		t.Errorf("expect %s was not called", methodName)
+	}
+}
+
+func setupPayrollHandler(method, url string, body io.Reader, contentType string) (res *httptest.ResponseRecorder, c echo.Context, h *PayrollHandlers, stub *stubPayrollServicer) {
+	e := echo.New()
+	req := httptest.NewRequest(method, url, body)
+	req.Header.Set(echo.HeaderContentType, contentType)
+	res = httptest.NewRecorder()
+	c = e.NewContext(req, res)
+	stub = &stubPayrollServicer{
+		expectToCall:    make(map[string]bool),
+		expectCallTimes: make(map[string]int),
+	}
+	h = NewPayrollHandlers(stub)
+	return
+}
+
+func TestPayrollCalculateHandler(t *testing.T) {
+	url := "/tax/payroll/withholdings"
+	t.Run("given invalid month should return 400 with error message ErrPayrollMonthInvalid", func(t *testing.T) {
+		body, _ := json.Marshal(models.PayrollRequest{MonthlySalary: 50_000, Month: 13})
+		res, c, h, stub := setupPayrollHandler(http.MethodPost, url, strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+
+		h.PayrollCalculateHandler(c)
+
+		stub.assertMethodWasNotCalled(t, "PayrollCalculate")
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrPayrollMonthInvalid.Error(), got.Message)
+	})
+	t.Run("given valid payroll should return 200 with withholding", func(t *testing.T) {
+		body, _ := json.Marshal(models.PayrollRequest{MonthlySalary: 50_000, Month: 1})
+		res, c, h, stub := setupPayrollHandler(http.MethodPost, url, strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+		stub.response = models.PayrollResponse{AnnualIncome: 600_000, AnnualTax: 41_000, RegularWithholding: 3_416.67, Withholding: 3_416.67}
+
+		h.PayrollCalculateHandler(c)
+
+		stub.assertMethodWasCalled(t, "PayrollCalculate")
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got models.PayrollResponse
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+			t.Fatalf("expect response body to be valid json but got %s", res.Body.String())
+		}
+		if !reflect.DeepEqual(stub.response, got) {
+			t.Errorf("expect %#v but got %#v", stub.response, got)
+		}
+	})
+	t.Run("given error from service should return 500 with error message", func(t *testing.T) {
+		body, _ := json.Marshal(models.PayrollRequest{MonthlySalary: 50_000, Month: 1})
+		res, c, h, stub := setupPayrollHandler(http.MethodPost, url, strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+		stub.err = errors.New("error 'xxx' occured")
+
+		h.PayrollCalculateHandler(c)
+
+		assertHttpCode(t, http.StatusInternalServerError, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
+	})
+}
+
+func TestPayrollUploadCsvHandler(t *testing.T) {
+	url := "/tax/payroll/withholdings/upload-csv"
+	initBody := func(t *testing.T, filePath, contentType string) (*bytes.Buffer, *multipart.Writer) {
+		body := new(bytes.Buffer)
+		dir, _ := os.Getwd()
+		fileData, err := os.Open(filepath.Join(dir, filePath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fileData.Close()
+
+		writer := multipart.NewWriter(body)
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", `form-data; name="payrollFile"; filename="payroll.csv"`)
+		h.Set("Content-Type", contentType)
+		part, err := writer.CreatePart(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(part, fileData); err != nil {
+			t.Fatal(err)
+		}
+		writer.Close()
+		return body, writer
+	}
+	t.Run("given correct csv file should return 200 with withholding of each employee", func(t *testing.T) {
+		body, writer := initBody(t, "../testdata/valid-payroll.csv", "text/csv")
+		res, c, h, stub := setupPayrollHandler(http.MethodPost, url, body, writer.FormDataContentType())
+		stub.csvResponse = models.PayrollCsvResponse{
+			Payroll: []models.PayrollCsvResult{
+				{EmployeeId: "E001", MonthlySalary: 50_000, Withholding: 3_416.67},
+			},
+		}
+
+		h.PayrollUploadCsvHandler(c)
+
+		stub.assertMethodWasCalled(t, "ExtractPayrollCsv")
+		stub.assertMethodWasCalled(t, "CalculatePayrollCsv")
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got models.PayrollCsvResponse
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+			t.Fatalf("expect response body to be valid json but got %s", res.Body.String())
+		}
+		if !reflect.DeepEqual(stub.csvResponse, got) {
+			t.Errorf("expect %#v but got %#v", stub.csvResponse, got)
+		}
+	})
+	t.Run("given upload non csv file should return 400 with error message 'support only csv file'", func(t *testing.T) {
+		body, writer := initBody(t, "../testdata/taxes.txt", "text/plain")
+		res, c, h, stub := setupPayrollHandler(http.MethodPost, url, body, writer.FormDataContentType())
+
+		h.PayrollUploadCsvHandler(c)
+
+		stub.assertMethodWasNotCalled(t, "ExtractPayrollCsv")
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, "support only csv file", got.Message)
+	})
+	t.Run("given error on extract csv should return 400 with error message from extract function", func(t *testing.T) {
+		body, writer := initBody(t, "../testdata/missing-value-payroll.csv", "text/csv")
+		res, c, h, stub := setupPayrollHandler(http.MethodPost, url, body, writer.FormDataContentType())
+		stub.extractErr = errors.New("value should not be empty")
+
+		h.PayrollUploadCsvHandler(c)
+
+		stub.assertMethodWasNotCalled(t, "CalculatePayrollCsv")
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, stub.extractErr.Error(), got.Message)
+	})
+	t.Run("given error on calculate function should return 500 with error 'internal server error'", func(t *testing.T) {
+		body, writer := initBody(t, "../testdata/valid-payroll.csv", "text/csv")
+		res, c, h, stub := setupPayrollHandler(http.MethodPost, url, body, writer.FormDataContentType())
+		stub.err = errors.New("error 'xxx' occured")
+
+		h.PayrollUploadCsvHandler(c)
+
+		assertHttpCode(t, http.StatusInternalServerError, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
+	})
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/labstack/echo/v4"
+)
+
+type PoolHandlers struct {
+	Service PoolServicer
+}
+
+type PoolServicer interface {
+	PoolStats() models.PoolStats
+}
+
+func NewPoolHandlers(service PoolServicer) *PoolHandlers {
+	return &PoolHandlers{Service: service}
+}
+
+// PoolStatsHandler
+//
+// @Summary Database Pool Stats API
+// @Description To get open, in use and idle connections of database pool, and how many times and how long requests waited for connection since start
+// @Tags admin, database
+// @Produce json
+// @Security BasicAuth
+// @Success 200 {object} PoolStats
+// @Router /admin/db/stats [get]
+// @Failure 401 {object} ErrorResponse "unauthorized"
+func (h *PoolHandlers) PoolStatsHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.Service.PoolStats())
+}
@@ -0,0 +1,41 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/labstack/echo/v4"
+)
+
+type stubPoolServicer struct {
+	stats models.PoolStats
+}
+
+func (s *stubPoolServicer) PoolStats() models.PoolStats {
+	return s.stats
+}
+
+func TestPoolStatsHandler(t *testing.T) {
+	t.Run("should return 200 with pool stats", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/db/stats", nil)
+		res := httptest.NewRecorder()
+		c := e.NewContext(req, res)
+		stub := &stubPoolServicer{stats: models.PoolStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2, WaitCount: 4, WaitDurationMs: 35}}
+		h := NewPoolHandlers(stub)
+
+		h.PoolStatsHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got models.PoolStats
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || got != stub.stats {
+			t.Errorf("expect %#v but got %s", stub.stats, res.Body.String())
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/baronight/assessment-tax/middlewares"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/services"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type RuleSetHandlers struct {
+	Service RuleSetServicer
+}
+
+type RuleSetServicer interface {
+	ExtractRuleSet(reader io.Reader, format string) (models.RuleSet, error)
+	CreateRuleSet(ctx context.Context, ruleSet models.RuleSet, activate bool, editor string) (models.RuleSet, error)
+	GetRuleSets(ctx context.Context) ([]models.RuleSet, error)
+	ActiveRuleSet() models.RuleSet
+	ActivateRuleSet(ctx context.Context, id uint, editor string) (models.RuleSet, error)
+}
+
+func NewRuleSetHandlers(service RuleSetServicer) *RuleSetHandlers {
+	return &RuleSetHandlers{Service: service}
+}
+
+// ruleSetFormat return format of rule set from content type or file extension
+func ruleSetFormat(contentType, filename string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == echo.MIMEApplicationJSON, strings.EqualFold(filepath.Ext(filename), ".json"):
+		return models.JsonRuleSetFormat
+	case strings.HasSuffix(mediaType, "yaml"), strings.EqualFold(filepath.Ext(filename), ".yaml"), strings.EqualFold(filepath.Ext(filename), ".yml"):
+		return models.YamlRuleSetFormat
+	}
+	return ""
+}
+
+func (h *RuleSetHandlers) createRuleSet(c echo.Context, reader io.Reader, format string) error {
+	activate, _ := strconv.ParseBool(c.QueryParam("activate"))
+	if err := validators.ValidateRuleSetFormat(format); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+	ruleSet, err := h.Service.ExtractRuleSet(reader, format)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.CreateRuleSet(c.Request().Context(), ruleSet, activate, middlewares.AdminUser(c))
+	if err != nil {
+		return activateErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusCreated, result)
+}
+
+// activateErrorResponse map error of activating rule set to response
+func activateErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, utils.ErrRuleSetNotFound):
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, services.ErrDeductionAmountInvalid):
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	default:
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+}
+
+// CreateRuleSetHandler
+//
+// @Summary Create Rule Set API
+// @Description To save tax rule set (brackets, deductions, caps and order) from json or yaml body by content type, and activate it when activate is true.
+// @Description Caps that differ from deduction amounts are proposed as pending deduction changes for another admin to approve
+// @Tags admin, rule-set
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Security BasicAuth
+// @Param ruleSet body RuleSet true "rule set in json or yaml"
+// @Param activate query bool false "activate rule set after save"
+// @Success 201 {object} RuleSet
+// @Router /admin/rule-sets [post]
+// @Failure 400 {object} ErrorResponse "validate error, cap out of deduction limit or cannot get body"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *RuleSetHandlers) CreateRuleSetHandler(c echo.Context) error {
+	format := ruleSetFormat(c.Request().Header.Get(echo.HeaderContentType), "")
+	return h.createRuleSet(c, c.Request().Body, format)
+}
+
+// RuleSetUploadHandler
+//
+// @Summary Rule Set From File API
+// @Description To save tax rule set from .json, .yaml or .yml file, and activate it when activate is true.
+// @Description Caps that differ from deduction amounts are proposed as pending deduction changes for another admin to approve
+// @Tags admin, rule-set
+// @Accept mpfd
+// @Produce json
+// @Security BasicAuth
+// @Param ruleSetFile formData file true "rule set file"
+// @Param activate query bool false "activate rule set after save"
+// @Success 201 {object} RuleSet
+// @Router /admin/rule-sets/upload [post]
+// @Failure 400 {object} ErrorResponse "validate error, cap out of deduction limit or cannot get file"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *RuleSetHandlers) RuleSetUploadHandler(c echo.Context) error {
+	file, err := c.FormFile("ruleSetFile")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+	defer src.Close()
+
+	return h.createRuleSet(c, src, ruleSetFormat("", file.Filename))
+}
+
+// GetRuleSetsHandler
+//
+// @Summary Rule Set List API
+// @Description To list saved tax rule sets, latest first
+// @Tags admin, rule-set
+// @Produce json
+// @Security BasicAuth
+// @Success 200 {array} RuleSet
+// @Router /admin/rule-sets [get]
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *RuleSetHandlers) GetRuleSetsHandler(c echo.Context) error {
+	result, err := h.Service.GetRuleSets(c.Request().Context())
+	if err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// ActiveRuleSetHandler
+//
+// @Summary Active Rule Set API
+// @Description To get tax rule set that tax is calculated with, it is default rule set when no rule set is activated
+// @Tags admin, rule-set
+// @Produce json
+// @Security BasicAuth
+// @Success 200 {object} RuleSet
+// @Router /admin/rule-sets/active [get]
+// @Failure 401 {object} ErrorResponse "unauthorized"
+func (h *RuleSetHandlers) ActiveRuleSetHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.Service.ActiveRuleSet())
+}
+
+// ActivateRuleSetHandler
+//
+// @Summary Activate Rule Set API
+// @Description To use saved rule set for tax calculate, caps that differ from deduction amounts are proposed as pending deduction changes
+// @Description and deduction amounts are changed only when another admin approve them
+// @Tags admin, rule-set
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "rule set id"
+// @Success 200 {object} RuleSet
+// @Router /admin/rule-sets/{id}/activate [post]
+// @Failure 400 {object} ErrorResponse "invalid id or cap out of deduction limit"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 404 {object} ErrorResponse "rule set not found"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *RuleSetHandlers) ActivateRuleSetHandler(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "rule set id should be number"})
+	}
+
+	result, err := h.Service.ActivateRuleSet(c.Request().Context(), uint(id), middlewares.AdminUser(c))
+	if err != nil {
+		return activateErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,236 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/middlewares"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/services"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type stubRuleSetServicer struct {
+	expectToCall map[string]bool
+	err          error
+	extractErr   error
+	format       string
+	activate     bool
+	editor       string
+}
+
+func (s *stubRuleSetServicer) ExtractRuleSet(reader io.Reader, format string) (models.RuleSet, error) {
+	s.expectToCall["ExtractRuleSet"] = true
+	s.format = format
+	return models.RuleSet{Name: "flat", TaxYear: 2568}, s.extractErr
+}
+func (s *stubRuleSetServicer) CreateRuleSet(ctx context.Context, ruleSet models.RuleSet, activate bool, editor string) (models.RuleSet, error) {
+	s.expectToCall["CreateRuleSet"] = true
+	s.activate, s.editor = activate, editor
+	ruleSet.Id, ruleSet.Active = 1, activate
+	return ruleSet, s.err
+}
+func (s *stubRuleSetServicer) GetRuleSets(ctx context.Context) ([]models.RuleSet, error) {
+	s.expectToCall["GetRuleSets"] = true
+	return []models.RuleSet{{Id: 1, Name: "flat"}}, s.err
+}
+func (s *stubRuleSetServicer) ActiveRuleSet() models.RuleSet {
+	s.expectToCall["ActiveRuleSet"] = true
+	return models.RuleSet{Name: "default", TaxYear: 2567}
+}
+func (s *stubRuleSetServicer) ActivateRuleSet(ctx context.Context, id uint, editor string) (models.RuleSet, error) {
+	s.expectToCall["ActivateRuleSet"] = true
+	s.editor = editor
+	return models.RuleSet{Id: id, Active: true}, s.err
+}
+
+func setupRuleSetHandler(method, target string, body io.Reader, contentType string) (res *httptest.ResponseRecorder, c echo.Context, h *RuleSetHandlers, stub *stubRuleSetServicer) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set(echo.HeaderContentType, contentType)
+	res = httptest.NewRecorder()
+	c = e.NewContext(req, res)
+	stub = &stubRuleSetServicer{expectToCall: make(map[string]bool)}
+	h = NewRuleSetHandlers(stub)
+	return
+}
+
+func TestCreateRuleSetHandler(t *testing.T) {
+	t.Run("given yaml body with activate should save and activate rule set", func(t *testing.T) {
+		res, c, h, stub := setupRuleSetHandler(http.MethodPost, "/admin/rule-sets?activate=true", strings.NewReader("name: flat"), "application/yaml")
+
+		h.CreateRuleSetHandler(c)
+
+		assertHttpCode(t, http.StatusCreated, res.Code)
+		if stub.format != models.YamlRuleSetFormat || !stub.activate {
+			t.Errorf("expect yaml rule set is activated but got format %q activate %v", stub.format, stub.activate)
+		}
+		var got models.RuleSet
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || !got.Active {
+			t.Errorf("expect active rule set but got %s", res.Body.String())
+		}
+	})
+	t.Run("given unsupported content type should return 400", func(t *testing.T) {
+		res, c, h, stub := setupRuleSetHandler(http.MethodPost, "/admin/rule-sets", strings.NewReader("<a/>"), echo.MIMEApplicationXML)
+
+		h.CreateRuleSetHandler(c)
+
+		if stub.expectToCall["ExtractRuleSet"] {
+			t.Error("expect ExtractRuleSet was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrRuleSetFormatInvalid.Error(), got.Message)
+	})
+	t.Run("given invalid rule set should return 400 with validate message", func(t *testing.T) {
+		res, c, h, stub := setupRuleSetHandler(http.MethodPost, "/admin/rule-sets", strings.NewReader("{}"), echo.MIMEApplicationJSON)
+		stub.extractErr = validators.ErrRuleSetNameRequired
+
+		h.CreateRuleSetHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrRuleSetNameRequired.Error(), got.Message)
+	})
+	t.Run("given error from service should return 500", func(t *testing.T) {
+		res, c, h, stub := setupRuleSetHandler(http.MethodPost, "/admin/rule-sets", strings.NewReader("{}"), echo.MIMEApplicationJSON)
+		stub.err = errors.New("error 'xxx' occured")
+
+		h.CreateRuleSetHandler(c)
+
+		assertHttpCode(t, http.StatusInternalServerError, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
+	})
+}
+
+func TestRuleSetUploadHandler(t *testing.T) {
+	initBody := func(t *testing.T, filename string) (*bytes.Buffer, *multipart.Writer) {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("ruleSetFile", filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte("name: flat"))
+		writer.Close()
+		return body, writer
+	}
+	t.Run("given yml file should read it as yaml", func(t *testing.T) {
+		body, writer := initBody(t, "rules.yml")
+		res, c, h, stub := setupRuleSetHandler(http.MethodPost, "/admin/rule-sets/upload", body, writer.FormDataContentType())
+
+		h.RuleSetUploadHandler(c)
+
+		assertHttpCode(t, http.StatusCreated, res.Code)
+		if stub.format != models.YamlRuleSetFormat || stub.activate {
+			t.Errorf("expect yaml rule set is not activated but got format %q activate %v", stub.format, stub.activate)
+		}
+	})
+	t.Run("given txt file should return 400", func(t *testing.T) {
+		body, writer := initBody(t, "rules.txt")
+		res, c, h, _ := setupRuleSetHandler(http.MethodPost, "/admin/rule-sets/upload", body, writer.FormDataContentType())
+
+		h.RuleSetUploadHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrRuleSetFormatInvalid.Error(), got.Message)
+	})
+	t.Run("given no file should return 400", func(t *testing.T) {
+		res, c, h, _ := setupRuleSetHandler(http.MethodPost, "/admin/rule-sets/upload", nil, echo.MIMEMultipartForm)
+
+		h.RuleSetUploadHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+}
+
+func TestGetRuleSetsHandler(t *testing.T) {
+	t.Run("should return saved rule sets", func(t *testing.T) {
+		res, c, h, _ := setupRuleSetHandler(http.MethodGet, "/admin/rule-sets", nil, "")
+
+		h.GetRuleSetsHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got []models.RuleSet
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || len(got) != 1 {
+			t.Errorf("expect 1 rule set but got %s", res.Body.String())
+		}
+	})
+	t.Run("should return active rule set", func(t *testing.T) {
+		res, c, h, _ := setupRuleSetHandler(http.MethodGet, "/admin/rule-sets/active", nil, "")
+
+		h.ActiveRuleSetHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got models.RuleSet
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || got.Name != "default" {
+			t.Errorf("expect default rule set but got %s", res.Body.String())
+		}
+	})
+}
+
+func TestActivateRuleSetHandler(t *testing.T) {
+	setup := func(id string) (*httptest.ResponseRecorder, echo.Context, *RuleSetHandlers, *stubRuleSetServicer) {
+		res, c, h, stub := setupRuleSetHandler(http.MethodPost, "/admin/rule-sets/"+id+"/activate", nil, "")
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return res, c, h, stub
+	}
+	t.Run("given exist id should return activated rule set", func(t *testing.T) {
+		res, c, h, stub := setup("2")
+		c.Set(middlewares.AdminUserKey, "editor")
+
+		h.ActivateRuleSetHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if stub.editor != "editor" {
+			t.Errorf("expect rule set is activated by editor but got %q", stub.editor)
+		}
+	})
+	t.Run("given cap out of deduction limit should return 400", func(t *testing.T) {
+		res, c, h, stub := setup("2")
+		stub.err = fmt.Errorf("deduction personal: %w", services.ErrDeductionAmountInvalid)
+
+		h.ActivateRuleSetHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, stub.err.Error(), got.Message)
+	})
+	t.Run("given id that is not number should return 400", func(t *testing.T) {
+		res, c, h, stub := setup("abc")
+
+		h.ActivateRuleSetHandler(c)
+
+		if stub.expectToCall["ActivateRuleSet"] {
+			t.Error("expect ActivateRuleSet was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+	t.Run("given not exist id should return 404", func(t *testing.T) {
+		res, c, h, stub := setup("9")
+		stub.err = utils.ErrRuleSetNotFound
+
+		h.ActivateRuleSetHandler(c)
+
+		assertHttpCode(t, http.StatusNotFound, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrRuleSetNotFound.Error(), got.Message)
+	})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type SpouseHandlers struct {
+	Service SpouseServicer
+}
+
+type SpouseServicer interface {
+	SpouseTaxCalculate(ctx context.Context, tax models.SpouseTaxRequest) (models.SpouseTaxResponse, error)
+	ExtractSpouseCsv(reader io.Reader) ([]models.SpouseTaxCsv, error)
+	CalculateSpouseTaxCsv(ctx context.Context, taxes []models.SpouseTaxCsv) (models.SpouseTaxCsvResponse, error)
+}
+
+func NewSpouseHandlers(service SpouseServicer) *SpouseHandlers {
+	return &SpouseHandlers{Service: service}
+}
+
+// SpouseTaxCalculateHandler
+//
+// @Summary Spouse Tax Calculate API
+// @Description To calculate tax of both spouses by joint filing and separate filing and recommend the option that has less total tax
+// @Tags tax, spouse
+// @Accept json
+// @Produce json
+// @Param tax body SpouseTaxRequest true "tax data of taxpayer and spouse"
+// @Success 200 {object} SpouseTaxResponse
+// @Router /tax/calculations/spouse [post]
+// @Failure 400 {object} ErrorResponse "validate error, cannot get body, exchange rate or taxpayer not found"
+// @Failure 401 {object} ErrorResponse "taxpayer id is sent without authentication"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *SpouseHandlers) SpouseTaxCalculateHandler(c echo.Context) error {
+	body := new(models.SpouseTaxRequest)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+	if !taxpayerAuthorized(c, body.Taxpayer.TaxpayerId, body.Spouse.TaxpayerId) {
+		return taxpayerUnauthorized(c)
+	}
+
+	if err := validators.ValidateSpouseTaxRequest(*body); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.SpouseTaxCalculate(c.Request().Context(), *body)
+
+	if err != nil {
+		c.Logger().Error(err)
+		if errors.Is(err, utils.ErrExchangeRateNotFound) || errors.Is(err, utils.ErrTaxpayerNotFound) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// SpouseTaxUploadCsvHandler
+//
+// @Summary Spouse Tax Calculate From CSV file API
+// @Description To calculate joint filing and separate filing tax from csv file and return recommend option of each row data
+// @Tags tax, spouse
+// @Accept mpfd
+// @Produce json
+// @Param taxFile formData file true "csv tax file with spouse columns"
+// @Success 200 {object} SpouseTaxCsvResponse
+// @Router /tax/calculations/spouse/upload-csv [post]
+// @Failure 400 {object} ErrorResponse "validate error or cannot get file"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *SpouseHandlers) SpouseTaxUploadCsvHandler(c echo.Context) error {
+	file, err := c.FormFile("taxFile")
+	if err != nil {
+		return rejectCsv(c, "spouse", csvMissingFile, err.Error())
+	}
+	if fileType := file.Header.Get("Content-Type"); fileType != "text/csv" {
+		return rejectCsv(c, "spouse", csvNotCsv, "support only csv file")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return rejectCsv(c, "spouse", csvUnreadable, err.Error())
+	}
+	defer src.Close()
+
+	csv, err := h.Service.ExtractSpouseCsv(src)
+	if err != nil {
+		return rejectCsv(c, "spouse", csvRejectReason(err), err.Error())
+	}
+	metrics.CsvRows.WithLabelValues("spouse").Add(float64(len(csv)))
+
+	result, err := h.Service.CalculateSpouseTaxCsv(c.Request().Context(), csv)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,153 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/middlewares"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type stubSpouseServicer struct {
+	expectToCall map[string]bool
+	err          error
+	response     models.SpouseTaxResponse
+	extractErr   error
+	csvResponse  models.SpouseTaxCsvResponse
+}
+
+func (s *stubSpouseServicer) SpouseTaxCalculate(ctx context.Context, tax models.SpouseTaxRequest) (models.SpouseTaxResponse, error) {
+	s.expectToCall["SpouseTaxCalculate"] = true
+	return s.response, s.err
+}
+func (s *stubSpouseServicer) ExtractSpouseCsv(reader io.Reader) ([]models.SpouseTaxCsv, error) {
+	s.expectToCall["ExtractSpouseCsv"] = true
+	return nil, s.extractErr
+}
+func (s *stubSpouseServicer) CalculateSpouseTaxCsv(ctx context.Context, taxes []models.SpouseTaxCsv) (models.SpouseTaxCsvResponse, error) {
+	s.expectToCall["CalculateSpouseTaxCsv"] = true
+	return s.csvResponse, s.err
+}
+
+func setupSpouseHandler(method, url string, body io.Reader, contentType string) (res *httptest.ResponseRecorder, c echo.Context, h *SpouseHandlers, stub *stubSpouseServicer) {
+	e := echo.New()
+	req := httptest.NewRequest(method, url, body)
+	req.Header.Set(echo.HeaderContentType, contentType)
+	res = httptest.NewRecorder()
+	c = e.NewContext(req, res)
+	stub = &stubSpouseServicer{expectToCall: make(map[string]bool)}
+	h = NewSpouseHandlers(stub)
+	return
+}
+
+func TestSpouseTaxCalculateHandler(t *testing.T) {
+	url := "/tax/calculations/spouse"
+	t.Run("given invalid spouse income should return 400 with spouse error message", func(t *testing.T) {
+		body, _ := json.Marshal(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 500_000},
+			Spouse:   models.TaxRequest{TotalIncome: -1},
+		})
+		res, c, h, stub := setupSpouseHandler(http.MethodPost, url, strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+
+		h.SpouseTaxCalculateHandler(c)
+
+		if stub.expectToCall["SpouseTaxCalculate"] {
+			t.Error("expect SpouseTaxCalculate was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, "spouse "+validators.ErrTotalIncomeInvalid.Error(), got.Message)
+	})
+	t.Run("given valid request should return 200 with joint and separate result", func(t *testing.T) {
+		body, _ := json.Marshal(models.SpouseTaxRequest{Taxpayer: models.TaxRequest{TotalIncome: 500_000}})
+		res, c, h, stub := setupSpouseHandler(http.MethodPost, url, strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+		stub.response = models.SpouseTaxResponse{
+			Joint:          models.TaxResponse{Tax: 23_000, TaxLevel: []models.TaxLevel{}},
+			Separate:       models.SeparateFilingResponse{Tax: 23_000, Taxpayer: models.TaxResponse{Tax: 23_000, TaxLevel: []models.TaxLevel{}}, Spouse: models.TaxResponse{TaxLevel: []models.TaxLevel{}}},
+			Recommendation: models.SeparateFiling,
+		}
+
+		h.SpouseTaxCalculateHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got models.SpouseTaxResponse
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+			t.Fatalf("expect response body to be valid json but got %s", res.Body.String())
+		}
+		if !reflect.DeepEqual(stub.response, got) {
+			t.Errorf("expect %#v but got %#v", stub.response, got)
+		}
+	})
+	t.Run("given error from service should return 500 with error message", func(t *testing.T) {
+		body, _ := json.Marshal(models.SpouseTaxRequest{Taxpayer: models.TaxRequest{TotalIncome: 500_000}})
+		res, c, h, stub := setupSpouseHandler(http.MethodPost, url, strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+		stub.err = errors.New("error 'xxx' occured")
+
+		h.SpouseTaxCalculateHandler(c)
+
+		assertHttpCode(t, http.StatusInternalServerError, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
+	})
+	t.Run("given exchange rate not found should return 400 with error message", func(t *testing.T) {
+		body, _ := json.Marshal(models.SpouseTaxRequest{Taxpayer: models.TaxRequest{TotalIncome: 500_000, Currency: "USD"}})
+		res, c, h, stub := setupSpouseHandler(http.MethodPost, url, strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+		stub.err = fmt.Errorf("%w: USD on 2024-12-31", utils.ErrExchangeRateNotFound)
+
+		h.SpouseTaxCalculateHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, stub.err.Error(), got.Message)
+	})
+	t.Run("given taxpayer id of spouse without authentication should return 401", func(t *testing.T) {
+		body, _ := json.Marshal(models.SpouseTaxRequest{Spouse: models.TaxRequest{TaxpayerId: "1234567890121"}})
+		res, c, h, stub := setupSpouseHandler(http.MethodPost, url, strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+
+		h.SpouseTaxCalculateHandler(c)
+
+		assertHttpCode(t, http.StatusUnauthorized, res.Code)
+		if stub.expectToCall["SpouseTaxCalculate"] {
+			t.Error("expect SpouseTaxCalculate was not called")
+		}
+	})
+	t.Run("given taxpayer not found should return 400 with error message", func(t *testing.T) {
+		body, _ := json.Marshal(models.SpouseTaxRequest{Taxpayer: models.TaxRequest{TaxpayerId: "1234567890121", TotalIncome: 500_000}})
+		res, c, h, stub := setupSpouseHandler(http.MethodPost, url, strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+		c.Set(middlewares.AdminUserKey, "adminTax")
+		stub.err = utils.ErrTaxpayerNotFound
+
+		h.SpouseTaxCalculateHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrTaxpayerNotFound.Error(), got.Message)
+	})
+}
+
+func TestSpouseTaxUploadCsvHandler(t *testing.T) {
+	t.Run("given missing upload file should return 400 with error message", func(t *testing.T) {
+		res, c, h, stub := setupSpouseHandler(http.MethodPost, "/tax/calculations/spouse/upload-csv", nil, echo.MIMEMultipartForm)
+
+		h.SpouseTaxUploadCsvHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		if stub.expectToCall["ExtractSpouseCsv"] {
+			t.Error("expect ExtractSpouseCsv was not called")
+		}
+	})
+}
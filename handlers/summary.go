@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type SummaryHandlers struct {
+	Service SummaryServicer
+}
+
+type SummaryServicer interface {
+	TaxCalculateSummary(ctx context.Context, tax models.TaxRequest) (models.TaxSummary, error)
+	TaxReturnSummary(ctx context.Context, id uint) (models.TaxSummary, error)
+	WriteTaxSummaryPdf(w io.Writer, summary models.TaxSummary) error
+}
+
+func NewSummaryHandlers(service SummaryServicer) *SummaryHandlers {
+	return &SummaryHandlers{Service: service}
+}
+
+func (h *SummaryHandlers) summaryPdf(c echo.Context, summary models.TaxSummary, filename string) error {
+	var buf bytes.Buffer
+	if err := h.Service.WriteTaxSummaryPdf(&buf, summary); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`inline; filename="%s"`, filename))
+	return c.Blob(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// TaxCalculatePdfHandler
+//
+// @Summary Tax Calculate PDF Summary API
+// @Description To calculate personal tax and return printable pdf summary of inputs, allowances with caps, tax by bracket and final tax or refund
+// @Tags tax
+// @Accept json
+// @Produce application/pdf
+// @Param tax body TaxRequest true "tax data that want to calculate"
+// @Success 200 {file} file
+// @Router /tax/calculations/pdf [post]
+// @Failure 400 {object} ErrorResponse "validate error, cannot get body, exchange rate or taxpayer not found"
+// @Failure 401 {object} ErrorResponse "taxpayer id is sent without authentication"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *SummaryHandlers) TaxCalculatePdfHandler(c echo.Context) error {
+	body := new(models.TaxRequest)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+	if !taxpayerAuthorized(c, body.TaxpayerId) {
+		return taxpayerUnauthorized(c)
+	}
+
+	if err := validators.ValidateTaxRequest(*body); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	summary, err := h.Service.TaxCalculateSummary(c.Request().Context(), *body)
+	if err != nil {
+		c.Logger().Error(err)
+		if errors.Is(err, utils.ErrExchangeRateNotFound) || errors.Is(err, utils.ErrTaxpayerNotFound) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+	}
+	return h.summaryPdf(c, summary, "tax-summary.pdf")
+}
+
+// TaxReturnPdfHandler
+//
+// @Summary Tax Return PDF Summary API
+// @Description To get printable pdf summary of saved tax return only from its snapshot of deductions, rule set, request in THB and taxpayer profile
+// @Tags tax, tax-return
+// @Produce application/pdf
+// @Security BasicAuth
+// @Param id path int true "tax return id"
+// @Success 200 {file} file
+// @Router /tax/returns/{id}/pdf [get]
+// @Failure 400 {object} ErrorResponse "invalid id"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 404 {object} ErrorResponse "tax return not found"
+// @Failure 409 {object} ErrorResponse "tax return was saved without taxpayer profile snapshot"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *SummaryHandlers) TaxReturnPdfHandler(c echo.Context) error {
+	id, err := taxReturnId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	summary, err := h.Service.TaxReturnSummary(c.Request().Context(), id)
+	if err != nil {
+		return taxReturnErrorResponse(c, err)
+	}
+	return h.summaryPdf(c, summary, fmt.Sprintf("tax-return-%d.pdf", id))
+}
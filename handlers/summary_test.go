@@ -0,0 +1,118 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/labstack/echo/v4"
+)
+
+type stubSummaryServicer struct {
+	expectToCall map[string]bool
+	err          error
+}
+
+func (s *stubSummaryServicer) TaxCalculateSummary(ctx context.Context, tax models.TaxRequest) (models.TaxSummary, error) {
+	s.expectToCall["TaxCalculateSummary"] = true
+	return models.TaxSummary{Request: tax}, s.err
+}
+func (s *stubSummaryServicer) TaxReturnSummary(ctx context.Context, id uint) (models.TaxSummary, error) {
+	s.expectToCall["TaxReturnSummary"] = true
+	return models.TaxSummary{TaxReturnId: id}, s.err
+}
+func (s *stubSummaryServicer) WriteTaxSummaryPdf(w io.Writer, summary models.TaxSummary) error {
+	s.expectToCall["WriteTaxSummaryPdf"] = true
+	_, err := io.WriteString(w, "%PDF-1.3")
+	return err
+}
+
+func setupSummaryHandler(method, target string, body io.Reader) (res *httptest.ResponseRecorder, c echo.Context, h *SummaryHandlers, stub *stubSummaryServicer) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	res = httptest.NewRecorder()
+	c = e.NewContext(req, res)
+	stub = &stubSummaryServicer{expectToCall: make(map[string]bool)}
+	h = NewSummaryHandlers(stub)
+	return
+}
+
+func TestTaxCalculatePdfHandler(t *testing.T) {
+	t.Run("given invalid tax should return 400", func(t *testing.T) {
+		res, c, h, stub := setupSummaryHandler(http.MethodPost, "/tax/calculations/pdf", strings.NewReader(`{"totalIncome":-1}`))
+
+		h.TaxCalculatePdfHandler(c)
+
+		if stub.expectToCall["TaxCalculateSummary"] {
+			t.Error("expect TaxCalculateSummary was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+	t.Run("given taxpayer id without authentication should return 401", func(t *testing.T) {
+		res, c, h, stub := setupSummaryHandler(http.MethodPost, "/tax/calculations/pdf", strings.NewReader(`{"taxpayerId":"1234567890121","totalIncome":500000}`))
+
+		h.TaxCalculatePdfHandler(c)
+
+		if stub.expectToCall["TaxCalculateSummary"] {
+			t.Error("expect TaxCalculateSummary was not called")
+		}
+		assertHttpCode(t, http.StatusUnauthorized, res.Code)
+	})
+	t.Run("given valid tax should return pdf", func(t *testing.T) {
+		res, c, h, _ := setupSummaryHandler(http.MethodPost, "/tax/calculations/pdf", strings.NewReader(`{"totalIncome":500000}`))
+
+		h.TaxCalculatePdfHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if got := res.Header().Get(echo.HeaderContentType); got != "application/pdf" {
+			t.Errorf("expect content type application/pdf but got %q", got)
+		}
+		if !strings.HasPrefix(res.Body.String(), "%PDF-") {
+			t.Errorf("expect pdf content but got %q", res.Body.String())
+		}
+	})
+}
+
+func TestTaxReturnPdfHandler(t *testing.T) {
+	t.Run("given not found error should return 404", func(t *testing.T) {
+		res, c, h, stub := setupSummaryHandler(http.MethodGet, "/tax/returns/9/pdf", nil)
+		c.SetParamNames("id")
+		c.SetParamValues("9")
+		stub.err = utils.ErrTaxReturnNotFound
+
+		h.TaxReturnPdfHandler(c)
+
+		assertHttpCode(t, http.StatusNotFound, res.Code)
+	})
+	t.Run("given return saved without snapshot of taxpayer profile should return 409", func(t *testing.T) {
+		res, c, h, stub := setupSummaryHandler(http.MethodGet, "/tax/returns/1/pdf", nil)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		stub.err = utils.ErrTaxReturnSnapshotMissing
+
+		h.TaxReturnPdfHandler(c)
+
+		assertHttpCode(t, http.StatusConflict, res.Code)
+	})
+	t.Run("given exist id should return pdf", func(t *testing.T) {
+		res, c, h, _ := setupSummaryHandler(http.MethodGet, "/tax/returns/1/pdf", nil)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		h.TaxReturnPdfHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if got := res.Header().Get(echo.HeaderContentDisposition); got != `inline; filename="tax-return-1.pdf"` {
+			t.Errorf("expect pdf file name of tax return but got %q", got)
+		}
+	})
+}
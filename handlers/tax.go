@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 
+	"github.com/baronight/assessment-tax/metrics"
 	"github.com/baronight/assessment-tax/models"
 	"github.com/baronight/assessment-tax/utils"
 	"github.com/baronight/assessment-tax/validators"
@@ -15,9 +18,9 @@ type TaxHandlers struct {
 }
 
 type TaxServicer interface {
-	TaxCalculate(tax models.TaxRequest) (models.TaxResponse, error)
+	TaxCalculate(ctx context.Context, tax models.TaxRequest) (models.TaxResponse, error)
 	ExtractCsv(reader io.Reader) ([]models.TaxCsv, error)
-	CalculateTaxCsv(taxes []models.TaxCsv) (models.TaxCsvResponse, error)
+	CalculateTaxCsv(ctx context.Context, taxes []models.TaxCsv) (models.TaxCsvResponse, error)
 }
 
 func NewTaxHandlers(service TaxServicer) *TaxHandlers {
@@ -34,23 +37,30 @@ func NewTaxHandlers(service TaxServicer) *TaxHandlers {
 // @Param tax body TaxRequest true "tax data that want to calculate"
 // @Success 200 {object} TaxResponse
 // @Router /tax/calculations [post]
-// @Failure 400 {object} ErrorResponse "validate error or cannot get body"
+// @Failure 400 {object} ErrorResponse "validate error, cannot get body, exchange rate or taxpayer not found"
+// @Failure 401 {object} ErrorResponse "taxpayer id is sent without authentication"
 // @Failure 500 {object} ErrorResponse "internal server error"
 func (h *TaxHandlers) TaxCalculateHandler(c echo.Context) error {
 	body := new(models.TaxRequest)
 	if err := c.Bind(body); err != nil {
 		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
 	}
+	if !taxpayerAuthorized(c, body.TaxpayerId) {
+		return taxpayerUnauthorized(c)
+	}
 
 	if err := validators.ValidateTaxRequest(*body); err != nil {
 		c.Logger().Error(err)
 		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
 	}
 
-	result, err := h.Service.TaxCalculate(*body)
+	result, err := h.Service.TaxCalculate(c.Request().Context(), *body)
 
 	if err != nil {
 		c.Logger().Error(err)
+		if errors.Is(err, utils.ErrExchangeRateNotFound) || errors.Is(err, utils.ErrTaxpayerNotFound) {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
 	}
 
@@ -72,24 +82,25 @@ func (h *TaxHandlers) TaxCalculateHandler(c echo.Context) error {
 func (h *TaxHandlers) TaxUploadCsvHandler(c echo.Context) error {
 	file, err := c.FormFile("taxFile")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+		return rejectCsv(c, "tax", csvMissingFile, err.Error())
 	}
 	if fileType := file.Header.Get("Content-Type"); fileType != "text/csv" {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: "support only csv file"})
+		return rejectCsv(c, "tax", csvNotCsv, "support only csv file")
 	}
 
 	src, err := file.Open()
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+		return rejectCsv(c, "tax", csvUnreadable, err.Error())
 	}
 	defer src.Close()
 
 	csv, err := h.Service.ExtractCsv(src)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+		return rejectCsv(c, "tax", csvRejectReason(err), err.Error())
 	}
+	metrics.CsvRows.WithLabelValues("tax").Add(float64(len(csv)))
 
-	result, err := h.Service.CalculateTaxCsv(csv)
+	result, err := h.Service.CalculateTaxCsv(c.Request().Context(), csv)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
 	}
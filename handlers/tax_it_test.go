@@ -106,6 +106,11 @@ func TestITTaxCalculations(t *testing.T) {
 				Tax:   0.0,
 			},
 		},
+		Installments: []models.Installment{
+			{No: 1, DueDate: "2025-03-31", Amount: 9_666.66},
+			{No: 2, DueDate: "2025-04-30", Amount: 9_666.66},
+			{No: 3, DueDate: "2025-05-31", Amount: 9_666.68},
+		},
 	}
 
 	if !reflect.DeepEqual(want, got) {
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type TaxReturnHandlers struct {
+	Service TaxReturnServicer
+}
+
+type TaxReturnServicer interface {
+	SaveTaxReturn(ctx context.Context, req models.TaxReturnRequest) (models.TaxReturn, error)
+	GetTaxReturns(ctx context.Context, taxpayerId string, taxYear int) ([]models.TaxReturn, error)
+	GetTaxReturn(ctx context.Context, id uint) (models.TaxReturn, error)
+	AmendTaxReturn(ctx context.Context, id uint, tax models.TaxRequest) (models.TaxReturn, error)
+}
+
+func NewTaxReturnHandlers(service TaxReturnServicer) *TaxReturnHandlers {
+	return &TaxReturnHandlers{Service: service}
+}
+
+func taxReturnErrorResponse(c echo.Context, err error) error {
+	c.Logger().Error(err)
+	switch {
+	case errors.Is(err, utils.ErrTaxReturnNotFound):
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, utils.ErrExchangeRateNotFound), errors.Is(err, utils.ErrTaxpayerNotFound):
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, utils.ErrTaxReturnExists), errors.Is(err, utils.ErrTaxReturnSnapshotMissing):
+		return c.JSON(http.StatusConflict, models.ErrorResponse{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+}
+
+func taxReturnId(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return 0, errors.New("tax return id should be number")
+	}
+	return uint(id), nil
+}
+
+// SaveTaxReturnHandler
+//
+// @Summary Save Tax Return API
+// @Description To calculate personal tax and save it as tax return of taxpayer with deduction config that used
+// @Tags tax, tax-return
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param taxReturn body TaxReturnRequest true "taxpayer, tax year and tax data that want to calculate"
+// @Success 201 {object} TaxReturn
+// @Router /tax/returns [post]
+// @Failure 400 {object} ErrorResponse "validate error, cannot get body or exchange rate or taxpayer not found"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 409 {object} ErrorResponse "tax return of taxpayer and tax year is already saved"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *TaxReturnHandlers) SaveTaxReturnHandler(c echo.Context) error {
+	body := new(models.TaxReturnRequest)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	if err := validators.ValidateTaxReturnRequest(*body); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.SaveTaxReturn(c.Request().Context(), *body)
+	if err != nil {
+		return taxReturnErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusCreated, result)
+}
+
+// GetTaxReturnsHandler
+//
+// @Summary Tax Return History API
+// @Description To list tax returns of taxpayer, filter by tax year when send
+// @Tags tax, tax-return
+// @Produce json
+// @Security BasicAuth
+// @Param taxpayerId query string true "taxpayer id"
+// @Param taxYear query int false "tax year in Buddhist Era"
+// @Success 200 {array} TaxReturn
+// @Router /tax/returns [get]
+// @Failure 400 {object} ErrorResponse "validate error"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *TaxReturnHandlers) GetTaxReturnsHandler(c echo.Context) error {
+	taxpayerId := c.QueryParam("taxpayerId")
+	if taxpayerId == "" {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: validators.ErrTaxpayerIdRequired.Error()})
+	}
+	var taxYear int
+	if year := c.QueryParam("taxYear"); year != "" {
+		var err error
+		if taxYear, err = strconv.Atoi(year); err != nil || validators.ValidateTaxYear(taxYear) != nil {
+			return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: validators.ErrTaxYearInvalid.Error()})
+		}
+	}
+
+	result, err := h.Service.GetTaxReturns(c.Request().Context(), taxpayerId, taxYear)
+	if err != nil {
+		return taxReturnErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetTaxReturnHandler
+//
+// @Summary Tax Return API
+// @Description To get saved tax return with its request, response and deduction config that used
+// @Tags tax, tax-return
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "tax return id"
+// @Success 200 {object} TaxReturn
+// @Router /tax/returns/{id} [get]
+// @Failure 400 {object} ErrorResponse "invalid id"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 404 {object} ErrorResponse "tax return not found"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *TaxReturnHandlers) GetTaxReturnHandler(c echo.Context) error {
+	id, err := taxReturnId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.GetTaxReturn(c.Request().Context(), id)
+	if err != nil {
+		return taxReturnErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// AmendTaxReturnHandler
+//
+// @Summary Amend Tax Return API
+// @Description To recalculate saved tax return with new tax data and current deduction config and save it as next revision, earlier revisions are kept
+// @Tags tax, tax-return
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param id path int true "tax return id"
+// @Param tax body TaxRequest true "tax data that want to amend"
+// @Success 200 {object} TaxReturn
+// @Router /tax/returns/{id} [put]
+// @Failure 400 {object} ErrorResponse "invalid id, validate error, taxpayer id is not of tax return, cannot get body or exchange rate or taxpayer not found"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 404 {object} ErrorResponse "tax return not found"
+// @Failure 409 {object} ErrorResponse "tax return is amended by other request at the same time"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *TaxReturnHandlers) AmendTaxReturnHandler(c echo.Context) error {
+	id, err := taxReturnId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+	body := new(models.TaxRequest)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	taxReturn, err := h.Service.GetTaxReturn(c.Request().Context(), id)
+	if err != nil {
+		return taxReturnErrorResponse(c, err)
+	}
+	// amended tax is checked with taxpayer and tax year of saved return as SaveTaxReturnHandler does
+	if err := validators.ValidateTaxReturnRequest(models.TaxReturnRequest{
+		TaxpayerId: taxReturn.TaxpayerId,
+		TaxYear:    taxReturn.TaxYear,
+		Tax:        *body,
+	}); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.AmendTaxReturn(c.Request().Context(), id, *body)
+	if err != nil {
+		return taxReturnErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
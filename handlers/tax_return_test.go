@@ -0,0 +1,218 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type stubTaxReturnServicer struct {
+	expectToCall map[string]bool
+	err          error
+	taxpayerId   string
+	taxYear      int
+}
+
+func (s *stubTaxReturnServicer) SaveTaxReturn(ctx context.Context, req models.TaxReturnRequest) (models.TaxReturn, error) {
+	s.expectToCall["SaveTaxReturn"] = true
+	return models.TaxReturn{Id: 1, TaxpayerId: req.TaxpayerId, TaxYear: req.TaxYear, Revision: 1, Request: req.Tax}, s.err
+}
+func (s *stubTaxReturnServicer) GetTaxReturns(ctx context.Context, taxpayerId string, taxYear int) ([]models.TaxReturn, error) {
+	s.expectToCall["GetTaxReturns"] = true
+	s.taxpayerId, s.taxYear = taxpayerId, taxYear
+	return []models.TaxReturn{}, s.err
+}
+func (s *stubTaxReturnServicer) GetTaxReturn(ctx context.Context, id uint) (models.TaxReturn, error) {
+	s.expectToCall["GetTaxReturn"] = true
+	return models.TaxReturn{Id: id, TaxpayerId: "1234567890121", TaxYear: 2567}, s.err
+}
+func (s *stubTaxReturnServicer) AmendTaxReturn(ctx context.Context, id uint, tax models.TaxRequest) (models.TaxReturn, error) {
+	s.expectToCall["AmendTaxReturn"] = true
+	return models.TaxReturn{Id: id, Revision: 2, Request: tax}, s.err
+}
+
+func setupTaxReturnHandler(method, target string, body io.Reader) (res *httptest.ResponseRecorder, c echo.Context, h *TaxReturnHandlers, stub *stubTaxReturnServicer) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	res = httptest.NewRecorder()
+	c = e.NewContext(req, res)
+	stub = &stubTaxReturnServicer{expectToCall: make(map[string]bool)}
+	h = NewTaxReturnHandlers(stub)
+	return
+}
+
+func TestSaveTaxReturnHandler(t *testing.T) {
+	t.Run("given missing taxpayer id should return 400 with validate message", func(t *testing.T) {
+		res, c, h, stub := setupTaxReturnHandler(http.MethodPost, "/tax/returns", strings.NewReader(`{"taxYear":2567,"tax":{"totalIncome":500000}}`))
+
+		h.SaveTaxReturnHandler(c)
+
+		if stub.expectToCall["SaveTaxReturn"] {
+			t.Error("expect SaveTaxReturn was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrTaxpayerIdRequired.Error(), got.Message)
+	})
+	t.Run("given valid request should return 201 with saved tax return", func(t *testing.T) {
+		res, c, h, _ := setupTaxReturnHandler(http.MethodPost, "/tax/returns", strings.NewReader(`{"taxpayerId":"1234567890121","taxYear":2567,"tax":{"totalIncome":500000}}`))
+
+		h.SaveTaxReturnHandler(c)
+
+		assertHttpCode(t, http.StatusCreated, res.Code)
+		var got models.TaxReturn
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || got.Id != 1 || got.TaxpayerId != "1234567890121" {
+			t.Errorf("expect saved tax return but got %s", res.Body.String())
+		}
+	})
+	t.Run("given error from service should return 500 with error message", func(t *testing.T) {
+		res, c, h, stub := setupTaxReturnHandler(http.MethodPost, "/tax/returns", strings.NewReader(`{"taxpayerId":"1234567890121","taxYear":2567,"tax":{"totalIncome":500000}}`))
+		stub.err = errors.New("error 'xxx' occured")
+
+		h.SaveTaxReturnHandler(c)
+
+		assertHttpCode(t, http.StatusInternalServerError, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
+	})
+	t.Run("given saved return of taxpayer and tax year should return 409 with error message", func(t *testing.T) {
+		res, c, h, stub := setupTaxReturnHandler(http.MethodPost, "/tax/returns", strings.NewReader(`{"taxpayerId":"1234567890121","taxYear":2567,"tax":{"totalIncome":500000}}`))
+		stub.err = utils.ErrTaxReturnExists
+
+		h.SaveTaxReturnHandler(c)
+
+		assertHttpCode(t, http.StatusConflict, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrTaxReturnExists.Error(), got.Message)
+	})
+}
+
+func TestGetTaxReturnsHandler(t *testing.T) {
+	t.Run("given missing taxpayer id should return 400", func(t *testing.T) {
+		res, c, h, _ := setupTaxReturnHandler(http.MethodGet, "/tax/returns", nil)
+
+		h.GetTaxReturnsHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrTaxpayerIdRequired.Error(), got.Message)
+	})
+	t.Run("given invalid tax year should return 400", func(t *testing.T) {
+		res, c, h, _ := setupTaxReturnHandler(http.MethodGet, "/tax/returns?taxpayerId=A&taxYear=abc", nil)
+
+		h.GetTaxReturnsHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrTaxYearInvalid.Error(), got.Message)
+	})
+	t.Run("given taxpayer id and tax year should return 200 with filter", func(t *testing.T) {
+		res, c, h, stub := setupTaxReturnHandler(http.MethodGet, "/tax/returns?taxpayerId=A&taxYear=2567", nil)
+
+		h.GetTaxReturnsHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if stub.taxpayerId != "A" || stub.taxYear != 2567 {
+			t.Errorf("expect filter taxpayer A year 2567 but got %s %d", stub.taxpayerId, stub.taxYear)
+		}
+	})
+}
+
+func TestGetTaxReturnHandler(t *testing.T) {
+	t.Run("given not number id should return 400", func(t *testing.T) {
+		res, c, h, stub := setupTaxReturnHandler(http.MethodGet, "/tax/returns/abc", nil)
+		c.SetParamNames("id")
+		c.SetParamValues("abc")
+
+		h.GetTaxReturnHandler(c)
+
+		if stub.expectToCall["GetTaxReturn"] {
+			t.Error("expect GetTaxReturn was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+	t.Run("given not found error should return 404", func(t *testing.T) {
+		res, c, h, stub := setupTaxReturnHandler(http.MethodGet, "/tax/returns/9", nil)
+		c.SetParamNames("id")
+		c.SetParamValues("9")
+		stub.err = utils.ErrTaxReturnNotFound
+
+		h.GetTaxReturnHandler(c)
+
+		assertHttpCode(t, http.StatusNotFound, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrTaxReturnNotFound.Error(), got.Message)
+	})
+}
+
+func TestAmendTaxReturnHandler(t *testing.T) {
+	t.Run("given invalid tax should return 400 with validate message", func(t *testing.T) {
+		res, c, h, stub := setupTaxReturnHandler(http.MethodPut, "/tax/returns/1", strings.NewReader(`{"totalIncome":-1}`))
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		h.AmendTaxReturnHandler(c)
+
+		if stub.expectToCall["AmendTaxReturn"] {
+			t.Error("expect AmendTaxReturn was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+	t.Run("given taxpayer id of other taxpayer should return 400 with mismatch message", func(t *testing.T) {
+		res, c, h, stub := setupTaxReturnHandler(http.MethodPut, "/tax/returns/1", strings.NewReader(`{"totalIncome":500000,"taxpayerId":"1101700203451"}`))
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		h.AmendTaxReturnHandler(c)
+
+		if !stub.expectToCall["GetTaxReturn"] {
+			t.Error("expect GetTaxReturn was called")
+		}
+		if stub.expectToCall["AmendTaxReturn"] {
+			t.Error("expect AmendTaxReturn was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrTaxpayerIdMismatch.Error(), got.Message)
+	})
+	t.Run("given not found error should return 404", func(t *testing.T) {
+		res, c, h, stub := setupTaxReturnHandler(http.MethodPut, "/tax/returns/9", strings.NewReader(`{"totalIncome":500000}`))
+		c.SetParamNames("id")
+		c.SetParamValues("9")
+		stub.err = utils.ErrTaxReturnNotFound
+
+		h.AmendTaxReturnHandler(c)
+
+		if stub.expectToCall["AmendTaxReturn"] {
+			t.Error("expect AmendTaxReturn was not called")
+		}
+		assertHttpCode(t, http.StatusNotFound, res.Code)
+	})
+	t.Run("given valid tax should return 200 with amended tax return", func(t *testing.T) {
+		res, c, h, _ := setupTaxReturnHandler(http.MethodPut, "/tax/returns/1", strings.NewReader(`{"totalIncome":500000}`))
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		h.AmendTaxReturnHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		var got models.TaxReturn
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || got.Revision != 2 {
+			t.Errorf("expect amended tax return but got %s", res.Body.String())
+		}
+	})
+}
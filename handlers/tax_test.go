@@ -5,6 +5,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,6 +20,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/baronight/assessment-tax/middlewares"
 	"github.com/baronight/assessment-tax/models"
 	"github.com/baronight/assessment-tax/utils"
 	"github.com/baronight/assessment-tax/validators"
@@ -33,11 +35,13 @@ type stubTaxCalculate struct {
 	extractResult   []models.TaxCsv
 	extractErr      error
 	csvResponse     models.TaxCsvResponse
+	ctx             context.Context
 }
 
-func (s *stubTaxCalculate) TaxCalculate(tax models.TaxRequest) (models.TaxResponse, error) {
+func (s *stubTaxCalculate) TaxCalculate(ctx context.Context, tax models.TaxRequest) (models.TaxResponse, error) {
 	s.expectToCall["TaxCalculate"] = true
 	s.expectCallTimes["TaxCalculate"]++
+	s.ctx = ctx
 	return s.response, s.err
 }
 func (s *stubTaxCalculate) ExtractCsv(reader io.Reader) ([]models.TaxCsv, error) {
@@ -45,7 +49,7 @@ func (s *stubTaxCalculate) ExtractCsv(reader io.Reader) ([]models.TaxCsv, error)
 	s.expectCallTimes["ExtractCsv"]++
 	return s.extractResult, s.extractErr
 }
-func (s *stubTaxCalculate) CalculateTaxCsv(taxes []models.TaxCsv) (models.TaxCsvResponse, error) {
+func (s *stubTaxCalculate) CalculateTaxCsv(ctx context.Context, taxes []models.TaxCsv) (models.TaxCsvResponse, error) {
 	s.expectToCall["CalculateTaxCsv"] = true
 	s.expectCallTimes["CalculateTaxCsv"]++
 	return s.csvResponse, s.err
@@ -146,6 +150,17 @@ func TestTaxCalculateHandler(t *testing.T) {
 			got := decodeErrorResponse(t, res)
 			assertErrorMessage(t, validators.ErrWhtMoreThanIncome.Error(), got.Message)
 		})
+		t.Run("when filing date is not valid should get error message ErrDateInvalid", func(t *testing.T) {
+			body, _ := json.Marshal(models.TaxRequest{TotalIncome: 200_000, FilingDate: "30/04/2025", DueDate: "2025-03-31"})
+			res, c, h, stub := setupTaxHandler(http.MethodPost, "/tax/calculations", strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+
+			h.TaxCalculateHandler(c)
+
+			stub.assertMethodWasNotCalled(t, "TaxCalculate")
+			assertHttpCode(t, http.StatusBadRequest, res.Code)
+			got := decodeErrorResponse(t, res)
+			assertErrorMessage(t, validators.ErrDateInvalid.Error(), got.Message)
+		})
 	})
 
 	t.Run("given valid total income should return status 200 with tax response", func(t *testing.T) {
@@ -199,6 +214,68 @@ func TestTaxCalculateHandler(t *testing.T) {
 	})
 }
 
+func TestTaxCalculateHandlerExchangeRateNotFound(t *testing.T) {
+	body, _ := json.Marshal(models.TaxRequest{TotalIncome: 20_000, Currency: "USD"})
+	res, c, h, stub := setupTaxHandler(http.MethodPost, "/tax/calculations", strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+	stub.err = fmt.Errorf("%w: USD on 2024-12-30", utils.ErrExchangeRateNotFound)
+
+	h.TaxCalculateHandler(c)
+
+	assertHttpCode(t, http.StatusBadRequest, res.Code)
+	got := decodeErrorResponse(t, res)
+	assertErrorMessage(t, stub.err.Error(), got.Message)
+}
+
+func TestTaxCalculateHandlerTaxpayerAuth(t *testing.T) {
+	body, _ := json.Marshal(models.TaxRequest{TaxpayerId: "1234567890121", TotalIncome: 500_000})
+	t.Run("given taxpayer id without authentication should return 401 and not calculate", func(t *testing.T) {
+		res, c, h, stub := setupTaxHandler(http.MethodPost, "/tax/calculations", strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+
+		h.TaxCalculateHandler(c)
+
+		if stub.expectToCall["TaxCalculate"] {
+			t.Error("expect TaxCalculate was not called")
+		}
+		assertHttpCode(t, http.StatusUnauthorized, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrTaxpayerAuthRequired.Error(), got.Message)
+	})
+	t.Run("given taxpayer id of authenticated admin should calculate with taxpayer profile", func(t *testing.T) {
+		res, c, h, stub := setupTaxHandler(http.MethodPost, "/tax/calculations", strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+		c.Set(middlewares.AdminUserKey, "adminTax")
+
+		h.TaxCalculateHandler(c)
+
+		stub.assertMethodCalledTime(t, "TaxCalculate", 1)
+		assertHttpCode(t, http.StatusOK, res.Code)
+	})
+	t.Run("given taxpayer not found should return 400 with error message", func(t *testing.T) {
+		res, c, h, stub := setupTaxHandler(http.MethodPost, "/tax/calculations", strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+		c.Set(middlewares.AdminUserKey, "adminTax")
+		stub.err = utils.ErrTaxpayerNotFound
+
+		h.TaxCalculateHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+}
+
+func TestTaxCalculateHandlerRequestContext(t *testing.T) {
+	body, _ := json.Marshal(models.TaxRequest{TotalIncome: 500_000})
+	res, c, h, stub := setupTaxHandler(http.MethodPost, "/tax/calculations", strings.NewReader(string(body)), echo.MIMEApplicationJSON)
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	c.SetRequest(c.Request().WithContext(ctx))
+	cancel()
+	stub.err = ctx.Err()
+
+	h.TaxCalculateHandler(c)
+
+	if stub.ctx == nil || !errors.Is(stub.ctx.Err(), context.Canceled) {
+		t.Errorf("expect service was called with cancelled request context but got %v", stub.ctx)
+	}
+	assertHttpCode(t, http.StatusInternalServerError, res.Code)
+}
+
 func TestTaxUploadCsvHandler(t *testing.T) {
 	csvMimeType := "text/csv"
 	uploadUrl := "/tax/calculations/upload-csv"
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/baronight/assessment-tax/middlewares"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type TaxpayerHandlers struct {
+	Service TaxpayerServicer
+}
+
+type TaxpayerServicer interface {
+	CreateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error)
+	GetTaxpayer(ctx context.Context, nationalId string) (models.Taxpayer, error)
+	UpdateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error)
+	DeleteTaxpayer(ctx context.Context, nationalId string) error
+}
+
+func NewTaxpayerHandlers(service TaxpayerServicer) *TaxpayerHandlers {
+	return &TaxpayerHandlers{Service: service}
+}
+
+func taxpayerErrorResponse(c echo.Context, err error) error {
+	c.Logger().Error(err)
+	switch {
+	case errors.Is(err, utils.ErrTaxpayerNotFound):
+		return c.JSON(http.StatusNotFound, models.ErrorResponse{Message: err.Error()})
+	case errors.Is(err, utils.ErrTaxpayerExists):
+		return c.JSON(http.StatusConflict, models.ErrorResponse{Message: err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Message: utils.ErrInternalServer.Error()})
+}
+
+// taxpayerAuthorized check that request which reference taxpayer profile is sent by authenticated admin,
+// profile is found by national id only so anonymous request could read family data of anyone
+func taxpayerAuthorized(c echo.Context, taxpayerIds ...string) bool {
+	if middlewares.AdminUser(c) != "" {
+		return true
+	}
+	for _, id := range taxpayerIds {
+		if id != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func taxpayerUnauthorized(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `basic realm="Restricted"`)
+	return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Message: utils.ErrTaxpayerAuthRequired.Error()})
+}
+
+// CreateTaxpayerHandler
+//
+// @Summary Create Taxpayer API
+// @Description To create taxpayer profile, number of children and parents is used as family allowance when calculation send taxpayer id
+// @Tags tax, taxpayer
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param taxpayer body Taxpayer true "taxpayer profile"
+// @Success 201 {object} Taxpayer
+// @Router /tax/taxpayers [post]
+// @Failure 400 {object} ErrorResponse "validate error or cannot get body"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 409 {object} ErrorResponse "taxpayer already exists"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *TaxpayerHandlers) CreateTaxpayerHandler(c echo.Context) error {
+	body := new(models.Taxpayer)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	if err := validators.ValidateTaxpayer(*body); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.CreateTaxpayer(c.Request().Context(), *body)
+	if err != nil {
+		return taxpayerErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusCreated, result)
+}
+
+// GetTaxpayerHandler
+//
+// @Summary Taxpayer API
+// @Description To get taxpayer profile by national id
+// @Tags tax, taxpayer
+// @Produce json
+// @Security BasicAuth
+// @Param nationalId path string true "13 digits national id"
+// @Success 200 {object} Taxpayer
+// @Router /tax/taxpayers/{nationalId} [get]
+// @Failure 400 {object} ErrorResponse "invalid national id"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 404 {object} ErrorResponse "taxpayer not found"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *TaxpayerHandlers) GetTaxpayerHandler(c echo.Context) error {
+	nationalId := c.Param("nationalId")
+	if err := validators.ValidateNationalId(nationalId); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.GetTaxpayer(c.Request().Context(), nationalId)
+	if err != nil {
+		return taxpayerErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// UpdateTaxpayerHandler
+//
+// @Summary Update Taxpayer API
+// @Description To update taxpayer profile, national id in path is used instead of one in body
+// @Tags tax, taxpayer
+// @Accept json
+// @Produce json
+// @Security BasicAuth
+// @Param nationalId path string true "13 digits national id"
+// @Param taxpayer body Taxpayer true "taxpayer profile"
+// @Success 200 {object} Taxpayer
+// @Router /tax/taxpayers/{nationalId} [put]
+// @Failure 400 {object} ErrorResponse "validate error or cannot get body"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 404 {object} ErrorResponse "taxpayer not found"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *TaxpayerHandlers) UpdateTaxpayerHandler(c echo.Context) error {
+	body := new(models.Taxpayer)
+	if err := c.Bind(body); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+	body.NationalId = c.Param("nationalId")
+
+	if err := validators.ValidateTaxpayer(*body); err != nil {
+		c.Logger().Error(err)
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	result, err := h.Service.UpdateTaxpayer(c.Request().Context(), *body)
+	if err != nil {
+		return taxpayerErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// DeleteTaxpayerHandler
+//
+// @Summary Delete Taxpayer API
+// @Description To delete taxpayer profile by national id
+// @Tags tax, taxpayer
+// @Security BasicAuth
+// @Param nationalId path string true "13 digits national id"
+// @Success 204
+// @Router /tax/taxpayers/{nationalId} [delete]
+// @Failure 400 {object} ErrorResponse "invalid national id"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 404 {object} ErrorResponse "taxpayer not found"
+// @Failure 500 {object} ErrorResponse "internal server error"
+func (h *TaxpayerHandlers) DeleteTaxpayerHandler(c echo.Context) error {
+	nationalId := c.Param("nationalId")
+	if err := validators.ValidateNationalId(nationalId); err != nil {
+		return c.JSON(http.StatusBadRequest, models.ErrorResponse{Message: err.Error()})
+	}
+
+	if err := h.Service.DeleteTaxpayer(c.Request().Context(), nationalId); err != nil {
+		return taxpayerErrorResponse(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
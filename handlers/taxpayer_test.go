@@ -0,0 +1,159 @@
+//go:build !integration
+// +build !integration
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"github.com/labstack/echo/v4"
+)
+
+type stubTaxpayerServicer struct {
+	expectToCall map[string]bool
+	err          error
+	updated      models.Taxpayer
+}
+
+func (s *stubTaxpayerServicer) CreateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error) {
+	s.expectToCall["CreateTaxpayer"] = true
+	return taxpayer, s.err
+}
+func (s *stubTaxpayerServicer) GetTaxpayer(ctx context.Context, nationalId string) (models.Taxpayer, error) {
+	s.expectToCall["GetTaxpayer"] = true
+	return models.Taxpayer{NationalId: nationalId}, s.err
+}
+func (s *stubTaxpayerServicer) UpdateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error) {
+	s.expectToCall["UpdateTaxpayer"] = true
+	s.updated = taxpayer
+	return taxpayer, s.err
+}
+func (s *stubTaxpayerServicer) DeleteTaxpayer(ctx context.Context, nationalId string) error {
+	s.expectToCall["DeleteTaxpayer"] = true
+	return s.err
+}
+
+func setupTaxpayerHandler(method, target string, body io.Reader, nationalId string) (res *httptest.ResponseRecorder, c echo.Context, h *TaxpayerHandlers, stub *stubTaxpayerServicer) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	res = httptest.NewRecorder()
+	c = e.NewContext(req, res)
+	if nationalId != "" {
+		c.SetParamNames("nationalId")
+		c.SetParamValues(nationalId)
+	}
+	stub = &stubTaxpayerServicer{expectToCall: make(map[string]bool)}
+	h = NewTaxpayerHandlers(stub)
+	return
+}
+
+const validTaxpayerBody = `{"nationalId":"1234567890121","name":"Somchai","maritalStatus":"married","children":2}`
+
+func TestCreateTaxpayerHandler(t *testing.T) {
+	t.Run("given invalid national id should return 400 with validate message", func(t *testing.T) {
+		res, c, h, stub := setupTaxpayerHandler(http.MethodPost, "/tax/taxpayers", strings.NewReader(`{"nationalId":"1234567890123","name":"Somchai","maritalStatus":"single"}`), "")
+
+		h.CreateTaxpayerHandler(c)
+
+		if stub.expectToCall["CreateTaxpayer"] {
+			t.Error("expect CreateTaxpayer was not called")
+		}
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, validators.ErrNationalIdInvalid.Error(), got.Message)
+	})
+	t.Run("given valid taxpayer should return 201", func(t *testing.T) {
+		res, c, h, _ := setupTaxpayerHandler(http.MethodPost, "/tax/taxpayers", strings.NewReader(validTaxpayerBody), "")
+
+		h.CreateTaxpayerHandler(c)
+
+		assertHttpCode(t, http.StatusCreated, res.Code)
+		var got models.Taxpayer
+		if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil || got.Children != 2 {
+			t.Errorf("expect created taxpayer but got %s", res.Body.String())
+		}
+	})
+	t.Run("given exist taxpayer should return 409", func(t *testing.T) {
+		res, c, h, stub := setupTaxpayerHandler(http.MethodPost, "/tax/taxpayers", strings.NewReader(validTaxpayerBody), "")
+		stub.err = utils.ErrTaxpayerExists
+
+		h.CreateTaxpayerHandler(c)
+
+		assertHttpCode(t, http.StatusConflict, res.Code)
+	})
+	t.Run("given error from service should return 500 with error message", func(t *testing.T) {
+		res, c, h, stub := setupTaxpayerHandler(http.MethodPost, "/tax/taxpayers", strings.NewReader(validTaxpayerBody), "")
+		stub.err = errors.New("error 'xxx' occured")
+
+		h.CreateTaxpayerHandler(c)
+
+		assertHttpCode(t, http.StatusInternalServerError, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrInternalServer.Error(), got.Message)
+	})
+}
+
+func TestGetTaxpayerHandler(t *testing.T) {
+	t.Run("given invalid national id should return 400", func(t *testing.T) {
+		res, c, h, _ := setupTaxpayerHandler(http.MethodGet, "/tax/taxpayers/123", nil, "123")
+
+		h.GetTaxpayerHandler(c)
+
+		assertHttpCode(t, http.StatusBadRequest, res.Code)
+	})
+	t.Run("given not found error should return 404", func(t *testing.T) {
+		res, c, h, stub := setupTaxpayerHandler(http.MethodGet, "/tax/taxpayers/1234567890121", nil, "1234567890121")
+		stub.err = utils.ErrTaxpayerNotFound
+
+		h.GetTaxpayerHandler(c)
+
+		assertHttpCode(t, http.StatusNotFound, res.Code)
+		got := decodeErrorResponse(t, res)
+		assertErrorMessage(t, utils.ErrTaxpayerNotFound.Error(), got.Message)
+	})
+}
+
+func TestUpdateTaxpayerHandler(t *testing.T) {
+	t.Run("given national id in path should update that taxpayer", func(t *testing.T) {
+		res, c, h, stub := setupTaxpayerHandler(http.MethodPut, "/tax/taxpayers/3100600123450", strings.NewReader(validTaxpayerBody), "3100600123450")
+
+		h.UpdateTaxpayerHandler(c)
+
+		assertHttpCode(t, http.StatusOK, res.Code)
+		if stub.updated.NationalId != "3100600123450" {
+			t.Errorf("expect update national id 3100600123450 but got %s", stub.updated.NationalId)
+		}
+	})
+}
+
+func TestDeleteTaxpayerHandler(t *testing.T) {
+	t.Run("given exist taxpayer should return 204", func(t *testing.T) {
+		res, c, h, stub := setupTaxpayerHandler(http.MethodDelete, "/tax/taxpayers/1234567890121", nil, "1234567890121")
+
+		h.DeleteTaxpayerHandler(c)
+
+		if !stub.expectToCall["DeleteTaxpayer"] {
+			t.Error("expect DeleteTaxpayer was called")
+		}
+		assertHttpCode(t, http.StatusNoContent, res.Code)
+	})
+	t.Run("given not found error should return 404", func(t *testing.T) {
+		res, c, h, stub := setupTaxpayerHandler(http.MethodDelete, "/tax/taxpayers/1234567890121", nil, "1234567890121")
+		stub.err = utils.ErrTaxpayerNotFound
+
+		h.DeleteTaxpayerHandler(c)
+
+		assertHttpCode(t, http.StatusNotFound, res.Code)
+	})
+}
@@ -6,19 +6,31 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/log"
 
-	"github.com/baronight/assessment-tax/db"
+	"github.com/baronight/assessment-tax/config"
 	_ "github.com/baronight/assessment-tax/docs"
 	"github.com/baronight/assessment-tax/handlers"
+	"github.com/baronight/assessment-tax/metrics"
 	"github.com/baronight/assessment-tax/middlewares"
 	"github.com/baronight/assessment-tax/services"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
+// logLevels map config.LogLevels to level of echo logger
+var logLevels = map[string]log.Lvl{
+	"debug": log.DEBUG,
+	"info":  log.INFO,
+	"warn":  log.WARN,
+	"error": log.ERROR,
+	"off":   log.OFF,
+}
+
 // @securityDefinitions.basic BasicAuth
 
 // @title			K-Tax API
@@ -26,19 +38,40 @@ import (
 // @description	K-Tax Calculate API
 // @host			localhost:8080
 func main() {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "1323"
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%s\n", err)
+		os.Exit(1)
 	}
-	db, err := db.New()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg.Database, os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	backend, err := openStorage(cfg)
 	if err != nil {
 		panic(err)
 	}
+	store := backend.store
+
+	ruleSetService := services.NewRuleSetService(store)
+	if _, err := ruleSetService.LoadActiveRuleSet(context.Background()); err != nil {
+		panic(err)
+	}
+	if backend.watchRuleSets != nil {
+		// rule set that other instance activate is applied here too
+		backend.watchRuleSets(ruleSetService)
+	}
 
 	e := echo.New()
 	// e.Validator = &models.CustomValidator{Validator: validator.New()}
+	e.Logger.SetLevel(logLevels[cfg.LogLevel])
 
-	e.Use(middleware.Logger(), middleware.Recover(), middleware.CORS())
+	e.Use(middlewares.MetricsMiddleware(), middleware.Logger(), middleware.Recover(), middleware.CORSWithConfig(middleware.CORSConfig{AllowOrigins: cfg.CORS.AllowOrigins}))
 	// setup swagger document
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
@@ -46,21 +79,102 @@ func main() {
 		return c.String(http.StatusOK, "Hello, Go Bootcamp!")
 	})
 
-	taxService := services.NewTaxService(db)
+	healthService := services.NewHealthService(store)
+	healthHandler := handlers.NewHealthHandlers(healthService)
+	e.GET("/healthz", healthHandler.LivenessHandler)
+	e.GET("/readyz", healthHandler.ReadinessHandler)
+	// serve prometheus metrics
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+
+	taxService := services.NewTaxService(store)
 	taxHandler := handlers.NewTaxHandlers(taxService)
 	groupTax := e.Group("/tax")
-	groupTax.POST("/calculations", taxHandler.TaxCalculateHandler)
+	// calculation with taxpayer id use family data of taxpayer profile, handler allow it only to authenticated admin
+	optionalAuth := middlewares.OptionalBasicAuthMiddleware(cfg.Auth)
+	groupTax.POST("/calculations", taxHandler.TaxCalculateHandler, optionalAuth)
 	groupTax.POST("/calculations/upload-csv", taxHandler.TaxUploadCsvHandler)
 
-	adminService := services.NewAdminService(db)
+	spouseService := services.NewSpouseService(store)
+	spouseHandler := handlers.NewSpouseHandlers(spouseService)
+	groupTax.POST("/calculations/spouse", spouseHandler.SpouseTaxCalculateHandler, optionalAuth)
+	groupTax.POST("/calculations/spouse/upload-csv", spouseHandler.SpouseTaxUploadCsvHandler)
+
+	certificateService := services.NewCertificateService(store)
+	certificateHandler := handlers.NewCertificateHandlers(certificateService)
+	groupTax.POST("/calculations/certificates", certificateHandler.CertificateTaxCalculateHandler, optionalAuth)
+	groupTax.POST("/calculations/certificates/upload-csv", certificateHandler.CertificateUploadCsvHandler, optionalAuth)
+
+	summaryService := services.NewSummaryService(store)
+	summaryHandler := handlers.NewSummaryHandlers(summaryService)
+	groupTax.POST("/calculations/pdf", summaryHandler.TaxCalculatePdfHandler, optionalAuth)
+
+	taxpayerService := services.NewTaxpayerService(store)
+	taxpayerHandler := handlers.NewTaxpayerHandlers(taxpayerService)
+	// taxpayer profiles hold national id and family data, they are served only to authenticated user
+	groupTaxpayers := groupTax.Group("/taxpayers", middlewares.BasicAuthMiddleware(cfg.Auth))
+	groupTaxpayers.POST("", taxpayerHandler.CreateTaxpayerHandler)
+	groupTaxpayers.GET("/:nationalId", taxpayerHandler.GetTaxpayerHandler)
+	groupTaxpayers.PUT("/:nationalId", taxpayerHandler.UpdateTaxpayerHandler)
+	groupTaxpayers.DELETE("/:nationalId", taxpayerHandler.DeleteTaxpayerHandler)
+
+	taxReturnService := services.NewTaxReturnService(store)
+	taxReturnHandler := handlers.NewTaxReturnHandlers(taxReturnService)
+	// saved returns hold personal income data, they are served only to authenticated user too
+	groupReturns := groupTax.Group("/returns", middlewares.BasicAuthMiddleware(cfg.Auth))
+	groupReturns.POST("", taxReturnHandler.SaveTaxReturnHandler)
+	groupReturns.GET("", taxReturnHandler.GetTaxReturnsHandler)
+	groupReturns.GET("/:id", taxReturnHandler.GetTaxReturnHandler)
+	groupReturns.PUT("/:id", taxReturnHandler.AmendTaxReturnHandler)
+	groupReturns.GET("/:id/pdf", summaryHandler.TaxReturnPdfHandler)
+
+	efilingService := services.NewEFilingService(store)
+	efilingHandler := handlers.NewEFilingHandlers(efilingService)
+	// e-filing record has name and family data of taxpayer profile, it is exported only to authenticated user
+	groupEFiling := groupTax.Group("/e-filing", middlewares.BasicAuthMiddleware(cfg.Auth))
+	groupEFiling.POST("", efilingHandler.EFilingExportHandler)
+	groupEFiling.POST("/upload-csv", efilingHandler.EFilingUploadCsvHandler)
+
+	payrollService := services.NewPayrollService(store)
+	payrollHandler := handlers.NewPayrollHandlers(payrollService)
+	groupTax.POST("/payroll/withholdings", payrollHandler.PayrollCalculateHandler)
+	groupTax.POST("/payroll/withholdings/upload-csv", payrollHandler.PayrollUploadCsvHandler)
+
+	adminService := services.NewAdminService(store)
 	adminHandler := handlers.NewAdminHandlers(adminService)
 	groupAdmin := e.Group("/admin")
-	groupAdmin.Use(middlewares.BasicAuthMiddleware())
+	groupAdmin.Use(middlewares.BasicAuthMiddleware(cfg.Auth))
+	groupAdmin.GET("/deductions/:slug", adminHandler.GetDeductionConfigHandler)
 	groupAdmin.POST("/deductions/personal", adminHandler.PersonalDeductionConfigHandler)
 	groupAdmin.POST("/deductions/k-receipt", adminHandler.KReceiptDeductionConfigHandler)
+	groupAdmin.GET("/deduction-changes", adminHandler.GetDeductionChangesHandler)
+	groupAdmin.POST("/deduction-changes/:id/approve", adminHandler.ApproveDeductionChangeHandler)
+	groupAdmin.POST("/deduction-changes/:id/reject", adminHandler.RejectDeductionChangeHandler)
+	groupAdmin.GET("/config/export", adminHandler.ExportConfigHandler)
+	groupAdmin.POST("/config/import", adminHandler.ImportConfigHandler)
+
+	if backend.cache != nil {
+		cacheHandler := handlers.NewCacheHandlers(backend.cache)
+		groupAdmin.GET("/cache/stats", cacheHandler.CacheStatsHandler)
+	}
+	if backend.pool != nil {
+		poolHandler := handlers.NewPoolHandlers(backend.pool)
+		groupAdmin.GET("/db/stats", poolHandler.PoolStatsHandler)
+	}
+
+	ruleSetHandler := handlers.NewRuleSetHandlers(ruleSetService)
+	groupAdmin.POST("/rule-sets", ruleSetHandler.CreateRuleSetHandler)
+	groupAdmin.POST("/rule-sets/upload", ruleSetHandler.RuleSetUploadHandler)
+	groupAdmin.GET("/rule-sets", ruleSetHandler.GetRuleSetsHandler)
+	groupAdmin.GET("/rule-sets/active", ruleSetHandler.ActiveRuleSetHandler)
+	groupAdmin.POST("/rule-sets/:id/activate", ruleSetHandler.ActivateRuleSetHandler)
+
+	exchangeRateService := services.NewExchangeRateService(store)
+	exchangeRateHandler := handlers.NewExchangeRateHandlers(exchangeRateService)
+	groupAdmin.POST("/exchange-rates", exchangeRateHandler.SaveExchangeRatesHandler)
+	groupAdmin.POST("/exchange-rates/upload-csv", exchangeRateHandler.ExchangeRateUploadCsvHandler)
 
 	// make graceful shutdown
-	shutdownCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	shutdownCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
 	e.POST("/quit", func(c echo.Context) error {
@@ -70,26 +184,29 @@ func main() {
 
 	// Start server
 	go func() {
-		if err := e.Start(fmt.Sprintf(":%s", port)); err != nil && err != http.ErrServerClosed {
+		if err := e.Start(fmt.Sprintf(":%d", cfg.Port)); err != nil && err != http.ErrServerClosed {
 			e.Logger.Fatal("shutting down the server")
 		}
 	}()
 
-	// Wait for interrupt signal to gracefully shutdown the server with a timeout of 10 seconds.
+	// Wait for interrupt or terminate signal to gracefully shutdown the server with a timeout of cfg.ShutdownTimeout.
 	<-shutdownCtx.Done()
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// report not ready and wait for load balancers to stop sending requests before shut down
+	healthService.Shutdown()
+	time.Sleep(cfg.ShutdownDrainDelay)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 	fmt.Println()
-	// close db
-	if err := db.Db.Close(); err != nil {
+	// close server first so requests that are still in flight can finish with storage
+	if err := e.Shutdown(ctx); err != nil {
 		e.Logger.Fatal(err)
 	} else {
-		fmt.Println("closing database connection")
+		fmt.Println("shutting down the server")
 	}
-	// close server
-	if err := e.Shutdown(ctx); err != nil {
+	// close storage
+	if err := backend.close(); err != nil {
 		e.Logger.Fatal(err)
 	} else {
-		fmt.Println("shutting down the server")
+		fmt.Println("closing storage")
 	}
 }
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+// GetPenalties implements services.TaxStorer.
+func (s *Store) GetPenalties(ctx context.Context) ([]models.Penalty, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.penalties), nil
+}
+
+// GetInstallmentConfigs implements services.TaxStorer.
+func (s *Store) GetInstallmentConfigs(ctx context.Context) ([]models.InstallmentConfig, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.installmentConfigs), nil
+}
+
+// GetExchangeRate implements services.TaxStorer.
+// It return latest rate of currency on or before date.
+func (s *Store) GetExchangeRate(ctx context.Context, currency string, date string) (models.ExchangeRate, error) {
+	if err := ctx.Err(); err != nil {
+		return models.ExchangeRate{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var rate models.ExchangeRate
+	found := false
+	for _, v := range s.exchangeRates {
+		// rate date is in models.DateLayout, so it can be compared as string
+		if v.Currency == currency && v.RateDate <= date && (!found || v.RateDate > rate.RateDate) {
+			rate, found = v, true
+		}
+	}
+	if !found {
+		return rate, sql.ErrNoRows
+	}
+	return rate, nil
+}
+
+// SaveExchangeRates implements services.ExchangeRateStorer.
+// It replace rate of same currency and date.
+func (s *Store) SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range rates {
+		i := slices.IndexFunc(s.exchangeRates, func(r models.ExchangeRate) bool {
+			return r.Currency == v.Currency && r.RateDate == v.RateDate
+		})
+		if i >= 0 {
+			s.exchangeRates[i].Rate = v.Rate
+			continue
+		}
+		v.Id = uint(len(s.exchangeRates) + 1)
+		s.exchangeRates = append(s.exchangeRates, v)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+func (s *Store) deductionIndex(slug string) int {
+	return slices.IndexFunc(s.deductions, func(d models.Deduction) bool { return d.Slug == slug })
+}
+
+// GetDeductions implements services.TaxStorer.
+func (s *Store) GetDeductions(ctx context.Context) ([]models.Deduction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.deductions), nil
+}
+
+// GetDeduction implements services.AdminStorer.
+func (s *Store) GetDeduction(ctx context.Context, slug string) (models.Deduction, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Deduction{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := s.deductionIndex(slug)
+	if i < 0 {
+		return models.Deduction{}, sql.ErrNoRows
+	}
+	return s.deductions[i], nil
+}
+
+// CreateDeductionChange implements services.AdminStorer.
+func (s *Store) CreateDeductionChange(ctx context.Context, change models.DeductionChange) (models.DeductionChange, error) {
+	if err := ctx.Err(); err != nil {
+		return change, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendDeductionChange(change), nil
+}
+
+// CreateDeductionChanges implements services.AdminStorer.
+func (s *Store) CreateDeductionChanges(ctx context.Context, changes []models.DeductionChange) ([]models.DeductionChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendDeductionChanges(changes), nil
+}
+
+// appendDeductionChange must be called with s.mu locked
+func (s *Store) appendDeductionChange(change models.DeductionChange) models.DeductionChange {
+	change.Id = uint(len(s.deductionChanges) + 1)
+	change.ReviewedBy, change.Reason, change.ReviewedAt = "", "", ""
+	change.CreatedAt = s.timestamp()
+	s.deductionChanges = append(s.deductionChanges, change)
+	return change
+}
+
+// appendDeductionChanges must be called with s.mu locked
+func (s *Store) appendDeductionChanges(changes []models.DeductionChange) []models.DeductionChange {
+	created := make([]models.DeductionChange, 0, len(changes))
+	for _, change := range changes {
+		created = append(created, s.appendDeductionChange(change))
+	}
+	return created
+}
+
+// GetDeductionChanges implements services.AdminStorer.
+func (s *Store) GetDeductionChanges(ctx context.Context, status string) ([]models.DeductionChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var changes []models.DeductionChange
+	for _, v := range s.deductionChanges {
+		if v.Status == status {
+			changes = append(changes, v)
+		}
+	}
+	return changes, nil
+}
+
+// GetDeductionChange implements services.AdminStorer.
+func (s *Store) GetDeductionChange(ctx context.Context, id uint) (models.DeductionChange, error) {
+	if err := ctx.Err(); err != nil {
+		return models.DeductionChange{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if id == 0 || id > uint(len(s.deductionChanges)) {
+		return models.DeductionChange{}, sql.ErrNoRows
+	}
+	return s.deductionChanges[id-1], nil
+}
+
+// pendingChange return stored change that can be reviewed, it return sql.ErrNoRows like db package when change was already reviewed
+func (s *Store) pendingChange(id uint) (*models.DeductionChange, error) {
+	if id == 0 || id > uint(len(s.deductionChanges)) || s.deductionChanges[id-1].Status != models.PendingChange {
+		return nil, sql.ErrNoRows
+	}
+	return &s.deductionChanges[id-1], nil
+}
+
+func (s *Store) review(change *models.DeductionChange, status, reviewer, reason string) {
+	change.Status = status
+	change.ReviewedBy = reviewer
+	change.Reason = reason
+	change.ReviewedAt = s.timestamp()
+}
+
+// ApproveDeductionChange implements services.AdminStorer.
+// It return utils.ErrVersionMismatch when deduction was changed after the change was proposed.
+func (s *Store) ApproveDeductionChange(ctx context.Context, id uint, reviewer string) (models.DeductionChange, error) {
+	if err := ctx.Err(); err != nil {
+		return models.DeductionChange{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change, err := s.pendingChange(id)
+	if err != nil {
+		return models.DeductionChange{}, err
+	}
+	i := s.deductionIndex(change.Slug)
+	if i < 0 || s.deductions[i].Version != change.Version {
+		return *change, utils.ErrVersionMismatch
+	}
+	s.review(change, models.ApprovedChange, reviewer, "")
+	s.deductions[i].Amount = change.Amount
+	s.deductions[i].Version++
+	return *change, nil
+}
+
+// RejectDeductionChange implements services.AdminStorer.
+func (s *Store) RejectDeductionChange(ctx context.Context, id uint, reviewer, reason string) (models.DeductionChange, error) {
+	if err := ctx.Err(); err != nil {
+		return models.DeductionChange{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change, err := s.pendingChange(id)
+	if err != nil {
+		return models.DeductionChange{}, err
+	}
+	s.review(change, models.RejectedChange, reviewer, reason)
+	return *change, nil
+}
@@ -0,0 +1,13 @@
+package memory
+
+import "context"
+
+// Ping implements services.HealthStorer, store is always reachable
+func (s *Store) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// PendingMigrations implements services.HealthStorer, store is created with seeded rows so nothing is pending
+func (s *Store) PendingMigrations(ctx context.Context) ([]int, error) {
+	return nil, ctx.Err()
+}
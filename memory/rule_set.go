@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+// CreateRuleSet implements services.RuleSetStorer.
+func (s *Store) CreateRuleSet(ctx context.Context, ruleSet models.RuleSet) (models.RuleSet, error) {
+	if err := ctx.Err(); err != nil {
+		return ruleSet, err
+	}
+	v, err := clone(ruleSet)
+	if err != nil {
+		return ruleSet, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v.Id = uint(len(s.ruleSets) + 1)
+	v.Active = false
+	v.CreatedAt = s.timestamp()
+	s.ruleSets = append(s.ruleSets, v)
+	return clone(v)
+}
+
+// GetRuleSets implements services.RuleSetStorer.
+func (s *Store) GetRuleSets(ctx context.Context) ([]models.RuleSet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ruleSets, err := clone(s.ruleSets)
+	slices.Reverse(ruleSets)
+	return ruleSets, err
+}
+
+// GetRuleSet implements services.RuleSetStorer.
+func (s *Store) GetRuleSet(ctx context.Context, id uint) (models.RuleSet, error) {
+	if err := ctx.Err(); err != nil {
+		return models.RuleSet{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if id == 0 || id > uint(len(s.ruleSets)) {
+		return models.RuleSet{}, sql.ErrNoRows
+	}
+	return clone(s.ruleSets[id-1])
+}
+
+// GetActiveRuleSet implements services.RuleSetStorer.
+func (s *Store) GetActiveRuleSet(ctx context.Context) (models.RuleSet, error) {
+	if err := ctx.Err(); err != nil {
+		return models.RuleSet{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := slices.IndexFunc(s.ruleSets, func(r models.RuleSet) bool { return r.Active })
+	if i < 0 {
+		return models.RuleSet{}, sql.ErrNoRows
+	}
+	return clone(s.ruleSets[i])
+}
+
+// ActivateRuleSet implements services.RuleSetStorer.
+// It mark rule set as the only active one and append pending changes under the same lock,
+// deduction amounts are changed by approval of deduction changes.
+func (s *Store) ActivateRuleSet(ctx context.Context, ruleSet models.RuleSet, changes []models.DeductionChange) ([]models.DeductionChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.ruleSets {
+		s.ruleSets[i].Active = s.ruleSets[i].Id == ruleSet.Id
+	}
+	return s.appendDeductionChanges(changes), nil
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+// Store keep every table in memory, it is used to run api without postgres (STORAGE=memory)
+// and is empty again on restart except rows that are seeded by migrations.
+type Store struct {
+	mu  sync.RWMutex
+	now func() time.Time
+
+	deductions         []models.Deduction
+	penalties          []models.Penalty
+	installmentConfigs []models.InstallmentConfig
+	exchangeRates      []models.ExchangeRate
+	taxpayers          map[string]models.Taxpayer
+	taxReturns         []models.TaxReturn
+	ruleSets           []models.RuleSet
+	deductionChanges   []models.DeductionChange
+}
+
+// New return store that is seeded with the same rows as migrations
+func New() *Store {
+	return &Store{
+		now:                time.Now,
+		deductions:         seedDeductions(),
+		penalties:          seedPenalties(),
+		installmentConfigs: seedInstallmentConfigs(),
+		taxpayers:          make(map[string]models.Taxpayer),
+	}
+}
+
+func seedDeductions() []models.Deduction {
+	return []models.Deduction{
+		{Id: 1, Slug: "k-receipt", Name: "kReceipt", Amount: 50_000, MinAmount: 0, MaxAmount: 100_000, Version: 1},
+		{Id: 2, Slug: "personal", Name: "personalDeduction", Amount: 60_000, MinAmount: 10_000, MaxAmount: 100_000, Version: 1},
+		{Id: 3, Slug: "donation", Name: "Donation", Amount: 100_000, MinAmount: 0, MaxAmount: 100_000, Version: 1},
+		{Id: 4, Slug: "spouse", Name: "Spouse", Amount: 60_000, MinAmount: 0, MaxAmount: 60_000, Version: 1},
+		{Id: 5, Slug: "child", Name: "Child", Amount: 30_000, MinAmount: 0, MaxAmount: 60_000, Version: 1},
+		{Id: 6, Slug: "parent", Name: "Parent", Amount: 30_000, MinAmount: 0, MaxAmount: 30_000, Version: 1},
+	}
+}
+
+func seedPenalties() []models.Penalty {
+	return []models.Penalty{
+		{Id: 1, Slug: "surcharge", Name: "Surcharge", Amount: 1.5},
+		{Id: 2, Slug: "late-filing", Name: "Late Filing Penalty", Amount: 200},
+		{Id: 3, Slug: "refund-interest", Name: "Refund Interest", Amount: 1},
+		{Id: 4, Slug: "refund-grace-months", Name: "Refund Grace Months", Amount: 3},
+	}
+}
+
+func seedInstallmentConfigs() []models.InstallmentConfig {
+	return []models.InstallmentConfig{
+		{Id: 1, Slug: "installment-threshold", Name: "Installment Threshold", Amount: 3000},
+		{Id: 2, Slug: "installment-count", Name: "Installment Count", Amount: 3},
+	}
+}
+
+// timestamp format current time as postgres timestamp is scanned by db package
+func (s *Store) timestamp() string {
+	return s.now().Format(time.RFC3339)
+}
+
+// clone deep copy value through json like jsonb column in postgres, so caller can not change stored row
+func clone[T any](v T) (T, error) {
+	var result T
+	b, err := json.Marshal(v)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(b, &result)
+	return result, err
+}
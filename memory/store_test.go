@@ -0,0 +1,218 @@
+//go:build !integration
+// +build !integration
+
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baronight/assessment-tax/migrations"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+func setupStore() *Store {
+	s := New()
+	s.now = func() time.Time { return time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestSeed(t *testing.T) {
+	files, err := fs.Glob(migrations.FS, "*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var migration string
+	for _, file := range files {
+		sql, err := migrations.FS.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		migration += string(sql)
+	}
+	s := New()
+	for _, v := range s.deductions {
+		row := fmt.Sprintf("'%s', %v, %v, %v)", v.Name, v.Amount, v.MinAmount, v.MaxAmount)
+		if !strings.Contains(migration, "'"+v.Slug+"'") || !strings.Contains(migration, row) {
+			t.Errorf("expect deduction %#v is seeded by migration", v)
+		}
+	}
+	for _, v := range s.penalties {
+		if !strings.Contains(migration, fmt.Sprintf("('%s', '%s', %v)", v.Slug, v.Name, v.Amount)) {
+			t.Errorf("expect penalty %#v is seeded by migration", v)
+		}
+	}
+	for _, v := range s.installmentConfigs {
+		if !strings.Contains(migration, fmt.Sprintf("('%s', '%s', %v)", v.Slug, v.Name, v.Amount)) {
+			t.Errorf("expect installment config %#v is seeded by migration", v)
+		}
+	}
+}
+
+func TestDeductionChange(t *testing.T) {
+	ctx := context.Background()
+	propose := func(s *Store, amount float64, version int) models.DeductionChange {
+		change, _ := s.CreateDeductionChange(ctx, models.DeductionChange{Slug: "personal", Amount: amount, Version: version, Status: models.PendingChange, ProposedBy: "editor"})
+		return change
+	}
+	t.Run("given changes should create all of them as pending", func(t *testing.T) {
+		s := setupStore()
+
+		got, err := s.CreateDeductionChanges(ctx, []models.DeductionChange{
+			{Slug: "personal", Amount: 70_000, Version: 1, Status: models.PendingChange, ProposedBy: "editor"},
+			{Slug: "k-receipt", Amount: 60_000, Version: 1, Status: models.PendingChange, ProposedBy: "editor"},
+		})
+
+		if pending, _ := s.GetDeductionChanges(ctx, models.PendingChange); err != nil || len(got) != 2 || !reflect.DeepEqual(got, pending) {
+			t.Errorf("expect 2 pending changes but got %#v, %#v, %v", got, pending, err)
+		}
+	})
+	t.Run("given pending change should approve it and update deduction", func(t *testing.T) {
+		s := setupStore()
+		change := propose(s, 70_000, 1)
+
+		got, err := s.ApproveDeductionChange(ctx, change.Id, "approver")
+
+		if err != nil || got.Status != models.ApprovedChange || got.ReviewedAt != "2025-01-15T10:00:00Z" {
+			t.Errorf("expect approved change but got %#v, %v", got, err)
+		}
+		d, _ := s.GetDeduction(ctx, "personal")
+		if d.Amount != 70_000 || d.Version != 2 {
+			t.Errorf("expect deduction was updated to next version but got %#v", d)
+		}
+		if _, err := s.ApproveDeductionChange(ctx, change.Id, "approver"); err != sql.ErrNoRows {
+			t.Errorf("expect reviewed change return %q but got %q", sql.ErrNoRows, err)
+		}
+	})
+	t.Run("given deduction was changed after proposal should keep change pending with version mismatch error", func(t *testing.T) {
+		s := setupStore()
+		first, second := propose(s, 70_000, 1), propose(s, 80_000, 1)
+		s.ApproveDeductionChange(ctx, first.Id, "approver")
+
+		_, err := s.ApproveDeductionChange(ctx, second.Id, "approver")
+
+		if err != utils.ErrVersionMismatch {
+			t.Errorf("expect %q but got %q", utils.ErrVersionMismatch, err)
+		}
+		pending, _ := s.GetDeductionChanges(ctx, models.PendingChange)
+		if len(pending) != 1 || pending[0].Id != second.Id {
+			t.Errorf("expect second change is still pending but got %#v", pending)
+		}
+	})
+	t.Run("given pending change should reject it with reason", func(t *testing.T) {
+		s := setupStore()
+		change := propose(s, 70_000, 1)
+
+		got, err := s.RejectDeductionChange(ctx, change.Id, "approver", "not announced")
+
+		if err != nil || got.Status != models.RejectedChange || got.Reason != "not announced" {
+			t.Errorf("expect rejected change but got %#v, %v", got, err)
+		}
+	})
+}
+
+func TestGetExchangeRate(t *testing.T) {
+	ctx := context.Background()
+	s := setupStore()
+	s.SaveExchangeRates(ctx, []models.ExchangeRate{
+		{Currency: "USD", Rate: 36, RateDate: "2024-12-01"},
+		{Currency: "USD", Rate: 37, RateDate: "2024-12-30"},
+		{Currency: "USD", Rate: 38, RateDate: "2025-01-10"},
+	})
+	s.SaveExchangeRates(ctx, []models.ExchangeRate{{Currency: "USD", Rate: 36.5, RateDate: "2024-12-30"}})
+
+	got, err := s.GetExchangeRate(ctx, "USD", "2025-01-01")
+
+	if err != nil || got.Rate != 36.5 || got.RateDate != "2024-12-30" {
+		t.Errorf("expect latest replaced rate on or before date but got %#v, %v", got, err)
+	}
+	if _, err := s.GetExchangeRate(ctx, "USD", "2024-11-30"); err != sql.ErrNoRows {
+		t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+	}
+}
+
+func TestTaxReturns(t *testing.T) {
+	ctx := context.Background()
+	s := setupStore()
+	for _, year := range []int{2566, 2567, 2567} {
+		s.CreateTaxReturn(ctx, models.TaxReturn{TaxpayerId: "1101700203000", TaxYear: year,
+			Deductions: []models.Deduction{{Slug: "personal", Amount: 60_000}}})
+	}
+
+	got, err := s.GetTaxReturns(ctx, "1101700203000", 0)
+
+	if err != nil || len(got) != 3 || got[0].Id != 3 || got[1].Id != 2 || got[2].Id != 1 {
+		t.Errorf("expect returns order by tax year and id descending but got %#v, %v", got, err)
+	}
+	got[0].Deductions[0].Amount = 0
+	if stored, _ := s.GetTaxReturn(ctx, 3); stored.Deductions[0].Amount != 60_000 {
+		t.Errorf("expect stored return was not changed by caller but got %#v", stored.Deductions)
+	}
+	if got[0].Revision != 2 || got[2].Revision != 1 {
+		t.Errorf("expect revision 0 was saved after the latest one of tax year but got %d, %d", got[0].Revision, got[2].Revision)
+	}
+	if _, err := s.CreateTaxReturn(ctx, models.TaxReturn{TaxpayerId: "1101700203000", TaxYear: 2567, Revision: 2}); err != utils.ErrTaxReturnExists {
+		t.Errorf("expect %q but got %q", utils.ErrTaxReturnExists, err)
+	}
+}
+
+func TestTaxpayer(t *testing.T) {
+	ctx := context.Background()
+	s := setupStore()
+	taxpayer := models.Taxpayer{NationalId: "1101700203000", Name: "Somchai Jaidee", MaritalStatus: "single"}
+	s.CreateTaxpayer(ctx, taxpayer)
+
+	if _, err := s.CreateTaxpayer(ctx, taxpayer); err != utils.ErrTaxpayerExists {
+		t.Errorf("expect %q but got %q", utils.ErrTaxpayerExists, err)
+	}
+	if err := s.DeleteTaxpayer(ctx, taxpayer.NationalId); err != nil {
+		t.Errorf("expect no error found but got %q", err)
+	}
+	if _, err := s.GetTaxpayer(ctx, taxpayer.NationalId); err != sql.ErrNoRows {
+		t.Errorf("expect %q but got %q", sql.ErrNoRows, err)
+	}
+}
+
+func TestActivateRuleSet(t *testing.T) {
+	ctx := context.Background()
+	s := setupStore()
+	first, _ := s.CreateRuleSet(ctx, models.RuleSet{Name: "2567", TaxYear: 2567})
+	second, _ := s.CreateRuleSet(ctx, models.RuleSet{Name: "2568", TaxYear: 2568,
+		Deductions: []models.RuleDeduction{{Slug: "donation", Amount: 80_000}}})
+	s.ActivateRuleSet(ctx, first, nil)
+
+	changes, err := s.ActivateRuleSet(ctx, second, []models.DeductionChange{
+		{Slug: "donation", Amount: 80_000, Version: 1, Status: models.PendingChange, ProposedBy: "editor"},
+	})
+
+	if err != nil {
+		t.Errorf("expect no error found but got %q", err)
+	}
+	if pending, _ := s.GetDeductionChanges(ctx, models.PendingChange); len(changes) != 1 || !reflect.DeepEqual(changes, pending) {
+		t.Errorf("expect donation change is pending but got %#v, %#v", changes, pending)
+	}
+	if active, _ := s.GetActiveRuleSet(ctx); active.Id != second.Id {
+		t.Errorf("expect only second rule set is active but got %#v", active)
+	}
+	if d, _ := s.GetDeduction(ctx, "donation"); d.Amount == 80_000 || d.Version != 1 {
+		t.Errorf("expect donation deduction is not changed before approval but got %#v", d)
+	}
+}
+
+func TestCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := setupStore().GetDeductions(ctx)
+
+	if err != context.Canceled {
+		t.Errorf("expect %q but got %q", context.Canceled, err)
+	}
+}
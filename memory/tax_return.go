@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+// CreateTaxReturn implements services.TaxReturnStorer.
+// Revision 0 is counted after the latest one, and revision that already exists is rejected.
+func (s *Store) CreateTaxReturn(ctx context.Context, taxReturn models.TaxReturn) (models.TaxReturn, error) {
+	if err := ctx.Err(); err != nil {
+		return taxReturn, err
+	}
+	v, err := clone(taxReturn)
+	if err != nil {
+		return taxReturn, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	latest := 0
+	for _, saved := range s.taxReturns {
+		if saved.TaxpayerId != v.TaxpayerId || saved.TaxYear != v.TaxYear {
+			continue
+		}
+		if saved.Revision == v.Revision {
+			return taxReturn, utils.ErrTaxReturnExists
+		}
+		latest = max(latest, saved.Revision)
+	}
+	if v.Revision == 0 {
+		v.Revision = latest + 1
+	}
+	v.Id = uint(len(s.taxReturns) + 1)
+	v.CreatedAt = s.timestamp()
+	v.UpdatedAt = v.CreatedAt
+	s.taxReturns = append(s.taxReturns, v)
+	return clone(v)
+}
+
+// GetTaxReturns implements services.TaxReturnStorer.
+// It return all returns of taxpayer when tax year is 0.
+func (s *Store) GetTaxReturns(ctx context.Context, taxpayerId string, taxYear int) ([]models.TaxReturn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var taxReturns []models.TaxReturn
+	for _, v := range s.taxReturns {
+		if v.TaxpayerId == taxpayerId && (taxYear == 0 || v.TaxYear == taxYear) {
+			taxReturns = append(taxReturns, v)
+		}
+	}
+	slices.SortFunc(taxReturns, func(a, b models.TaxReturn) int {
+		if a.TaxYear != b.TaxYear {
+			return cmp.Compare(b.TaxYear, a.TaxYear)
+		}
+		return cmp.Compare(b.Id, a.Id)
+	})
+	return clone(taxReturns)
+}
+
+// GetTaxReturn implements services.TaxReturnStorer.
+func (s *Store) GetTaxReturn(ctx context.Context, id uint) (models.TaxReturn, error) {
+	if err := ctx.Err(); err != nil {
+		return models.TaxReturn{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if id == 0 || id > uint(len(s.taxReturns)) {
+		return models.TaxReturn{}, sql.ErrNoRows
+	}
+	return clone(s.taxReturns[id-1])
+}
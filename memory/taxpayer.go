@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+// CreateTaxpayer implements services.TaxpayerStorer.
+func (s *Store) CreateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error) {
+	if err := ctx.Err(); err != nil {
+		return taxpayer, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.taxpayers[taxpayer.NationalId]; ok {
+		return models.Taxpayer{}, utils.ErrTaxpayerExists
+	}
+	taxpayer.CreatedAt = s.timestamp()
+	taxpayer.UpdatedAt = taxpayer.CreatedAt
+	s.taxpayers[taxpayer.NationalId] = taxpayer
+	return taxpayer, nil
+}
+
+// GetTaxpayer implements services.TaxpayerStorer and services.TaxStorer.
+func (s *Store) GetTaxpayer(ctx context.Context, nationalId string) (models.Taxpayer, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Taxpayer{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	taxpayer, ok := s.taxpayers[nationalId]
+	if !ok {
+		return models.Taxpayer{}, sql.ErrNoRows
+	}
+	return taxpayer, nil
+}
+
+// UpdateTaxpayer implements services.TaxpayerStorer.
+func (s *Store) UpdateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Taxpayer{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.taxpayers[taxpayer.NationalId]
+	if !ok {
+		return models.Taxpayer{}, sql.ErrNoRows
+	}
+	taxpayer.CreatedAt = stored.CreatedAt
+	taxpayer.UpdatedAt = s.timestamp()
+	s.taxpayers[taxpayer.NationalId] = taxpayer
+	return taxpayer, nil
+}
+
+// DeleteTaxpayer implements services.TaxpayerStorer.
+// It return sql.ErrNoRows when there is no taxpayer to delete.
+func (s *Store) DeleteTaxpayer(ctx context.Context, nationalId string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.taxpayers[nationalId]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(s.taxpayers, nationalId)
+	return nil
+}
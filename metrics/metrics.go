@@ -0,0 +1,109 @@
+// Package metrics keep prometheus collectors of api, they are served in text format by Handler on /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ktax"
+
+const (
+	OutcomePayable = "payable"
+	OutcomeRefund  = "refund"
+	// OutcomeNone is tax that is neither payable nor refund
+	OutcomeNone = "none"
+)
+
+// Registry has every collector of api with go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	Calculations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "calculations_total",
+		Help:      "Number of calculations performed by kind: tax, spouse or payroll.",
+	}, []string{"kind"})
+	TaxOutcomes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tax_outcomes_total",
+		Help:      "Number of tax calculations by kind and outcome: payable, refund or none.",
+	}, []string{"kind", "outcome"})
+	CsvRows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "csv_rows_processed_total",
+		Help:      "Number of CSV rows processed by upload.",
+	}, []string{"upload"})
+	CsvRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "csv_rejections_total",
+		Help:      "Number of rejected CSV uploads by upload and reason.",
+	}, []string{"upload", "reason"})
+	DeductionUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deduction_updates_total",
+		Help:      "Number of deduction amounts that were changed by slug and source: approval.",
+	}, []string{"slug", "source"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of database queries by storer method.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"method"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPRequestDuration,
+		Calculations, TaxOutcomes, CsvRows, CsvRejections, DeductionUpdates,
+		DBQueryDuration,
+	)
+}
+
+// Handler serve every collector of Registry in prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB add connection pool stats of db to Registry, they are labelled with db_name of database driver
+func RegisterDB(db *sql.DB, driver string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, driver))
+}
+
+// ObserveTax count calculation of kind with its outcome
+func ObserveTax(kind string, tax, taxRefund float64) {
+	outcome := OutcomeNone
+	switch {
+	case tax > 0:
+		outcome = OutcomePayable
+	case taxRefund > 0:
+		outcome = OutcomeRefund
+	}
+	Calculations.WithLabelValues(kind).Inc()
+	TaxOutcomes.WithLabelValues(kind, outcome).Inc()
+}
+
+// ObserveQuery record latency of storer method since start
+func ObserveQuery(method string, start time.Time) {
+	DBQueryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,66 @@
+//go:build !integration
+// +build !integration
+
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveTax(t *testing.T) {
+	testCases := []struct {
+		name      string
+		tax       float64
+		taxRefund float64
+		outcome   string
+	}{
+		{name: "given tax should count payable", tax: 29_000, outcome: OutcomePayable},
+		{name: "given tax refund should count refund", taxRefund: 1_000, outcome: OutcomeRefund},
+		{name: "given neither tax nor refund should count none", outcome: OutcomeNone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calculations := testutil.ToFloat64(Calculations.WithLabelValues("test"))
+			outcomes := testutil.ToFloat64(TaxOutcomes.WithLabelValues("test", tc.outcome))
+
+			ObserveTax("test", tc.tax, tc.taxRefund)
+
+			if got := testutil.ToFloat64(Calculations.WithLabelValues("test")) - calculations; got != 1 {
+				t.Errorf("expect one calculation but got %v", got)
+			}
+			if got := testutil.ToFloat64(TaxOutcomes.WithLabelValues("test", tc.outcome)) - outcomes; got != 1 {
+				t.Errorf("expect one %s outcome but got %v", tc.outcome, got)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("should serve collectors in prometheus text format", func(t *testing.T) {
+		CsvRows.WithLabelValues("tax").Add(3)
+		res := httptest.NewRecorder()
+
+		Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		body, _ := io.ReadAll(res.Body)
+		if res.Code != http.StatusOK {
+			t.Errorf("expect status 200 but got %d", res.Code)
+		}
+		for _, want := range []string{
+			"# TYPE ktax_csv_rows_processed_total counter",
+			`ktax_csv_rows_processed_total{upload="tax"}`,
+			"go_goroutines",
+		} {
+			if !strings.Contains(string(body), want) {
+				t.Errorf("expect metrics contain %q but got %s", want, body)
+			}
+		}
+	})
+}
@@ -2,20 +2,46 @@ package middlewares
 
 import (
 	"crypto/subtle"
-	"os"
 
+	"github.com/baronight/assessment-tax/config"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-func BasicAuthMiddleware() echo.MiddlewareFunc {
-	return middleware.BasicAuth(func(user, pass string, ctx echo.Context) (bool, error) {
-		adminUser := os.Getenv("ADMIN_USERNAME")
-		adminPass := os.Getenv("ADMIN_PASSWORD")
-		if subtle.ConstantTimeCompare([]byte(user), []byte(adminUser)) == 1 &&
-			subtle.ConstantTimeCompare([]byte(pass), []byte(adminPass)) == 1 {
-			return true, nil
+// AdminUserKey is key of authenticated admin username in echo context
+const AdminUserKey = "adminUser"
+
+// BasicAuthMiddleware allow only admin in auth config
+func BasicAuthMiddleware(auth config.Auth) echo.MiddlewareFunc {
+	return middleware.BasicAuth(adminValidator(auth))
+}
+
+// OptionalBasicAuthMiddleware authenticate admin only when request has credentials, anonymous request is passed
+// to handler that decide by AdminUser which data it can serve
+func OptionalBasicAuthMiddleware(auth config.Auth) echo.MiddlewareFunc {
+	return middleware.BasicAuthWithConfig(middleware.BasicAuthConfig{
+		Skipper: func(c echo.Context) bool {
+			return c.Request().Header.Get(echo.HeaderAuthorization) == ""
+		},
+		Validator: adminValidator(auth),
+	})
+}
+
+func adminValidator(auth config.Auth) middleware.BasicAuthValidator {
+	return func(user, pass string, ctx echo.Context) (bool, error) {
+		for adminUser, adminPass := range auth.Admins {
+			if subtle.ConstantTimeCompare([]byte(user), []byte(adminUser)) == 1 &&
+				subtle.ConstantTimeCompare([]byte(pass), []byte(adminPass)) == 1 {
+				ctx.Set(AdminUserKey, adminUser)
+				return true, nil
+			}
 		}
 		return false, nil
-	})
+	}
+}
+
+// AdminUser return username of admin that authenticated by BasicAuthMiddleware
+func AdminUser(c echo.Context) string {
+	user, _ := c.Get(AdminUserKey).(string)
+	return user
 }
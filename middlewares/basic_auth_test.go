@@ -0,0 +1,50 @@
+//go:build !integration
+// +build !integration
+
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baronight/assessment-tax/config"
+	"github.com/labstack/echo/v4"
+)
+
+func TestOptionalBasicAuthMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(OptionalBasicAuthMiddleware(config.Auth{Admins: map[string]string{"adminTax": "admin!"}}))
+	e.GET("/tax/calculations", func(c echo.Context) error {
+		return c.String(http.StatusOK, AdminUser(c))
+	})
+	serve := func(setAuth func(req *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/tax/calculations", nil)
+		setAuth(req)
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("given no credentials should pass request as anonymous", func(t *testing.T) {
+		res := serve(func(req *http.Request) {})
+
+		if res.Code != http.StatusOK || res.Body.String() != "" {
+			t.Errorf("expect anonymous request to pass but got %d %q", res.Code, res.Body.String())
+		}
+	})
+	t.Run("given admin credentials should set admin user", func(t *testing.T) {
+		res := serve(func(req *http.Request) { req.SetBasicAuth("adminTax", "admin!") })
+
+		if res.Code != http.StatusOK || res.Body.String() != "adminTax" {
+			t.Errorf("expect request of adminTax but got %d %q", res.Code, res.Body.String())
+		}
+	})
+	t.Run("given wrong credentials should return 401", func(t *testing.T) {
+		res := serve(func(req *http.Request) { req.SetBasicAuth("adminTax", "wrong") })
+
+		if res.Code != http.StatusUnauthorized {
+			t.Errorf("expect status code 401 but got %d", res.Code)
+		}
+	})
+}
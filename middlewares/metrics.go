@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+// unmatchedRoute is route label of requests that match no route, so unknown paths do not create new series
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware count every request and record its latency by method, route and status
+func MetricsMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// write error response now like middleware.Logger, so its status is recorded
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = unmatchedRoute
+			}
+			labels := []string{c.Request().Method, route, strconv.Itoa(c.Response().Status)}
+			metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+			return nil
+		}
+	}
+}
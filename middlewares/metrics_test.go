@@ -0,0 +1,62 @@
+//go:build !integration
+// +build !integration
+
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(MetricsMiddleware())
+	e.GET("/tax/returns/:id", func(c echo.Context) error {
+		if c.Param("id") == "0" {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid id")
+		}
+		return c.String(http.StatusOK, "OK")
+	})
+	serve := func(path string) int {
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, httptest.NewRequest(http.MethodGet, path, nil))
+		return res.Code
+	}
+
+	t.Run("given requests of the same route should count them by route template and status", func(t *testing.T) {
+		ok := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/tax/returns/:id", "200")
+		before := testutil.ToFloat64(ok)
+
+		serve("/tax/returns/1")
+		serve("/tax/returns/2")
+
+		if got := testutil.ToFloat64(ok) - before; got != 2 {
+			t.Errorf("expect 2 requests but got %v", got)
+		}
+	})
+	t.Run("given handler return error should count status of error response", func(t *testing.T) {
+		bad := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/tax/returns/:id", "400")
+		before := testutil.ToFloat64(bad)
+
+		code := serve("/tax/returns/0")
+
+		if got := testutil.ToFloat64(bad) - before; got != 1 || code != http.StatusBadRequest {
+			t.Errorf("expect one 400 request but got %v requests with status %d", got, code)
+		}
+	})
+	t.Run("given unknown path should count it as unmatched route", func(t *testing.T) {
+		notFound := metrics.HTTPRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")
+		before := testutil.ToFloat64(notFound)
+
+		serve("/unknown/path")
+
+		if got := testutil.ToFloat64(notFound) - before; got != 1 {
+			t.Errorf("expect one unmatched request but got %v", got)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/baronight/assessment-tax/config"
+	"github.com/baronight/assessment-tax/db"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate run `migrate up`, `migrate down [steps]` or `migrate status` against database with migrations of its driver
+func runMigrate(cfg config.Database, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	p, err := db.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer p.Db.Close()
+	ms, err := p.Migrations()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := p.MigrateUp(ms)
+		for _, v := range done {
+			fmt.Fprintf(out, "applied %d\n", v)
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return errors.New(migrateUsage)
+			}
+		}
+		done, err := p.MigrateDown(ms, steps)
+		for _, v := range done {
+			fmt.Fprintf(out, "rolled back %d\n", v)
+		}
+		return err
+	case "status":
+		statuses, err := p.MigrationStatuses(ms)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			appliedAt := s.AppliedAt
+			if appliedAt == "" {
+				appliedAt = "pending"
+			}
+			fmt.Fprintf(out, "%06d %-30s %s\n", s.Version, s.Name, appliedAt)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
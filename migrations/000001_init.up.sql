@@ -1,3 +1,4 @@
+-- schema of init.sql that created database before versioned migrations, ON CONFLICT let it run on that database
 CREATE TABLE IF NOT EXISTS deductions (
   id SERIAL NOT NULL,
   slug VARCHAR NOT NULL,
@@ -22,4 +23,5 @@ INSERT INTO
 VALUES
   ('k-receipt', 'kReceipt', 50000, 0, 100000),
   ('personal','personalDeduction', 60000, 10000, 100000),
-  ('donation', 'Donation', 100000, 0, 100000);
+  ('donation', 'Donation', 100000, 0, 100000)
+ON CONFLICT (slug) DO NOTHING;
@@ -0,0 +1,18 @@
+CREATE TABLE penalties (
+  id SERIAL NOT NULL,
+  slug VARCHAR NOT NULL,
+	"name" VARCHAR NOT NULL,
+  amount DECIMAL(10,2) NOT NULL,
+	CONSTRAINT penalties_pk PRIMARY KEY (id),
+	CONSTRAINT penalties_slug_unique UNIQUE (slug)
+);
+
+COMMENT ON COLUMN "penalties".amount IS 'percent per month for surcharge and refund interest, baht for fixed penalty, month for refund grace period';
+
+INSERT INTO 
+  penalties (slug, "name", amount)
+VALUES
+  ('surcharge', 'Surcharge', 1.5),
+  ('late-filing', 'Late Filing Penalty', 200),
+  ('refund-interest', 'Refund Interest', 1),
+  ('refund-grace-months', 'Refund Grace Months', 3);
@@ -0,0 +1,16 @@
+CREATE TABLE installment_configs (
+  id SERIAL NOT NULL,
+  slug VARCHAR NOT NULL,
+	"name" VARCHAR NOT NULL,
+  amount DECIMAL(10,2) NOT NULL,
+	CONSTRAINT installment_configs_pk PRIMARY KEY (id),
+	CONSTRAINT installment_configs_slug_unique UNIQUE (slug)
+);
+
+COMMENT ON COLUMN "installment_configs".amount IS 'lowest tax payable that allow installment for threshold, number of installments for count';
+
+INSERT INTO 
+  installment_configs (slug, "name", amount)
+VALUES
+  ('installment-threshold', 'Installment Threshold', 3000),
+  ('installment-count', 'Installment Count', 3);
@@ -0,0 +1 @@
+DELETE FROM deductions WHERE slug IN ('spouse', 'child', 'parent');
@@ -0,0 +1,10 @@
+CREATE TABLE exchange_rates (
+  id SERIAL NOT NULL,
+  currency VARCHAR(3) NOT NULL,
+  rate DECIMAL(12,6) NOT NULL,
+  "rateDate" DATE NOT NULL,
+	CONSTRAINT exchange_rates_pk PRIMARY KEY (id),
+	CONSTRAINT exchange_rates_currency_date_unique UNIQUE (currency, "rateDate")
+);
+
+COMMENT ON COLUMN "exchange_rates".rate IS 'THB per 1 unit of currency';
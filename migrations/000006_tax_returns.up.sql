@@ -0,0 +1,16 @@
+CREATE TABLE tax_returns (
+  id SERIAL NOT NULL,
+  "taxpayerId" VARCHAR NOT NULL,
+  "taxYear" INTEGER NOT NULL,
+  revision INTEGER NOT NULL DEFAULT 1,
+  request JSONB NOT NULL,
+  response JSONB NOT NULL,
+  deductions JSONB NOT NULL,
+  "createdAt" TIMESTAMPTZ NOT NULL DEFAULT now(),
+  "updatedAt" TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT tax_returns_pk PRIMARY KEY (id)
+);
+
+CREATE INDEX tax_returns_taxpayer_year_idx ON tax_returns ("taxpayerId", "taxYear");
+
+COMMENT ON COLUMN "tax_returns".deductions IS 'snapshot of deduction config that used to calculate response';
@@ -0,0 +1,14 @@
+CREATE TABLE taxpayers (
+  "nationalId" VARCHAR(13) NOT NULL,
+  "name" VARCHAR NOT NULL,
+  "maritalStatus" VARCHAR NOT NULL,
+  "spouseHasIncome" BOOLEAN NOT NULL DEFAULT false,
+  children INTEGER NOT NULL DEFAULT 0,
+  parents INTEGER NOT NULL DEFAULT 0,
+  "createdAt" TIMESTAMPTZ NOT NULL DEFAULT now(),
+  "updatedAt" TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT taxpayers_pk PRIMARY KEY ("nationalId")
+);
+
+COMMENT ON COLUMN "taxpayers".children IS 'number of children that taxpayer can claim child allowance';
+COMMENT ON COLUMN "taxpayers".parents IS 'number of parents that taxpayer can claim parent allowance';
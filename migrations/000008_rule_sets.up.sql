@@ -0,0 +1,13 @@
+CREATE TABLE rule_sets (
+  id SERIAL NOT NULL,
+  "name" VARCHAR NOT NULL,
+  "taxYear" INTEGER NOT NULL,
+  content JSONB NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT false,
+  "createdAt" TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT rule_sets_pk PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX rule_sets_active_idx ON rule_sets (active) WHERE active;
+
+COMMENT ON COLUMN "rule_sets".content IS 'brackets and deductions of rule set, default rule set is embedded in application and used when no rule set is active';
@@ -0,0 +1,18 @@
+CREATE TABLE deduction_changes (
+  id SERIAL NOT NULL,
+  slug VARCHAR NOT NULL,
+  amount DECIMAL(10,2) NOT NULL,
+  version INTEGER NOT NULL,
+  status VARCHAR NOT NULL DEFAULT 'pending',
+  "proposedBy" VARCHAR NOT NULL,
+  "reviewedBy" VARCHAR,
+  reason VARCHAR,
+  "createdAt" TIMESTAMPTZ NOT NULL DEFAULT now(),
+  "reviewedAt" TIMESTAMPTZ,
+	CONSTRAINT deduction_changes_pk PRIMARY KEY (id),
+	CONSTRAINT deduction_changes_reviewer_check CHECK ("reviewedBy" IS NULL OR "reviewedBy" <> "proposedBy")
+);
+
+CREATE INDEX deduction_changes_status_idx ON deduction_changes (status);
+
+COMMENT ON TABLE "deduction_changes" IS 'deduction amount proposed by admin, it is written to deductions only when other admin approve';
@@ -0,0 +1 @@
+ALTER TABLE deductions DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+ALTER TABLE deductions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN "deductions".version IS 'increase on every amount update, it is sent as ETag and checked with If-Match';
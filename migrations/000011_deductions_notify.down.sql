@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS deductions_changed ON deductions;
+DROP FUNCTION IF EXISTS notify_deductions_changed();
@@ -0,0 +1,14 @@
+CREATE OR REPLACE FUNCTION notify_deductions_changed() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('deductions_changed', TG_OP);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS deductions_changed ON deductions;
+
+CREATE TRIGGER deductions_changed
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON deductions
+FOR EACH STATEMENT EXECUTE FUNCTION notify_deductions_changed();
+
+COMMENT ON FUNCTION notify_deductions_changed() IS 'tell every instance to drop cached deductions';
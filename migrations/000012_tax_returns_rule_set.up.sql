@@ -0,0 +1,3 @@
+ALTER TABLE tax_returns ADD COLUMN IF NOT EXISTS "ruleSet" JSONB NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN "tax_returns"."ruleSet" IS 'snapshot of rule set that used to calculate response';
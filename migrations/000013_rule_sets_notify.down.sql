@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS rule_sets_changed ON rule_sets;
+DROP FUNCTION IF EXISTS notify_rule_sets_changed();
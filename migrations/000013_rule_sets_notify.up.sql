@@ -0,0 +1,14 @@
+CREATE OR REPLACE FUNCTION notify_rule_sets_changed() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('rule_sets_changed', TG_OP);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rule_sets_changed ON rule_sets;
+
+CREATE TRIGGER rule_sets_changed
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON rule_sets
+FOR EACH STATEMENT EXECUTE FUNCTION notify_rule_sets_changed();
+
+COMMENT ON FUNCTION notify_rule_sets_changed() IS 'tell every instance to reload active rule set';
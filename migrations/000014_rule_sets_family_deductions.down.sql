@@ -0,0 +1,4 @@
+UPDATE rule_sets SET content = jsonb_set(content, '{deductions}', COALESCE(
+  (SELECT jsonb_agg(d ORDER BY i) FROM jsonb_array_elements(content->'deductions') WITH ORDINALITY AS e(d, i) WHERE d->>'type' <> 'family'),
+  '[]'::jsonb))
+WHERE jsonb_typeof(content->'deductions') = 'array';
@@ -0,0 +1,13 @@
+-- spouse, child and parent allowances were calculated in application before they became family deductions of rule set,
+-- they are added with amounts that were used to saved rule sets that have none,
+-- rule set snapshots of tax returns are kept as they were saved
+UPDATE rule_sets SET content = jsonb_set(content, '{deductions}',
+  CASE WHEN jsonb_typeof(content->'deductions') = 'array' THEN content->'deductions' ELSE '[]'::jsonb END || '[
+    {"slug": "spouse", "type": "family", "member": "spouse", "amount": 60000, "halfOnHalfYear": true},
+    {"slug": "child", "type": "family", "member": "child", "amount": 30000, "halfOnHalfYear": true},
+    {"slug": "parent", "type": "family", "member": "parent", "amount": 30000, "halfOnHalfYear": true}
+  ]'::jsonb)
+WHERE NOT EXISTS (
+  SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(content->'deductions') = 'array' THEN content->'deductions' ELSE '[]'::jsonb END) d
+  WHERE d->>'type' = 'family' OR d->>'slug' IN ('spouse', 'child', 'parent')
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS tax_returns_taxpayer_year_revision_idx;
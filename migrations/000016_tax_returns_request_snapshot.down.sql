@@ -0,0 +1,2 @@
+ALTER TABLE tax_returns DROP COLUMN IF EXISTS taxpayer;
+ALTER TABLE tax_returns DROP COLUMN IF EXISTS "requestThb";
@@ -0,0 +1,6 @@
+-- returns that were saved before have no snapshot, so they are left null
+ALTER TABLE tax_returns ADD COLUMN IF NOT EXISTS "requestThb" JSONB;
+ALTER TABLE tax_returns ADD COLUMN IF NOT EXISTS taxpayer JSONB;
+
+COMMENT ON COLUMN "tax_returns"."requestThb" IS 'snapshot of request with amounts converted to THB that used to calculate response';
+COMMENT ON COLUMN "tax_returns".taxpayer IS 'snapshot of taxpayer profile that family allowances of response come from';
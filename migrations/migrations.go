@@ -0,0 +1,9 @@
+// Package migrations embed versioned schema migrations, they are applied by db.New on startup.
+package migrations
+
+import "embed"
+
+// FS has <version>_<name>.up.sql and <version>_<name>.down.sql of every migration
+//
+//go:embed *.sql
+var FS embed.FS
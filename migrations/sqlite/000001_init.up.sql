@@ -0,0 +1,17 @@
+-- same schema and rows as migrations of postgres with same name, see comment of columns there
+CREATE TABLE deductions (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  slug VARCHAR NOT NULL,
+  "name" VARCHAR NOT NULL,
+  amount DECIMAL(10,2) NOT NULL,
+  "minAmount" DECIMAL(10,2) NOT NULL DEFAULT 0,
+  "maxAmount" DECIMAL(10,2) NOT NULL DEFAULT 0,
+  CONSTRAINT deductions_slug_unique UNIQUE (slug)
+);
+
+INSERT INTO
+  deductions (slug, "name", amount, "minAmount", "maxAmount")
+VALUES
+  ('k-receipt', 'kReceipt', 50000, 0, 100000),
+  ('personal','personalDeduction', 60000, 10000, 100000),
+  ('donation', 'Donation', 100000, 0, 100000);
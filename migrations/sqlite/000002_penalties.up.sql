@@ -0,0 +1,15 @@
+CREATE TABLE penalties (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  slug VARCHAR NOT NULL,
+  "name" VARCHAR NOT NULL,
+  amount DECIMAL(10,2) NOT NULL,
+  CONSTRAINT penalties_slug_unique UNIQUE (slug)
+);
+
+INSERT INTO
+  penalties (slug, "name", amount)
+VALUES
+  ('surcharge', 'Surcharge', 1.5),
+  ('late-filing', 'Late Filing Penalty', 200),
+  ('refund-interest', 'Refund Interest', 1),
+  ('refund-grace-months', 'Refund Grace Months', 3);
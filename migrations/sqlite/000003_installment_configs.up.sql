@@ -0,0 +1,13 @@
+CREATE TABLE installment_configs (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  slug VARCHAR NOT NULL,
+  "name" VARCHAR NOT NULL,
+  amount DECIMAL(10,2) NOT NULL,
+  CONSTRAINT installment_configs_slug_unique UNIQUE (slug)
+);
+
+INSERT INTO
+  installment_configs (slug, "name", amount)
+VALUES
+  ('installment-threshold', 'Installment Threshold', 3000),
+  ('installment-count', 'Installment Count', 3);
@@ -0,0 +1,6 @@
+INSERT INTO
+  deductions (slug, "name", amount, "minAmount", "maxAmount")
+VALUES
+  ('spouse', 'Spouse', 60000, 0, 60000),
+  ('child', 'Child', 30000, 0, 60000),
+  ('parent', 'Parent', 30000, 0, 30000);
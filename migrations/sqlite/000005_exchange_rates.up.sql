@@ -0,0 +1,7 @@
+CREATE TABLE exchange_rates (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  currency VARCHAR(3) NOT NULL,
+  rate DECIMAL(12,6) NOT NULL,
+  "rateDate" DATE NOT NULL,
+  CONSTRAINT exchange_rates_currency_date_unique UNIQUE (currency, "rateDate")
+);
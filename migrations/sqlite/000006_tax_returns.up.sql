@@ -0,0 +1,14 @@
+-- request, response and deductions are json of tax return
+CREATE TABLE tax_returns (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  "taxpayerId" VARCHAR NOT NULL,
+  "taxYear" INTEGER NOT NULL,
+  revision INTEGER NOT NULL DEFAULT 1,
+  request TEXT NOT NULL,
+  response TEXT NOT NULL,
+  deductions TEXT NOT NULL,
+  "createdAt" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updatedAt" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX tax_returns_taxpayer_year_idx ON tax_returns ("taxpayerId", "taxYear");
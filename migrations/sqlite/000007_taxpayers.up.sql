@@ -0,0 +1,10 @@
+CREATE TABLE taxpayers (
+  "nationalId" VARCHAR(13) NOT NULL PRIMARY KEY,
+  "name" VARCHAR NOT NULL,
+  "maritalStatus" VARCHAR NOT NULL,
+  "spouseHasIncome" BOOLEAN NOT NULL DEFAULT false,
+  children INTEGER NOT NULL DEFAULT 0,
+  parents INTEGER NOT NULL DEFAULT 0,
+  "createdAt" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updatedAt" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+CREATE TABLE rule_sets (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  "name" VARCHAR NOT NULL,
+  "taxYear" INTEGER NOT NULL,
+  content TEXT NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT false,
+  "createdAt" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX rule_sets_active_idx ON rule_sets (active) WHERE active;
@@ -0,0 +1,15 @@
+CREATE TABLE deduction_changes (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  slug VARCHAR NOT NULL,
+  amount DECIMAL(10,2) NOT NULL,
+  version INTEGER NOT NULL,
+  status VARCHAR NOT NULL DEFAULT 'pending',
+  "proposedBy" VARCHAR NOT NULL,
+  "reviewedBy" VARCHAR,
+  reason VARCHAR,
+  "createdAt" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "reviewedAt" TIMESTAMP,
+  CONSTRAINT deduction_changes_reviewer_check CHECK ("reviewedBy" IS NULL OR "reviewedBy" <> "proposedBy")
+);
+
+CREATE INDEX deduction_changes_status_idx ON deduction_changes (status);
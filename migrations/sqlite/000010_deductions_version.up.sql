@@ -0,0 +1 @@
+ALTER TABLE deductions ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
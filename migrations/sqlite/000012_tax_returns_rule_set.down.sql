@@ -0,0 +1 @@
+ALTER TABLE tax_returns DROP COLUMN "ruleSet";
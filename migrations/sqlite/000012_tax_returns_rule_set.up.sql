@@ -0,0 +1 @@
+ALTER TABLE tax_returns ADD COLUMN "ruleSet" TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- sqlite has no LISTEN/NOTIFY, this version keep migrations in same sequence as postgres
+SELECT 1;
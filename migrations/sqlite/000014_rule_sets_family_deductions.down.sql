@@ -0,0 +1,4 @@
+UPDATE rule_sets SET content = json_set(content, '$.deductions', (
+  SELECT json_group_array(json(value)) FROM json_each(content, '$.deductions') WHERE json_extract(value, '$.type') <> 'family'
+))
+WHERE json_type(content, '$.deductions') = 'array';
@@ -0,0 +1,18 @@
+-- spouse, child and parent allowances were calculated in application before they became family deductions of rule set,
+-- they are added with amounts that were used to saved rule sets that have none,
+-- rule set snapshots of tax returns are kept as they were saved
+UPDATE rule_sets SET content = json_set(content, '$.deductions', (
+  SELECT json_group_array(json(value)) FROM (
+    SELECT value FROM json_each(content, '$.deductions') WHERE json_type(content, '$.deductions') = 'array'
+    UNION ALL
+    SELECT value FROM json_each('[
+      {"slug": "spouse", "type": "family", "member": "spouse", "amount": 60000, "halfOnHalfYear": true},
+      {"slug": "child", "type": "family", "member": "child", "amount": 30000, "halfOnHalfYear": true},
+      {"slug": "parent", "type": "family", "member": "parent", "amount": 30000, "halfOnHalfYear": true}
+    ]')
+  )
+))
+WHERE NOT EXISTS (
+  SELECT 1 FROM json_each(content, '$.deductions')
+  WHERE json_extract(value, '$.type') = 'family' OR json_extract(value, '$.slug') IN ('spouse', 'child', 'parent')
+);
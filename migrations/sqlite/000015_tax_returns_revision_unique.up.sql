@@ -0,0 +1,10 @@
+-- returns that were saved twice or amended at the same time could have the same revision,
+-- they are numbered again in order of revision and id before revision becomes unique,
+-- so returns of taxpayer and tax year that have no duplicate keep their revision
+UPDATE tax_returns SET revision = (
+  SELECT COUNT(*) FROM tax_returns o
+  WHERE o."taxpayerId" = tax_returns."taxpayerId" AND o."taxYear" = tax_returns."taxYear"
+    AND (o.revision < tax_returns.revision OR (o.revision = tax_returns.revision AND o.id <= tax_returns.id))
+);
+
+CREATE UNIQUE INDEX tax_returns_taxpayer_year_revision_idx ON tax_returns ("taxpayerId", "taxYear", revision);
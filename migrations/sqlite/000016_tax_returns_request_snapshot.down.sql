@@ -0,0 +1,2 @@
+ALTER TABLE tax_returns DROP COLUMN taxpayer;
+ALTER TABLE tax_returns DROP COLUMN "requestThb";
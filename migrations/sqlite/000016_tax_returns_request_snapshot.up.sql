@@ -0,0 +1,3 @@
+-- returns that were saved before have no snapshot, so they are left null
+ALTER TABLE tax_returns ADD COLUMN "requestThb" TEXT;
+ALTER TABLE tax_returns ADD COLUMN taxpayer TEXT;
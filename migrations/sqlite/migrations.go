@@ -0,0 +1,10 @@
+// Package sqlite embed versioned schema migrations of sqlite database (DATABASE_DRIVER=sqlite),
+// they create same tables and rows as postgres migrations of same version in sqlite syntax.
+package sqlite
+
+import "embed"
+
+// FS has <version>_<name>.up.sql and <version>_<name>.down.sql of every migration
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,8 @@
+package models
+
+type CacheStats struct {
+	Hits   uint64 `json:"hits" example:"120"`
+	Misses uint64 `json:"misses" example:"3"`
+	// Listening is false when listener connection is down and cache is expired by TTL
+	Listening bool `json:"listening" example:"true"`
+} //@Name CacheStats
@@ -0,0 +1,33 @@
+package models
+
+// Certificate is withholding tax certificate (50 Tawi) that issued by payer
+type Certificate struct {
+	PayerTaxId  string  `json:"payerTaxId" example:"0105556123453"`
+	IncomeType  string  `json:"incomeType" example:"salary"`
+	AmountPaid  float64 `json:"amountPaid" example:"500000"`
+	TaxWithheld float64 `json:"taxWithheld" example:"25000"`
+}
+
+type CertificateTaxRequest struct {
+	TaxpayerId   string        `json:"taxpayerId,omitempty" example:"1234567890121"`
+	Allowances   []Allowance   `json:"allowances,omitempty"`
+	Certificates []Certificate `json:"certificates"`
+} //@Name CertificateTaxRequest
+
+type IncomeByType struct {
+	IncomeType  string  `json:"incomeType"`
+	AmountPaid  float64 `json:"amountPaid"`
+	TaxWithheld float64 `json:"taxWithheld"`
+}
+
+type CertificateResult struct {
+	No int `json:"no"`
+	Certificate
+}
+
+type CertificateTaxResponse struct {
+	Certificates []CertificateResult `json:"certificates"`
+	IncomeByType []IncomeByType      `json:"incomeByType"`
+	Input        TaxRequest          `json:"input"`
+	Result       TaxResponse         `json:"result"`
+} //@Name CertificateTaxResponse
@@ -0,0 +1,29 @@
+package models
+
+// DeductionConfig is deduction row with its limit in exported config
+type DeductionConfig struct {
+	Slug      string  `json:"slug" example:"personal"`
+	Name      string  `json:"name,omitempty" example:"personalDeduction"`
+	Amount    float64 `json:"amount" example:"60000"`
+	MinAmount float64 `json:"minAmount" example:"10000"`
+	MaxAmount float64 `json:"maxAmount" example:"100000"`
+} //@Name DeductionConfig
+
+// ConfigExport is configuration that can be imported to other environment
+type ConfigExport struct {
+	Deductions []DeductionConfig `json:"deductions"`
+} //@Name ConfigExport
+
+// DeductionConfigDiff is deduction that import will change, from is current config and to is imported config
+type DeductionConfigDiff struct {
+	Slug string          `json:"slug" example:"personal"`
+	From DeductionConfig `json:"from"`
+	To   DeductionConfig `json:"to"`
+} //@Name DeductionConfigDiff
+
+type ConfigImportResponse struct {
+	DryRun  bool                  `json:"dryRun" example:"true"`
+	Changes []DeductionConfigDiff `json:"changes"`
+	// PendingChanges are proposed changes of amount that wait for other admin to approve, it is empty on dry run
+	PendingChanges []DeductionChange `json:"pendingChanges"`
+} //@Name ConfigImportResponse
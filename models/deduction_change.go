@@ -0,0 +1,26 @@
+package models
+
+const (
+	PendingChange  = "pending"
+	ApprovedChange = "approved"
+	RejectedChange = "rejected"
+)
+
+// DeductionChange is amount of deduction that proposed by an admin and wait for another admin to review
+type DeductionChange struct {
+	Id     uint    `json:"id" example:"1"`
+	Slug   string  `json:"slug" example:"personal"`
+	Amount float64 `json:"amount" example:"70000"`
+	// Version of deduction that change is proposed on, change can be approved only when deduction is still in this version
+	Version    int    `json:"version" example:"1"`
+	Status     string `json:"status" example:"pending"`
+	ProposedBy string `json:"proposedBy" example:"editor"`
+	ReviewedBy string `json:"reviewedBy,omitempty" example:"approver"`
+	Reason     string `json:"reason,omitempty" example:"amount is not announced yet"`
+	CreatedAt  string `json:"createdAt" example:"2025-01-15T10:00:00Z"`
+	ReviewedAt string `json:"reviewedAt,omitempty" example:"2025-01-16T10:00:00Z"`
+} //@Name DeductionChange
+
+type DeductionChangeReview struct {
+	Reason string `json:"reason" example:"amount is not announced yet"`
+} //@Name DeductionChangeReview
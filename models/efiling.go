@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/xml"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	PND90Form = "PND90"
+	PND91Form = "PND91"
+)
+
+const (
+	EFilingFixedWidth = "txt"
+	EFilingXml        = "xml"
+)
+
+// EFilingEncoding is encoding of fixed-width e-filing file, Thai single-byte TIS-620 as Windows code page 874
+var EFilingEncoding = charmap.Windows874
+
+// EFilingCharset is charset of fixed-width e-filing file in Content-Type
+const EFilingCharset = "windows-874"
+
+type EFilingRequest struct {
+	Returns []TaxReturnRequest `json:"returns"`
+} //@Name EFilingRequest
+
+// EFilingRecord is one return in Revenue Department e-filing import, amounts are in THB.
+// Fields are in order of the form: income, allowances, net income, tax on it, tax paid in advance
+// and tax to pay or refund, so Income - allowances = NetIncome and TaxOnNetIncome - Wht - PrepaidTax = Tax - TaxRefund.
+type EFilingRecord struct {
+	FormType          string  `xml:"FormType"`
+	TaxYear           int     `xml:"TaxYear"`
+	NationalId        string  `xml:"NationalId"`
+	Name              string  `xml:"Name"`
+	Income            float64 `xml:"Income"`
+	PersonalAllowance float64 `xml:"PersonalAllowance"`
+	SpouseAllowance   float64 `xml:"SpouseAllowance"`
+	FamilyAllowance   float64 `xml:"FamilyAllowance"`
+	Donation          float64 `xml:"Donation"`
+	KReceipt          float64 `xml:"KReceipt"`
+	// OtherAllowance is total of rule set deductions that have no line of their own, e.g. provident fund
+	OtherAllowance float64 `xml:"OtherAllowance"`
+	NetIncome      float64 `xml:"NetIncome"`
+	TaxOnNetIncome float64 `xml:"TaxOnNetIncome"`
+	Wht            float64 `xml:"Wht"`
+	// PrepaidTax is tax paid by half-year return (PND 94)
+	PrepaidTax float64 `xml:"PrepaidTax"`
+	Tax        float64 `xml:"Tax"`
+	TaxRefund  float64 `xml:"TaxRefund"`
+}
+
+type EFilingFile struct {
+	XMLName xml.Name        `xml:"EFiling"`
+	Records []EFilingRecord `xml:"Return"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// ThbCurrency is local currency that every amount is converted to before calculate tax
+const ThbCurrency = "THB"
+
+type ExchangeRate struct {
+	Id       uint    `postgres:"id" json:"-"`
+	Currency string  `postgres:"currency" json:"currency" example:"USD"`
+	Rate     float64 `postgres:"rate" json:"rate" example:"36.5"`
+	RateDate string  `postgres:"rateDate" json:"rateDate" example:"2024-12-30"`
+} //@Name ExchangeRate
+
+type ExchangeRateRequest struct {
+	Rates []ExchangeRate `json:"rates"`
+} //@Name ExchangeRateRequest
+
+type ExchangeRateResponse struct {
+	Saved int `json:"saved"`
+} //@Name ExchangeRateResponse
+
+type ConvertedAmount struct {
+	Field     string  `json:"field" example:"totalIncome"`
+	Currency  string  `json:"currency" example:"USD"`
+	Amount    float64 `json:"amount"`
+	Rate      float64 `json:"rate"`
+	RateDate  string  `json:"rateDate"`
+	AmountThb float64 `json:"amountThb"`
+} //@Name ConvertedAmount
@@ -0,0 +1,26 @@
+package models
+
+const (
+	StatusAlive    = "alive"
+	StatusReady    = "ready"
+	StatusNotReady = "not ready"
+
+	ComponentUp   = "up"
+	ComponentDown = "down"
+)
+
+type Liveness struct {
+	Status string `json:"status" example:"alive"`
+} //@Name Liveness
+
+// Readiness is ready only when every component is up
+type Readiness struct {
+	Status     string                     `json:"status" example:"ready"`
+	Components map[string]ComponentHealth `json:"components"`
+} //@Name Readiness
+
+type ComponentHealth struct {
+	Status string `json:"status" example:"up"`
+	// Error is why component is down
+	Error string `json:"error,omitempty" example:""`
+} //@Name ComponentHealth
@@ -0,0 +1,19 @@
+package models
+
+const (
+	InstallmentThresholdSlug = "installment-threshold"
+	InstallmentCountSlug     = "installment-count"
+)
+
+type InstallmentConfig struct {
+	Id     uint    `postgres:"id" json:"-"`
+	Slug   string  `postgres:"slug" json:"slug"`
+	Name   string  `postgres:"name" json:"name"`
+	Amount float64 `postgres:"amount" json:"amount"`
+} //@Name InstallmentConfig
+
+type Installment struct {
+	No      int     `json:"no"`
+	DueDate string  `json:"dueDate"`
+	Amount  float64 `json:"amount"`
+} //@Name Installment
@@ -0,0 +1,40 @@
+package models
+
+type PayrollRequest struct {
+	MonthlySalary float64     `json:"monthlySalary" example:"50000"`
+	Month         int         `json:"month" example:"1"`
+	BonusMonths   float64     `json:"bonusMonths,omitempty"`
+	YtdIncome     float64     `json:"ytdIncome,omitempty"`
+	YtdWht        float64     `json:"ytdWht,omitempty"`
+	Allowances    []Allowance `json:"allowances,omitempty"`
+} //@Name PayrollRequest
+
+type PayrollResponse struct {
+	AnnualIncome       float64 `json:"annualIncome"`
+	AnnualTax          float64 `json:"annualTax"`
+	Bonus              float64 `json:"bonus,omitempty"`
+	RegularWithholding float64 `json:"regularWithholding"`
+	BonusWithholding   float64 `json:"bonusWithholding,omitempty"`
+	Withholding        float64 `json:"withholding"`
+} //@Name PayrollResponse
+
+type PayrollCsv struct {
+	EmployeeId    string  `csv:"employeeId,omitempty"`
+	MonthlySalary float64 `csv:"monthlySalary"`
+	Month         int     `csv:"month"`
+	BonusMonths   float64 `csv:"bonusMonths,omitempty"`
+	YtdIncome     float64 `csv:"ytdIncome,omitempty"`
+	YtdWht        float64 `csv:"ytdWht,omitempty"`
+	Donation      float64 `csv:"donation,omitempty"`
+	KReceipt      float64 `csv:"k-receipt,omitempty"`
+}
+
+type PayrollCsvResponse struct {
+	Payroll []PayrollCsvResult `json:"payroll"`
+} //@Name PayrollCsvResponse
+
+type PayrollCsvResult struct {
+	EmployeeId    string  `json:"employeeId,omitempty"`
+	MonthlySalary float64 `json:"monthlySalary"`
+	Withholding   float64 `json:"withholding"`
+} //@Name PayrollCsvResult
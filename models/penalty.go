@@ -0,0 +1,18 @@
+package models
+
+const (
+	SurchargeSlug         = "surcharge"
+	LateFilingPenaltySlug = "late-filing"
+	RefundInterestSlug    = "refund-interest"
+	RefundGraceMonthsSlug = "refund-grace-months"
+)
+
+// DateLayout is format of every date field that accept from request
+const DateLayout = "2006-01-02"
+
+type Penalty struct {
+	Id     uint    `postgres:"id" json:"-"`
+	Slug   string  `postgres:"slug" json:"slug"`
+	Name   string  `postgres:"name" json:"name"`
+	Amount float64 `postgres:"amount" json:"amount"`
+} //@Name Penalty
@@ -0,0 +1,15 @@
+package models
+
+type PoolStats struct {
+	// MaxOpenConnections is 0 when open connections are not limited
+	MaxOpenConnections int `json:"maxOpenConnections" example:"10"`
+	OpenConnections    int `json:"openConnections" example:"3"`
+	InUse              int `json:"inUse" example:"1"`
+	Idle               int `json:"idle" example:"2"`
+	// WaitCount and WaitDurationMs are how many times and how long requests waited for free connection since start
+	WaitCount         int64 `json:"waitCount" example:"4"`
+	WaitDurationMs    int64 `json:"waitDurationMs" example:"35"`
+	MaxIdleClosed     int64 `json:"maxIdleClosed" example:"0"`
+	MaxIdleTimeClosed int64 `json:"maxIdleTimeClosed" example:"5"`
+	MaxLifetimeClosed int64 `json:"maxLifetimeClosed" example:"2"`
+} //@Name PoolStats
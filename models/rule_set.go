@@ -0,0 +1,45 @@
+package models
+
+const (
+	// FixedRuleType allow deduction amount as is without claim, e.g. personal allowance
+	FixedRuleType = "fixed"
+	// AllowanceRuleType sum allowances of same type in request and limit by deduction amount
+	AllowanceRuleType = "allowance"
+	// FamilyRuleType allow deduction amount for each family member of taxpayer profile, e.g. child allowance
+	FamilyRuleType = "family"
+
+	// family members that family deduction is given for, spouse is counted only when taxpayer is married
+	// and spouse has no income
+	SpouseMember = "spouse"
+	ChildMember  = "child"
+	ParentMember = "parent"
+
+	JsonRuleSetFormat = "json"
+	YamlRuleSetFormat = "yaml"
+)
+
+type RuleDeduction struct {
+	Slug string `json:"slug" yaml:"slug" example:"donation"`
+	Type string `json:"type" yaml:"type" example:"allowance"`
+	// Amount is cap of deduction, 0 mean no limit
+	Amount float64 `json:"amount" yaml:"amount" example:"100000"`
+	// PercentLimit cap deduction to percent of total income, 0 mean no limit
+	PercentLimit float64 `json:"percentLimit,omitempty" yaml:"percentLimit,omitempty" example:"10"`
+	// Member is family member that family deduction amount is given for each of them
+	Member string `json:"member,omitempty" yaml:"member,omitempty" example:"child"`
+	// HalfOnHalfYear allow only half of deduction on half-year return (PND 94)
+	HalfOnHalfYear bool `json:"halfOnHalfYear,omitempty" yaml:"halfOnHalfYear,omitempty"`
+	// Periods that deduction is eligible, empty mean every period
+	Periods []string `json:"periods,omitempty" yaml:"periods,omitempty"`
+} //@Name RuleDeduction
+
+// RuleSet describe tax brackets and deductions of a tax year, deductions are applied in listed order
+type RuleSet struct {
+	Id         uint            `json:"id,omitempty" yaml:"-"`
+	Name       string          `json:"name" yaml:"name" example:"default"`
+	TaxYear    int             `json:"taxYear" yaml:"taxYear" example:"2567"`
+	Brackets   []TaxStep       `json:"brackets" yaml:"brackets"`
+	Deductions []RuleDeduction `json:"deductions" yaml:"deductions"`
+	Active     bool            `json:"active" yaml:"-"`
+	CreatedAt  string          `json:"createdAt,omitempty" yaml:"-" example:"2025-01-15T10:00:00Z"`
+} //@Name RuleSet
@@ -0,0 +1,43 @@
+package models
+
+const (
+	JointFiling    = "joint"
+	SeparateFiling = "separate"
+)
+
+type SpouseTaxRequest struct {
+	Taxpayer TaxRequest `json:"taxpayer"`
+	Spouse   TaxRequest `json:"spouse"`
+} //@Name SpouseTaxRequest
+
+type SpouseTaxResponse struct {
+	Joint          TaxResponse            `json:"joint"`
+	Separate       SeparateFilingResponse `json:"separate"`
+	Recommendation string                 `json:"recommendation" example:"separate"`
+} //@Name SpouseTaxResponse
+
+type SeparateFilingResponse struct {
+	Tax       float64     `json:"tax"`
+	TaxRefund float64     `json:"taxRefund,omitempty"`
+	Taxpayer  TaxResponse `json:"taxpayer"`
+	Spouse    TaxResponse `json:"spouse"`
+} //@Name SeparateFilingResponse
+
+type SpouseTaxCsv struct {
+	Taxpayer TaxCsv
+	Spouse   TaxCsv
+}
+
+type SpouseTaxCsvResponse struct {
+	Taxes []SpouseCsvCalculateResult `json:"taxes"`
+} //@Name SpouseTaxCsvResponse
+
+type SpouseCsvCalculateResult struct {
+	TotalIncome       float64 `json:"totalIncome"`
+	SpouseTotalIncome float64 `json:"spouseTotalIncome"`
+	JointTax          float64 `json:"jointTax"`
+	JointTaxRefund    float64 `json:"jointTaxRefund,omitempty"`
+	SeparateTax       float64 `json:"separateTax"`
+	SeparateTaxRefund float64 `json:"separateTaxRefund,omitempty"`
+	Recommendation    string  `json:"recommendation"`
+} //@Name SpouseCsvCalculateResult
@@ -0,0 +1,17 @@
+package models
+
+type AppliedAllowance struct {
+	Type    string  `json:"allowanceType"`
+	Claimed float64 `json:"claimed"`
+	Cap     float64 `json:"cap"`
+	Applied float64 `json:"applied"`
+}
+
+// TaxSummary is data for printable summary of tax calculation
+type TaxSummary struct {
+	TaxReturnId uint               `json:"taxReturnId,omitempty"`
+	TaxYear     int                `json:"taxYear,omitempty"`
+	Request     TaxRequest         `json:"request"`
+	Response    TaxResponse        `json:"response"`
+	Allowances  []AppliedAllowance `json:"allowances"`
+}
@@ -4,29 +4,65 @@ const (
 	DonationSlug = "donation"
 	PersonalSlug = "personal"
 	KReceiptSlug = "k-receipt"
+	SpouseSlug   = "spouse"
+	ChildSlug    = "child"
+	ParentSlug   = "parent"
 )
 
+const (
+	HalfYearPeriod = "half-year"
+	FullYearPeriod = "full-year"
+)
+
+const (
+	SalaryIncome       = "salary"
+	RentalIncome       = "rental"
+	ProfessionalIncome = "professional"
+	ContractIncome     = "contract"
+	BusinessIncome     = "business"
+)
+
+// HalfYearIncomeTypes are income types under section 40(5) - 40(8) that should file half-year return (PND 94)
+var HalfYearIncomeTypes []string = []string{RentalIncome, ProfessionalIncome, ContractIncome, BusinessIncome}
+
 type TaxRequest struct {
+	TaxpayerId  string      `json:"taxpayerId,omitempty" example:"1234567890121"`
 	TotalIncome float64     `json:"totalIncome" validate:"gte=0" example:"500000"`
 	Wht         float64     `json:"wht,omitempty" validate:"omitempty,ltefield=totalIncome,gte=0"`
 	Allowances  []Allowance `json:"allowances,omitempty" validate:"omitempty,dive"`
+	FilingDate  string      `json:"filingDate,omitempty" example:"2025-04-30"`
+	DueDate     string      `json:"dueDate,omitempty" example:"2025-03-31"`
+	RefundDate  string      `json:"refundDate,omitempty" example:"2025-09-30"`
+	Period      string      `json:"period,omitempty" example:"full-year"`
+	IncomeType  string      `json:"incomeType,omitempty" example:"salary"`
+	PrepaidTax  float64     `json:"prepaidTax,omitempty"`
+	Currency    string      `json:"currency,omitempty" example:"THB"`
+	RateDate    string      `json:"rateDate,omitempty" example:"2024-12-30"`
 } //@Name TaxRequest
 
 type Allowance struct {
-	Type   string  `json:"allowanceType" validate:"required,oneof=donation k-receipt"`
-	Amount float64 `json:"amount" validate:"gte=0"`
+	// Type is slug of deduction rule in active rule set that allowance is claimed for
+	Type     string  `json:"allowanceType" validate:"required" example:"donation"`
+	Amount   float64 `json:"amount" validate:"gte=0"`
+	Currency string  `json:"currency,omitempty"`
 } //@Name Allowance
 
 type TaxResponse struct {
-	Tax       float64    `json:"tax"`
-	TaxRefund float64    `json:"taxRefund,omitempty"`
-	TaxLevel  []TaxLevel `json:"taxLevel"`
+	Tax            float64           `json:"tax"`
+	TaxRefund      float64           `json:"taxRefund,omitempty"`
+	TaxLevel       []TaxLevel        `json:"taxLevel"`
+	Surcharge      float64           `json:"surcharge,omitempty"`
+	Penalty        float64           `json:"penalty,omitempty"`
+	RefundInterest float64           `json:"refundInterest,omitempty"`
+	Installments   []Installment     `json:"installments,omitempty"`
+	ExchangeRates  []ConvertedAmount `json:"exchangeRates,omitempty"`
 } //@Name TaxResponse
 
 type TaxStep struct {
-	MinIncome float64
-	MaxIncome float64
-	Rate      float64
+	MinIncome float64 `json:"minIncome" yaml:"minIncome" example:"150000"`
+	// MaxIncome 0 mean no ceiling, allow only on last step
+	MaxIncome float64 `json:"maxIncome" yaml:"maxIncome" example:"500000"`
+	Rate      float64 `json:"rate" yaml:"rate" example:"0.1"`
 } //@Name TaxStep
 
 type TaxLevel struct {
@@ -41,6 +77,8 @@ type Deduction struct {
 	Amount    float64 `postgres:"amount" json:"amount"`
 	MinAmount float64 `postgres:"minAmount" json:"-"`
 	MaxAmount float64 `postgres:"maxAmount" json:"-"`
+	// Version increase on every update, it is sent as ETag to prevent lost update
+	Version int `postgres:"version" json:"version,omitempty"`
 } //@Name Deduction
 
 type TaxCsv struct {
@@ -0,0 +1,34 @@
+package models
+
+type TaxReturnRequest struct {
+	TaxpayerId string     `json:"taxpayerId" example:"1234567890121"`
+	TaxYear    int        `json:"taxYear" example:"2567"`
+	Tax        TaxRequest `json:"tax"`
+} //@Name TaxReturnRequest
+
+type TaxReturn struct {
+	Id         uint        `postgres:"id" json:"id"`
+	TaxpayerId string      `postgres:"taxpayerId" json:"taxpayerId"`
+	TaxYear    int         `postgres:"taxYear" json:"taxYear"`
+	Revision   int         `postgres:"revision" json:"revision"`
+	Request    TaxRequest  `postgres:"request" json:"request"`
+	Response   TaxResponse `postgres:"response" json:"response"`
+	Deductions []Deduction `postgres:"deductions" json:"deductions"`
+	RuleSet    RuleSet     `postgres:"ruleSet" json:"ruleSet"`
+	// RequestThb and Taxpayer are request in THB and profile that result is calculated with, they are empty
+	// on return that was saved before they were kept
+	RequestThb *TaxRequest `postgres:"requestThb" json:"requestThb,omitempty"`
+	Taxpayer   *Taxpayer   `postgres:"taxpayer" json:"taxpayer,omitempty"`
+	CreatedAt  string      `postgres:"createdAt" json:"createdAt"`
+	UpdatedAt  string      `postgres:"updatedAt" json:"updatedAt"`
+} //@Name TaxReturn
+
+// TaxConfig is deduction config, rule set and data that tax is calculated with, it is kept as snapshot of tax return
+type TaxConfig struct {
+	Deductions []Deduction
+	RuleSet    RuleSet
+	// RequestThb is tax request with amounts converted to THB
+	RequestThb TaxRequest
+	// Taxpayer is profile that family allowances come from, it is nil when request has no taxpayer id
+	Taxpayer *Taxpayer
+}
@@ -0,0 +1,20 @@
+package models
+
+const (
+	SingleStatus   = "single"
+	MarriedStatus  = "married"
+	DivorcedStatus = "divorced"
+	WidowedStatus  = "widowed"
+)
+
+type Taxpayer struct {
+	NationalId      string `postgres:"nationalId" json:"nationalId" example:"1234567890121"`
+	Name            string `postgres:"name" json:"name" example:"Somchai Jaidee"`
+	MaritalStatus   string `postgres:"maritalStatus" json:"maritalStatus" example:"married"`
+	SpouseHasIncome bool   `postgres:"spouseHasIncome" json:"spouseHasIncome"`
+	Children        int    `postgres:"children" json:"children" example:"2"`
+	// Parents that taxpayer can claim allowance, up to 2 or 4 with parents of spouse who has no income
+	Parents   int    `postgres:"parents" json:"parents" example:"0"`
+	CreatedAt string `postgres:"createdAt" json:"createdAt,omitempty"`
+	UpdatedAt string `postgres:"updatedAt" json:"updatedAt,omitempty"`
+} //@Name Taxpayer
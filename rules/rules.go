@@ -0,0 +1,9 @@
+// Package rules embed default tax rule set, it is active until admin activate another rule set.
+package rules
+
+import _ "embed"
+
+// Default is rule set of tax year 2567 in yaml format
+//
+//go:embed default.yaml
+var Default []byte
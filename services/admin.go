@@ -1,24 +1,47 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 
+	"github.com/baronight/assessment-tax/metrics"
 	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
 
 var (
-	ErrDeductionInvalid = errors.New("invalid deduction")
+	ErrDeductionInvalid       = errors.New("invalid deduction")
+	ErrDeductionAmountInvalid = errors.New("deduction amount is out of limit")
 )
 
+// deductionLimitError keep message of limit that amount is out of, and match ErrDeductionAmountInvalid
+type deductionLimitError struct {
+	message string
+}
+
+func (e deductionLimitError) Error() string { return e.message }
+
+func (e deductionLimitError) Is(target error) bool { return target == ErrDeductionAmountInvalid }
+
 type AdminService struct {
 	Db AdminStorer
 }
 
 type AdminStorer interface {
-	GetDeduction(slug string) (models.Deduction, error)
-	UpdateDeduction(slug string, amount float64) (models.Deduction, error)
+	GetDeduction(ctx context.Context, slug string) (models.Deduction, error)
+	GetDeductions(ctx context.Context) ([]models.Deduction, error)
+	CreateDeductionChange(ctx context.Context, change models.DeductionChange) (models.DeductionChange, error)
+	// CreateDeductionChanges insert every change in one transaction, none is saved when one fail
+	CreateDeductionChanges(ctx context.Context, changes []models.DeductionChange) ([]models.DeductionChange, error)
+	GetDeductionChanges(ctx context.Context, status string) ([]models.DeductionChange, error)
+	GetDeductionChange(ctx context.Context, id uint) (models.DeductionChange, error)
+	// ApproveDeductionChange mark pending change as approved and write its amount to deduction in one transaction
+	ApproveDeductionChange(ctx context.Context, id uint, reviewer string) (models.DeductionChange, error)
+	// RejectDeductionChange mark pending change as rejected
+	RejectDeductionChange(ctx context.Context, id uint, reviewer, reason string) (models.DeductionChange, error)
 }
 
 func NewAdminService(db AdminStorer) *AdminService {
@@ -27,30 +50,128 @@ func NewAdminService(db AdminStorer) *AdminService {
 	}
 }
 
-func (as *AdminService) UpdateDeductionConfig(slug string, amount models.DeductionRequest) (response models.Deduction, err error) {
-	err = as.ValidateDeductionRequest(slug, amount.Amount)
+// GetDeductionConfig return deduction with its version
+func (as *AdminService) GetDeductionConfig(ctx context.Context, slug string) (models.Deduction, error) {
+	return as.Db.GetDeduction(ctx, slug)
+}
+
+// ProposeDeductionChange save new amount as pending change on version of deduction that editor read,
+// deduction is not changed until other admin approve it
+func (as *AdminService) ProposeDeductionChange(ctx context.Context, slug string, amount models.DeductionRequest, version int, editor string) (models.DeductionChange, error) {
+	deduction, err := as.Db.GetDeduction(ctx, slug)
 	if err != nil {
-		return
+		return models.DeductionChange{}, ErrDeductionInvalid
+	}
+	if deduction.Version != version {
+		return models.DeductionChange{}, utils.ErrVersionMismatch
+	}
+	if err := validateDeductionAmount(deduction, amount.Amount); err != nil {
+		return models.DeductionChange{}, err
 	}
 
-	response, err = as.Db.UpdateDeduction(slug, amount.Amount)
-	return
+	return as.Db.CreateDeductionChange(ctx, models.DeductionChange{
+		Slug:       slug,
+		Amount:     amount.Amount,
+		Version:    version,
+		Status:     models.PendingChange,
+		ProposedBy: editor,
+	})
 }
 
-func (as *AdminService) ValidateDeductionRequest(slug string, amount float64) error {
-	printer := message.NewPrinter(language.English)
-	deduction, err := as.Db.GetDeduction(slug)
+// newDeductionChange validate amount with limit of deduction and make pending change on its current version,
+// it is used for amounts that admin propose in bulk by rule set or config import
+func newDeductionChange(deduction models.Deduction, amount float64, editor string) (models.DeductionChange, error) {
+	if err := validateDeductionAmount(deduction, amount); err != nil {
+		return models.DeductionChange{}, err
+	}
+	return models.DeductionChange{
+		Slug:       deduction.Slug,
+		Amount:     amount,
+		Version:    deduction.Version,
+		Status:     models.PendingChange,
+		ProposedBy: editor,
+	}, nil
+}
+
+// GetDeductionChanges return changes in status, pending changes when status is not send
+func (as *AdminService) GetDeductionChanges(ctx context.Context, status string) ([]models.DeductionChange, error) {
+	if status == "" {
+		status = models.PendingChange
+	}
+	changes, err := as.Db.GetDeductionChanges(ctx, status)
+	if err == sql.ErrNoRows || changes == nil {
+		return []models.DeductionChange{}, nil
+	}
+	return changes, err
+}
+
+// pendingChange return change that reviewer can review
+func (as *AdminService) pendingChange(ctx context.Context, id uint, reviewer string) (models.DeductionChange, error) {
+	change, err := as.Db.GetDeductionChange(ctx, id)
+	if err == sql.ErrNoRows {
+		return change, utils.ErrDeductionChangeNotFound
+	}
+	if err != nil {
+		return change, err
+	}
+	if change.Status != models.PendingChange {
+		return change, utils.ErrDeductionChangeReviewed
+	}
+	if change.ProposedBy == reviewer {
+		return change, utils.ErrSelfReview
+	}
+	return change, nil
+}
+
+// ApproveDeductionChange validate amount again with current limit and write it to deduction,
+// it return utils.ErrVersionMismatch when deduction was changed after the change was proposed
+func (as *AdminService) ApproveDeductionChange(ctx context.Context, id uint, reviewer string) (models.DeductionChange, error) {
+	change, err := as.pendingChange(ctx, id, reviewer)
+	if err != nil {
+		return change, err
+	}
+	if err := as.ValidateDeductionRequest(ctx, change.Slug, change.Amount); err != nil {
+		return change, err
+	}
+
+	change, err = as.Db.ApproveDeductionChange(ctx, id, reviewer)
+	if err == sql.ErrNoRows {
+		// other admin reviewed it in between
+		return change, utils.ErrDeductionChangeReviewed
+	}
+	if err == nil {
+		metrics.DeductionUpdates.WithLabelValues(change.Slug, "approval").Inc()
+	}
+	return change, err
+}
+
+func (as *AdminService) RejectDeductionChange(ctx context.Context, id uint, reviewer string, review models.DeductionChangeReview) (models.DeductionChange, error) {
+	if _, err := as.pendingChange(ctx, id, reviewer); err != nil {
+		return models.DeductionChange{}, err
+	}
+
+	change, err := as.Db.RejectDeductionChange(ctx, id, reviewer, review.Reason)
+	if err == sql.ErrNoRows {
+		return change, utils.ErrDeductionChangeReviewed
+	}
+	return change, err
+}
+
+func (as *AdminService) ValidateDeductionRequest(ctx context.Context, slug string, amount float64) error {
+	deduction, err := as.Db.GetDeduction(ctx, slug)
 	if err != nil {
 		return ErrDeductionInvalid
 	}
+	return validateDeductionAmount(deduction, amount)
+}
 
+func validateDeductionAmount(deduction models.Deduction, amount float64) error {
+	printer := message.NewPrinter(language.English)
 	if amount < deduction.MinAmount {
-		err = errors.New(printer.Sprintf("amount should not be less than %.2f", deduction.MinAmount))
-		return err
+		return deductionLimitError{printer.Sprintf("amount should not be less than %.2f", deduction.MinAmount)}
 	}
 	if deduction.MaxAmount > 0 && amount > deduction.MaxAmount {
-		err = errors.New(printer.Sprintf("amount should not be more than %.2f", deduction.MaxAmount))
-		return err
+		return deductionLimitError{printer.Sprintf("amount should not be more than %.2f", deduction.MaxAmount)}
 	}
 	return nil
 }
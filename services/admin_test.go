@@ -4,20 +4,23 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
 )
 
 type PersonalTestSuite struct {
-	name                string
-	stub                StubAdminStorer
-	want                models.Deduction
-	params              models.DeductionRequest
-	wantError           error
-	updateDeductionCall bool
+	name             string
+	stub             StubAdminStorer
+	want             models.DeductionChange
+	params           models.DeductionRequest
+	wantError        error
+	createChangeCall bool
 }
 
 type DbDeductionResult struct {
@@ -26,24 +29,81 @@ type DbDeductionResult struct {
 }
 
 type StubAdminStorer struct {
-	getDeduction       models.Deduction
-	updateDeduction    models.Deduction
-	getDeductionErr    error
-	updateDeductionErr error
-	expectToCall       map[string]bool
-	expectCallTimes    map[string]int
+	getDeduction    models.Deduction
+	getDeductionErr error
+	deductions      []models.Deduction
+	proposed        []models.DeductionChange
+	changes         map[uint]models.DeductionChange
+	changeErr       error
+	expectToCall    map[string]bool
+	expectCallTimes map[string]int
 }
 
-func (s *StubAdminStorer) GetDeduction(slug string) (models.Deduction, error) {
+func (s *StubAdminStorer) GetDeduction(ctx context.Context, slug string) (models.Deduction, error) {
 	s.expectToCall["GetDeduction"] = true
 	s.expectCallTimes["GetDeduction"]++
 	return s.getDeduction, s.getDeductionErr
 }
 
-func (s *StubAdminStorer) UpdateDeduction(slug string, amount float64) (models.Deduction, error) {
-	s.expectToCall["UpdateDeduction"] = true
-	s.expectCallTimes["UpdateDeduction"]++
-	return s.updateDeduction, s.updateDeductionErr
+func (s *StubAdminStorer) GetDeductions(ctx context.Context) ([]models.Deduction, error) {
+	s.expectToCall["GetDeductions"] = true
+	return s.deductions, s.getDeductionErr
+}
+
+func (s *StubAdminStorer) CreateDeductionChange(ctx context.Context, change models.DeductionChange) (models.DeductionChange, error) {
+	s.expectToCall["CreateDeductionChange"] = true
+	s.expectCallTimes["CreateDeductionChange"]++
+	change.Id = 1
+	s.proposed = append(s.proposed, change)
+	return change, s.changeErr
+}
+
+// CreateDeductionChanges save nothing on error like rolled back transaction
+func (s *StubAdminStorer) CreateDeductionChanges(ctx context.Context, changes []models.DeductionChange) ([]models.DeductionChange, error) {
+	s.expectToCall["CreateDeductionChanges"] = true
+	s.expectCallTimes["CreateDeductionChanges"]++
+	if s.changeErr != nil {
+		return nil, s.changeErr
+	}
+	for _, change := range changes {
+		change.Id = uint(len(s.proposed) + 1)
+		s.proposed = append(s.proposed, change)
+	}
+	return s.proposed, nil
+}
+
+func (s *StubAdminStorer) GetDeductionChanges(ctx context.Context, status string) ([]models.DeductionChange, error) {
+	s.expectToCall["GetDeductionChanges"] = true
+	var changes []models.DeductionChange
+	for _, v := range s.changes {
+		if v.Status == status {
+			changes = append(changes, v)
+		}
+	}
+	return changes, s.changeErr
+}
+
+func (s *StubAdminStorer) GetDeductionChange(ctx context.Context, id uint) (models.DeductionChange, error) {
+	s.expectToCall["GetDeductionChange"] = true
+	change, ok := s.changes[id]
+	if !ok {
+		return change, sql.ErrNoRows
+	}
+	return change, nil
+}
+
+func (s *StubAdminStorer) ApproveDeductionChange(ctx context.Context, id uint, reviewer string) (models.DeductionChange, error) {
+	s.expectToCall["ApproveDeductionChange"] = true
+	change := s.changes[id]
+	change.Status, change.ReviewedBy = models.ApprovedChange, reviewer
+	return change, s.changeErr
+}
+
+func (s *StubAdminStorer) RejectDeductionChange(ctx context.Context, id uint, reviewer, reason string) (models.DeductionChange, error) {
+	s.expectToCall["RejectDeductionChange"] = true
+	change := s.changes[id]
+	change.Status, change.ReviewedBy, change.Reason = models.RejectedChange, reviewer, reason
+	return change, s.changeErr
 }
 
 func (s *StubAdminStorer) assertMethodWasCalled(t *testing.T, methodName string) {
@@ -71,14 +131,13 @@ func setupAdminService(stub StubAdminStorer) *AdminService {
 	return service
 }
 
-func initStubAdminStorer(getDeduction, updateDeduction DbDeductionResult) StubAdminStorer {
+func initStubAdminStorer(getDeduction DbDeductionResult, changeErr error) StubAdminStorer {
 	return StubAdminStorer{
-		expectToCall:       map[string]bool{},
-		expectCallTimes:    map[string]int{},
-		getDeduction:       getDeduction.deduction,
-		getDeductionErr:    getDeduction.err,
-		updateDeduction:    updateDeduction.deduction,
-		updateDeductionErr: updateDeduction.err,
+		expectToCall:    map[string]bool{},
+		expectCallTimes: map[string]int{},
+		getDeduction:    getDeduction.deduction,
+		getDeductionErr: getDeduction.err,
+		changeErr:       changeErr,
 	}
 }
 
@@ -91,7 +150,7 @@ func TestValidateDeduction(t *testing.T) {
 		}
 		service := setupAdminService(stub)
 
-		err := service.ValidateDeductionRequest("xxx", 5_000)
+		err := service.ValidateDeductionRequest(context.Background(), "xxx", 5_000)
 
 		stub.assertMethodWasCalled(t, "GetDeduction")
 		stub.assertMethodCalledTime(t, "GetDeduction", 1)
@@ -109,7 +168,7 @@ func TestValidateDeduction(t *testing.T) {
 		}
 		service := setupAdminService(stub)
 
-		err := service.ValidateDeductionRequest("xxx", 100_001)
+		err := service.ValidateDeductionRequest(context.Background(), "xxx", 100_001)
 
 		stub.assertMethodWasCalled(t, "GetDeduction")
 		stub.assertMethodCalledTime(t, "GetDeduction", 1)
@@ -127,7 +186,7 @@ func TestValidateDeduction(t *testing.T) {
 		}
 		service := setupAdminService(stub)
 
-		err := service.ValidateDeductionRequest("xxx", 5_000)
+		err := service.ValidateDeductionRequest(context.Background(), "xxx", 5_000)
 
 		stub.assertMethodWasCalled(t, "GetDeduction")
 		stub.assertMethodCalledTime(t, "GetDeduction", 1)
@@ -145,7 +204,7 @@ func TestValidateDeduction(t *testing.T) {
 		}
 		service := setupAdminService(stub)
 
-		err := service.ValidateDeductionRequest("xxx", 50_000)
+		err := service.ValidateDeductionRequest(context.Background(), "xxx", 50_000)
 
 		stub.assertMethodWasCalled(t, "GetDeduction")
 		stub.assertMethodCalledTime(t, "GetDeduction", 1)
@@ -156,7 +215,7 @@ func TestValidateDeduction(t *testing.T) {
 	})
 }
 
-func TestUpdateDeductionConfig(t *testing.T) {
+func TestProposeDeductionChange(t *testing.T) {
 	testSuites := []PersonalTestSuite{
 		{
 			name: "given error when call \"getDeduction\" should return error",
@@ -165,64 +224,71 @@ func TestUpdateDeductionConfig(t *testing.T) {
 					deduction: models.Deduction{},
 					err:       errors.New("error xxx occured"),
 				},
-				DbDeductionResult{},
+				nil,
 			),
-			want:                models.Deduction{},
-			params:              models.DeductionRequest{},
-			wantError:           ErrDeductionInvalid,
-			updateDeductionCall: false,
+			params:           models.DeductionRequest{},
+			wantError:        ErrDeductionInvalid,
+			createChangeCall: false,
 		},
 		{
 			name: "given amount is less than minimum acceptable amount should return error 'amount should not be less than xxx'",
 			stub: initStubAdminStorer(
 				DbDeductionResult{
-					deduction: models.Deduction{MinAmount: 10_000},
+					deduction: models.Deduction{MinAmount: 10_000, Version: 1},
 				},
-				DbDeductionResult{},
+				nil,
 			),
-			params:              models.DeductionRequest{Amount: 5_000},
-			wantError:           errors.New("amount should not be less than 10,000.00"),
-			updateDeductionCall: false,
+			params:           models.DeductionRequest{Amount: 5_000},
+			wantError:        errors.New("amount should not be less than 10,000.00"),
+			createChangeCall: false,
 		},
 		{
 			name: "given amount is more than maximum acceptable amount should return error 'amount should not be more than xxx'",
 			stub: initStubAdminStorer(
 				DbDeductionResult{
-					deduction: models.Deduction{MaxAmount: 100_000},
+					deduction: models.Deduction{MaxAmount: 100_000, Version: 1},
 				},
-				DbDeductionResult{},
+				nil,
 			),
-			params:              models.DeductionRequest{Amount: 200_000},
-			wantError:           errors.New("amount should not be more than 100,000.00"),
-			updateDeductionCall: false,
+			params:           models.DeductionRequest{Amount: 200_000},
+			wantError:        errors.New("amount should not be more than 100,000.00"),
+			createChangeCall: false,
 		},
 		{
-			name: "given error on called 'updateDeduction' should return error",
+			name: "given version that is not current version of deduction should return version mismatch error",
 			stub: initStubAdminStorer(
 				DbDeductionResult{
-					deduction: models.Deduction{MaxAmount: 100_000},
-				},
-				DbDeductionResult{
-					err: errors.New("error xxx occured"),
+					deduction: models.Deduction{MaxAmount: 100_000, Version: 2},
 				},
+				nil,
 			),
-			params:              models.DeductionRequest{Amount: 50_000},
-			wantError:           errors.New("error xxx occured"),
-			updateDeductionCall: true,
+			params:           models.DeductionRequest{Amount: 50_000},
+			wantError:        utils.ErrVersionMismatch,
+			createChangeCall: false,
 		},
 		{
-			name: "given amount is in range of minimum and maximum acceptable amount should return updated amount",
+			name: "given error on called 'CreateDeductionChange' should return error",
 			stub: initStubAdminStorer(
 				DbDeductionResult{
-					deduction: models.Deduction{MaxAmount: 100_000},
+					deduction: models.Deduction{MaxAmount: 100_000, Version: 1},
 				},
+				errors.New("error xxx occured"),
+			),
+			params:           models.DeductionRequest{Amount: 50_000},
+			wantError:        errors.New("error xxx occured"),
+			createChangeCall: true,
+		},
+		{
+			name: "given amount is in range of minimum and maximum acceptable amount should return pending change",
+			stub: initStubAdminStorer(
 				DbDeductionResult{
-					deduction: models.Deduction{Amount: 50_000},
+					deduction: models.Deduction{MaxAmount: 100_000, Version: 1},
 				},
+				nil,
 			),
-			params:              models.DeductionRequest{Amount: 50_000},
-			want:                models.Deduction{Amount: 50_000},
-			updateDeductionCall: true,
+			params:           models.DeductionRequest{Amount: 50_000},
+			want:             models.DeductionChange{Id: 1, Slug: "test", Amount: 50_000, Version: 1, Status: models.PendingChange, ProposedBy: "editor"},
+			createChangeCall: true,
 		},
 	}
 
@@ -230,17 +296,17 @@ func TestUpdateDeductionConfig(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			service := setupAdminService(tc.stub)
 
-			result, err := service.UpdateDeductionConfig("test", tc.params)
+			result, err := service.ProposeDeductionChange(context.Background(), "test", tc.params, 1, "editor")
 
 			// verify get deduction was called
 			tc.stub.assertMethodWasCalled(t, "GetDeduction")
 			tc.stub.assertMethodCalledTime(t, "GetDeduction", 1)
-			// verify update deduction was called or not
-			if tc.updateDeductionCall {
-				tc.stub.assertMethodWasCalled(t, "UpdateDeduction")
-				tc.stub.assertMethodCalledTime(t, "UpdateDeduction", 1)
+			// verify change was created or not
+			if tc.createChangeCall {
+				tc.stub.assertMethodWasCalled(t, "CreateDeductionChange")
+				tc.stub.assertMethodCalledTime(t, "CreateDeductionChange", 1)
 			} else {
-				tc.stub.assertMethodWasNotCalled(t, "")
+				tc.stub.assertMethodWasNotCalled(t, "CreateDeductionChange")
 			}
 			if tc.wantError != nil {
 				if err == nil {
@@ -254,3 +320,85 @@ func TestUpdateDeductionConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestReviewDeductionChange(t *testing.T) {
+	initStub := func() StubAdminStorer {
+		stub := initStubAdminStorer(DbDeductionResult{deduction: models.Deduction{MinAmount: 10_000, MaxAmount: 100_000}}, nil)
+		stub.changes = map[uint]models.DeductionChange{
+			1: {Id: 1, Slug: models.PersonalSlug, Amount: 70_000, Status: models.PendingChange, ProposedBy: "editor"},
+			2: {Id: 2, Slug: models.PersonalSlug, Amount: 70_000, Status: models.ApprovedChange, ProposedBy: "editor", ReviewedBy: "approver"},
+		}
+		return stub
+	}
+	t.Run("given other admin approve pending change should validate amount again and approve it", func(t *testing.T) {
+		stub := initStub()
+
+		got, err := setupAdminService(stub).ApproveDeductionChange(context.Background(), 1, "approver")
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodWasCalled(t, "GetDeduction")
+		stub.assertMethodWasCalled(t, "ApproveDeductionChange")
+		assertIsEqual(t, models.ApprovedChange, got.Status, "expect change is approved")
+	})
+	t.Run("given proposer approve own change should return ErrSelfReview", func(t *testing.T) {
+		stub := initStub()
+
+		_, err := setupAdminService(stub).ApproveDeductionChange(context.Background(), 1, "editor")
+
+		assertIsEqual(t, utils.ErrSelfReview, err, "expect self review error")
+		stub.assertMethodWasNotCalled(t, "ApproveDeductionChange")
+	})
+	t.Run("given limit was changed after proposal should not approve", func(t *testing.T) {
+		stub := initStub()
+		stub.getDeduction.MaxAmount = 60_000
+
+		_, err := setupAdminService(stub).ApproveDeductionChange(context.Background(), 1, "approver")
+
+		if !errors.Is(err, ErrDeductionAmountInvalid) {
+			t.Errorf("expect amount invalid error but got %v", err)
+		}
+		stub.assertMethodWasNotCalled(t, "ApproveDeductionChange")
+	})
+	t.Run("given reviewed change should return ErrDeductionChangeReviewed", func(t *testing.T) {
+		stub := initStub()
+
+		_, err := setupAdminService(stub).RejectDeductionChange(context.Background(), 2, "approver", models.DeductionChangeReview{})
+
+		assertIsEqual(t, utils.ErrDeductionChangeReviewed, err, "expect change reviewed error")
+	})
+	t.Run("given change was reviewed in between should return ErrDeductionChangeReviewed", func(t *testing.T) {
+		stub := initStub()
+		stub.changeErr = sql.ErrNoRows
+
+		_, err := setupAdminService(stub).ApproveDeductionChange(context.Background(), 1, "approver")
+
+		assertIsEqual(t, utils.ErrDeductionChangeReviewed, err, "expect change reviewed error")
+	})
+	t.Run("given not exist change should return ErrDeductionChangeNotFound", func(t *testing.T) {
+		stub := initStub()
+
+		_, err := setupAdminService(stub).ApproveDeductionChange(context.Background(), 9, "approver")
+
+		assertIsEqual(t, utils.ErrDeductionChangeNotFound, err, "expect change not found error")
+	})
+	t.Run("given other admin reject pending change should keep reason", func(t *testing.T) {
+		stub := initStub()
+
+		got, err := setupAdminService(stub).RejectDeductionChange(context.Background(), 1, "approver", models.DeductionChangeReview{Reason: "not announced"})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodWasNotCalled(t, "GetDeduction")
+		assertObjectIsEqual(t, models.DeductionChange{
+			Id: 1, Slug: models.PersonalSlug, Amount: 70_000, Status: models.RejectedChange,
+			ProposedBy: "editor", ReviewedBy: "approver", Reason: "not announced",
+		}, got)
+	})
+	t.Run("given no status should list pending changes", func(t *testing.T) {
+		stub := initStub()
+
+		got, err := setupAdminService(stub).GetDeductionChanges(context.Background(), "")
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 1, len(got), "expect 1 pending change")
+	})
+}
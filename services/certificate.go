@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+)
+
+type CertificateService struct {
+	Db TaxStorer
+}
+
+func NewCertificateService(db TaxStorer) *CertificateService {
+	return &CertificateService{
+		Db: db,
+	}
+}
+
+// AggregateCertificates sum amount paid and tax withheld of certificates by income type in order of first found
+func AggregateCertificates(certificates []models.Certificate) []models.IncomeByType {
+	incomes := []models.IncomeByType{}
+	index := map[string]int{}
+	for _, v := range certificates {
+		i, ok := index[v.IncomeType]
+		if !ok {
+			i = len(incomes)
+			index[v.IncomeType] = i
+			incomes = append(incomes, models.IncomeByType{IncomeType: v.IncomeType})
+		}
+		incomes[i].AmountPaid += v.AmountPaid
+		incomes[i].TaxWithheld += v.TaxWithheld
+	}
+	return incomes
+}
+
+// CertificateTaxInput make tax request from certificates, total income and wht are sum of all certificates.
+// Income type is salary when there is only salary income, otherwise it is the other income type that has highest amount.
+func CertificateTaxInput(req models.CertificateTaxRequest, incomes []models.IncomeByType) models.TaxRequest {
+	tax := models.TaxRequest{
+		TaxpayerId: req.TaxpayerId,
+		Allowances: req.Allowances,
+		IncomeType: models.SalaryIncome,
+	}
+	var highest float64
+	for _, v := range incomes {
+		tax.TotalIncome += v.AmountPaid
+		tax.Wht += v.TaxWithheld
+		if v.IncomeType != models.SalaryIncome && v.AmountPaid > highest {
+			highest = v.AmountPaid
+			tax.IncomeType = v.IncomeType
+		}
+	}
+	tax.TotalIncome = math.Round(tax.TotalIncome*100) / 100
+	tax.Wht = math.Round(tax.Wht*100) / 100
+	return tax
+}
+
+func (cs *CertificateService) CertificateTaxCalculate(ctx context.Context, req models.CertificateTaxRequest) (models.CertificateTaxResponse, error) {
+	result := models.CertificateTaxResponse{
+		Certificates: []models.CertificateResult{},
+	}
+	for i, v := range req.Certificates {
+		result.Certificates = append(result.Certificates, models.CertificateResult{No: i + 1, Certificate: v})
+	}
+	result.IncomeByType = AggregateCertificates(req.Certificates)
+	result.Input = CertificateTaxInput(req, result.IncomeByType)
+
+	tax, err := NewTaxService(cs.Db).TaxCalculate(ctx, result.Input)
+	if err != nil {
+		return models.CertificateTaxResponse{}, err
+	}
+	result.Result = tax
+	return result, nil
+}
+
+// ExtractCertificateCsv read certificates with column payerTaxId, incomeType, amountPaid and taxWithheld
+func (cs *CertificateService) ExtractCertificateCsv(reader io.Reader) ([]models.Certificate, error) {
+	certificates := []models.Certificate{}
+	csvReader := csv.NewReader(reader)
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, utils.ErrCsvHeaderMissing
+	}
+	header := rows[0]
+	if !validators.IsAllStringInArray(header, []string{"payerTaxId", "incomeType", "amountPaid", "taxWithheld"}) {
+		return nil, utils.ErrCsvHeaderMissing
+	}
+	for _, row := range rows[1:] {
+		var certificate models.Certificate
+		for idx, col := range row {
+			switch header[idx] {
+			case "payerTaxId":
+				certificate.PayerTaxId = col
+			case "incomeType":
+				certificate.IncomeType = col
+			case "amountPaid", "taxWithheld":
+				if col == "" {
+					return nil, utils.ErrCsvValueEmpty
+				}
+				val, err := strconv.ParseFloat(col, 64)
+				if err != nil {
+					return nil, err
+				}
+				if header[idx] == "amountPaid" {
+					certificate.AmountPaid = val
+				} else {
+					certificate.TaxWithheld = val
+				}
+			}
+		}
+		if err := validators.ValidateCertificate(certificate); err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates, nil
+}
@@ -0,0 +1,92 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+var certificates = []models.Certificate{
+	{PayerTaxId: "0105556123453", IncomeType: models.SalaryIncome, AmountPaid: 400_000, TaxWithheld: 15_000},
+	{PayerTaxId: "1234567890121", IncomeType: models.ContractIncome, AmountPaid: 100_000, TaxWithheld: 3_000},
+	{PayerTaxId: "0105556123453", IncomeType: models.SalaryIncome, AmountPaid: 50_000},
+}
+
+func TestAggregateCertificates(t *testing.T) {
+	got := AggregateCertificates(certificates)
+
+	assertObjectIsEqual(t, []models.IncomeByType{
+		{IncomeType: models.SalaryIncome, AmountPaid: 450_000, TaxWithheld: 15_000},
+		{IncomeType: models.ContractIncome, AmountPaid: 100_000, TaxWithheld: 3_000},
+	}, got)
+}
+
+func TestCertificateTaxInput(t *testing.T) {
+	t.Run("given salary only should use salary income type", func(t *testing.T) {
+		got := CertificateTaxInput(models.CertificateTaxRequest{}, AggregateCertificates(certificates[:1]))
+
+		assertIsEqual(t, models.SalaryIncome, got.IncomeType, "expect salary income type")
+	})
+	t.Run("given mixed income should sum income and wht and use other income type", func(t *testing.T) {
+		got := CertificateTaxInput(models.CertificateTaxRequest{TaxpayerId: "1234567890121"}, AggregateCertificates(certificates))
+
+		assertObjectIsEqual(t, models.TaxRequest{
+			TaxpayerId:  "1234567890121",
+			TotalIncome: 550_000,
+			Wht:         18_000,
+			IncomeType:  models.ContractIncome,
+		}, got)
+	})
+}
+
+func TestCertificateTaxCalculate(t *testing.T) {
+	t.Run("given certificates should return tax with per-certificate breakdown", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		service := NewCertificateService(&stub)
+
+		got, err := service.CertificateTaxCalculate(context.Background(), models.CertificateTaxRequest{Certificates: certificates})
+
+		// net income 490,000 has tax 34,000 and 18,000 is withheld
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 3, len(got.Certificates), "expect 3 certificates in breakdown")
+		assertIsEqual(t, 2, got.Certificates[1].No, "expect certificate number start from 1")
+		assertIsEqual(t, 16_000.0, got.Result.Tax, expectTaxValueMsg(16_000, got.Result.Tax))
+	})
+}
+
+func TestExtractCertificateCsv(t *testing.T) {
+	service := NewCertificateService(nil)
+	t.Run("when csv is empty should return error 'missing required header field'", func(t *testing.T) {
+		_, err := service.ExtractCertificateCsv(strings.NewReader(""))
+
+		assertObjectIsEqual(t, utils.ErrCsvHeaderMissing, err)
+	})
+	t.Run("given valid csv should return certificates", func(t *testing.T) {
+		dir, _ := os.Getwd()
+		file, err := os.Open(filepath.Join(dir, "../testdata/valid-certificates.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		got, err := service.ExtractCertificateCsv(file)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, certificates, got)
+	})
+	t.Run("given invalid payer tax id should return validate error", func(t *testing.T) {
+		_, err := service.ExtractCertificateCsv(strings.NewReader("payerTaxId,incomeType,amountPaid,taxWithheld\n123,salary,100,0\n"))
+
+		if err == nil {
+			t.Error("expect payer tax id error")
+		}
+	})
+}
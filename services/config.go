@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+var (
+	ErrDeductionLimitChanged     = errors.New("minAmount and maxAmount should be the same as current limit, limit cannot be imported")
+	ErrDeductionConfigDuplicated = errors.New("deduction is duplicated")
+)
+
+func toDeductionConfig(d models.Deduction) models.DeductionConfig {
+	return models.DeductionConfig{
+		Slug:      d.Slug,
+		Name:      d.Name,
+		Amount:    d.Amount,
+		MinAmount: d.MinAmount,
+		MaxAmount: d.MaxAmount,
+	}
+}
+
+// ExportConfig return all deduction rows with their limit
+func (as *AdminService) ExportConfig(ctx context.Context) (models.ConfigExport, error) {
+	export := models.ConfigExport{Deductions: []models.DeductionConfig{}}
+	ds, err := as.Db.GetDeductions(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return export, err
+	}
+	for _, d := range ds {
+		export.Deductions = append(export.Deductions, toDeductionConfig(d))
+	}
+	return export, nil
+}
+
+// ImportConfig validate amount of every deduction with its current limit and propose changed amounts as
+// pending changes that other admin has to approve, deduction that is not in config is not changed.
+// Limits are not imported so they should be the same as current limits. On dry run it only return what would be changed.
+func (as *AdminService) ImportConfig(ctx context.Context, config models.ConfigExport, dryRun bool, editor string) (models.ConfigImportResponse, error) {
+	result := models.ConfigImportResponse{DryRun: dryRun, Changes: []models.DeductionConfigDiff{}, PendingChanges: []models.DeductionChange{}}
+	ds, err := as.Db.GetDeductions(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return result, err
+	}
+	current := map[string]models.Deduction{}
+	for _, d := range ds {
+		current[d.Slug] = d
+	}
+
+	var changes []models.DeductionChange
+	seen := map[string]bool{}
+	for i, v := range config.Deductions {
+		deduction, ok := current[v.Slug]
+		if !ok {
+			return result, fmt.Errorf("deduction %d: %w", i+1, ErrDeductionInvalid)
+		}
+		if seen[v.Slug] {
+			return result, fmt.Errorf("deduction %d: %w", i+1, ErrDeductionConfigDuplicated)
+		}
+		seen[v.Slug] = true
+		if v.MinAmount != deduction.MinAmount || v.MaxAmount != deduction.MaxAmount {
+			return result, fmt.Errorf("deduction %d: %w", i+1, ErrDeductionLimitChanged)
+		}
+		if v.Amount == deduction.Amount {
+			continue
+		}
+		change, err := newDeductionChange(deduction, v.Amount, editor)
+		if err != nil {
+			return result, fmt.Errorf("deduction %d: %w", i+1, err)
+		}
+		changes = append(changes, change)
+		to := toDeductionConfig(deduction)
+		to.Amount = v.Amount
+		result.Changes = append(result.Changes, models.DeductionConfigDiff{
+			Slug: v.Slug,
+			From: toDeductionConfig(deduction),
+			To:   to,
+		})
+	}
+
+	if dryRun || len(changes) == 0 {
+		return result, nil
+	}
+	pending, err := as.Db.CreateDeductionChanges(ctx, changes)
+	if err != nil {
+		return result, err
+	}
+	result.PendingChanges = pending
+	return result, nil
+}
@@ -0,0 +1,128 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestExportConfig(t *testing.T) {
+	t.Run("given deductions in db should export them with min and max amount", func(t *testing.T) {
+		stub := initStubAdminStorer(DbDeductionResult{}, nil)
+		stub.deductions = []models.Deduction{{Slug: "personal", Name: "personalDeduction", Amount: 60_000, MinAmount: 10_000, MaxAmount: 100_000, Version: 2}}
+		service := NewAdminService(&stub)
+
+		got, err := service.ExportConfig(context.Background())
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, models.ConfigExport{Deductions: []models.DeductionConfig{
+			{Slug: "personal", Name: "personalDeduction", Amount: 60_000, MinAmount: 10_000, MaxAmount: 100_000},
+		}}, got)
+	})
+	t.Run("given error from db should return error", func(t *testing.T) {
+		stub := initStubAdminStorer(DbDeductionResult{err: errors.New("error xxx occured")}, nil)
+		service := NewAdminService(&stub)
+
+		_, err := service.ExportConfig(context.Background())
+
+		assertIsEqual(t, stub.getDeductionErr, err, "expect error from db")
+	})
+}
+
+func TestImportConfig(t *testing.T) {
+	initStub := func() *StubAdminStorer {
+		stub := initStubAdminStorer(DbDeductionResult{}, nil)
+		stub.deductions = []models.Deduction{
+			{Slug: "personal", Amount: 60_000, MinAmount: 10_000, MaxAmount: 100_000, Version: 2},
+			{Slug: "k-receipt", Amount: 50_000, MinAmount: 0, MaxAmount: 100_000, Version: 1},
+		}
+		return &stub
+	}
+	config := models.ConfigExport{Deductions: []models.DeductionConfig{
+		{Slug: "personal", Amount: 60_000, MinAmount: 10_000, MaxAmount: 100_000},
+		{Slug: "k-receipt", Amount: 70_000, MinAmount: 0, MaxAmount: 100_000},
+	}}
+	wantChanges := []models.DeductionConfigDiff{{
+		Slug: "k-receipt",
+		From: models.DeductionConfig{Slug: "k-receipt", Amount: 50_000, MaxAmount: 100_000},
+		To:   models.DeductionConfig{Slug: "k-receipt", Amount: 70_000, MaxAmount: 100_000},
+	}}
+
+	t.Run("given dry run should return changed deductions without proposing them", func(t *testing.T) {
+		stub := initStub()
+		service := NewAdminService(stub)
+
+		got, err := service.ImportConfig(context.Background(), config, true, "editor")
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, models.ConfigImportResponse{DryRun: true, Changes: wantChanges, PendingChanges: []models.DeductionChange{}}, got)
+		stub.assertMethodWasNotCalled(t, "CreateDeductionChanges")
+	})
+	t.Run("given config should propose only changed amounts as pending changes on their current version", func(t *testing.T) {
+		stub := initStub()
+		service := NewAdminService(stub)
+
+		got, err := service.ImportConfig(context.Background(), config, false, "editor")
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, wantChanges, got.Changes)
+		want := []models.DeductionChange{{Id: 1, Slug: "k-receipt", Amount: 70_000, Version: 1, Status: models.PendingChange, ProposedBy: "editor"}}
+		assertObjectIsEqual(t, want, stub.proposed)
+		assertObjectIsEqual(t, want, got.PendingChanges)
+		stub.assertMethodCalledTime(t, "CreateDeductionChanges", 1)
+	})
+	t.Run("given config without change should not propose change", func(t *testing.T) {
+		stub := initStub()
+		service := NewAdminService(stub)
+
+		got, err := service.ImportConfig(context.Background(), models.ConfigExport{Deductions: config.Deductions[:1]}, false, "editor")
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 0, len(got.Changes), "expect no change")
+		stub.assertMethodWasNotCalled(t, "CreateDeductionChanges")
+	})
+
+	testSuites := []struct {
+		name string
+		row  models.DeductionConfig
+		want error
+	}{
+		{"given not exist slug should return ErrDeductionInvalid", models.DeductionConfig{Slug: "xxx"}, ErrDeductionInvalid},
+		{"given duplicated slug should return ErrDeductionConfigDuplicated", models.DeductionConfig{Slug: "personal", Amount: 60_000, MinAmount: 10_000}, ErrDeductionConfigDuplicated},
+		{"given limit other than current limit should return ErrDeductionLimitChanged", models.DeductionConfig{Slug: "k-receipt", Amount: 70_000, MaxAmount: 80_000}, ErrDeductionLimitChanged},
+		{"given amount out of current limit should return ErrDeductionAmountInvalid", models.DeductionConfig{Slug: "k-receipt", Amount: 200_000, MaxAmount: 100_000}, ErrDeductionAmountInvalid},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := initStub()
+			service := NewAdminService(stub)
+
+			_, err := service.ImportConfig(context.Background(), models.ConfigExport{Deductions: []models.DeductionConfig{config.Deductions[0], tc.row}}, false, "editor")
+
+			if !errors.Is(err, tc.want) {
+				t.Errorf("expect error %q but got %v", tc.want, err)
+			}
+			stub.assertMethodWasNotCalled(t, "CreateDeductionChanges")
+		})
+	}
+	t.Run("given error on propose should return error without any pending change", func(t *testing.T) {
+		stub := initStub()
+		stub.changeErr = errors.New("error xxx occured")
+		service := NewAdminService(stub)
+		changed := models.ConfigExport{Deductions: []models.DeductionConfig{
+			{Slug: "personal", Amount: 70_000, MinAmount: 10_000, MaxAmount: 100_000},
+			{Slug: "k-receipt", Amount: 70_000, MinAmount: 0, MaxAmount: 100_000},
+		}}
+
+		got, err := service.ImportConfig(context.Background(), changed, false, "editor")
+
+		assertIsEqual(t, stub.changeErr, err, "expect error from db")
+		assertIsEqual(t, 0, len(stub.proposed), "expect no change is proposed")
+		assertIsEqual(t, 0, len(got.PendingChanges), "expect no pending change")
+	})
+}
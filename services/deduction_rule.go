@@ -0,0 +1,123 @@
+package services
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+// DeductionRule compute allowed amount of one deduction from tax request and its config.
+// Config amount is the cap of deduction, 0 mean no limit.
+type DeductionRule interface {
+	Slug() string
+	Allowed(tax models.TaxRequest, config models.Deduction) float64
+}
+
+// DeductionRegistry keep deduction rules by slug in order of registration
+type DeductionRegistry struct {
+	mu    sync.RWMutex
+	rules map[string]DeductionRule
+	slugs []string
+}
+
+func NewDeductionRegistry(rules ...DeductionRule) *DeductionRegistry {
+	registry := &DeductionRegistry{rules: map[string]DeductionRule{}}
+	for _, rule := range rules {
+		registry.Register(rule)
+	}
+	return registry
+}
+
+// Register add rule to registry, rule with same slug is replaced at its position
+func (r *DeductionRegistry) Register(rule DeductionRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rules[rule.Slug()]; !ok {
+		r.slugs = append(r.slugs, rule.Slug())
+	}
+	r.rules[rule.Slug()] = rule
+}
+
+// Replace remove all rules and register new rules
+func (r *DeductionRegistry) Replace(rules ...DeductionRule) {
+	next := NewDeductionRegistry(rules...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules, r.slugs = next.rules, next.slugs
+}
+
+func (r *DeductionRegistry) Get(slug string) (DeductionRule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[slug]
+	return rule, ok
+}
+
+func (r *DeductionRegistry) Rules() []DeductionRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rules := make([]DeductionRule, 0, len(r.slugs))
+	for _, slug := range r.slugs {
+		rules = append(rules, r.rules[slug])
+	}
+	return rules
+}
+
+// RuleSetDeduction is deduction rule that described in rule set
+type RuleSetDeduction struct {
+	models.RuleDeduction
+}
+
+func (r RuleSetDeduction) Slug() string { return r.RuleDeduction.Slug }
+
+func (r RuleSetDeduction) Allowed(tax models.TaxRequest, config models.Deduction) float64 {
+	period := tax.Period
+	if period == "" {
+		period = models.FullYearPeriod
+	}
+	if len(r.Periods) > 0 && !slices.Contains(r.Periods, period) {
+		return 0
+	}
+
+	amount := config.Amount
+	if r.Type == models.AllowanceRuleType {
+		amount = CalculateDeductionByType(r.RuleDeduction.Slug, tax.Allowances, config)
+	}
+	if r.PercentLimit > 0 {
+		amount = min(amount, tax.TotalIncome*r.PercentLimit/100)
+	}
+	if r.HalfOnHalfYear && period == models.HalfYearPeriod {
+		amount = amount / 2
+	}
+	return amount
+}
+
+// familyMember return member that family deduction of rule set is given for, it is empty for other rules
+func familyMember(rule DeductionRule) string {
+	if r, ok := rule.(RuleSetDeduction); ok && r.Type == models.FamilyRuleType {
+		return r.Member
+	}
+	return ""
+}
+
+// AllowanceTypes return slug of rules in DeductionRules that taxpayer claim in allowances of tax request,
+// fixed and family deductions of rule set are given without claim so they are not one of them
+func AllowanceTypes() []string {
+	var types []string
+	for _, rule := range DeductionRules.Rules() {
+		if isAllowanceRule(rule) {
+			types = append(types, rule.Slug())
+		}
+	}
+	return types
+}
+
+// isAllowanceRule tell whether rule deduct amount that taxpayer claim in allowances of tax request
+func isAllowanceRule(rule DeductionRule) bool {
+	r, ok := rule.(RuleSetDeduction)
+	return !ok || r.Type == models.AllowanceRuleType
+}
+
+// DeductionRules is registry that CalculateTaxOutput use to deduct income, it is built from active rule set
+var DeductionRules = NewDeductionRegistry()
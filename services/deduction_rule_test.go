@@ -0,0 +1,133 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/validators"
+)
+
+type StubDeductionRule struct {
+	slug   string
+	amount float64
+}
+
+func (r StubDeductionRule) Slug() string { return r.slug }
+
+func (r StubDeductionRule) Allowed(tax models.TaxRequest, config models.Deduction) float64 {
+	return min(r.amount, config.Amount)
+}
+
+var (
+	personalRule = RuleSetDeduction{models.RuleDeduction{Slug: models.PersonalSlug, Type: models.FixedRuleType, Amount: 60_000, HalfOnHalfYear: true}}
+	donationRule = RuleSetDeduction{models.RuleDeduction{Slug: models.DonationSlug, Type: models.AllowanceRuleType, Amount: 100_000}}
+	kReceiptRule = RuleSetDeduction{models.RuleDeduction{Slug: models.KReceiptSlug, Type: models.AllowanceRuleType, Amount: 50_000}}
+)
+
+func TestDeductionRegistry(t *testing.T) {
+	t.Run("given default registry should keep personal, family allowances, donation and k-receipt in order", func(t *testing.T) {
+		rules := DeductionRules.Rules()
+
+		slugs := []string{}
+		for _, rule := range rules {
+			slugs = append(slugs, rule.Slug())
+		}
+		assertObjectIsEqual(t, []string{
+			models.PersonalSlug, models.SpouseSlug, models.ChildSlug, models.ParentSlug, models.DonationSlug, models.KReceiptSlug,
+		}, slugs)
+	})
+	t.Run("given rule with same slug should replace it at same position", func(t *testing.T) {
+		registry := NewDeductionRegistry(personalRule, donationRule)
+
+		registry.Register(StubDeductionRule{slug: models.PersonalSlug, amount: 1})
+
+		rules := registry.Rules()
+		assertIsEqual(t, 2, len(rules), "expect registry has 2 rules")
+		assertObjectIsEqual(t, StubDeductionRule{slug: models.PersonalSlug, amount: 1}, rules[0])
+	})
+	t.Run("given unknown slug should return not found", func(t *testing.T) {
+		_, ok := DeductionRules.Get("unknown")
+
+		assertIsEqual(t, false, ok, "expect rule is not found")
+	})
+}
+
+func TestAllowanceTypes(t *testing.T) {
+	t.Run("given default rule set should allow donation and k-receipt but not fixed personal", func(t *testing.T) {
+		assertObjectIsEqual(t, []string{models.DonationSlug, models.KReceiptSlug}, AllowanceTypes())
+	})
+	t.Run("given rule set with new allowance should validate tax request with its slug", func(t *testing.T) {
+		restoreDefaultRuleSet(t)
+		ruleSet := ActiveRuleSet()
+		ruleSet.Deductions = []models.RuleDeduction{
+			{Slug: models.PersonalSlug, Type: models.FixedRuleType, Amount: 60_000},
+			{Slug: "provident-fund", Type: models.AllowanceRuleType, Amount: 500_000},
+		}
+		ApplyRuleSet(ruleSet)
+
+		err := validators.ValidateTaxRequest(models.TaxRequest{Allowances: []models.Allowance{{Type: "provident-fund", Amount: 1000}}})
+		assertIsNil(t, err, expectNilErrMsg)
+
+		err = validators.ValidateTaxRequest(models.TaxRequest{Allowances: []models.Allowance{{Type: models.DonationSlug, Amount: 1000}}})
+		if !errors.Is(err, validators.ErrAllowanceTypeInvalid) {
+			t.Errorf("expect error %q but got %v", validators.ErrAllowanceTypeInvalid, err)
+		}
+	})
+}
+
+func TestDeductionRuleAllowed(t *testing.T) {
+	t.Run("fixed rule should allow only half on half-year return", func(t *testing.T) {
+		config := models.Deduction{Slug: models.PersonalSlug, Amount: 60_000}
+
+		assertIsEqual(t, 60_000.0, personalRule.Allowed(models.TaxRequest{}, config), "expect full personal allowance")
+		assertIsEqual(t, 30_000.0, personalRule.Allowed(models.TaxRequest{Period: models.HalfYearPeriod}, config), "expect half personal allowance")
+	})
+	t.Run("allowance rule should sum allowances of its type and limit by config", func(t *testing.T) {
+		tax := models.TaxRequest{Allowances: []models.Allowance{
+			{Type: models.KReceiptSlug, Amount: 30_000},
+			{Type: models.KReceiptSlug, Amount: 40_000},
+			{Type: models.DonationSlug, Amount: 10_000},
+		}}
+
+		got := kReceiptRule.Allowed(tax, models.Deduction{Slug: models.KReceiptSlug, Amount: 50_000})
+
+		assertIsEqual(t, 50_000.0, got, "expect k-receipt is limited to 50,000")
+	})
+	t.Run("percent limit should cap allowance to percent of total income", func(t *testing.T) {
+		rule := RuleSetDeduction{models.RuleDeduction{Slug: models.DonationSlug, Type: models.AllowanceRuleType, PercentLimit: 10}}
+		tax := models.TaxRequest{TotalIncome: 300_000, Allowances: []models.Allowance{{Type: models.DonationSlug, Amount: 50_000}}}
+
+		got := rule.Allowed(tax, models.Deduction{Slug: models.DonationSlug})
+
+		assertIsEqual(t, 30_000.0, got, "expect donation is limited to 10% of income")
+	})
+	t.Run("rule that not eligible for period should allow nothing", func(t *testing.T) {
+		rule := RuleSetDeduction{models.RuleDeduction{Slug: models.KReceiptSlug, Type: models.AllowanceRuleType, Periods: []string{models.FullYearPeriod}}}
+		tax := models.TaxRequest{Period: models.HalfYearPeriod, Allowances: []models.Allowance{{Type: models.KReceiptSlug, Amount: 10_000}}}
+
+		got := rule.Allowed(tax, models.Deduction{Slug: models.KReceiptSlug})
+
+		assertIsEqual(t, 0.0, got, "expect k-receipt is not allowed on half-year return")
+	})
+}
+
+func TestCalculateTaxOutputWithCustomRule(t *testing.T) {
+	original := DeductionRules.Rules()
+	DeductionRules.Register(StubDeductionRule{slug: "custom", amount: 100_000})
+	t.Cleanup(func() { DeductionRules.Replace(original...) })
+
+	result := CalculateTaxOutput(TaxInput{
+		tax: models.TaxRequest{TotalIncome: 500_000},
+		deductions: map[string]models.Deduction{
+			models.PersonalSlug: {Slug: models.PersonalSlug, Amount: 60_000},
+			"custom":            {Slug: "custom", Amount: 40_000},
+		},
+	})
+
+	// net income 500,000 - 60,000 - 40,000 = 400,000
+	assertIsEqual(t, 25_000.0, result.Tax, expectTaxValueMsg(25_000, result.Tax))
+}
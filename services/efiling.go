@@ -0,0 +1,253 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+)
+
+type EFilingService struct {
+	Db TaxStorer
+}
+
+func NewEFilingService(db TaxStorer) *EFilingService {
+	return &EFilingService{
+		Db: db,
+	}
+}
+
+type efilingField struct {
+	name   string
+	width  int
+	text   func(r models.EFilingRecord) string
+	amount func(r models.EFilingRecord) float64
+}
+
+// efilingLayout is fixed-width layout of each record line in e-filing import file, every field of
+// models.EFilingRecord is written in its order. It is the layout that our accountants import and it is not
+// checked against RD PND 90/91 record specification, so compare it with the specification before
+// the file is submitted to RD.
+//
+//	position  width  field
+//	1-5       5      FormType, PND90 or PND91
+//	6-9       4      TaxYear in Buddhist era
+//	10-22     13     NationalId
+//	23-122    100    Name
+//	123-317   15     each of Income, PersonalAllowance, SpouseAllowance, FamilyAllowance, Donation,
+//	                 KReceipt, OtherAllowance, NetIncome, TaxOnNetIncome, Wht, PrepaidTax, Tax, TaxRefund
+//	318-319   2      CRLF
+//
+// Width is counted in bytes of the file that is written in models.EFilingEncoding, so Thai text takes
+// one byte per character like Latin text. Text is padded right with space and amount is written
+// in satang padded left with zero.
+var efilingLayout []efilingField = []efilingField{
+	{name: "FormType", width: 5, text: func(r models.EFilingRecord) string { return r.FormType }},
+	{name: "TaxYear", width: 4, text: func(r models.EFilingRecord) string { return strconv.Itoa(r.TaxYear) }},
+	{name: "NationalId", width: 13, text: func(r models.EFilingRecord) string { return r.NationalId }},
+	{name: "Name", width: 100, text: func(r models.EFilingRecord) string { return r.Name }},
+	{name: "Income", width: 15, amount: func(r models.EFilingRecord) float64 { return r.Income }},
+	{name: "PersonalAllowance", width: 15, amount: func(r models.EFilingRecord) float64 { return r.PersonalAllowance }},
+	{name: "SpouseAllowance", width: 15, amount: func(r models.EFilingRecord) float64 { return r.SpouseAllowance }},
+	{name: "FamilyAllowance", width: 15, amount: func(r models.EFilingRecord) float64 { return r.FamilyAllowance }},
+	{name: "Donation", width: 15, amount: func(r models.EFilingRecord) float64 { return r.Donation }},
+	{name: "KReceipt", width: 15, amount: func(r models.EFilingRecord) float64 { return r.KReceipt }},
+	{name: "OtherAllowance", width: 15, amount: func(r models.EFilingRecord) float64 { return r.OtherAllowance }},
+	{name: "NetIncome", width: 15, amount: func(r models.EFilingRecord) float64 { return r.NetIncome }},
+	{name: "TaxOnNetIncome", width: 15, amount: func(r models.EFilingRecord) float64 { return r.TaxOnNetIncome }},
+	{name: "Wht", width: 15, amount: func(r models.EFilingRecord) float64 { return r.Wht }},
+	{name: "PrepaidTax", width: 15, amount: func(r models.EFilingRecord) float64 { return r.PrepaidTax }},
+	{name: "Tax", width: 15, amount: func(r models.EFilingRecord) float64 { return r.Tax }},
+	{name: "TaxRefund", width: 15, amount: func(r models.EFilingRecord) float64 { return r.TaxRefund }},
+}
+
+// EFilingFormType return PND 91 for salary only income and PND 90 for other income
+func EFilingFormType(tax models.TaxRequest) string {
+	if tax.IncomeType == "" || tax.IncomeType == models.SalaryIncome {
+		return models.PND91Form
+	}
+	return models.PND90Form
+}
+
+// EFilingRecord calculate tax return and map income, allowances and wht to form lines.
+// Allowance lines and net income are taken from the same calculation as tax.
+// Taxpayer profile is required for name and family allowances.
+func (es *EFilingService) EFilingRecord(ctx context.Context, req models.TaxReturnRequest) (models.EFilingRecord, error) {
+	tax, _, err := NewTaxService(es.Db).ConvertToThb(ctx, req.Tax)
+	if err != nil {
+		return models.EFilingRecord{}, err
+	}
+	taxpayer, err := es.Db.GetTaxpayer(ctx, req.TaxpayerId)
+	if err != nil {
+		return models.EFilingRecord{}, taxpayerNotFound(err)
+	}
+	ds, err := es.Db.GetDeductions(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return models.EFilingRecord{}, err
+	}
+
+	input := newTaxInput(tax, ds)
+	input.members = FamilyMembers(taxpayer)
+	input.ruleSet, input.rules = activeRules()
+	output, lines := calculateTax(input)
+	record := models.EFilingRecord{
+		FormType:          EFilingFormType(tax),
+		TaxYear:           req.TaxYear,
+		NationalId:        taxpayer.NationalId,
+		Name:              taxpayer.Name,
+		Income:            tax.TotalIncome,
+		PersonalAllowance: lines.allowances[models.PersonalSlug],
+		SpouseAllowance:   lines.spouse,
+		FamilyAllowance:   lines.family,
+		Donation:          lines.allowances[models.DonationSlug],
+		KReceipt:          lines.allowances[models.KReceiptSlug],
+		NetIncome:         math.Max(0, lines.netIncome),
+		Wht:               tax.Wht,
+		PrepaidTax:        tax.PrepaidTax,
+		Tax:               output.Tax,
+		TaxRefund:         output.TaxRefund,
+	}
+	for _, rule := range input.rules {
+		switch rule.Slug() {
+		case models.PersonalSlug, models.DonationSlug, models.KReceiptSlug:
+		default:
+			if familyMember(rule) == "" {
+				record.OtherAllowance += lines.allowances[rule.Slug()]
+			}
+		}
+	}
+	for _, v := range output.TaxLevel {
+		record.TaxOnNetIncome += v.Tax
+	}
+
+	if err := validators.ValidateEFilingRecord(record); err != nil {
+		return record, fmt.Errorf("%w: %w", utils.ErrEFilingRecordInvalid, err)
+	}
+	return record, nil
+}
+
+func (es *EFilingService) EFilingRecords(ctx context.Context, returns []models.TaxReturnRequest) ([]models.EFilingRecord, error) {
+	records := []models.EFilingRecord{}
+	for i, v := range returns {
+		record, err := es.EFilingRecord(ctx, v)
+		if err != nil {
+			return nil, fmt.Errorf("return %d: %w", i+1, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// formatEFilingField encode field of record in models.EFilingEncoding and pad it to field width in bytes
+func formatEFilingField(field efilingField, r models.EFilingRecord) ([]byte, error) {
+	if field.amount != nil {
+		satang := int64(math.Round(field.amount(r) * 100))
+		return []byte(fmt.Sprintf("%0*d", field.width, satang)), nil
+	}
+	text, err := models.EFilingEncoding.NewEncoder().Bytes([]byte(field.text(r)))
+	if err != nil {
+		return nil, err
+	}
+	if len(text) > field.width {
+		text = text[:field.width]
+	}
+	return append(text, bytes.Repeat([]byte(" "), field.width-len(text))...), nil
+}
+
+// WriteEFiling write records in fixed-width (one record per line with CRLF) in models.EFilingEncoding
+// or xml layout in UTF-8
+func (es *EFilingService) WriteEFiling(w io.Writer, records []models.EFilingRecord, format string) error {
+	if format == models.EFilingXml {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		encoder := xml.NewEncoder(w)
+		encoder.Indent("", "  ")
+		return encoder.Encode(models.EFilingFile{Records: records})
+	}
+	for _, r := range records {
+		var line bytes.Buffer
+		for _, field := range efilingLayout {
+			text, err := formatEFilingField(field, r)
+			if err != nil {
+				return err
+			}
+			line.Write(text)
+		}
+		line.WriteString("\r\n")
+		if _, err := w.Write(line.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExtractEFilingCsv read tax csv that also has taxpayerId and taxYear column of each row
+func (es *EFilingService) ExtractEFilingCsv(reader io.Reader) ([]models.TaxReturnRequest, error) {
+	returns := []models.TaxReturnRequest{}
+	csvReader := csv.NewReader(reader)
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, utils.ErrCsvHeaderMissing
+	}
+	header := rows[0]
+	if !validators.IsAllStringInArray(header, []string{"taxpayerId", "taxYear", "totalIncome", "wht", "donation"}) {
+		return nil, utils.ErrCsvHeaderMissing
+	}
+	for _, row := range rows[1:] {
+		var taxReturn models.TaxReturnRequest
+		var tax models.TaxCsv
+		for idx, col := range row {
+			switch header[idx] {
+			case "taxpayerId":
+				taxReturn.TaxpayerId = col
+				continue
+			case "taxYear":
+				year, err := strconv.Atoi(col)
+				if err != nil {
+					return nil, validators.ErrTaxYearInvalid
+				}
+				taxReturn.TaxYear = year
+				continue
+			case "totalIncome", "wht", "donation", "k-receipt":
+			default:
+				continue
+			}
+			if col == "" {
+				return nil, utils.ErrCsvValueEmpty
+			}
+			val, err := strconv.ParseFloat(col, 64)
+			if err != nil {
+				return nil, err
+			}
+			switch header[idx] {
+			case "totalIncome":
+				tax.TotalIncome = val
+			case "wht":
+				tax.Wht = val
+			case "donation":
+				tax.Donation = val
+			case "k-receipt":
+				tax.KReceipt = val
+			}
+		}
+		if err := validators.ValidateTaxCsv(tax); err != nil {
+			return nil, err
+		}
+		taxReturn.Tax = TransformTaxCsvToTaxRequest(tax)
+		returns = append(returns, taxReturn)
+	}
+	return returns, nil
+}
@@ -0,0 +1,266 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+func initEFilingStub() StubTaxStore {
+	stub := initStub([]models.Deduction{
+		{Slug: models.PersonalSlug, Amount: 60_000},
+		{Slug: models.DonationSlug, Amount: 100_000},
+		{Slug: models.KReceiptSlug, Amount: 50_000},
+		{Slug: models.SpouseSlug, Amount: 60_000},
+		{Slug: models.ChildSlug, Amount: 30_000},
+	}, nil)
+	stub.taxpayers = map[string]models.Taxpayer{
+		"1234567890121": {NationalId: "1234567890121", Name: "สมชาย ใจดี", MaritalStatus: models.MarriedStatus, Children: 1},
+		"3100600123450": {NationalId: "3100600123450", MaritalStatus: models.SingleStatus},
+	}
+	return stub
+}
+
+var efilingRecord = models.EFilingRecord{
+	FormType:          models.PND91Form,
+	TaxYear:           2567,
+	NationalId:        "1234567890121",
+	Name:              "สมชาย ใจดี",
+	Income:            500_000,
+	PersonalAllowance: 60_000,
+	SpouseAllowance:   60_000,
+	FamilyAllowance:   30_000,
+	Donation:          100_000,
+	NetIncome:         250_000,
+	TaxOnNetIncome:    10_000,
+	Wht:               10_000,
+	TaxRefund:         0,
+	Tax:               0,
+}
+
+// efilingSample is record of testdata/efiling-sample.txt, which is written byte by byte from layout table of efilingLayout
+var efilingSample = models.EFilingRecord{
+	FormType:          models.PND90Form,
+	TaxYear:           2567,
+	NationalId:        "1234567890121",
+	Name:              "สมชาย ใจดี",
+	Income:            800_000,
+	PersonalAllowance: 60_000,
+	SpouseAllowance:   60_000,
+	FamilyAllowance:   30_000,
+	Donation:          20_000,
+	KReceipt:          50_000,
+	OtherAllowance:    75_000,
+	NetIncome:         505_000,
+	TaxOnNetIncome:    35_750,
+	Wht:               20_000,
+	PrepaidTax:        10_000.25,
+	Tax:               5_749.75,
+}
+
+func TestEFilingRecord(t *testing.T) {
+	t.Run("given salary return should map income, allowances and wht to PND 91 lines", func(t *testing.T) {
+		stub := initEFilingStub()
+		service := NewEFilingService(&stub)
+
+		got, err := service.EFilingRecord(context.Background(), models.TaxReturnRequest{
+			TaxpayerId: "1234567890121",
+			TaxYear:    2567,
+			Tax: models.TaxRequest{
+				TotalIncome: 500_000,
+				Wht:         10_000,
+				Allowances:  []models.Allowance{{Type: models.DonationSlug, Amount: 150_000}},
+			},
+		})
+
+		// net income 250,000 should have tax 10,000 that is already paid by wht
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, efilingRecord, got)
+	})
+	t.Run("given rule set deduction without form line should take net income from calculation", func(t *testing.T) {
+		restoreDefaultRuleSet(t)
+		ruleSet := ActiveRuleSet()
+		ruleSet.Deductions = append(slices.Clone(ruleSet.Deductions), models.RuleDeduction{
+			Slug: "provident-fund", Type: models.AllowanceRuleType, Amount: 500_000, PercentLimit: 15,
+		})
+		ApplyRuleSet(ruleSet)
+		stub := initEFilingStub()
+		service := NewEFilingService(&stub)
+
+		got, err := service.EFilingRecord(context.Background(), models.TaxReturnRequest{
+			TaxpayerId: "1234567890121",
+			TaxYear:    2567,
+			Tax: models.TaxRequest{
+				TotalIncome: 500_000,
+				Wht:         10_000,
+				Allowances:  []models.Allowance{{Type: "provident-fund", Amount: 100_000}},
+			},
+		})
+
+		// provident fund is limited to 15% of income, 500,000 - 60,000 - 60,000 - 30,000 - 75,000 = 275,000
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 275_000.0, got.NetIncome, "expect net income that tax is calculated from")
+		assertIsEqual(t, 75_000.0, got.OtherAllowance, "expect provident fund in other allowance line")
+		assertIsEqual(t, 0.0, got.Donation, "expect no donation line")
+		assertIsEqual(t, 2_500.0, got.Tax, expectTaxValueMsg(2_500, got.Tax))
+	})
+	t.Run("given prepaid tax should map it to its line and take it from tax", func(t *testing.T) {
+		stub := initEFilingStub()
+		service := NewEFilingService(&stub)
+
+		got, err := service.EFilingRecord(context.Background(), models.TaxReturnRequest{
+			TaxpayerId: "1234567890121",
+			TaxYear:    2567,
+			Tax:        models.TaxRequest{TotalIncome: 500_000, Wht: 5_000, PrepaidTax: 2_000, IncomeType: models.RentalIncome},
+		})
+
+		// 500,000 - 60,000 - 60,000 - 30,000 = 350,000 has tax 20,000
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, models.PND90Form, got.FormType, "expect PND90 form")
+		assertIsEqual(t, 20_000.0, got.TaxOnNetIncome, "expect tax on net income")
+		assertIsEqual(t, 2_000.0, got.PrepaidTax, "expect prepaid tax line")
+		assertIsEqual(t, 13_000.0, got.Tax, expectTaxValueMsg(13_000, got.Tax))
+	})
+	t.Run("given other income type should use PND 90 form", func(t *testing.T) {
+		assertIsEqual(t, models.PND90Form, EFilingFormType(models.TaxRequest{IncomeType: models.RentalIncome}), "expect PND90 form")
+	})
+	t.Run("given taxpayer without name should reject record", func(t *testing.T) {
+		stub := initEFilingStub()
+		service := NewEFilingService(&stub)
+
+		_, err := service.EFilingRecord(context.Background(), models.TaxReturnRequest{TaxpayerId: "3100600123450", TaxYear: 2567})
+
+		if !errors.Is(err, utils.ErrEFilingRecordInvalid) {
+			t.Errorf("expect error %q but got %v", utils.ErrEFilingRecordInvalid, err)
+		}
+	})
+	t.Run("given not exist taxpayer should return not found error", func(t *testing.T) {
+		stub := initEFilingStub()
+		service := NewEFilingService(&stub)
+
+		_, err := service.EFilingRecords(context.Background(), []models.TaxReturnRequest{{TaxpayerId: "1101700203000", TaxYear: 2567}})
+
+		if !errors.Is(err, utils.ErrTaxpayerNotFound) {
+			t.Errorf("expect error %q but got %v", utils.ErrTaxpayerNotFound, err)
+		}
+	})
+}
+
+func TestWriteEFiling(t *testing.T) {
+	service := NewEFilingService(nil)
+	t.Run("given sample record should write sample file byte by byte", func(t *testing.T) {
+		want, err := os.ReadFile("../testdata/efiling-sample.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+
+		err = service.WriteEFiling(&buf, []models.EFilingRecord{efilingSample}, models.EFilingFixedWidth)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		got := buf.Bytes()
+		assertIsEqual(t, len(want), len(got), "expect line width in bytes")
+		start := 0
+		for _, field := range efilingLayout {
+			end := start + field.width
+			if end > len(got) || !bytes.Equal(want[start:end], got[start:end]) {
+				t.Errorf("expect %s at byte %d-%d is %q but got %q", field.name, start+1, end, want[start:end], got[start:min(end, len(got))])
+			}
+			start = end
+		}
+		if !bytes.Equal(want, got) {
+			t.Errorf("expect sample file %q but got %q", want, got)
+		}
+	})
+	t.Run("given fixed-width format should write padded line per record", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := service.WriteEFiling(&buf, []models.EFilingRecord{efilingRecord}, models.EFilingFixedWidth)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		name, _ := models.EFilingEncoding.NewEncoder().String("สมชาย ใจดี")
+		want := "PND912567" + "1234567890121" + name + strings.Repeat(" ", 90) +
+			"000000050000000" + "000000006000000" + "000000006000000" + "000000003000000" + "000000010000000" +
+			"000000000000000" + "000000000000000" + "000000025000000" + "000000001000000" + "000000001000000" +
+			"000000000000000" + "000000000000000" + "000000000000000" + "\r\n"
+		assertIsEqual(t, want, buf.String(), "expect fixed-width line should be equal")
+		// every line has the same width in bytes whatever characters of name
+		assertIsEqual(t, 5+4+13+100+15*13+2, buf.Len(), "expect line width in bytes")
+	})
+	t.Run("given name longer than field should cut it at field width in bytes", func(t *testing.T) {
+		var buf bytes.Buffer
+		record := efilingRecord
+		record.Name = strings.Repeat("ก", 120)
+
+		err := service.WriteEFiling(&buf, []models.EFilingRecord{record}, models.EFilingFixedWidth)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 5+4+13+100+15*13+2, buf.Len(), "expect line width in bytes")
+	})
+	t.Run("given name out of TIS-620 should return error", func(t *testing.T) {
+		var buf bytes.Buffer
+		record := efilingRecord
+		record.Name = "王小明"
+
+		err := service.WriteEFiling(&buf, []models.EFilingRecord{record}, models.EFilingFixedWidth)
+
+		if err == nil {
+			t.Error("expect error of character that is not in TIS-620")
+		}
+	})
+	t.Run("given xml format should write EFiling document", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := service.WriteEFiling(&buf, []models.EFilingRecord{efilingRecord}, models.EFilingXml)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		for _, want := range []string{"<?xml", "<EFiling>", "<Return>", "<FormType>PND91</FormType>", "<NetIncome>250000</NetIncome>", "<PrepaidTax>0</PrepaidTax>"} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("expect xml contains %q but got %s", want, buf.String())
+			}
+		}
+	})
+}
+
+func TestExtractEFilingCsv(t *testing.T) {
+	service := NewEFilingService(nil)
+	t.Run("when csv is empty should return error 'missing required header field'", func(t *testing.T) {
+		_, err := service.ExtractEFilingCsv(strings.NewReader(""))
+
+		assertObjectIsEqual(t, utils.ErrCsvHeaderMissing, err)
+	})
+	t.Run("given valid csv should return tax returns", func(t *testing.T) {
+		dir, _ := os.Getwd()
+		file, err := os.Open(filepath.Join(dir, "../testdata/valid-efiling.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		got, err := service.ExtractEFilingCsv(file)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 2, len(got), "expect 2 returns")
+		assertIsEqual(t, "3100600123450", got[1].TaxpayerId, "expect taxpayer id of second row")
+		assertIsEqual(t, 2567, got[1].TaxYear, "expect tax year of second row")
+		assertIsEqual(t, 40_000.0, got[1].Tax.Wht, "expect wht of second row")
+	})
+	t.Run("given csv without taxpayer id column should return error", func(t *testing.T) {
+		_, err := service.ExtractEFilingCsv(strings.NewReader("totalIncome,wht,donation\n500000,0,0\n"))
+
+		if err == nil {
+			t.Error("expect missing header error")
+		}
+	})
+}
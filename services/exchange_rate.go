@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+)
+
+type ExchangeRateService struct {
+	Db ExchangeRateStorer
+}
+
+type ExchangeRateStorer interface {
+	SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) error
+}
+
+func NewExchangeRateService(db ExchangeRateStorer) *ExchangeRateService {
+	return &ExchangeRateService{
+		Db: db,
+	}
+}
+
+func isForeignCurrency(currency string) bool {
+	return currency != "" && currency != models.ThbCurrency
+}
+
+// ConvertToThb convert total income, wht and allowances that are in foreign currency to THB
+// with latest rate on or before rate date of request (today when not send), and return detail of each conversion.
+func (ts *TaxService) ConvertToThb(ctx context.Context, tax models.TaxRequest) (models.TaxRequest, []models.ConvertedAmount, error) {
+	var converted []models.ConvertedAmount
+	rateDate := tax.RateDate
+	if rateDate == "" {
+		rateDate = time.Now().Format(models.DateLayout)
+	}
+	rates := map[string]models.ExchangeRate{}
+	convert := func(field, currency string, amount float64) (float64, error) {
+		if !isForeignCurrency(currency) {
+			return amount, nil
+		}
+		rate, ok := rates[currency]
+		if !ok {
+			var err error
+			rate, err = ts.Db.GetExchangeRate(ctx, currency, rateDate)
+			if err == sql.ErrNoRows {
+				return 0, fmt.Errorf("%w: %s on %s", utils.ErrExchangeRateNotFound, currency, rateDate)
+			}
+			if err != nil {
+				return 0, err
+			}
+			rates[currency] = rate
+		}
+		thb := math.Round(amount*rate.Rate*100) / 100
+		converted = append(converted, models.ConvertedAmount{
+			Field:     field,
+			Currency:  currency,
+			Amount:    amount,
+			Rate:      rate.Rate,
+			RateDate:  rate.RateDate,
+			AmountThb: thb,
+		})
+		return thb, nil
+	}
+
+	var err error
+	if tax.TotalIncome, err = convert("totalIncome", tax.Currency, tax.TotalIncome); err != nil {
+		return tax, nil, err
+	}
+	if tax.Wht > 0 {
+		if tax.Wht, err = convert("wht", tax.Currency, tax.Wht); err != nil {
+			return tax, nil, err
+		}
+	}
+	allowances := make([]models.Allowance, len(tax.Allowances))
+	for i, v := range tax.Allowances {
+		currency := v.Currency
+		if currency == "" {
+			currency = tax.Currency
+		}
+		if v.Amount, err = convert(fmt.Sprintf("allowances[%d]", i), currency, v.Amount); err != nil {
+			return tax, nil, err
+		}
+		v.Currency = ""
+		allowances[i] = v
+	}
+	if tax.Allowances != nil {
+		tax.Allowances = allowances
+	}
+	tax.Currency = ""
+	return tax, converted, nil
+}
+
+// ConvertedRequest return tax request with amounts in THB that ConvertToThb converted it to
+func ConvertedRequest(tax models.TaxRequest, converted []models.ConvertedAmount) models.TaxRequest {
+	tax.Allowances = slices.Clone(tax.Allowances)
+	for _, v := range converted {
+		switch v.Field {
+		case "totalIncome":
+			tax.TotalIncome = v.AmountThb
+		case "wht":
+			tax.Wht = v.AmountThb
+		default:
+			index, ok := strings.CutPrefix(v.Field, "allowances[")
+			if i, err := strconv.Atoi(strings.TrimSuffix(index, "]")); ok && err == nil && i < len(tax.Allowances) {
+				tax.Allowances[i].Amount = v.AmountThb
+			}
+		}
+	}
+	for i := range tax.Allowances {
+		tax.Allowances[i].Currency = ""
+	}
+	tax.Currency = ""
+	return tax
+}
+
+func (es *ExchangeRateService) SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) (models.ExchangeRateResponse, error) {
+	if err := es.Db.SaveExchangeRates(ctx, rates); err != nil {
+		return models.ExchangeRateResponse{}, err
+	}
+	return models.ExchangeRateResponse{Saved: len(rates)}, nil
+}
+
+// ExtractExchangeRateCsv read daily rates in format of Bank of Thailand reference rate (date, currency, rate)
+func (es *ExchangeRateService) ExtractExchangeRateCsv(reader io.Reader) ([]models.ExchangeRate, error) {
+	rates := []models.ExchangeRate{}
+	csvReader := csv.NewReader(reader)
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, utils.ErrCsvHeaderMissing
+	}
+	header := rows[0]
+	if !validators.IsAllStringInArray(header, []string{"date", "currency", "rate"}) {
+		return nil, utils.ErrCsvHeaderMissing
+	}
+	for _, row := range rows[1:] {
+		var rate models.ExchangeRate
+		for idx, col := range row {
+			switch header[idx] {
+			case "date":
+				rate.RateDate = col
+			case "currency":
+				rate.Currency = strings.ToUpper(col)
+			case "rate":
+				if col == "" {
+					return nil, utils.ErrCsvValueEmpty
+				}
+				val, err := strconv.ParseFloat(col, 64)
+				if err != nil {
+					return nil, err
+				}
+				rate.Rate = val
+			}
+		}
+		if err := validators.ValidateExchangeRate(rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
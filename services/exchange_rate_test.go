@@ -0,0 +1,185 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+type StubExchangeRateStorer struct {
+	saved []models.ExchangeRate
+	err   error
+}
+
+func (s *StubExchangeRateStorer) SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) error {
+	s.saved = rates
+	return s.err
+}
+
+func TestConvertToThb(t *testing.T) {
+	usd := models.ExchangeRate{Currency: "USD", Rate: 35, RateDate: "2024-12-30"}
+	t.Run("given THB request should not get exchange rate", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		s := NewTaxService(&stub)
+		tax := models.TaxRequest{TotalIncome: 500_000, Currency: models.ThbCurrency}
+
+		result, converted, err := s.ConvertToThb(context.Background(), tax)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		if stub.expectToCall["GetExchangeRate"] {
+			t.Error("expect GetExchangeRate was not called")
+		}
+		assertIsEqual(t, 500_000.0, result.TotalIncome, "expect total income should not change")
+		if converted != nil {
+			t.Errorf("expect no converted amount but got %#v", converted)
+		}
+	})
+	t.Run("given foreign currency should convert income, wht and allowances with one rate lookup", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.exchangeRates = map[string]models.ExchangeRate{"USD": usd}
+		s := NewTaxService(&stub)
+		tax := models.TaxRequest{
+			TotalIncome: 20_000,
+			Wht:         500,
+			Currency:    "USD",
+			RateDate:    "2024-12-31",
+			Allowances: []models.Allowance{
+				{Type: models.DonationSlug, Amount: 100},
+				{Type: models.KReceiptSlug, Amount: 10_000, Currency: models.ThbCurrency},
+			},
+		}
+
+		result, converted, err := s.ConvertToThb(context.Background(), tax)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodCalledTime(t, "GetExchangeRate", 1)
+		assertObjectIsEqual(t, models.TaxRequest{
+			TotalIncome: 700_000,
+			Wht:         17_500,
+			RateDate:    "2024-12-31",
+			Allowances: []models.Allowance{
+				{Type: models.DonationSlug, Amount: 3_500},
+				{Type: models.KReceiptSlug, Amount: 10_000},
+			},
+		}, result)
+		assertObjectIsEqual(t, []models.ConvertedAmount{
+			{Field: "totalIncome", Currency: "USD", Amount: 20_000, Rate: 35, RateDate: "2024-12-30", AmountThb: 700_000},
+			{Field: "wht", Currency: "USD", Amount: 500, Rate: 35, RateDate: "2024-12-30", AmountThb: 17_500},
+			{Field: "allowances[0]", Currency: "USD", Amount: 100, Rate: 35, RateDate: "2024-12-30", AmountThb: 3_500},
+		}, converted)
+	})
+	t.Run("given currency without rate should return ErrExchangeRateNotFound", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		s := NewTaxService(&stub)
+
+		_, _, err := s.ConvertToThb(context.Background(), models.TaxRequest{TotalIncome: 1_000, Currency: "EUR", RateDate: "2024-12-30"})
+
+		if !errors.Is(err, utils.ErrExchangeRateNotFound) {
+			t.Errorf("expect ErrExchangeRateNotFound but got %v", err)
+		}
+	})
+	t.Run("given foreign income should calculate tax from converted amount", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.exchangeRates = map[string]models.ExchangeRate{"USD": usd}
+		s := NewTaxService(&stub)
+
+		result, err := s.TaxCalculate(context.Background(), models.TaxRequest{TotalIncome: 20_000, Currency: "USD"})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		// 700,000 - 60,000 = 640,000
+		assertIsEqual(t, 56_000.0, result.Tax, expectTaxValueMsg(56_000, result.Tax))
+		if len(result.ExchangeRates) != 1 {
+			t.Errorf("expect response show 1 converted amount but got %d", len(result.ExchangeRates))
+		}
+	})
+}
+
+func TestConvertedRequest(t *testing.T) {
+	tax := models.TaxRequest{
+		TotalIncome: 20_000,
+		Wht:         1_000,
+		Currency:    "USD",
+		Allowances:  []models.Allowance{{Type: models.DonationSlug, Amount: 100, Currency: "USD"}, {Type: models.KReceiptSlug, Amount: 5_000}},
+	}
+
+	got := ConvertedRequest(tax, []models.ConvertedAmount{
+		{Field: "totalIncome", Currency: "USD", Amount: 20_000, Rate: 35, AmountThb: 700_000},
+		{Field: "wht", Currency: "USD", Amount: 1_000, Rate: 35, AmountThb: 35_000},
+		{Field: "allowances[0]", Currency: "USD", Amount: 100, Rate: 35, AmountThb: 3_500},
+	})
+
+	assertObjectIsEqual(t, models.TaxRequest{
+		TotalIncome: 700_000,
+		Wht:         35_000,
+		Allowances:  []models.Allowance{{Type: models.DonationSlug, Amount: 3_500}, {Type: models.KReceiptSlug, Amount: 5_000}},
+	}, got)
+	assertIsEqual(t, 100.0, tax.Allowances[0].Amount, "expect request was not modified")
+}
+
+func TestFuncExtractExchangeRateCsv(t *testing.T) {
+	s := NewExchangeRateService(&StubExchangeRateStorer{})
+	t.Run("when csv is empty should return error 'missing required header field'", func(t *testing.T) {
+		_, err := s.ExtractExchangeRateCsv(strings.NewReader(""))
+
+		assertObjectIsEqual(t, utils.ErrCsvHeaderMissing, err)
+	})
+	t.Run("when csv is missing required colum should return error 'missing required header field'", func(t *testing.T) {
+		dir, _ := os.Getwd()
+		fileData, err := os.Open(filepath.Join(dir, "../testdata/valid-taxes.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fileData.Close()
+
+		_, err = s.ExtractExchangeRateCsv(fileData)
+
+		assertObjectIsEqual(t, errors.New("missing required header field"), err)
+	})
+	t.Run("when valid csv should return rates with upper case currency", func(t *testing.T) {
+		dir, _ := os.Getwd()
+		fileData, err := os.Open(filepath.Join(dir, "../testdata/exchange-rates.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fileData.Close()
+
+		result, err := s.ExtractExchangeRateCsv(fileData)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, []models.ExchangeRate{
+			{Currency: "USD", Rate: 34.1234, RateDate: "2024-12-30"},
+			{Currency: "JPY", Rate: 0.2175, RateDate: "2024-12-30"},
+		}, result)
+	})
+}
+
+func TestSaveExchangeRates(t *testing.T) {
+	t.Run("given error from db should return error", func(t *testing.T) {
+		stub := &StubExchangeRateStorer{err: errors.New("error 'xxx' occured")}
+		s := NewExchangeRateService(stub)
+
+		_, err := s.SaveExchangeRates(context.Background(), []models.ExchangeRate{{Currency: "USD", Rate: 35, RateDate: "2024-12-30"}})
+
+		assertIsEqual(t, stub.err, err, "expect error from db")
+	})
+	t.Run("given rates should return number of saved rates", func(t *testing.T) {
+		stub := &StubExchangeRateStorer{}
+		s := NewExchangeRateService(stub)
+		rates := []models.ExchangeRate{{Currency: "USD", Rate: 35, RateDate: "2024-12-30"}}
+
+		result, err := s.SaveExchangeRates(context.Background(), rates)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, models.ExchangeRateResponse{Saved: 1}, result)
+		assertObjectIsEqual(t, rates, stub.saved)
+	})
+}
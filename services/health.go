@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+var (
+	ErrShuttingDown = errors.New("server is shutting down")
+	// ErrUnavailable replace storage error in public readiness, which can tell database host and driver
+	ErrUnavailable = errors.New("unavailable")
+)
+
+type HealthService struct {
+	Db           HealthStorer
+	shuttingDown atomic.Bool
+}
+
+type HealthStorer interface {
+	Ping(ctx context.Context) error
+	// PendingMigrations return version of migrations that are not applied to storage yet
+	PendingMigrations(ctx context.Context) ([]int, error)
+	GetDeductions(ctx context.Context) ([]models.Deduction, error)
+}
+
+func NewHealthService(db HealthStorer) *HealthService {
+	return &HealthService{
+		Db: db,
+	}
+}
+
+// Shutdown mark service as not ready, so load balancers stop sending requests before server is shut down
+func (hs *HealthService) Shutdown() {
+	hs.shuttingDown.Store(true)
+}
+
+// Readiness check every component that api need to serve requests
+func (hs *HealthService) Readiness(ctx context.Context) models.Readiness {
+	readiness := models.Readiness{
+		Status: models.StatusReady,
+		Components: map[string]models.ComponentHealth{
+			"server":     hs.checkServer(),
+			"database":   componentHealth(unavailable("database", hs.Db.Ping(ctx))),
+			"migrations": componentHealth(hs.checkMigrations(ctx)),
+			"deductions": componentHealth(hs.checkDeductions(ctx)),
+		},
+	}
+	for _, component := range readiness.Components {
+		if component.Status != models.ComponentUp {
+			readiness.Status = models.StatusNotReady
+		}
+	}
+	return readiness
+}
+
+func (hs *HealthService) checkServer() models.ComponentHealth {
+	if hs.shuttingDown.Load() {
+		return componentHealth(ErrShuttingDown)
+	}
+	return componentHealth(nil)
+}
+
+func (hs *HealthService) checkMigrations(ctx context.Context) error {
+	pending, err := hs.Db.PendingMigrations(ctx)
+	if err != nil {
+		return unavailable("migrations", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("migrations %v are not applied", pending)
+	}
+	return nil
+}
+
+func (hs *HealthService) checkDeductions(ctx context.Context) error {
+	deductions, err := hs.Db.GetDeductions(ctx)
+	if err != nil {
+		return unavailable("deductions", err)
+	}
+	if len(deductions) == 0 {
+		return errors.New("no deduction config")
+	}
+	return nil
+}
+
+// unavailable log storage error of component and return ErrUnavailable instead
+func unavailable(component string, err error) error {
+	if err == nil {
+		return nil
+	}
+	log.Printf("readiness: %s is unavailable: %v", component, err)
+	return ErrUnavailable
+}
+
+func componentHealth(err error) models.ComponentHealth {
+	if err != nil {
+		return models.ComponentHealth{Status: models.ComponentDown, Error: err.Error()}
+	}
+	return models.ComponentHealth{Status: models.ComponentUp}
+}
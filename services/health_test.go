@@ -0,0 +1,99 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+type StubHealthStorer struct {
+	pingErr       error
+	pending       []int
+	migrationErr  error
+	deductions    []models.Deduction
+	deductionsErr error
+}
+
+func (s *StubHealthStorer) Ping(ctx context.Context) error {
+	return s.pingErr
+}
+
+func (s *StubHealthStorer) PendingMigrations(ctx context.Context) ([]int, error) {
+	return s.pending, s.migrationErr
+}
+
+func (s *StubHealthStorer) GetDeductions(ctx context.Context) ([]models.Deduction, error) {
+	return s.deductions, s.deductionsErr
+}
+
+func TestReadiness(t *testing.T) {
+	deductions := []models.Deduction{{Slug: models.PersonalSlug, Amount: 60_000}}
+	testCases := []struct {
+		name     string
+		stub     StubHealthStorer
+		shutdown bool
+		want     string
+		down     map[string]string
+	}{
+		{
+			name: "given every component is up should be ready",
+			stub: StubHealthStorer{deductions: deductions},
+			want: models.StatusReady,
+		},
+		{
+			name: "given database is unreachable should not be ready without telling storage error",
+			stub: StubHealthStorer{pingErr: errors.New("dial tcp db.internal:5432: connection refused"), migrationErr: errors.New("connection refused"), deductionsErr: errors.New("connection refused")},
+			want: models.StatusNotReady,
+			down: map[string]string{"database": "unavailable", "migrations": "unavailable", "deductions": "unavailable"},
+		},
+		{
+			name: "given pending migrations should not be ready",
+			stub: StubHealthStorer{pending: []int{2, 3}, deductions: deductions},
+			want: models.StatusNotReady,
+			down: map[string]string{"migrations": "migrations [2 3] are not applied"},
+		},
+		{
+			name: "given no deduction config should not be ready",
+			stub: StubHealthStorer{},
+			want: models.StatusNotReady,
+			down: map[string]string{"deductions": "no deduction config"},
+		},
+		{
+			name:     "given server is shutting down should not be ready",
+			stub:     StubHealthStorer{deductions: deductions},
+			shutdown: true,
+			want:     models.StatusNotReady,
+			down:     map[string]string{"server": ErrShuttingDown.Error()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewHealthService(&tc.stub)
+			if tc.shutdown {
+				service.Shutdown()
+			}
+
+			got := service.Readiness(context.Background())
+
+			if got.Status != tc.want {
+				t.Errorf("expect status %q but got %q", tc.want, got.Status)
+			}
+			for _, name := range []string{"server", "database", "migrations", "deductions"} {
+				component, ok := got.Components[name]
+				want := models.ComponentHealth{Status: models.ComponentUp}
+				if message, down := tc.down[name]; down {
+					want = models.ComponentHealth{Status: models.ComponentDown, Error: message}
+				}
+				if !ok || component != want {
+					t.Errorf("expect %s is %#v but got %#v", name, want, component)
+				}
+			}
+		})
+	}
+}
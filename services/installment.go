@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+type InstallmentConfig struct {
+	Threshold float64
+	Count     int
+}
+
+var (
+	DefaultInstallmentThreshold float64 = 3_000
+	DefaultInstallmentCount     int     = 3
+	// DefaultTaxDueDate is filing due date of tax year 2567 when request not send due date
+	DefaultTaxDueDate string = "2025-03-31"
+	// DefaultHalfYearDueDate is filing due date of half-year return (PND 94) of tax year 2567
+	DefaultHalfYearDueDate string = "2024-09-30"
+)
+
+func (ts *TaxService) GetInstallmentConfig(ctx context.Context) (config InstallmentConfig, err error) {
+	config = InstallmentConfig{
+		Threshold: DefaultInstallmentThreshold,
+		Count:     DefaultInstallmentCount,
+	}
+	configs, err := ts.Db.GetInstallmentConfigs(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return config, err
+	}
+	for _, v := range configs {
+		switch v.Slug {
+		case models.InstallmentThresholdSlug:
+			config.Threshold = v.Amount
+		case models.InstallmentCountSlug:
+			config.Count = int(v.Amount)
+		}
+	}
+	return config, nil
+}
+
+// CalculateInstallments split tax payable into equal monthly installments start from due date.
+// Each installment is rounded down to satang and the last one take the remain, so sum of them is always equal tax.
+// It return nil when tax is lower than threshold or tax return was filed after due date.
+func CalculateInstallments(taxPayable float64, tax models.TaxRequest, config InstallmentConfig) []models.Installment {
+	if config.Count < 2 || taxPayable < config.Threshold {
+		return nil
+	}
+	dueDate := tax.DueDate
+	if dueDate == "" && tax.Period == models.HalfYearPeriod {
+		dueDate = DefaultHalfYearDueDate
+	} else if dueDate == "" {
+		dueDate = DefaultTaxDueDate
+	}
+	due, err := time.Parse(models.DateLayout, dueDate)
+	if err != nil {
+		return nil
+	}
+	if tax.FilingDate != "" {
+		if filing, err := time.Parse(models.DateLayout, tax.FilingDate); err == nil && filing.After(due) {
+			// installment allow only for return that filed on time
+			return nil
+		}
+	}
+
+	installments := []models.Installment{}
+	amount := math.Floor(taxPayable/float64(config.Count)*100) / 100
+	remain := taxPayable
+	for i := 0; i < config.Count; i++ {
+		if i == config.Count-1 {
+			amount = math.Round(remain*100) / 100
+		}
+		installments = append(installments, models.Installment{
+			No:      i + 1,
+			DueDate: AddMonths(due, i).Format(models.DateLayout),
+			Amount:  amount,
+		})
+		remain -= amount
+	}
+	return installments
+}
@@ -0,0 +1,108 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestCalculateInstallments(t *testing.T) {
+	config := InstallmentConfig{Threshold: 3_000, Count: 3}
+	t.Run("given tax lower than threshold should not offer installments", func(t *testing.T) {
+		result := CalculateInstallments(2_999.99, models.TaxRequest{}, config)
+
+		if result != nil {
+			t.Errorf("expect no installments but got %#v", result)
+		}
+	})
+	t.Run("given tax equal threshold should split into 3 installments from default due date", func(t *testing.T) {
+		result := CalculateInstallments(3_000, models.TaxRequest{}, config)
+
+		assertObjectIsEqual(t, []models.Installment{
+			{No: 1, DueDate: "2025-03-31", Amount: 1_000},
+			{No: 2, DueDate: "2025-04-30", Amount: 1_000},
+			{No: 3, DueDate: "2025-05-31", Amount: 1_000},
+		}, result)
+	})
+	t.Run("given tax that cannot split equally last installment should take the remain", func(t *testing.T) {
+		result := CalculateInstallments(29_000.01, models.TaxRequest{DueDate: "2025-04-08"}, config)
+
+		assertObjectIsEqual(t, []models.Installment{
+			{No: 1, DueDate: "2025-04-08", Amount: 9_666.67},
+			{No: 2, DueDate: "2025-05-08", Amount: 9_666.67},
+			{No: 3, DueDate: "2025-06-08", Amount: 9_666.67},
+		}, result)
+		result = CalculateInstallments(10_000, models.TaxRequest{}, config)
+
+		assertObjectIsEqual(t, []models.Installment{
+			{No: 1, DueDate: "2025-03-31", Amount: 3_333.33},
+			{No: 2, DueDate: "2025-04-30", Amount: 3_333.33},
+			{No: 3, DueDate: "2025-05-31", Amount: 3_333.34},
+		}, result)
+	})
+	t.Run("given filing after due date should not offer installments", func(t *testing.T) {
+		result := CalculateInstallments(29_000, models.TaxRequest{FilingDate: "2025-04-01", DueDate: "2025-03-31"}, config)
+
+		if result != nil {
+			t.Errorf("expect no installments but got %#v", result)
+		}
+	})
+	t.Run("given count from config should split by that count", func(t *testing.T) {
+		result := CalculateInstallments(29_000, models.TaxRequest{}, InstallmentConfig{Threshold: 3_000, Count: 2})
+
+		if len(result) != 2 {
+			t.Errorf("expect 2 installments but got %d", len(result))
+		}
+	})
+}
+
+func TestGetInstallmentConfig(t *testing.T) {
+	t.Run("given no config in db should return default config", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		s := NewTaxService(&stub)
+
+		config, err := s.GetInstallmentConfig(context.Background())
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, InstallmentConfig{Threshold: DefaultInstallmentThreshold, Count: DefaultInstallmentCount}, config)
+	})
+	t.Run("given config in db should override default config", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.installments = []models.InstallmentConfig{
+			{Slug: models.InstallmentThresholdSlug, Amount: 5_000},
+			{Slug: models.InstallmentCountSlug, Amount: 6},
+		}
+		s := NewTaxService(&stub)
+
+		config, err := s.GetInstallmentConfig(context.Background())
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, InstallmentConfig{Threshold: 5_000, Count: 6}, config)
+	})
+	t.Run("given error should return error", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.installmentsErr = errors.New("error 'xxx' occured")
+		s := NewTaxService(&stub)
+
+		_, err := s.TaxCalculate(context.Background(), models.TaxRequest{TotalIncome: 500_000})
+
+		assertIsEqual(t, stub.installmentsErr, err, "expect error from db")
+	})
+	t.Run("given tax payable should return installments in tax response", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		s := NewTaxService(&stub)
+
+		result, err := s.TaxCalculate(context.Background(), models.TaxRequest{TotalIncome: 500_000})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodCalledTime(t, "GetInstallmentConfigs", 1)
+		if len(result.Installments) != 3 {
+			t.Errorf("expect 3 installments but got %d", len(result.Installments))
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+)
+
+type PayrollService struct {
+	Db TaxStorer
+}
+
+var PayrollCsvFields []string = []string{"employeeId", "monthlySalary", "month", "bonusMonths", "ytdIncome", "ytdWht", "donation", "k-receipt"}
+
+func NewPayrollService(db TaxStorer) *PayrollService {
+	return &PayrollService{
+		Db: db,
+	}
+}
+
+// CalculateWithholding annualise salary of payroll month with the same tax step and deductions as TaxCalculate.
+// Regular tax that not withheld yet is spread over the remaining months of the year,
+// while tax on one-off bonus is withheld entirely in the month it is paid (Revenue Department method for PND 1).
+func CalculateWithholding(payroll models.PayrollRequest, input TaxInput) models.PayrollResponse {
+	remainMonths := float64(13 - payroll.Month)
+	regularIncome := payroll.YtdIncome + payroll.MonthlySalary*remainMonths
+	bonus := payroll.MonthlySalary * payroll.BonusMonths
+
+	input.tax = models.TaxRequest{TotalIncome: regularIncome, Allowances: payroll.Allowances}
+	regularTax := CalculateTaxOutput(input).Tax
+	input.tax.TotalIncome = regularIncome + bonus
+	annualTax := CalculateTaxOutput(input).Tax
+
+	regularWithholding := (regularTax - payroll.YtdWht) / remainMonths
+	if regularWithholding < 0 {
+		// already withheld more than whole year tax
+		regularWithholding = 0
+	}
+	regularWithholding = math.Round(regularWithholding*100) / 100
+	bonusWithholding := math.Round((annualTax-regularTax)*100) / 100
+
+	return models.PayrollResponse{
+		AnnualIncome:       regularIncome + bonus,
+		AnnualTax:          annualTax,
+		Bonus:              bonus,
+		RegularWithholding: regularWithholding,
+		BonusWithholding:   bonusWithholding,
+		Withholding:        math.Round((regularWithholding+bonusWithholding)*100) / 100,
+	}
+}
+
+func (ps *PayrollService) PayrollCalculate(ctx context.Context, payroll models.PayrollRequest) (models.PayrollResponse, error) {
+	deductions, err := NewTaxService(ps.Db).GetDeductionConfigs(ctx)
+	if err != nil {
+		return models.PayrollResponse{}, err
+	}
+
+	result := CalculateWithholding(payroll, TaxInput{deductions: deductions})
+	metrics.Calculations.WithLabelValues("payroll").Inc()
+	return result, nil
+}
+
+func (ps *PayrollService) ExtractPayrollCsv(reader io.Reader) ([]models.PayrollCsv, error) {
+	payroll := []models.PayrollCsv{}
+	csvReader := csv.NewReader(reader)
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, utils.ErrCsvHeaderMissing
+	}
+	header := rows[0]
+	if !validators.IsAllStringInArray(header, []string{"monthlySalary", "month"}) {
+		return nil, utils.ErrCsvHeaderMissing
+	}
+
+	for _, row := range rows[1:] {
+		var p models.PayrollCsv
+		for idx, col := range row {
+			if !slices.Contains(PayrollCsvFields, header[idx]) {
+				continue
+			}
+			if header[idx] == "employeeId" {
+				p.EmployeeId = col
+				continue
+			}
+			if col == "" {
+				return nil, utils.ErrCsvValueEmpty
+			}
+			if header[idx] == "month" {
+				month, err := strconv.Atoi(col)
+				if err != nil {
+					return nil, err
+				}
+				p.Month = month
+				continue
+			}
+			val, err := strconv.ParseFloat(col, 64)
+			if err != nil {
+				return nil, err
+			}
+			switch header[idx] {
+			case "monthlySalary":
+				p.MonthlySalary = val
+			case "bonusMonths":
+				p.BonusMonths = val
+			case "ytdIncome":
+				p.YtdIncome = val
+			case "ytdWht":
+				p.YtdWht = val
+			case "donation":
+				p.Donation = val
+			case "k-receipt":
+				p.KReceipt = val
+			}
+		}
+		// validate each row data when it is all number value
+		if err := validators.ValidatePayrollCsv(p); err != nil {
+			return nil, err
+		}
+		payroll = append(payroll, p)
+	}
+	return payroll, nil
+}
+
+func TransformPayrollCsvToPayrollRequest(csv models.PayrollCsv) (request models.PayrollRequest) {
+	request.MonthlySalary = csv.MonthlySalary
+	request.Month = csv.Month
+	request.BonusMonths = csv.BonusMonths
+	request.YtdIncome = csv.YtdIncome
+	request.YtdWht = csv.YtdWht
+	request.Allowances = []models.Allowance{
+		{Type: models.DonationSlug, Amount: csv.Donation},
+		{Type: models.KReceiptSlug, Amount: csv.KReceipt},
+	}
+	return
+}
+
+func (ps *PayrollService) CalculatePayrollCsv(ctx context.Context, payroll []models.PayrollCsv) (models.PayrollCsvResponse, error) {
+	var result models.PayrollCsvResponse = models.PayrollCsvResponse{
+		Payroll: []models.PayrollCsvResult{},
+	}
+	deductions, err := NewTaxService(ps.Db).GetDeductionConfigs(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, p := range payroll {
+		output := CalculateWithholding(TransformPayrollCsvToPayrollRequest(p), TaxInput{deductions: deductions})
+		metrics.Calculations.WithLabelValues("payroll").Inc()
+		result.Payroll = append(result.Payroll, models.PayrollCsvResult{
+			EmployeeId:    p.EmployeeId,
+			MonthlySalary: p.MonthlySalary,
+			Withholding:   output.Withholding,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,172 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+func TestCalculateWithholding(t *testing.T) {
+	input := TaxInput{
+		deductions: map[string]models.Deduction{
+			models.PersonalSlug: {Slug: models.PersonalSlug, Amount: 60_000},
+			models.DonationSlug: {Slug: models.DonationSlug, Amount: 100_000},
+			models.KReceiptSlug: {Slug: models.KReceiptSlug, Amount: 50_000},
+		},
+	}
+	t.Run("given first month salary without bonus should spread annual tax over 12 months", func(t *testing.T) {
+		result := CalculateWithholding(models.PayrollRequest{MonthlySalary: 50_000, Month: 1}, input)
+
+		assertObjectIsEqual(t, models.PayrollResponse{
+			AnnualIncome:       600_000,
+			AnnualTax:          41_000,
+			RegularWithholding: 3_416.67,
+			Withholding:        3_416.67,
+		}, result)
+	})
+	t.Run("given bonus month should withhold all tax on bonus in this month", func(t *testing.T) {
+		result := CalculateWithholding(models.PayrollRequest{MonthlySalary: 50_000, Month: 1, BonusMonths: 2}, input)
+
+		assertObjectIsEqual(t, models.PayrollResponse{
+			AnnualIncome:       700_000,
+			AnnualTax:          56_000,
+			Bonus:              100_000,
+			RegularWithholding: 3_416.67,
+			BonusWithholding:   15_000,
+			Withholding:        18_416.67,
+		}, result)
+	})
+	t.Run("given year-to-date data should spread remain tax over remain months", func(t *testing.T) {
+		result := CalculateWithholding(models.PayrollRequest{MonthlySalary: 100_000, Month: 7, YtdIncome: 600_000, YtdWht: 60_000}, input)
+
+		assertIsEqual(t, 13_000.0, result.Withholding, expectTaxValueMsg(13_000, result.Withholding))
+	})
+	t.Run("given withheld more than annual tax should not withhold this month", func(t *testing.T) {
+		result := CalculateWithholding(models.PayrollRequest{MonthlySalary: 20_000, Month: 12, YtdIncome: 220_000, YtdWht: 50_000}, input)
+
+		assertIsEqual(t, 0.0, result.Withholding, expectTaxValueMsg(0, result.Withholding))
+	})
+	t.Run("given allowances should deduct before annualise tax", func(t *testing.T) {
+		result := CalculateWithholding(models.PayrollRequest{
+			MonthlySalary: 50_000,
+			Month:         1,
+			Allowances:    []models.Allowance{{Type: models.KReceiptSlug, Amount: 60_000}},
+		}, input)
+
+		// 600,000 - 60,000 - 50,000 = 490,000 -> 34,000 / 12
+		assertIsEqual(t, 2_833.33, result.Withholding, expectTaxValueMsg(2_833.33, result.Withholding))
+	})
+}
+
+func TestPayrollCalculate(t *testing.T) {
+	t.Run("given error on get deduction config should return error", func(t *testing.T) {
+		stub := initStub(nil, errors.New("error 'xxx' occured"))
+		s := NewPayrollService(&stub)
+
+		_, err := s.PayrollCalculate(context.Background(), models.PayrollRequest{MonthlySalary: 50_000, Month: 1})
+
+		stub.assertMethodWasCalled(t, "GetDeductions")
+		if err == nil {
+			t.Fatal("expect error should not be null")
+		}
+	})
+	t.Run("given valid payroll should return withholding from deduction config", func(t *testing.T) {
+		stub := initStub([]models.Deduction{{Slug: models.PersonalSlug, Amount: 60_000}}, nil)
+		s := NewPayrollService(&stub)
+
+		result, err := s.PayrollCalculate(context.Background(), models.PayrollRequest{MonthlySalary: 50_000, Month: 1})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodCalledTime(t, "GetDeductions", 1)
+		assertIsEqual(t, 3_416.67, result.Withholding, expectTaxValueMsg(3_416.67, result.Withholding))
+	})
+}
+
+func TestFuncExtractPayrollCsv(t *testing.T) {
+	openCsvFile := func(t *testing.T, filePath string) *os.File {
+		t.Helper()
+		dir, _ := os.Getwd()
+		fileData, err := os.Open(filepath.Join(dir, filePath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fileData
+	}
+	s := NewPayrollService(nil)
+	t.Run("when csv is empty should return error 'missing required header field'", func(t *testing.T) {
+		_, err := s.ExtractPayrollCsv(strings.NewReader(""))
+
+		assertObjectIsEqual(t, utils.ErrCsvHeaderMissing, err)
+	})
+	t.Run("when csv is missing required colum should return error 'missing required header field'", func(t *testing.T) {
+		fileData := openCsvFile(t, "../testdata/missing-column-payroll.csv")
+		defer fileData.Close()
+
+		_, err := s.ExtractPayrollCsv(fileData)
+
+		assertObjectIsEqual(t, errors.New("missing required header field"), err)
+	})
+	t.Run("when csv is missing value on required field should return error 'value should not be empty'", func(t *testing.T) {
+		fileData := openCsvFile(t, "../testdata/missing-value-payroll.csv")
+		defer fileData.Close()
+
+		_, err := s.ExtractPayrollCsv(fileData)
+
+		assertObjectIsEqual(t, errors.New("value should not be empty"), err)
+	})
+	t.Run("when valid csv field should return array of payroll csv data", func(t *testing.T) {
+		fileData := openCsvFile(t, "../testdata/valid-payroll.csv")
+		defer fileData.Close()
+
+		result, err := s.ExtractPayrollCsv(fileData)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, []models.PayrollCsv{
+			{EmployeeId: "E001", MonthlySalary: 50_000, Month: 1},
+			{EmployeeId: "E002", MonthlySalary: 50_000, Month: 1, BonusMonths: 2},
+			{EmployeeId: "E003", MonthlySalary: 100_000, Month: 7, YtdIncome: 600_000, YtdWht: 60_000},
+		}, result)
+	})
+}
+
+func TestFuncCalculatePayrollCsv(t *testing.T) {
+	t.Run("given error on get deduction config should return error", func(t *testing.T) {
+		stub := initStub(nil, errors.New("error 'xxx' occured"))
+		s := NewPayrollService(&stub)
+
+		_, err := s.CalculatePayrollCsv(context.Background(), []models.PayrollCsv{{MonthlySalary: 50_000, Month: 1}})
+
+		if err == nil {
+			t.Fatal("expect error should not be null")
+		}
+	})
+	t.Run("given list of payroll csv should return withholding of each employee", func(t *testing.T) {
+		stub := initStub([]models.Deduction{}, nil)
+		s := NewPayrollService(&stub)
+
+		result, err := s.CalculatePayrollCsv(context.Background(), []models.PayrollCsv{
+			{EmployeeId: "E001", MonthlySalary: 50_000, Month: 1},
+			{EmployeeId: "E002", MonthlySalary: 50_000, Month: 1, BonusMonths: 2},
+			{EmployeeId: "E003", MonthlySalary: 100_000, Month: 7, YtdIncome: 600_000, YtdWht: 60_000},
+		})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodCalledTime(t, "GetDeductions", 1)
+		assertObjectIsEqual(t, models.PayrollCsvResponse{
+			Payroll: []models.PayrollCsvResult{
+				{EmployeeId: "E001", MonthlySalary: 50_000, Withholding: 3_416.67},
+				{EmployeeId: "E002", MonthlySalary: 50_000, Withholding: 18_416.67},
+				{EmployeeId: "E003", MonthlySalary: 100_000, Withholding: 13_000},
+			},
+		}, result)
+	})
+}
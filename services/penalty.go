@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+type PenaltyConfig struct {
+	// SurchargeRate is percent per month or fraction of month
+	SurchargeRate     float64
+	LateFilingPenalty float64
+	// RefundInterestRate is percent per month or fraction of month
+	RefundInterestRate float64
+	RefundGraceMonths  int
+}
+
+var (
+	DefaultSurchargeRate      float64 = 1.5
+	DefaultLateFilingPenalty  float64 = 200
+	DefaultRefundInterestRate float64 = 1
+	DefaultRefundGraceMonths  int     = 3
+)
+
+func (ts *TaxService) GetPenaltyConfig(ctx context.Context) (config PenaltyConfig, err error) {
+	config = PenaltyConfig{
+		SurchargeRate:      DefaultSurchargeRate,
+		LateFilingPenalty:  DefaultLateFilingPenalty,
+		RefundInterestRate: DefaultRefundInterestRate,
+		RefundGraceMonths:  DefaultRefundGraceMonths,
+	}
+	penalties, err := ts.Db.GetPenalties(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return config, err
+	}
+	for _, v := range penalties {
+		switch v.Slug {
+		case models.SurchargeSlug:
+			config.SurchargeRate = v.Amount
+		case models.LateFilingPenaltySlug:
+			config.LateFilingPenalty = v.Amount
+		case models.RefundInterestSlug:
+			config.RefundInterestRate = v.Amount
+		case models.RefundGraceMonthsSlug:
+			config.RefundGraceMonths = int(v.Amount)
+		}
+	}
+	return config, nil
+}
+
+// AddMonths add months to date and keep it at the end of month when that day not exist (e.g. 31 Mar + 1 month = 30 Apr)
+func AddMonths(date time.Time, months int) time.Time {
+	result := date.AddDate(0, months, 0)
+	if result.Day() != date.Day() {
+		// overflow to next month, go back to last day of previous month
+		result = result.AddDate(0, 0, -result.Day())
+	}
+	return result
+}
+
+// CountLateMonths return number of month or fraction of month from the day after due date until paid date
+func CountLateMonths(due, paid time.Time) (months int) {
+	for AddMonths(due, months).Before(paid) {
+		months++
+	}
+	return
+}
+
+// CalculateLatePayment add surcharge, penalty and refund interest to tax result
+// base on filing date, due date and refund date of tax request.
+// It expect dates in request were validated before.
+func CalculateLatePayment(result models.TaxResponse, tax models.TaxRequest, config PenaltyConfig) models.TaxResponse {
+	if tax.FilingDate == "" || tax.DueDate == "" {
+		return result
+	}
+	filing, _ := time.Parse(models.DateLayout, tax.FilingDate)
+	due, _ := time.Parse(models.DateLayout, tax.DueDate)
+
+	if lateMonths := CountLateMonths(due, filing); lateMonths > 0 {
+		result.Penalty = config.LateFilingPenalty
+		surcharge := result.Tax * config.SurchargeRate / 100 * float64(lateMonths)
+		if surcharge > result.Tax {
+			surcharge = result.Tax
+		}
+		result.Surcharge = math.Round(surcharge*100) / 100
+	}
+
+	if tax.RefundDate != "" && result.TaxRefund > 0 {
+		refund, _ := time.Parse(models.DateLayout, tax.RefundDate)
+		// Revenue Department has grace months to pay refund count from due date or filing date which is later
+		start := due
+		if filing.After(due) {
+			start = filing
+		}
+		start = AddMonths(start, config.RefundGraceMonths)
+		interest := result.TaxRefund * config.RefundInterestRate / 100 * float64(CountLateMonths(start, refund))
+		if interest > result.TaxRefund {
+			interest = result.TaxRefund
+		}
+		result.RefundInterest = math.Round(interest*100) / 100
+	}
+	return result
+}
@@ -0,0 +1,156 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestCountLateMonths(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse(models.DateLayout, s)
+		return d
+	}
+	testSuites := []struct {
+		name string
+		due  string
+		paid string
+		want int
+	}{
+		{"when paid before due date should be 0", "2025-03-31", "2025-03-01", 0},
+		{"when paid on due date should be 0", "2025-03-31", "2025-03-31", 0},
+		{"when paid 1 day late should be 1", "2025-03-31", "2025-04-01", 1},
+		{"when paid exactly 1 month late should be 1", "2025-03-31", "2025-04-30", 1},
+		{"when paid 1 month and 1 day late should be 2", "2025-03-31", "2025-05-01", 2},
+		{"when paid 1 year late should be 12", "2025-03-31", "2026-03-31", 12},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CountLateMonths(date(tc.due), date(tc.paid))
+
+			assertIsEqual(t, tc.want, got, "unexpect late months")
+		})
+	}
+}
+
+func TestCalculateLatePayment(t *testing.T) {
+	config := PenaltyConfig{
+		SurchargeRate:      1.5,
+		LateFilingPenalty:  200,
+		RefundInterestRate: 1,
+		RefundGraceMonths:  3,
+	}
+	t.Run("given no filing date should return same result", func(t *testing.T) {
+		result := CalculateLatePayment(models.TaxResponse{Tax: 29_000}, models.TaxRequest{}, config)
+
+		assertObjectIsEqual(t, models.TaxResponse{Tax: 29_000}, result)
+	})
+	t.Run("given filing on time should have no surcharge and penalty", func(t *testing.T) {
+		result := CalculateLatePayment(models.TaxResponse{Tax: 29_000}, models.TaxRequest{FilingDate: "2025-03-31", DueDate: "2025-03-31"}, config)
+
+		assertObjectIsEqual(t, models.TaxResponse{Tax: 29_000}, result)
+	})
+	t.Run("given filing late 2 months should have 3% surcharge and penalty", func(t *testing.T) {
+		result := CalculateLatePayment(models.TaxResponse{Tax: 29_000}, models.TaxRequest{FilingDate: "2025-05-15", DueDate: "2025-03-31"}, config)
+
+		assertObjectIsEqual(t, models.TaxResponse{Tax: 29_000, Surcharge: 870, Penalty: 200}, result)
+	})
+	t.Run("given filing very late surcharge should not more than tax", func(t *testing.T) {
+		result := CalculateLatePayment(models.TaxResponse{Tax: 1_000}, models.TaxRequest{FilingDate: "2031-04-01", DueDate: "2025-03-31"}, config)
+
+		assertIsEqual(t, 1_000.0, result.Surcharge, "expect surcharge should be capped at tax")
+	})
+	t.Run("given refund paid within grace months should have no refund interest", func(t *testing.T) {
+		result := CalculateLatePayment(models.TaxResponse{TaxRefund: 1_000}, models.TaxRequest{FilingDate: "2025-03-01", DueDate: "2025-03-31", RefundDate: "2025-06-30"}, config)
+
+		assertObjectIsEqual(t, models.TaxResponse{TaxRefund: 1_000}, result)
+	})
+	t.Run("given refund paid late should have refund interest per month", func(t *testing.T) {
+		result := CalculateLatePayment(models.TaxResponse{TaxRefund: 1_000}, models.TaxRequest{FilingDate: "2025-03-01", DueDate: "2025-03-31", RefundDate: "2025-08-15"}, config)
+
+		assertObjectIsEqual(t, models.TaxResponse{TaxRefund: 1_000, RefundInterest: 20}, result)
+	})
+}
+
+func TestGetPenaltyConfig(t *testing.T) {
+	t.Run("given no row should return default config", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.penaltiesErr = sql.ErrNoRows
+		s := NewTaxService(&stub)
+
+		config, err := s.GetPenaltyConfig(context.Background())
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, PenaltyConfig{
+			SurchargeRate:      DefaultSurchargeRate,
+			LateFilingPenalty:  DefaultLateFilingPenalty,
+			RefundInterestRate: DefaultRefundInterestRate,
+			RefundGraceMonths:  DefaultRefundGraceMonths,
+		}, config)
+	})
+	t.Run("given error should return error", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.penaltiesErr = errors.New("error 'xxx' occured")
+		s := NewTaxService(&stub)
+
+		_, err := s.GetPenaltyConfig(context.Background())
+
+		assertIsEqual(t, stub.penaltiesErr, err, "expect error from db")
+	})
+	t.Run("given penalties from db should override default config", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.penalties = []models.Penalty{
+			{Slug: models.SurchargeSlug, Amount: 2},
+			{Slug: models.LateFilingPenaltySlug, Amount: 1_000},
+			{Slug: models.RefundInterestSlug, Amount: 0.5},
+			{Slug: models.RefundGraceMonthsSlug, Amount: 6},
+		}
+		s := NewTaxService(&stub)
+
+		config, err := s.GetPenaltyConfig(context.Background())
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, PenaltyConfig{SurchargeRate: 2, LateFilingPenalty: 1_000, RefundInterestRate: 0.5, RefundGraceMonths: 6}, config)
+	})
+}
+
+func TestTaxCalculateWithFilingDate(t *testing.T) {
+	t.Run("given no filing date should not get penalty config", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		s := NewTaxService(&stub)
+
+		_, err := s.TaxCalculate(context.Background(), models.TaxRequest{TotalIncome: 500_000})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		if stub.expectToCall["GetPenalties"] {
+			t.Error("expect GetPenalties was not called")
+		}
+	})
+	t.Run("given late filing should return surcharge and penalty", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		s := NewTaxService(&stub)
+
+		result, err := s.TaxCalculate(context.Background(), models.TaxRequest{TotalIncome: 500_000, FilingDate: "2025-04-30", DueDate: "2025-03-31"})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodCalledTime(t, "GetPenalties", 1)
+		assertIsEqual(t, 435.0, result.Surcharge, "expect surcharge 1.5% of 29,000")
+		assertIsEqual(t, DefaultLateFilingPenalty, result.Penalty, "expect default late filing penalty")
+	})
+	t.Run("given error on get penalty config should return error", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.penaltiesErr = errors.New("error 'xxx' occured")
+		s := NewTaxService(&stub)
+
+		_, err := s.TaxCalculate(context.Background(), models.TaxRequest{TotalIncome: 500_000, FilingDate: "2025-04-30", DueDate: "2025-03-31"})
+
+		assertIsEqual(t, stub.penaltiesErr, err, "expect error from db")
+	})
+}
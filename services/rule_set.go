@@ -0,0 +1,204 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/rules"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+	"gopkg.in/yaml.v3"
+)
+
+type RuleSetService struct {
+	Db RuleSetStorer
+}
+
+type RuleSetStorer interface {
+	CreateRuleSet(ctx context.Context, ruleSet models.RuleSet) (models.RuleSet, error)
+	GetRuleSets(ctx context.Context) ([]models.RuleSet, error)
+	GetRuleSet(ctx context.Context, id uint) (models.RuleSet, error)
+	GetActiveRuleSet(ctx context.Context) (models.RuleSet, error)
+	// ActivateRuleSet mark rule set as the only active one and insert its pending deduction changes in one transaction,
+	// so rule set is not active when a change cannot be proposed
+	ActivateRuleSet(ctx context.Context, ruleSet models.RuleSet, changes []models.DeductionChange) ([]models.DeductionChange, error)
+	GetDeductions(ctx context.Context) ([]models.Deduction, error)
+}
+
+func NewRuleSetService(db RuleSetStorer) *RuleSetService {
+	return &RuleSetService{
+		Db: db,
+	}
+}
+
+var activeRuleSet struct {
+	sync.RWMutex
+	ruleSet models.RuleSet
+}
+
+func init() {
+	ruleSet, err := DefaultRuleSet()
+	if err != nil {
+		panic(err)
+	}
+	ApplyRuleSet(ruleSet)
+	validators.AllowanceTypes = AllowanceTypes
+}
+
+// DefaultRuleSet return rule set that shipped with application
+func DefaultRuleSet() (models.RuleSet, error) {
+	return ParseRuleSet(rules.Default, models.YamlRuleSetFormat)
+}
+
+// ParseRuleSet decode rule set in json or yaml, unknown field is not allowed, and validate it
+func ParseRuleSet(data []byte, format string) (ruleSet models.RuleSet, err error) {
+	switch format {
+	case models.JsonRuleSetFormat:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&ruleSet)
+	case models.YamlRuleSetFormat:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&ruleSet)
+	default:
+		err = validators.ErrRuleSetFormatInvalid
+	}
+	if err != nil {
+		return models.RuleSet{}, err
+	}
+	if err := validators.ValidateRuleSet(ruleSet); err != nil {
+		return models.RuleSet{}, err
+	}
+	return ruleSet, nil
+}
+
+// ActiveRuleSet return rule set that tax is calculated with
+func ActiveRuleSet() models.RuleSet {
+	activeRuleSet.RLock()
+	defer activeRuleSet.RUnlock()
+	return activeRuleSet.ruleSet
+}
+
+// activeRules return active rule set with DeductionRules that are built from it
+func activeRules() (models.RuleSet, []DeductionRule) {
+	activeRuleSet.RLock()
+	defer activeRuleSet.RUnlock()
+	return activeRuleSet.ruleSet, DeductionRules.Rules()
+}
+
+// ApplyRuleSet make rule set active for tax calculation and rebuild DeductionRules in its order
+func ApplyRuleSet(ruleSet models.RuleSet) {
+	activeRuleSet.Lock()
+	defer activeRuleSet.Unlock()
+	activeRuleSet.ruleSet = ruleSet
+	deductionRules := make([]DeductionRule, 0, len(ruleSet.Deductions))
+	for _, v := range ruleSet.Deductions {
+		deductionRules = append(deductionRules, RuleSetDeduction{v})
+	}
+	DeductionRules.Replace(deductionRules...)
+}
+
+// LoadActiveRuleSet apply rule set that is active in db, keep default rule set when there is none
+func (rs *RuleSetService) LoadActiveRuleSet(ctx context.Context) (models.RuleSet, error) {
+	ruleSet, err := rs.Db.GetActiveRuleSet(ctx)
+	if err == sql.ErrNoRows {
+		return ActiveRuleSet(), nil
+	}
+	if err != nil {
+		return models.RuleSet{}, err
+	}
+	ApplyRuleSet(ruleSet)
+	return ruleSet, nil
+}
+
+func (rs *RuleSetService) GetRuleSets(ctx context.Context) ([]models.RuleSet, error) {
+	ruleSets, err := rs.Db.GetRuleSets(ctx)
+	if err == sql.ErrNoRows || ruleSets == nil {
+		return []models.RuleSet{}, nil
+	}
+	return ruleSets, err
+}
+
+func (rs *RuleSetService) CreateRuleSet(ctx context.Context, ruleSet models.RuleSet, activate bool, editor string) (models.RuleSet, error) {
+	if activate {
+		// check caps before save, so rule set that cannot be activated is not saved either
+		if _, err := rs.deductionChanges(ctx, ruleSet, editor); err != nil {
+			return ruleSet, err
+		}
+	}
+	ruleSet, err := rs.Db.CreateRuleSet(ctx, ruleSet)
+	if err != nil || !activate {
+		return ruleSet, err
+	}
+	return rs.activate(ctx, ruleSet, editor)
+}
+
+func (rs *RuleSetService) ActivateRuleSet(ctx context.Context, id uint, editor string) (models.RuleSet, error) {
+	ruleSet, err := rs.Db.GetRuleSet(ctx, id)
+	if err == sql.ErrNoRows {
+		return ruleSet, utils.ErrRuleSetNotFound
+	}
+	if err != nil {
+		return ruleSet, err
+	}
+	return rs.activate(ctx, ruleSet, editor)
+}
+
+// deductionChanges validate cap of every rule that has deduction row with limit of the row,
+// and return changes for rows that amount differ from cap
+func (rs *RuleSetService) deductionChanges(ctx context.Context, ruleSet models.RuleSet, editor string) ([]models.DeductionChange, error) {
+	ds, err := rs.Db.GetDeductions(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	var changes []models.DeductionChange
+	for _, v := range ruleSet.Deductions {
+		i := slices.IndexFunc(ds, func(d models.Deduction) bool { return d.Slug == v.Slug })
+		if i < 0 || ds[i].Amount == v.Amount {
+			continue
+		}
+		change, err := newDeductionChange(ds[i], v.Amount, editor)
+		if err != nil {
+			return nil, fmt.Errorf("deduction %s: %w", v.Slug, err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// activate make rule set active, deduction rows are not written here but
+// cap that differ from row is proposed as pending change that other admin has to approve.
+// Rule set is applied only after it and its changes are saved.
+func (rs *RuleSetService) activate(ctx context.Context, ruleSet models.RuleSet, editor string) (models.RuleSet, error) {
+	changes, err := rs.deductionChanges(ctx, ruleSet, editor)
+	if err != nil {
+		return ruleSet, err
+	}
+	if _, err := rs.Db.ActivateRuleSet(ctx, ruleSet, changes); err != nil {
+		return ruleSet, err
+	}
+	ruleSet.Active = true
+	ApplyRuleSet(ruleSet)
+	return ruleSet, nil
+}
+
+// ExtractRuleSet read rule set file in json or yaml
+func (rs *RuleSetService) ExtractRuleSet(reader io.Reader, format string) (models.RuleSet, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return models.RuleSet{}, err
+	}
+	return ParseRuleSet(data, format)
+}
+
+func (rs *RuleSetService) ActiveRuleSet() models.RuleSet {
+	return ActiveRuleSet()
+}
@@ -0,0 +1,263 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+)
+
+type StubRuleSetStore struct {
+	ruleSets     map[uint]models.RuleSet
+	activeId     uint
+	deductions   []models.Deduction
+	changes      []models.DeductionChange
+	err          error
+	expectToCall map[string]bool
+}
+
+func (s *StubRuleSetStore) CreateRuleSet(ctx context.Context, ruleSet models.RuleSet) (models.RuleSet, error) {
+	s.expectToCall["CreateRuleSet"] = true
+	ruleSet.Id = uint(len(s.ruleSets) + 1)
+	s.ruleSets[ruleSet.Id] = ruleSet
+	return ruleSet, s.err
+}
+
+func (s *StubRuleSetStore) GetRuleSets(ctx context.Context) ([]models.RuleSet, error) {
+	s.expectToCall["GetRuleSets"] = true
+	var ruleSets []models.RuleSet
+	for _, v := range s.ruleSets {
+		ruleSets = append(ruleSets, v)
+	}
+	return ruleSets, s.err
+}
+
+func (s *StubRuleSetStore) GetRuleSet(ctx context.Context, id uint) (models.RuleSet, error) {
+	s.expectToCall["GetRuleSet"] = true
+	ruleSet, ok := s.ruleSets[id]
+	if !ok {
+		return ruleSet, sql.ErrNoRows
+	}
+	return ruleSet, s.err
+}
+
+func (s *StubRuleSetStore) GetActiveRuleSet(ctx context.Context) (models.RuleSet, error) {
+	s.expectToCall["GetActiveRuleSet"] = true
+	if s.activeId == 0 {
+		return models.RuleSet{}, sql.ErrNoRows
+	}
+	return s.ruleSets[s.activeId], s.err
+}
+
+// ActivateRuleSet save nothing on error like rolled back transaction
+func (s *StubRuleSetStore) ActivateRuleSet(ctx context.Context, ruleSet models.RuleSet, changes []models.DeductionChange) ([]models.DeductionChange, error) {
+	s.expectToCall["ActivateRuleSet"] = true
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.activeId = ruleSet.Id
+	for _, change := range changes {
+		change.Id = uint(len(s.changes) + 1)
+		s.changes = append(s.changes, change)
+	}
+	return s.changes, nil
+}
+
+func (s *StubRuleSetStore) GetDeductions(ctx context.Context) ([]models.Deduction, error) {
+	s.expectToCall["GetDeductions"] = true
+	return s.deductions, nil
+}
+
+// restoreDefaultRuleSet apply default rule set after test that activate another rule set
+func restoreDefaultRuleSet(t *testing.T) {
+	t.Cleanup(func() {
+		ruleSet, _ := DefaultRuleSet()
+		ApplyRuleSet(ruleSet)
+	})
+}
+
+const flatRuleSetJson = `{
+	"name": "flat",
+	"taxYear": 2568,
+	"brackets": [{"minIncome": 0, "maxIncome": 0, "rate": 0.1}],
+	"deductions": [{"slug": "personal", "type": "fixed", "amount": 100000}]
+}`
+
+func TestParseRuleSet(t *testing.T) {
+	t.Run("given default rule set should keep current brackets and deductions", func(t *testing.T) {
+		got, err := DefaultRuleSet()
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 5, len(got.Brackets), "expect 5 tax brackets")
+		assertObjectIsEqual(t, []models.RuleDeduction{
+			{Slug: models.PersonalSlug, Type: models.FixedRuleType, Amount: 60_000, HalfOnHalfYear: true},
+			{Slug: models.SpouseSlug, Type: models.FamilyRuleType, Member: models.SpouseMember, Amount: 60_000, HalfOnHalfYear: true},
+			{Slug: models.ChildSlug, Type: models.FamilyRuleType, Member: models.ChildMember, Amount: 30_000, HalfOnHalfYear: true},
+			{Slug: models.ParentSlug, Type: models.FamilyRuleType, Member: models.ParentMember, Amount: 30_000, HalfOnHalfYear: true},
+			{Slug: models.DonationSlug, Type: models.AllowanceRuleType, Amount: 100_000},
+			{Slug: models.KReceiptSlug, Type: models.AllowanceRuleType, Amount: 50_000},
+		}, got.Deductions)
+	})
+	t.Run("given json rule set should decode it", func(t *testing.T) {
+		got, err := ParseRuleSet([]byte(flatRuleSetJson), models.JsonRuleSetFormat)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, "flat", got.Name, "expect rule set name is flat")
+		assertObjectIsEqual(t, []models.TaxStep{{MinIncome: 0, MaxIncome: 0, Rate: 0.1}}, got.Brackets)
+	})
+	t.Run("given unknown field should return error", func(t *testing.T) {
+		_, err := ParseRuleSet([]byte("name: x\ntaxYear: 2567\nunknown: 1\n"), models.YamlRuleSetFormat)
+
+		if err == nil {
+			t.Fatal("expect error should not be null")
+		}
+	})
+	t.Run("given invalid rule set should return validate error", func(t *testing.T) {
+		_, err := ParseRuleSet([]byte(`{"name": "x", "taxYear": 2567}`), models.JsonRuleSetFormat)
+
+		assertIsEqual(t, validators.ErrRuleSetBracketsRequired, err, "expect brackets required error")
+	})
+	t.Run("given unsupported format should return error", func(t *testing.T) {
+		_, err := ParseRuleSet([]byte(flatRuleSetJson), "xml")
+
+		assertIsEqual(t, validators.ErrRuleSetFormatInvalid, err, "expect format invalid error")
+	})
+}
+
+func TestApplyRuleSet(t *testing.T) {
+	restoreDefaultRuleSet(t)
+	ruleSet, _ := ParseRuleSet([]byte(flatRuleSetJson), models.JsonRuleSetFormat)
+
+	ApplyRuleSet(ruleSet)
+	result := CalculateTaxOutput(TaxInput{
+		tax:        models.TaxRequest{TotalIncome: 500_000, Allowances: []models.Allowance{{Type: models.DonationSlug, Amount: 100_000}}},
+		deductions: DeductionConfigs(nil),
+	})
+
+	// donation is not in rule set, net income 500,000 - 100,000 = 400,000 at flat 10%
+	assertIsEqual(t, 40_000.0, result.Tax, expectTaxValueMsg(40_000, result.Tax))
+	assertObjectIsEqual(t, []models.TaxLevel{{Level: "1 ขึ้นไป", Tax: 40_000}}, result.TaxLevel)
+}
+
+func TestRuleSetService(t *testing.T) {
+	t.Run("given no active rule set in db should keep default rule set", func(t *testing.T) {
+		stub := &StubRuleSetStore{ruleSets: map[uint]models.RuleSet{}, expectToCall: map[string]bool{}}
+
+		got, err := NewRuleSetService(stub).LoadActiveRuleSet(context.Background())
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, "default", got.Name, "expect default rule set")
+	})
+	t.Run("given create with activate should activate new rule set", func(t *testing.T) {
+		restoreDefaultRuleSet(t)
+		stub := &StubRuleSetStore{ruleSets: map[uint]models.RuleSet{}, expectToCall: map[string]bool{}}
+		ruleSet, _ := ParseRuleSet([]byte(flatRuleSetJson), models.JsonRuleSetFormat)
+
+		got, err := NewRuleSetService(stub).CreateRuleSet(context.Background(), ruleSet, true, "editor")
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, true, got.Active, "expect rule set is active")
+		assertIsEqual(t, uint(1), stub.activeId, "expect rule set 1 is active in db")
+		assertIsEqual(t, "flat", ActiveRuleSet().Name, "expect flat rule set is applied")
+	})
+	t.Run("given create without activate should not change active rule set", func(t *testing.T) {
+		stub := &StubRuleSetStore{ruleSets: map[uint]models.RuleSet{}, expectToCall: map[string]bool{}}
+		ruleSet, _ := ParseRuleSet([]byte(flatRuleSetJson), models.JsonRuleSetFormat)
+
+		_, err := NewRuleSetService(stub).CreateRuleSet(context.Background(), ruleSet, false, "editor")
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, false, stub.expectToCall["ActivateRuleSet"], "expect activate is not called")
+		assertIsEqual(t, "default", ActiveRuleSet().Name, "expect default rule set is still applied")
+	})
+	t.Run("given not exist id should return not found error", func(t *testing.T) {
+		stub := &StubRuleSetStore{ruleSets: map[uint]models.RuleSet{}, expectToCall: map[string]bool{}}
+
+		_, err := NewRuleSetService(stub).ActivateRuleSet(context.Background(), 9, "editor")
+
+		assertIsEqual(t, utils.ErrRuleSetNotFound, err, "expect rule set not found error")
+	})
+	t.Run("given error on activate or propose change should keep current rule set", func(t *testing.T) {
+		ruleSet, _ := ParseRuleSet([]byte(flatRuleSetJson), models.JsonRuleSetFormat)
+		ruleSet.Id = 1
+		stub := &StubRuleSetStore{ruleSets: map[uint]models.RuleSet{1: ruleSet}, expectToCall: map[string]bool{},
+			deductions: []models.Deduction{{Slug: models.PersonalSlug, Amount: 60_000, MinAmount: 10_000, MaxAmount: 100_000, Version: 3}}}
+		stub.err = errors.New("error 'xxx' occured")
+
+		_, err := NewRuleSetService(stub).ActivateRuleSet(context.Background(), 1, "editor")
+
+		assertIsEqual(t, stub.err, err, "expect error from db")
+		assertIsEqual(t, uint(0), stub.activeId, "expect no rule set is active in db")
+		assertIsEqual(t, 0, len(stub.changes), "expect no change is proposed")
+		assertIsEqual(t, "default", ActiveRuleSet().Name, "expect default rule set is still applied")
+	})
+	t.Run("given cap differ from deduction should propose pending change instead of write it", func(t *testing.T) {
+		restoreDefaultRuleSet(t)
+		ruleSet, _ := ParseRuleSet([]byte(flatRuleSetJson), models.JsonRuleSetFormat)
+		ruleSet.Id = 1
+		stub := &StubRuleSetStore{ruleSets: map[uint]models.RuleSet{1: ruleSet}, expectToCall: map[string]bool{},
+			deductions: []models.Deduction{{Slug: models.PersonalSlug, Amount: 60_000, MinAmount: 10_000, MaxAmount: 100_000, Version: 3}}}
+
+		_, err := NewRuleSetService(stub).ActivateRuleSet(context.Background(), 1, "editor")
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, []models.DeductionChange{{
+			Id: 1, Slug: models.PersonalSlug, Amount: 100_000, Version: 3, Status: models.PendingChange, ProposedBy: "editor",
+		}}, stub.changes)
+		assertIsEqual(t, "flat", ActiveRuleSet().Name, "expect flat rule set is applied")
+	})
+	t.Run("given cap equal to deduction should not propose change", func(t *testing.T) {
+		restoreDefaultRuleSet(t)
+		ruleSet, _ := ParseRuleSet([]byte(flatRuleSetJson), models.JsonRuleSetFormat)
+		ruleSet.Id = 1
+		stub := &StubRuleSetStore{ruleSets: map[uint]models.RuleSet{1: ruleSet}, expectToCall: map[string]bool{},
+			deductions: []models.Deduction{{Slug: models.PersonalSlug, Amount: 100_000, MinAmount: 10_000, MaxAmount: 100_000, Version: 3}}}
+
+		_, err := NewRuleSetService(stub).ActivateRuleSet(context.Background(), 1, "editor")
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 0, len(stub.changes), "expect no change is proposed")
+	})
+	t.Run("given cap out of deduction limit should not activate rule set", func(t *testing.T) {
+		ruleSet, _ := ParseRuleSet([]byte(flatRuleSetJson), models.JsonRuleSetFormat)
+		ruleSet.Id = 1
+		stub := &StubRuleSetStore{ruleSets: map[uint]models.RuleSet{1: ruleSet}, expectToCall: map[string]bool{},
+			deductions: []models.Deduction{{Slug: models.PersonalSlug, Amount: 60_000, MinAmount: 10_000, MaxAmount: 80_000, Version: 1}}}
+
+		_, err := NewRuleSetService(stub).ActivateRuleSet(context.Background(), 1, "editor")
+
+		if !errors.Is(err, ErrDeductionAmountInvalid) {
+			t.Errorf("expect error %q but got %v", ErrDeductionAmountInvalid, err)
+		}
+		assertIsEqual(t, false, stub.expectToCall["ActivateRuleSet"], "expect activate is not called")
+		assertIsEqual(t, 0, len(stub.changes), "expect no change is proposed")
+		assertIsEqual(t, "default", ActiveRuleSet().Name, "expect default rule set is still applied")
+	})
+	t.Run("given create with activate and cap out of deduction limit should not save rule set", func(t *testing.T) {
+		stub := &StubRuleSetStore{ruleSets: map[uint]models.RuleSet{}, expectToCall: map[string]bool{},
+			deductions: []models.Deduction{{Slug: models.PersonalSlug, Amount: 60_000, MinAmount: 10_000, MaxAmount: 80_000, Version: 1}}}
+		ruleSet, _ := ParseRuleSet([]byte(flatRuleSetJson), models.JsonRuleSetFormat)
+
+		_, err := NewRuleSetService(stub).CreateRuleSet(context.Background(), ruleSet, true, "editor")
+
+		if !errors.Is(err, ErrDeductionAmountInvalid) {
+			t.Errorf("expect error %q but got %v", ErrDeductionAmountInvalid, err)
+		}
+		assertIsEqual(t, false, stub.expectToCall["CreateRuleSet"], "expect rule set is not saved")
+	})
+	t.Run("given no rule set in db should return empty list", func(t *testing.T) {
+		stub := &StubRuleSetStore{ruleSets: map[uint]models.RuleSet{}, expectToCall: map[string]bool{}}
+
+		got, err := NewRuleSetService(stub).GetRuleSets(context.Background())
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, []models.RuleSet{}, got)
+	})
+}
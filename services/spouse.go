@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/baronight/assessment-tax/validators"
+)
+
+type SpouseService struct {
+	Db TaxStorer
+}
+
+// SpouseCsvFields map csv header to spouse and field name of TaxCsv
+var SpouseCsvFields map[string][2]string = map[string][2]string{
+	"totalIncome":       {"taxpayer", "totalIncome"},
+	"wht":               {"taxpayer", "wht"},
+	"donation":          {"taxpayer", "donation"},
+	"k-receipt":         {"taxpayer", "k-receipt"},
+	"spouseTotalIncome": {"spouse", "totalIncome"},
+	"spouseWht":         {"spouse", "wht"},
+	"spouseDonation":    {"spouse", "donation"},
+	"spouseKReceipt":    {"spouse", "k-receipt"},
+}
+
+func NewSpouseService(db TaxStorer) *SpouseService {
+	return &SpouseService{
+		Db: db,
+	}
+}
+
+// GetSpouseConfig return deduction config and rules of active rule set for calculate tax with spouse allowance
+func (ss *SpouseService) GetSpouseConfig(ctx context.Context) (input TaxInput, err error) {
+	ds, err := ss.Db.GetDeductions(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return input, err
+	}
+	return newTaxInput(models.TaxRequest{}, ds), nil
+}
+
+func netTax(tax models.TaxResponse) float64 {
+	return tax.Tax - tax.TaxRefund
+}
+
+// spouseMembers return family members with spouse that is counted only when spouse has no income
+func spouseMembers(members map[string]int, spouseIncome float64) map[string]int {
+	members = maps.Clone(members)
+	if members == nil {
+		members = map[string]int{}
+	}
+	delete(members, models.SpouseMember)
+	if spouseIncome == 0 {
+		members[models.SpouseMember] = 1
+	}
+	return members
+}
+
+// CalculateSpouseTaxOutput compare tax of joint filing and separate filing, family members of config are
+// the ones of taxpayer. Spouse allowance is applied on joint filing, and on separate filing only when
+// the other spouse has no income.
+func CalculateSpouseTaxOutput(tax models.SpouseTaxRequest, config TaxInput) models.SpouseTaxResponse {
+	var result models.SpouseTaxResponse
+	if config.rules == nil {
+		config.ruleSet, config.rules = activeRules()
+	}
+
+	// separate filing
+	taxpayerInput, spouseInput := config, config
+	taxpayerInput.tax, spouseInput.tax = tax.Taxpayer, tax.Spouse
+	taxpayerInput.members = spouseMembers(config.members, tax.Spouse.TotalIncome)
+	spouseInput.members = spouseMembers(nil, tax.Taxpayer.TotalIncome)
+	result.Separate.Taxpayer = CalculateTaxOutput(taxpayerInput)
+	result.Separate.Spouse = CalculateTaxOutput(spouseInput)
+	separate := netTax(result.Separate.Taxpayer) + netTax(result.Separate.Spouse)
+	if separate < 0 {
+		result.Separate.TaxRefund = math.Round(-separate*100) / 100
+	} else {
+		result.Separate.Tax = math.Round(separate*100) / 100
+	}
+
+	// joint filing is one return of taxpayer with income of both spouses
+	jointInput := config
+	jointInput.tax, jointInput.deductions = jointTaxRequest(tax, config)
+	jointInput.members = spouseMembers(config.members, 0)
+	result.Joint = CalculateTaxOutput(jointInput)
+
+	result.Recommendation = models.SeparateFiling
+	if netTax(result.Joint) < separate {
+		result.Recommendation = models.JointFiling
+	}
+	return result
+}
+
+// jointTaxRequest combine tax requests of both spouses into request of joint filing with its deduction config.
+// Claim of every allowance rule is limited by its cap for each spouse before combine, so combined claim has no cap,
+// then percent limit and period of rule apply on joint filing as on any other tax request.
+func jointTaxRequest(tax models.SpouseTaxRequest, config TaxInput) (models.TaxRequest, map[string]models.Deduction) {
+	joint := tax.Taxpayer
+	joint.TotalIncome = tax.Taxpayer.TotalIncome + tax.Spouse.TotalIncome
+	joint.Wht = tax.Taxpayer.Wht + tax.Spouse.Wht
+	joint.PrepaidTax = tax.Taxpayer.PrepaidTax + tax.Spouse.PrepaidTax
+	// both spouses file in the same period, it is validated by validators.ValidateSpouseTaxRequest
+	joint.Allowances = []models.Allowance{}
+	deductions := maps.Clone(config.deductions)
+	for _, rule := range config.rules {
+		if !isAllowanceRule(rule) {
+			continue
+		}
+		slug := rule.Slug()
+		joint.Allowances = append(joint.Allowances, models.Allowance{
+			Type: slug,
+			Amount: CalculateDeductionByType(slug, tax.Taxpayer.Allowances, config.deductions[slug]) +
+				CalculateDeductionByType(slug, tax.Spouse.Allowances, config.deductions[slug]),
+		})
+		deductions[slug] = models.Deduction{Slug: slug}
+	}
+	return joint, deductions
+}
+
+func (ss *SpouseService) SpouseTaxCalculate(ctx context.Context, tax models.SpouseTaxRequest) (models.SpouseTaxResponse, error) {
+	// amounts of each spouse are converted to THB before they are compared or combined
+	taxService := NewTaxService(ss.Db)
+	taxpayer, taxpayerConverted, err := taxService.ConvertToThb(ctx, tax.Taxpayer)
+	if err != nil {
+		return models.SpouseTaxResponse{}, err
+	}
+	spouse, spouseConverted, err := taxService.ConvertToThb(ctx, tax.Spouse)
+	if err != nil {
+		return models.SpouseTaxResponse{}, err
+	}
+	tax = models.SpouseTaxRequest{Taxpayer: taxpayer, Spouse: spouse}
+
+	config, err := ss.GetSpouseConfig(ctx)
+	if err != nil {
+		return models.SpouseTaxResponse{}, err
+	}
+	if taxpayer.TaxpayerId != "" {
+		if config.members, err = taxService.GetFamilyMembers(ctx, taxpayer.TaxpayerId); err != nil {
+			return models.SpouseTaxResponse{}, err
+		}
+	}
+
+	result := CalculateSpouseTaxOutput(tax, config)
+	joint, _ := jointTaxRequest(tax, config)
+	if result.Joint, err = taxService.addPaymentTerms(ctx, result.Joint, joint); err != nil {
+		return models.SpouseTaxResponse{}, err
+	}
+	if result.Separate.Taxpayer, err = taxService.addPaymentTerms(ctx, result.Separate.Taxpayer, taxpayer); err != nil {
+		return models.SpouseTaxResponse{}, err
+	}
+	if result.Separate.Spouse, err = taxService.addPaymentTerms(ctx, result.Separate.Spouse, spouse); err != nil {
+		return models.SpouseTaxResponse{}, err
+	}
+	result.Joint.ExchangeRates = append(slices.Clone(taxpayerConverted), spouseConverted...)
+	result.Separate.Taxpayer.ExchangeRates = taxpayerConverted
+	result.Separate.Spouse.ExchangeRates = spouseConverted
+	observeSpouseTax(result)
+	return result, nil
+}
+
+// observeSpouseTax count spouse calculation with outcome of recommended filing
+func observeSpouseTax(result models.SpouseTaxResponse) {
+	if result.Recommendation == models.JointFiling {
+		metrics.ObserveTax("spouse", result.Joint.Tax, result.Joint.TaxRefund)
+		return
+	}
+	metrics.ObserveTax("spouse", result.Separate.Tax, result.Separate.TaxRefund)
+}
+
+func (ss *SpouseService) ExtractSpouseCsv(reader io.Reader) ([]models.SpouseTaxCsv, error) {
+	taxes := []models.SpouseTaxCsv{}
+	csvReader := csv.NewReader(reader)
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, utils.ErrCsvHeaderMissing
+	}
+	header := rows[0]
+	if !validators.IsAllStringInArray(header, []string{"totalIncome", "wht", "donation", "spouseTotalIncome", "spouseWht", "spouseDonation"}) {
+		return nil, utils.ErrCsvHeaderMissing
+	}
+	for _, row := range rows[1:] {
+		var tax models.SpouseTaxCsv
+		for idx, col := range row {
+			field, ok := SpouseCsvFields[header[idx]]
+			if !ok {
+				continue
+			}
+			if col == "" {
+				return nil, utils.ErrCsvValueEmpty
+			}
+			val, err := strconv.ParseFloat(col, 64)
+			if err != nil {
+				return nil, err
+			}
+			target := &tax.Taxpayer
+			if field[0] == "spouse" {
+				target = &tax.Spouse
+			}
+			switch field[1] {
+			case "totalIncome":
+				target.TotalIncome = val
+			case "wht":
+				target.Wht = val
+			case "donation":
+				target.Donation = val
+			case "k-receipt":
+				target.KReceipt = val
+			}
+		}
+		// validate each row data when it is all number value
+		if err := validators.ValidateSpouseTaxCsv(tax); err != nil {
+			return nil, err
+		}
+		taxes = append(taxes, tax)
+	}
+	return taxes, nil
+}
+
+func (ss *SpouseService) CalculateSpouseTaxCsv(ctx context.Context, taxes []models.SpouseTaxCsv) (models.SpouseTaxCsvResponse, error) {
+	var result models.SpouseTaxCsvResponse = models.SpouseTaxCsvResponse{
+		Taxes: []models.SpouseCsvCalculateResult{},
+	}
+
+	config, err := ss.GetSpouseConfig(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, tax := range taxes {
+		output := CalculateSpouseTaxOutput(models.SpouseTaxRequest{
+			Taxpayer: TransformTaxCsvToTaxRequest(tax.Taxpayer),
+			Spouse:   TransformTaxCsvToTaxRequest(tax.Spouse),
+		}, config)
+		observeSpouseTax(output)
+		result.Taxes = append(result.Taxes, models.SpouseCsvCalculateResult{
+			TotalIncome:       tax.Taxpayer.TotalIncome,
+			SpouseTotalIncome: tax.Spouse.TotalIncome,
+			JointTax:          output.Joint.Tax,
+			JointTaxRefund:    output.Joint.TaxRefund,
+			SeparateTax:       output.Separate.Tax,
+			SeparateTaxRefund: output.Separate.TaxRefund,
+			Recommendation:    output.Recommendation,
+		})
+	}
+	return result, nil
+}
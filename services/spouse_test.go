@@ -0,0 +1,293 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+func TestCalculateSpouseTaxOutput(t *testing.T) {
+	config := TaxInput{
+		deductions: map[string]models.Deduction{
+			models.PersonalSlug: {Slug: models.PersonalSlug, Amount: 60_000},
+			models.DonationSlug: {Slug: models.DonationSlug, Amount: 100_000},
+			models.KReceiptSlug: {Slug: models.KReceiptSlug, Amount: 50_000},
+			models.SpouseSlug:   {Slug: models.SpouseSlug, Amount: 60_000},
+		},
+	}
+	t.Run("given spouse has no income should apply spouse allowance on both filing", func(t *testing.T) {
+		result := CalculateSpouseTaxOutput(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 500_000},
+		}, config)
+
+		assertIsEqual(t, 23_000.0, result.Joint.Tax, expectTaxValueMsg(23_000, result.Joint.Tax))
+		assertIsEqual(t, 23_000.0, result.Separate.Taxpayer.Tax, expectTaxValueMsg(23_000, result.Separate.Taxpayer.Tax))
+		assertIsEqual(t, 0.0, result.Separate.Spouse.Tax, expectTaxValueMsg(0, result.Separate.Spouse.Tax))
+		assertIsEqual(t, 23_000.0, result.Separate.Tax, expectTaxValueMsg(23_000, result.Separate.Tax))
+		assertIsEqual(t, models.SeparateFiling, result.Recommendation, "expect recommend separate filing when tax is equal")
+	})
+	t.Run("given both spouses have income should recommend separate filing", func(t *testing.T) {
+		result := CalculateSpouseTaxOutput(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 1_000_000},
+			Spouse:   models.TaxRequest{TotalIncome: 300_000},
+		}, config)
+
+		assertIsEqual(t, 146_000.0, result.Joint.Tax, expectTaxValueMsg(146_000, result.Joint.Tax))
+		assertIsEqual(t, 101_000.0, result.Separate.Taxpayer.Tax, expectTaxValueMsg(101_000, result.Separate.Taxpayer.Tax))
+		assertIsEqual(t, 9_000.0, result.Separate.Spouse.Tax, expectTaxValueMsg(9_000, result.Separate.Spouse.Tax))
+		assertIsEqual(t, 110_000.0, result.Separate.Tax, expectTaxValueMsg(110_000, result.Separate.Tax))
+		assertIsEqual(t, models.SeparateFiling, result.Recommendation, "expect recommend separate filing")
+	})
+	t.Run("given allowances should limit each spouse before combine on joint filing", func(t *testing.T) {
+		result := CalculateSpouseTaxOutput(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 600_000, Allowances: []models.Allowance{{Type: models.KReceiptSlug, Amount: 80_000}}},
+			Spouse:   models.TaxRequest{TotalIncome: 400_000, Allowances: []models.Allowance{{Type: models.KReceiptSlug, Amount: 10_000}}},
+		}, config)
+
+		assertIsEqual(t, 83_000.0, result.Joint.Tax, expectTaxValueMsg(83_000, result.Joint.Tax))
+		assertIsEqual(t, 52_000.0, result.Separate.Tax, expectTaxValueMsg(52_000, result.Separate.Tax))
+	})
+	t.Run("given wht more than tax should return refund on both filing", func(t *testing.T) {
+		result := CalculateSpouseTaxOutput(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 500_000, Wht: 30_000},
+		}, config)
+
+		assertIsEqual(t, 7_000.0, result.Joint.TaxRefund, expectTaxRefundValueMsg(7_000, result.Joint.TaxRefund))
+		assertIsEqual(t, 7_000.0, result.Separate.TaxRefund, expectTaxRefundValueMsg(7_000, result.Separate.TaxRefund))
+	})
+	t.Run("given joint tax lower than separate should recommend joint filing", func(t *testing.T) {
+		// spouse has small income so spouse allowance can apply only on joint filing
+		noLimit := config
+		noLimit.deductions = maps.Clone(config.deductions)
+		noLimit.deductions[models.SpouseSlug] = models.Deduction{Slug: models.SpouseSlug, Amount: 200_000}
+		result := CalculateSpouseTaxOutput(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 500_000},
+			Spouse:   models.TaxRequest{TotalIncome: 10_000},
+		}, noLimit)
+
+		assertIsEqual(t, models.JointFiling, result.Recommendation, "expect recommend joint filing")
+	})
+	t.Run("given prepaid tax should credit it on joint filing as on separate filing", func(t *testing.T) {
+		result := CalculateSpouseTaxOutput(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 1_000_000, PrepaidTax: 20_000},
+			Spouse:   models.TaxRequest{TotalIncome: 300_000, PrepaidTax: 5_000},
+		}, config)
+
+		assertIsEqual(t, 121_000.0, result.Joint.Tax, expectTaxValueMsg(121_000, result.Joint.Tax))
+		assertIsEqual(t, 85_000.0, result.Separate.Tax, expectTaxValueMsg(85_000, result.Separate.Tax))
+	})
+	t.Run("given half-year period should halve spouse allowance on joint filing", func(t *testing.T) {
+		result := CalculateSpouseTaxOutput(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 500_000, Period: models.HalfYearPeriod},
+			Spouse:   models.TaxRequest{Period: models.HalfYearPeriod},
+		}, config)
+
+		// half of personal and spouse allowance: 500,000 - 30,000 - 30,000
+		assertIsEqual(t, 29_000.0, result.Joint.Tax, expectTaxValueMsg(29_000, result.Joint.Tax))
+		assertIsEqual(t, result.Separate.Tax, result.Joint.Tax, "expect joint filing apply the same period as separate filing")
+	})
+	t.Run("given allowance rule of rule set should combine claim of both spouses on joint filing", func(t *testing.T) {
+		restoreDefaultRuleSet(t)
+		ruleSet := ActiveRuleSet()
+		ruleSet.Deductions = append(slices.Clone(ruleSet.Deductions), models.RuleDeduction{
+			Slug: "provident-fund", Type: models.AllowanceRuleType, Amount: 500_000, PercentLimit: 15,
+		})
+		ApplyRuleSet(ruleSet)
+
+		result := CalculateSpouseTaxOutput(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 600_000, Allowances: []models.Allowance{{Type: "provident-fund", Amount: 100_000}}},
+			Spouse:   models.TaxRequest{TotalIncome: 400_000, Allowances: []models.Allowance{{Type: "provident-fund", Amount: 50_000}}},
+		}, config)
+
+		// 1,000,000 - 60,000 personal - 60,000 spouse - 150,000 provident fund limited to 15% of joint income
+		assertIsEqual(t, 69_500.0, result.Joint.Tax, expectTaxValueMsg(69_500, result.Joint.Tax))
+	})
+	t.Run("given family members of taxpayer should apply their allowances on joint filing and separate filing of taxpayer", func(t *testing.T) {
+		withChildren := config
+		withChildren.deductions = maps.Clone(config.deductions)
+		withChildren.deductions[models.ChildSlug] = models.Deduction{Slug: models.ChildSlug, Amount: 30_000}
+		withChildren.members = map[string]int{models.ChildMember: 2}
+		result := CalculateSpouseTaxOutput(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 500_000},
+		}, withChildren)
+
+		// 500,000 - 60,000 personal - 60,000 spouse - 60,000 for 2 children
+		assertIsEqual(t, 17_000.0, result.Joint.Tax, expectTaxValueMsg(17_000, result.Joint.Tax))
+		assertIsEqual(t, 17_000.0, result.Separate.Taxpayer.Tax, expectTaxValueMsg(17_000, result.Separate.Taxpayer.Tax))
+		assertIsEqual(t, 0.0, result.Separate.Spouse.Tax, expectTaxValueMsg(0, result.Separate.Spouse.Tax))
+	})
+}
+
+func TestSpouseTaxCalculate(t *testing.T) {
+	t.Run("given error on get deduction config should return error", func(t *testing.T) {
+		stub := initStub(nil, errors.New("error 'xxx' occured"))
+		s := NewSpouseService(&stub)
+
+		_, err := s.SpouseTaxCalculate(context.Background(), models.SpouseTaxRequest{})
+
+		if err == nil {
+			t.Fatal("expect error should not be null")
+		}
+	})
+	t.Run("given spouse deduction in db should use it as spouse allowance", func(t *testing.T) {
+		stub := initStub([]models.Deduction{{Slug: models.SpouseSlug, Amount: 30_000}}, nil)
+		s := NewSpouseService(&stub)
+
+		result, err := s.SpouseTaxCalculate(context.Background(), models.SpouseTaxRequest{Taxpayer: models.TaxRequest{TotalIncome: 500_000}})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodCalledTime(t, "GetDeductions", 1)
+		assertIsEqual(t, 26_000.0, result.Joint.Tax, expectTaxValueMsg(26_000, result.Joint.Tax))
+	})
+	t.Run("given foreign currency should convert both spouses to THB before calculate", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.exchangeRates = map[string]models.ExchangeRate{"USD": {Currency: "USD", Rate: 35, RateDate: "2024-12-30"}}
+		s := NewSpouseService(&stub)
+
+		result, err := s.SpouseTaxCalculate(context.Background(), models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 20_000, Currency: "USD", RateDate: "2024-12-31"},
+			Spouse:   models.TaxRequest{TotalIncome: 300_000},
+		})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		// 20,000 USD * 35 - 60,000 personal allowance
+		assertIsEqual(t, 56_000.0, result.Separate.Taxpayer.Tax, expectTaxValueMsg(56_000, result.Separate.Taxpayer.Tax))
+		if len(result.Separate.Taxpayer.ExchangeRates) != 1 || result.Separate.Taxpayer.ExchangeRates[0].AmountThb != 700_000 {
+			t.Errorf("expect converted total income of taxpayer but got %#v", result.Separate.Taxpayer.ExchangeRates)
+		}
+		if len(result.Separate.Spouse.ExchangeRates) != 0 {
+			t.Errorf("expect no conversion of spouse in THB but got %#v", result.Separate.Spouse.ExchangeRates)
+		}
+		if len(result.Joint.ExchangeRates) != 1 || result.Joint.ExchangeRates[0].AmountThb != 700_000 {
+			t.Errorf("expect converted total income of taxpayer on joint filing but got %#v", result.Joint.ExchangeRates)
+		}
+	})
+	t.Run("given taxpayer profile and late filing should apply family allowances and payment terms on joint filing", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.taxpayers = map[string]models.Taxpayer{"1234567890121": {NationalId: "1234567890121", MaritalStatus: models.MarriedStatus, Children: 1}}
+		s := NewSpouseService(&stub)
+
+		result, err := s.SpouseTaxCalculate(context.Background(), models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TaxpayerId: "1234567890121", TotalIncome: 500_000, FilingDate: "2025-04-30", DueDate: "2025-03-31"},
+		})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodCalledTime(t, "GetTaxpayer", 1)
+		// 500,000 - 60,000 personal - 60,000 spouse - 30,000 child
+		assertIsEqual(t, 20_000.0, result.Joint.Tax, expectTaxValueMsg(20_000, result.Joint.Tax))
+		assertIsEqual(t, 300.0, result.Joint.Surcharge, "expect surcharge 1.5% of 20,000")
+		assertIsEqual(t, DefaultLateFilingPenalty, result.Joint.Penalty, "expect default late filing penalty")
+		assertIsEqual(t, result.Joint.Surcharge, result.Separate.Taxpayer.Surcharge, "expect the same surcharge on separate filing of taxpayer")
+	})
+	t.Run("given filing on time should return installments of joint filing", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		s := NewSpouseService(&stub)
+
+		result, err := s.SpouseTaxCalculate(context.Background(), models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 600_000, FilingDate: "2025-03-01", DueDate: "2025-03-31"},
+			Spouse:   models.TaxRequest{TotalIncome: 400_000},
+		})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		if len(result.Joint.Installments) == 0 {
+			t.Error("expect installments of joint filing")
+		}
+	})
+	t.Run("given unknown taxpayer profile should return error", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		s := NewSpouseService(&stub)
+
+		_, err := s.SpouseTaxCalculate(context.Background(), models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TaxpayerId: "1234567890121", TotalIncome: 500_000},
+		})
+
+		if err == nil {
+			t.Fatal("expect error should not be null")
+		}
+	})
+	t.Run("given no exchange rate should return error", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		s := NewSpouseService(&stub)
+
+		_, err := s.SpouseTaxCalculate(context.Background(), models.SpouseTaxRequest{
+			Spouse: models.TaxRequest{TotalIncome: 10_000, Currency: "USD"},
+		})
+
+		if !errors.Is(err, utils.ErrExchangeRateNotFound) {
+			t.Errorf("expect %q but got %v", utils.ErrExchangeRateNotFound, err)
+		}
+	})
+}
+
+func TestFuncExtractSpouseCsv(t *testing.T) {
+	s := NewSpouseService(nil)
+	t.Run("when csv is empty should return error 'missing required header field'", func(t *testing.T) {
+		_, err := s.ExtractSpouseCsv(strings.NewReader(""))
+
+		assertObjectIsEqual(t, utils.ErrCsvHeaderMissing, err)
+	})
+	t.Run("when csv is missing spouse colum should return error 'missing required header field'", func(t *testing.T) {
+		dir, _ := os.Getwd()
+		fileData, err := os.Open(filepath.Join(dir, "../testdata/valid-taxes.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fileData.Close()
+
+		_, err = s.ExtractSpouseCsv(fileData)
+
+		assertObjectIsEqual(t, errors.New("missing required header field"), err)
+	})
+	t.Run("when valid csv field should return array of spouse tax csv data", func(t *testing.T) {
+		dir, _ := os.Getwd()
+		fileData, err := os.Open(filepath.Join(dir, "../testdata/valid-spouse-taxes.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fileData.Close()
+
+		result, err := s.ExtractSpouseCsv(fileData)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, []models.SpouseTaxCsv{
+			{Taxpayer: models.TaxCsv{TotalIncome: 500_000, Wht: 30_000}},
+			{
+				Taxpayer: models.TaxCsv{TotalIncome: 600_000, KReceipt: 80_000},
+				Spouse:   models.TaxCsv{TotalIncome: 400_000, KReceipt: 10_000},
+			},
+		}, result)
+	})
+}
+
+func TestFuncCalculateSpouseTaxCsv(t *testing.T) {
+	stub := initStub([]models.Deduction{}, nil)
+	s := NewSpouseService(&stub)
+
+	result, err := s.CalculateSpouseTaxCsv(context.Background(), []models.SpouseTaxCsv{
+		{Taxpayer: models.TaxCsv{TotalIncome: 500_000, Wht: 30_000}},
+		{
+			Taxpayer: models.TaxCsv{TotalIncome: 600_000, KReceipt: 80_000},
+			Spouse:   models.TaxCsv{TotalIncome: 400_000, KReceipt: 10_000},
+		},
+	})
+
+	assertIsNil(t, err, expectNilErrMsg)
+	stub.assertMethodCalledTime(t, "GetDeductions", 1)
+	assertObjectIsEqual(t, models.SpouseTaxCsvResponse{
+		Taxes: []models.SpouseCsvCalculateResult{
+			{TotalIncome: 500_000, JointTaxRefund: 7_000, SeparateTaxRefund: 7_000, Recommendation: models.SeparateFiling},
+			{TotalIncome: 600_000, SpouseTotalIncome: 400_000, JointTax: 83_000, SeparateTax: 52_000, Recommendation: models.SeparateFiling},
+		},
+	}, result)
+}
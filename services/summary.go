@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/baronight/assessment-tax/fonts"
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+type SummaryService struct {
+	Db TaxReturnStorer
+}
+
+func NewSummaryService(db TaxReturnStorer) *SummaryService {
+	return &SummaryService{
+		Db: db,
+	}
+}
+
+// AppliedAllowances list allowances that deducted from income with claimed amount and cap of each one.
+// Cap 0 mean no limit. Family allowances are listed only when taxpayer profile is sent.
+// Return that was saved before its rule set was kept in snapshot is shown with active rule set.
+func AppliedAllowances(tax models.TaxRequest, config models.TaxConfig, taxpayer *models.Taxpayer) []models.AppliedAllowance {
+	ruleSet, ds := config.RuleSet, config.Deductions
+	if len(ruleSet.Brackets) == 0 {
+		ruleSet = ActiveRuleSet()
+	}
+	configs := deductionConfigs(ruleSet, ds)
+	var members map[string]int
+	if taxpayer != nil {
+		members = FamilyMembers(*taxpayer)
+	}
+	// fixed deductions of rule set come first, then family and claimed allowances
+	var fixed, family, claimed []models.AppliedAllowance
+	for _, v := range ruleSet.Deductions {
+		config := configs[v.Slug]
+		switch v.Type {
+		case models.FixedRuleType:
+			fixed = append(fixed, models.AppliedAllowance{
+				Type: v.Slug, Claimed: config.Amount, Cap: config.Amount, Applied: RuleSetDeduction{v}.Allowed(tax, config),
+			})
+		case models.FamilyRuleType:
+			if members[v.Member] == 0 {
+				continue
+			}
+			config.Amount *= float64(members[v.Member])
+			family = append(family, models.AppliedAllowance{
+				Type: v.Slug, Claimed: config.Amount, Cap: config.Amount, Applied: RuleSetDeduction{v}.Allowed(tax, config),
+			})
+		default:
+			claimed = append(claimed, models.AppliedAllowance{
+				Type:    v.Slug,
+				Claimed: CalculateDeductionByType(v.Slug, tax.Allowances, models.Deduction{}),
+				Cap:     config.Amount,
+				Applied: RuleSetDeduction{v}.Allowed(tax, config),
+			})
+		}
+	}
+	return append(append(fixed, family...), claimed...)
+}
+
+// BuildTaxSummary make summary of calculated result with allowances that applied by config it is calculated with
+func BuildTaxSummary(tax models.TaxRequest, result models.TaxResponse, config models.TaxConfig) models.TaxSummary {
+	return models.TaxSummary{
+		Request:    tax,
+		Response:   result,
+		Allowances: AppliedAllowances(config.RequestThb, config, config.Taxpayer),
+	}
+}
+
+func (ss *SummaryService) TaxCalculateSummary(ctx context.Context, tax models.TaxRequest) (models.TaxSummary, error) {
+	result, config, err := NewTaxService(ss.Db).TaxCalculateWithConfig(ctx, tax)
+	if err != nil {
+		return models.TaxSummary{}, err
+	}
+	return BuildTaxSummary(tax, result, config), nil
+}
+
+// TaxReturnConfig return snapshot of config that saved tax return is calculated with. Return that was saved
+// before its request in THB was kept get it from converted amounts of its response, family allowances of it
+// cannot be known without profile snapshot.
+func TaxReturnConfig(taxReturn models.TaxReturn) (models.TaxConfig, error) {
+	config := models.TaxConfig{
+		Deductions: taxReturn.Deductions,
+		RuleSet:    taxReturn.RuleSet,
+		Taxpayer:   taxReturn.Taxpayer,
+	}
+	if taxReturn.RequestThb != nil {
+		config.RequestThb = *taxReturn.RequestThb
+		return config, nil
+	}
+	if taxReturn.Request.TaxpayerId != "" {
+		return config, utils.ErrTaxReturnSnapshotMissing
+	}
+	config.RequestThb = ConvertedRequest(taxReturn.Request, taxReturn.Response.ExchangeRates)
+	return config, nil
+}
+
+// TaxReturnSummary make summary of saved tax return only from its snapshot, so it is the same as when it was saved
+func (ss *SummaryService) TaxReturnSummary(ctx context.Context, id uint) (models.TaxSummary, error) {
+	taxReturn, err := NewTaxReturnService(ss.Db).GetTaxReturn(ctx, id)
+	if err != nil {
+		return models.TaxSummary{}, err
+	}
+	config, err := TaxReturnConfig(taxReturn)
+	if err != nil {
+		return models.TaxSummary{}, err
+	}
+	summary := BuildTaxSummary(taxReturn.Request, taxReturn.Response, config)
+	summary.TaxReturnId = taxReturn.Id
+	summary.TaxYear = taxReturn.TaxYear
+	return summary, nil
+}
+
+// WriteTaxSummaryPdf render summary as A4 pdf with embedded fonts
+func (ss *SummaryService) WriteTaxSummaryPdf(w io.Writer, summary models.TaxSummary) error {
+	regular, err := fonts.Load(fonts.Regular)
+	if err != nil {
+		return err
+	}
+	bold, err := fonts.Load(fonts.Bold)
+	if err != nil {
+		return err
+	}
+	p := message.NewPrinter(language.English)
+	amount := func(v float64) string { return p.Sprintf("%.2f", v) }
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("summary", "", regular)
+	pdf.AddUTF8FontFromBytes("summary", "B", bold)
+	pdf.AddPage()
+
+	heading := func(text string) {
+		pdf.Ln(4)
+		pdf.SetFont("summary", "B", 12)
+		pdf.CellFormat(0, 8, text, "B", 1, "L", false, 0, "")
+		pdf.SetFont("summary", "", 10)
+	}
+	row := func(widths []float64, cells ...string) {
+		for i, v := range cells {
+			align := "R"
+			if i == 0 {
+				align = "L"
+			}
+			pdf.CellFormat(widths[i], 7, v, "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	header := func(widths []float64, cells ...string) {
+		pdf.SetFont("summary", "B", 10)
+		row(widths, cells...)
+		pdf.SetFont("summary", "", 10)
+	}
+
+	pdf.SetFont("summary", "B", 16)
+	pdf.CellFormat(0, 10, "Personal Income Tax Summary", "", 1, "C", false, 0, "")
+	pdf.SetFont("summary", "", 10)
+	if summary.TaxReturnId != 0 {
+		pdf.CellFormat(0, 6, fmt.Sprintf("Tax return #%d, tax year %d", summary.TaxReturnId, summary.TaxYear), "", 1, "C", false, 0, "")
+	}
+
+	tax := summary.Request
+	currency := tax.Currency
+	if currency == "" {
+		currency = models.ThbCurrency
+	}
+	inputs := [][2]string{
+		{"Taxpayer ID", tax.TaxpayerId},
+		{"Period", tax.Period},
+		{"Income type", tax.IncomeType},
+		{"Total income (" + currency + ")", amount(tax.TotalIncome)},
+		{"Withholding tax (" + currency + ")", amount(tax.Wht)},
+		{"Prepaid tax", amount(tax.PrepaidTax)},
+		{"Filing date", tax.FilingDate},
+		{"Due date", tax.DueDate},
+	}
+	heading("Inputs")
+	for _, v := range inputs {
+		if v[1] == "" {
+			continue
+		}
+		row([]float64{95, 95}, v[0], v[1])
+	}
+
+	widths := []float64{70, 40, 40, 40}
+	heading("Allowances (THB)")
+	header(widths, "Allowance", "Claimed", "Cap", "Applied")
+	for _, v := range summary.Allowances {
+		limit := "no limit"
+		if v.Cap > 0 {
+			limit = amount(v.Cap)
+		}
+		row(widths, v.Type, amount(v.Claimed), limit, amount(v.Applied))
+	}
+
+	heading("Tax by bracket (THB)")
+	header([]float64{110, 80}, "Net income level", "Tax")
+	for _, v := range summary.Response.TaxLevel {
+		row([]float64{110, 80}, v.Level, amount(v.Tax))
+	}
+
+	result := summary.Response
+	heading("Result (THB)")
+	lines := [][2]string{}
+	if result.TaxRefund > 0 {
+		lines = append(lines, [2]string{"Tax refund", amount(result.TaxRefund)})
+	} else {
+		lines = append(lines, [2]string{"Tax payable", amount(result.Tax)})
+	}
+	for _, v := range []struct {
+		name  string
+		value float64
+	}{{"Surcharge", result.Surcharge}, {"Penalty", result.Penalty}, {"Refund interest", result.RefundInterest}} {
+		if v.value > 0 {
+			lines = append(lines, [2]string{v.name, amount(v.value)})
+		}
+	}
+	pdf.SetFont("summary", "B", 10)
+	for _, v := range lines {
+		row([]float64{110, 80}, v[0], v[1])
+	}
+	pdf.SetFont("summary", "", 10)
+
+	if len(result.Installments) > 0 {
+		heading("Installments (THB)")
+		header([]float64{30, 80, 80}, "No", "Due date", "Amount")
+		for _, v := range result.Installments {
+			row([]float64{30, 80, 80}, fmt.Sprint(v.No), v.DueDate, amount(v.Amount))
+		}
+	}
+
+	return pdf.Output(w)
+}
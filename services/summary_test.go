@@ -0,0 +1,153 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+func TestAppliedAllowances(t *testing.T) {
+	ds := []models.Deduction{
+		{Slug: models.PersonalSlug, Amount: 60_000},
+		{Slug: models.DonationSlug, Amount: 100_000},
+		{Slug: models.KReceiptSlug, Amount: 50_000},
+	}
+	t.Run("given allowances over cap should show claimed, cap and applied amount", func(t *testing.T) {
+		got := AppliedAllowances(models.TaxRequest{
+			TotalIncome: 500_000,
+			Allowances: []models.Allowance{
+				{Type: models.DonationSlug, Amount: 150_000},
+				{Type: models.KReceiptSlug, Amount: 20_000},
+			},
+		}, models.TaxConfig{Deductions: ds}, nil)
+
+		assertObjectIsEqual(t, []models.AppliedAllowance{
+			{Type: models.PersonalSlug, Claimed: 60_000, Cap: 60_000, Applied: 60_000},
+			{Type: models.DonationSlug, Claimed: 150_000, Cap: 100_000, Applied: 100_000},
+			{Type: models.KReceiptSlug, Claimed: 20_000, Cap: 50_000, Applied: 20_000},
+		}, got)
+	})
+	t.Run("given taxpayer profile on half-year period should show half of family allowances", func(t *testing.T) {
+		taxpayer := models.Taxpayer{MaritalStatus: models.MarriedStatus, Children: 2}
+
+		got := AppliedAllowances(models.TaxRequest{Period: models.HalfYearPeriod}, models.TaxConfig{Deductions: ds}, &taxpayer)
+
+		assertObjectIsEqual(t, []models.AppliedAllowance{
+			{Type: models.PersonalSlug, Claimed: 60_000, Cap: 60_000, Applied: 30_000},
+			{Type: models.SpouseSlug, Claimed: 60_000, Cap: 60_000, Applied: 30_000},
+			{Type: models.ChildSlug, Claimed: 60_000, Cap: 60_000, Applied: 30_000},
+			{Type: models.DonationSlug, Claimed: 0, Cap: 100_000, Applied: 0},
+			{Type: models.KReceiptSlug, Claimed: 0, Cap: 50_000, Applied: 0},
+		}, got)
+	})
+}
+
+func TestTaxReturnSummary(t *testing.T) {
+	t.Run("given saved return should use its deduction snapshot", func(t *testing.T) {
+		stub := initTaxReturnStub(map[uint]models.TaxReturn{
+			1: {
+				Id:         1,
+				TaxYear:    2567,
+				Request:    models.TaxRequest{TotalIncome: 500_000},
+				Response:   models.TaxResponse{Tax: 29_000},
+				Deductions: []models.Deduction{{Slug: models.PersonalSlug, Amount: 50_000}},
+			},
+		})
+		service := NewSummaryService(stub)
+
+		got, err := service.TaxReturnSummary(context.Background(), 1)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, uint(1), got.TaxReturnId, "expect tax return id should be 1")
+		assertIsEqual(t, 50_000.0, got.Allowances[0].Applied, "expect personal allowance from snapshot")
+		if stub.expectToCall["GetDeductions"] {
+			t.Error("expect GetDeductions was not called")
+		}
+	})
+	t.Run("given snapshot of request and taxpayer should not read current rate and profile", func(t *testing.T) {
+		stub := initTaxReturnStub(map[uint]models.TaxReturn{
+			1: {
+				Id:         1,
+				TaxpayerId: "A",
+				Request:    models.TaxRequest{TaxpayerId: "A", TotalIncome: 20_000, Currency: "USD"},
+				RequestThb: &models.TaxRequest{TaxpayerId: "A", TotalIncome: 700_000},
+				Taxpayer:   &models.Taxpayer{NationalId: "A", Children: 1},
+				Deductions: []models.Deduction{{Slug: models.PersonalSlug, Amount: 60_000}, {Slug: models.ChildSlug, Amount: 30_000}},
+			},
+		})
+		// profile was changed after return was saved
+		stub.taxpayers["A"] = models.Taxpayer{NationalId: "A", Children: 3}
+		service := NewSummaryService(stub)
+
+		got, err := service.TaxReturnSummary(context.Background(), 1)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, models.AppliedAllowance{Type: models.ChildSlug, Claimed: 30_000, Cap: 30_000, Applied: 30_000}, got.Allowances[1])
+		assertObjectIsEqual(t, models.TaxRequest{TaxpayerId: "A", TotalIncome: 20_000, Currency: "USD"}, got.Request)
+		if stub.expectToCall["GetTaxpayer"] || stub.expectToCall["GetExchangeRate"] {
+			t.Error("expect GetTaxpayer and GetExchangeRate were not called")
+		}
+	})
+	t.Run("given return saved before snapshot of request should take THB amounts from its response", func(t *testing.T) {
+		stub := initTaxReturnStub(map[uint]models.TaxReturn{
+			1: {
+				Id:      1,
+				Request: models.TaxRequest{TotalIncome: 20_000, Currency: "USD", Allowances: []models.Allowance{{Type: models.DonationSlug, Amount: 100}}},
+				Response: models.TaxResponse{ExchangeRates: []models.ConvertedAmount{
+					{Field: "totalIncome", Currency: "USD", Amount: 20_000, Rate: 35, AmountThb: 700_000},
+					{Field: "allowances[0]", Currency: "USD", Amount: 100, Rate: 35, AmountThb: 3_500},
+				}},
+				Deductions: []models.Deduction{{Slug: models.PersonalSlug, Amount: 60_000}, {Slug: models.DonationSlug, Amount: 100_000}},
+			},
+		})
+		service := NewSummaryService(stub)
+
+		got, err := service.TaxReturnSummary(context.Background(), 1)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, models.AppliedAllowance{Type: models.DonationSlug, Claimed: 3_500, Cap: 100_000, Applied: 3_500}, got.Allowances[1])
+		if stub.expectToCall["GetExchangeRate"] {
+			t.Error("expect GetExchangeRate was not called")
+		}
+	})
+	t.Run("given return saved with taxpayer id before snapshot of profile should return snapshot missing error", func(t *testing.T) {
+		stub := initTaxReturnStub(map[uint]models.TaxReturn{
+			1: {Id: 1, TaxpayerId: "A", Request: models.TaxRequest{TaxpayerId: "A", TotalIncome: 500_000}},
+		})
+		service := NewSummaryService(stub)
+
+		_, err := service.TaxReturnSummary(context.Background(), 1)
+
+		assertIsEqual(t, utils.ErrTaxReturnSnapshotMissing, err, "expect snapshot missing error")
+	})
+	t.Run("given not exist id should return not found error", func(t *testing.T) {
+		service := NewSummaryService(initTaxReturnStub(nil))
+
+		_, err := service.TaxReturnSummary(context.Background(), 9)
+
+		assertIsEqual(t, utils.ErrTaxReturnNotFound, err, "expect tax return not found error")
+	})
+}
+
+func TestWriteTaxSummaryPdf(t *testing.T) {
+	t.Run("given summary should write pdf document", func(t *testing.T) {
+		stub := initTaxReturnStub(nil)
+		service := NewSummaryService(stub)
+		summary, err := service.TaxCalculateSummary(context.Background(), models.TaxRequest{TotalIncome: 500_000})
+		assertIsNil(t, err, expectNilErrMsg)
+
+		var buf bytes.Buffer
+		err = service.WriteTaxSummaryPdf(&buf, summary)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+			t.Errorf("expect pdf document but got %q", buf.String()[:10])
+		}
+	})
+}
@@ -1,25 +1,32 @@
 package services
 
 import (
+	"cmp"
+	"context"
 	"database/sql"
 	"encoding/csv"
-	"errors"
 	"io"
 	"math"
 	"slices"
 	"strconv"
 
+	"github.com/baronight/assessment-tax/metrics"
 	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
 	"github.com/baronight/assessment-tax/validators"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
 
 type TaxInput struct {
-	tax      models.TaxRequest
-	personal models.Deduction
-	donation models.Deduction
-	kReceipt models.Deduction
+	tax models.TaxRequest
+	// deductions is config of each deduction rule by slug
+	deductions map[string]models.Deduction
+	// members is number of each family member that family deductions are given for
+	members map[string]int
+	// ruleSet and its rules that tax is calculated with, active rule set is used when rules is nil
+	ruleSet models.RuleSet
+	rules   []DeductionRule
 }
 
 type TaxService struct {
@@ -27,21 +34,11 @@ type TaxService struct {
 }
 
 type TaxStorer interface {
-	GetDeductions() ([]models.Deduction, error)
-}
-
-var (
-	DefaultPersonalDeduction float64 = 60_000
-	DefaultDonationDeduction float64 = 100_000
-	DefaultKReceiptDeduction float64 = 50_000
-)
-
-var TaxStep []models.TaxStep = []models.TaxStep{
-	{MinIncome: -1, MaxIncome: 150_000, Rate: 0},
-	{MinIncome: 150_000, MaxIncome: 500_000, Rate: 0.1},
-	{MinIncome: 500_000, MaxIncome: 1_000_000, Rate: 0.15},
-	{MinIncome: 1_000_000, MaxIncome: 2_000_000, Rate: 0.2},
-	{MinIncome: 2_000_000, MaxIncome: 0, Rate: 0.35},
+	GetDeductions(ctx context.Context) ([]models.Deduction, error)
+	GetPenalties(ctx context.Context) ([]models.Penalty, error)
+	GetInstallmentConfigs(ctx context.Context) ([]models.InstallmentConfig, error)
+	GetExchangeRate(ctx context.Context, currency string, date string) (models.ExchangeRate, error)
+	GetTaxpayer(ctx context.Context, nationalId string) (models.Taxpayer, error)
 }
 
 func NewTaxService(db TaxStorer) *TaxService {
@@ -50,42 +47,51 @@ func NewTaxService(db TaxStorer) *TaxService {
 	}
 }
 
-func (ts *TaxService) GetDeductionConfig() (personal, donation, kReceipt models.Deduction, err error) {
-	var deductions map[string]models.Deduction = map[string]models.Deduction{}
-	ds, err := ts.Db.GetDeductions()
+// GetDeductionConfigs return config of all deductions by slug, deduction of active rule set use its amount when missing
+func (ts *TaxService) GetDeductionConfigs(ctx context.Context) (map[string]models.Deduction, error) {
+	ds, err := ts.Db.GetDeductions(ctx)
 	if err != nil && err != sql.ErrNoRows {
-		return personal, donation, kReceipt, err
+		return nil, err
 	}
+	return DeductionConfigs(ds), nil
+}
 
-	for _, v := range ds {
-		deductions[v.Slug] = v
-		switch v.Slug {
-		case models.DonationSlug:
-			donation = v
-		case models.PersonalSlug:
-			personal = v
-		case models.KReceiptSlug:
-			kReceipt = v
-		}
-	}
+// DeductionConfigs map deduction rows by slug, amount in active rule set is used for deduction that has no row
+func DeductionConfigs(ds []models.Deduction) map[string]models.Deduction {
+	return deductionConfigs(ActiveRuleSet(), ds)
+}
 
-	// no personal data in db
-	if personal.Amount == 0 && personal.Slug == "" {
-		personal.Amount = DefaultPersonalDeduction
-		personal.Slug = models.PersonalSlug
+// deductionConfigs map deduction rows by slug with deductions of rule set that have no row
+func deductionConfigs(ruleSet models.RuleSet, ds []models.Deduction) map[string]models.Deduction {
+	configs := map[string]models.Deduction{}
+	for _, v := range ruleSet.Deductions {
+		configs[v.Slug] = models.Deduction{Slug: v.Slug, Amount: v.Amount}
 	}
-	// no donation data in db
-	if donation.Amount == 0 && donation.Slug == "" {
-		donation.Amount = DefaultDonationDeduction
-		donation.Slug = models.DonationSlug
+	for _, v := range ds {
+		configs[v.Slug] = v
 	}
-	// no k-receipt data in db
-	if kReceipt.Amount == 0 && kReceipt.Slug == "" {
-		kReceipt.Amount = DefaultKReceiptDeduction
-		kReceipt.Slug = models.KReceiptSlug
+	return configs
+}
+
+// newTaxInput make input of tax with deduction config from rows and active rule set
+func newTaxInput(tax models.TaxRequest, ds []models.Deduction) TaxInput {
+	ruleSet, rules := activeRules()
+	return TaxInput{
+		tax:        tax,
+		deductions: deductionConfigs(ruleSet, ds),
+		ruleSet:    ruleSet,
+		rules:      rules,
 	}
+}
 
-	return personal, donation, kReceipt, nil
+// config return deduction config and rule set that input is calculated with, deductions are sorted by slug
+func (input TaxInput) config() models.TaxConfig {
+	config := models.TaxConfig{Deductions: []models.Deduction{}, RuleSet: input.ruleSet, RequestThb: input.tax}
+	for _, v := range input.deductions {
+		config.Deductions = append(config.Deductions, v)
+	}
+	slices.SortFunc(config.Deductions, func(a, b models.Deduction) int { return cmp.Compare(a.Slug, b.Slug) })
+	return config
 }
 
 func CalculateDeductionByType(typeSlug string, allowances []models.Allowance, deduction models.Deduction) (amount float64) {
@@ -101,22 +107,61 @@ func CalculateDeductionByType(typeSlug string, allowances []models.Allowance, de
 	return
 }
 
+// taxLines is net income and allowances that tax of calculateTax is calculated from
+type taxLines struct {
+	netIncome float64
+	// allowances is allowed amount of each deduction rule by slug
+	allowances map[string]float64
+	// spouse and family is total of family deductions for spouse and for child and parent
+	spouse float64
+	family float64
+}
+
 func CalculateTaxOutput(input TaxInput) models.TaxResponse {
+	result, _ := calculateTax(input)
+	return result
+}
+
+// calculateTax return tax result with net income and allowances that it is calculated from,
+// so form lines can be taken from the same calculation as tax
+func calculateTax(input TaxInput) (models.TaxResponse, taxLines) {
 	tax := input.tax
-	personal := input.personal
-	donation := input.donation
-	kReceipt := input.kReceipt
-
-	netIncome := tax.TotalIncome -
-		personal.Amount -
-		CalculateDeductionByType(models.DonationSlug, tax.Allowances, donation) -
-		CalculateDeductionByType(models.KReceiptSlug, tax.Allowances, kReceipt)
+
+	if input.rules == nil {
+		input.ruleSet, input.rules = activeRules()
+	}
+	lines := taxLines{allowances: map[string]float64{}}
+	netIncome := tax.TotalIncome
+	for _, rule := range input.rules {
+		config := input.deductions[rule.Slug()]
+		member := familyMember(rule)
+		if member != "" {
+			// family deduction amount is given for each member
+			config.Amount *= float64(input.members[member])
+		}
+		allowed := rule.Allowed(tax, config)
+		lines.allowances[rule.Slug()] = allowed
+		switch member {
+		case "":
+		case models.SpouseMember:
+			lines.spouse += allowed
+		default:
+			lines.family += allowed
+		}
+		netIncome -= allowed
+	}
+	lines.netIncome = netIncome
 	var result models.TaxResponse
 	result.TaxLevel = []models.TaxLevel{}
-	for _, v := range TaxStep {
+	for _, v := range input.ruleSet.Brackets {
 		var taxStep float64
 		p := message.NewPrinter(language.English)
-		level := p.Sprintf("%.0f-%.0f", v.MinIncome+1, v.MaxIncome)
+		// first level start from 0, others start from next baht of previous level
+		lower := v.MinIncome + 1
+		if v.MinIncome == 0 {
+			lower = 0
+		}
+		level := p.Sprintf("%.0f-%.0f", lower, v.MaxIncome)
 		overflowStep := netIncome - v.MaxIncome
 		if v.MaxIncome <= 0 {
 			// that mean unlimit ceiling income
@@ -139,29 +184,75 @@ func CalculateTaxOutput(input TaxInput) models.TaxResponse {
 		result.TaxLevel = append(result.TaxLevel, models.TaxLevel{Level: level, Tax: taxStep})
 	}
 
-	if tax.Wht > result.Tax {
+	// tax paid in advance by wht and half-year return (PND 94)
+	paid := tax.Wht + tax.PrepaidTax
+	if paid > result.Tax {
 		// over payment tax should refund
-		result.TaxRefund = math.Round((tax.Wht-result.Tax)*100) / 100
+		result.TaxRefund = math.Round((paid-result.Tax)*100) / 100
 		result.Tax = 0
 	} else {
-		result.Tax = math.Round((result.Tax-tax.Wht)*100) / 100
+		result.Tax = math.Round((result.Tax-paid)*100) / 100
 	}
 
-	return result
+	return result, lines
+}
+
+func (ts *TaxService) TaxCalculate(ctx context.Context, tax models.TaxRequest) (models.TaxResponse, error) {
+	result, _, err := ts.TaxCalculateWithConfig(ctx, tax)
+	return result, err
 }
 
-func (ts *TaxService) TaxCalculate(tax models.TaxRequest) (models.TaxResponse, error) {
-	personal, donation, kReceipt, err := ts.GetDeductionConfig()
+// TaxCalculateWithConfig calculate tax and return deduction config, rule set, request in THB and taxpayer profile
+// that it is calculated with. They are read once, so returned config is always the one that result come from.
+func (ts *TaxService) TaxCalculateWithConfig(ctx context.Context, tax models.TaxRequest) (models.TaxResponse, models.TaxConfig, error) {
+	tax, converted, err := ts.ConvertToThb(ctx, tax)
 	if err != nil {
-		return models.TaxResponse{}, err
+		return models.TaxResponse{}, models.TaxConfig{}, err
 	}
 
-	result := CalculateTaxOutput(TaxInput{
-		tax:      tax,
-		personal: personal,
-		donation: donation,
-		kReceipt: kReceipt,
-	})
+	ds, err := ts.Db.GetDeductions(ctx)
+	if err != nil && err != sql.ErrNoRows {
+		return models.TaxResponse{}, models.TaxConfig{}, err
+	}
+
+	input := newTaxInput(tax, ds)
+	var taxpayer *models.Taxpayer
+	if tax.TaxpayerId != "" {
+		v, err := ts.Db.GetTaxpayer(ctx, tax.TaxpayerId)
+		if err != nil {
+			return models.TaxResponse{}, models.TaxConfig{}, taxpayerNotFound(err)
+		}
+		taxpayer, input.members = &v, FamilyMembers(v)
+	}
+
+	result, err := ts.addPaymentTerms(ctx, CalculateTaxOutput(input), tax)
+	if err != nil {
+		return models.TaxResponse{}, models.TaxConfig{}, err
+	}
+	result.ExchangeRates = converted
+	metrics.ObserveTax("tax", result.Tax, result.TaxRefund)
+	config := input.config()
+	config.Taxpayer = taxpayer
+	return result, config, nil
+}
+
+// addPaymentTerms add surcharge, penalty and refund interest of late filing and installments of tax payable to result of tax
+func (ts *TaxService) addPaymentTerms(ctx context.Context, result models.TaxResponse, tax models.TaxRequest) (models.TaxResponse, error) {
+	if tax.FilingDate != "" {
+		config, err := ts.GetPenaltyConfig(ctx)
+		if err != nil {
+			return result, err
+		}
+		result = CalculateLatePayment(result, tax, config)
+	}
+
+	if result.Tax > 0 {
+		config, err := ts.GetInstallmentConfig(ctx)
+		if err != nil {
+			return result, err
+		}
+		result.Installments = CalculateInstallments(result.Tax, tax, config)
+	}
 	return result, nil
 }
 
@@ -172,9 +263,12 @@ func (ts *TaxService) ExtractCsv(reader io.Reader) ([]models.TaxCsv, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(rows) == 0 {
+		return nil, utils.ErrCsvHeaderMissing
+	}
 	header := rows[0]
 	if !validators.IsAllStringInArray(header, []string{"totalIncome", "wht", "donation"}) {
-		return nil, errors.New("missing required header field")
+		return nil, utils.ErrCsvHeaderMissing
 	}
 	for _, row := range rows[1:] {
 		var tax models.TaxCsv
@@ -183,7 +277,7 @@ func (ts *TaxService) ExtractCsv(reader io.Reader) ([]models.TaxCsv, error) {
 				continue
 			}
 			if col == "" {
-				return nil, errors.New("value should not be empty")
+				return nil, utils.ErrCsvValueEmpty
 			}
 			val, err := strconv.ParseFloat(col, 64)
 			if err != nil {
@@ -224,23 +318,22 @@ func TransformTaxCsvToTaxRequest(csv models.TaxCsv) (request models.TaxRequest)
 	return
 }
 
-func (ts *TaxService) CalculateTaxCsv(taxes []models.TaxCsv) (models.TaxCsvResponse, error) {
+func (ts *TaxService) CalculateTaxCsv(ctx context.Context, taxes []models.TaxCsv) (models.TaxCsvResponse, error) {
 	var result models.TaxCsvResponse = models.TaxCsvResponse{
 		Taxes: []models.CsvCalculateResult{},
 	}
 
-	personal, donation, kReceipt, err := ts.GetDeductionConfig()
+	deductions, err := ts.GetDeductionConfigs(ctx)
 	if err != nil {
 		return result, err
 	}
 
 	for _, tax := range taxes {
 		taxOutput := CalculateTaxOutput(TaxInput{
-			personal: personal,
-			donation: donation,
-			kReceipt: kReceipt,
-			tax:      TransformTaxCsvToTaxRequest(tax),
+			deductions: deductions,
+			tax:        TransformTaxCsvToTaxRequest(tax),
 		})
+		metrics.ObserveTax("tax", taxOutput.Tax, taxOutput.TaxRefund)
 		result.Taxes = append(result.Taxes, models.CsvCalculateResult{
 			TotalIncome: tax.TotalIncome,
 			Tax:         taxOutput.Tax,
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+type TaxReturnService struct {
+	Db TaxReturnStorer
+}
+
+type TaxReturnStorer interface {
+	TaxStorer
+	// CreateTaxReturn save tax return as new row, revision 0 is saved as next revision after the latest one
+	// of taxpayer and tax year, revision that already exists return utils.ErrTaxReturnExists
+	CreateTaxReturn(ctx context.Context, taxReturn models.TaxReturn) (models.TaxReturn, error)
+	GetTaxReturns(ctx context.Context, taxpayerId string, taxYear int) ([]models.TaxReturn, error)
+	GetTaxReturn(ctx context.Context, id uint) (models.TaxReturn, error)
+}
+
+func NewTaxReturnService(db TaxReturnStorer) *TaxReturnService {
+	return &TaxReturnService{
+		Db: db,
+	}
+}
+
+// SaveTaxReturn save first revision of tax return, return of taxpayer and tax year that is already saved
+// should be amended instead
+func (trs *TaxReturnService) SaveTaxReturn(ctx context.Context, req models.TaxReturnRequest) (models.TaxReturn, error) {
+	// family allowances of saved return come from profile of its taxpayer
+	req.Tax.TaxpayerId = req.TaxpayerId
+	result, config, err := NewTaxService(trs.Db).TaxCalculateWithConfig(ctx, req.Tax)
+	if err != nil {
+		return models.TaxReturn{}, err
+	}
+	return trs.Db.CreateTaxReturn(ctx, models.TaxReturn{
+		TaxpayerId: req.TaxpayerId,
+		TaxYear:    req.TaxYear,
+		Revision:   1,
+		Request:    req.Tax,
+		Response:   result,
+		Deductions: config.Deductions,
+		RuleSet:    config.RuleSet,
+		RequestThb: &config.RequestThb,
+		Taxpayer:   config.Taxpayer,
+	})
+}
+
+func (trs *TaxReturnService) GetTaxReturns(ctx context.Context, taxpayerId string, taxYear int) ([]models.TaxReturn, error) {
+	taxReturns, err := trs.Db.GetTaxReturns(ctx, taxpayerId, taxYear)
+	if err == sql.ErrNoRows || taxReturns == nil {
+		return []models.TaxReturn{}, nil
+	}
+	return taxReturns, err
+}
+
+func (trs *TaxReturnService) GetTaxReturn(ctx context.Context, id uint) (models.TaxReturn, error) {
+	taxReturn, err := trs.Db.GetTaxReturn(ctx, id)
+	if err == sql.ErrNoRows {
+		return taxReturn, utils.ErrTaxReturnNotFound
+	}
+	return taxReturn, err
+}
+
+// AmendTaxReturn recalculate tax return with new tax data and current deduction config and save it as
+// new revision after the latest one of same taxpayer and tax year, earlier revisions are kept as is.
+// Revision is counted by storer when it is saved, so concurrent amendments cannot get the same revision.
+func (trs *TaxReturnService) AmendTaxReturn(ctx context.Context, id uint, tax models.TaxRequest) (models.TaxReturn, error) {
+	taxReturn, err := trs.GetTaxReturn(ctx, id)
+	if err != nil {
+		return taxReturn, err
+	}
+	tax.TaxpayerId = taxReturn.TaxpayerId
+	result, config, err := NewTaxService(trs.Db).TaxCalculateWithConfig(ctx, tax)
+	if err != nil {
+		return models.TaxReturn{}, err
+	}
+	return trs.Db.CreateTaxReturn(ctx, models.TaxReturn{
+		TaxpayerId: taxReturn.TaxpayerId,
+		TaxYear:    taxReturn.TaxYear,
+		Request:    tax,
+		Response:   result,
+		Deductions: config.Deductions,
+		RuleSet:    config.RuleSet,
+		RequestThb: &config.RequestThb,
+		Taxpayer:   config.Taxpayer,
+	})
+}
@@ -0,0 +1,283 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+type StubTaxReturnStore struct {
+	StubTaxStore
+	taxReturns   map[uint]models.TaxReturn
+	taxReturnErr error
+	saved        models.TaxReturn
+}
+
+func (s *StubTaxReturnStore) CreateTaxReturn(ctx context.Context, taxReturn models.TaxReturn) (models.TaxReturn, error) {
+	s.expectToCall["CreateTaxReturn"] = true
+	for _, v := range s.taxReturns {
+		if v.TaxpayerId != taxReturn.TaxpayerId || v.TaxYear != taxReturn.TaxYear {
+			continue
+		}
+		if v.Revision == taxReturn.Revision {
+			return taxReturn, utils.ErrTaxReturnExists
+		}
+	}
+	if taxReturn.Revision == 0 {
+		for _, v := range s.taxReturns {
+			if v.TaxpayerId == taxReturn.TaxpayerId && v.TaxYear == taxReturn.TaxYear {
+				taxReturn.Revision = max(taxReturn.Revision, v.Revision)
+			}
+		}
+		taxReturn.Revision++
+	}
+	taxReturn.Id = 1
+	s.saved = taxReturn
+	return taxReturn, s.taxReturnErr
+}
+
+func (s *StubTaxReturnStore) GetTaxReturns(ctx context.Context, taxpayerId string, taxYear int) ([]models.TaxReturn, error) {
+	s.expectToCall["GetTaxReturns"] = true
+	var taxReturns []models.TaxReturn
+	for _, v := range s.taxReturns {
+		if v.TaxpayerId == taxpayerId && (taxYear == 0 || v.TaxYear == taxYear) {
+			taxReturns = append(taxReturns, v)
+		}
+	}
+	return taxReturns, s.taxReturnErr
+}
+
+func (s *StubTaxReturnStore) GetTaxReturn(ctx context.Context, id uint) (models.TaxReturn, error) {
+	s.expectToCall["GetTaxReturn"] = true
+	taxReturn, ok := s.taxReturns[id]
+	if !ok {
+		return taxReturn, sql.ErrNoRows
+	}
+	return taxReturn, nil
+}
+
+func initTaxReturnStub(taxReturns map[uint]models.TaxReturn) *StubTaxReturnStore {
+	stub := &StubTaxReturnStore{
+		StubTaxStore: initStub([]models.Deduction{
+			{Slug: models.PersonalSlug, Amount: 60_000},
+			{Slug: models.DonationSlug, Amount: 100_000},
+			{Slug: models.KReceiptSlug, Amount: 50_000},
+		}, nil),
+		taxReturns: taxReturns,
+	}
+	// family allowances of saved return come from profile of its taxpayer
+	stub.taxpayers = map[string]models.Taxpayer{
+		"A":             {NationalId: "A"},
+		"1234567890121": {NationalId: "1234567890121"},
+	}
+	return stub
+}
+
+func TestSaveTaxReturn(t *testing.T) {
+	t.Run("given valid request should save calculated result with deduction snapshot", func(t *testing.T) {
+		stub := initTaxReturnStub(nil)
+		service := NewTaxReturnService(stub)
+		req := models.TaxReturnRequest{
+			TaxpayerId: "1234567890121",
+			TaxYear:    2567,
+			Tax:        models.TaxRequest{TotalIncome: 500_000},
+		}
+
+		got, err := service.SaveTaxReturn(context.Background(), req)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodWasCalled(t, "CreateTaxReturn")
+		assertIsEqual(t, uint(1), got.Id, "expect tax return id should be 1")
+		assertIsEqual(t, 1, got.Revision, "expect first revision should be 1")
+		assertIsEqual(t, 29_000.0, got.Response.Tax, expectTaxValueMsg(29_000, got.Response.Tax))
+		assertObjectIsEqual(t, models.TaxRequest{TotalIncome: 500_000, TaxpayerId: "1234567890121"}, got.Request)
+		assertObjectIsEqual(t, []models.Deduction{
+			{Slug: models.ChildSlug, Amount: 30_000},
+			{Slug: models.DonationSlug, Amount: 100_000},
+			{Slug: models.KReceiptSlug, Amount: 50_000},
+			{Slug: models.ParentSlug, Amount: 30_000},
+			{Slug: models.PersonalSlug, Amount: 60_000},
+			{Slug: models.SpouseSlug, Amount: 60_000},
+		}, got.Deductions)
+		assertObjectIsEqual(t, ActiveRuleSet(), got.RuleSet)
+	})
+	t.Run("given active rule set should keep it in snapshot that result is calculated with", func(t *testing.T) {
+		restoreDefaultRuleSet(t)
+		ApplyRuleSet(models.RuleSet{
+			Name:       "flat",
+			Brackets:   []models.TaxStep{{MinIncome: 0, MaxIncome: 0, Rate: 0.1}},
+			Deductions: []models.RuleDeduction{{Slug: "provident-fund", Type: models.AllowanceRuleType, Amount: 500_000}},
+		})
+		stub := initTaxReturnStub(nil)
+		service := NewTaxReturnService(stub)
+		tax := models.TaxRequest{TotalIncome: 500_000, Allowances: []models.Allowance{{Type: "provident-fund", Amount: 100_000}}}
+
+		got, err := service.SaveTaxReturn(context.Background(), models.TaxReturnRequest{TaxpayerId: "A", TaxYear: 2567, Tax: tax})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 40_000.0, got.Response.Tax, expectTaxValueMsg(40_000, got.Response.Tax))
+		assertIsEqual(t, "flat", got.RuleSet.Name, "expect snapshot has rule set that result is calculated with")
+		assertIsEqual(t, true, slices.Contains(got.Deductions, models.Deduction{Slug: "provident-fund", Amount: 500_000}),
+			"expect snapshot has deduction of rule set")
+	})
+	t.Run("given taxpayer profile should keep family allowance config in snapshot", func(t *testing.T) {
+		stub := initTaxReturnStub(nil)
+		stub.deductions = append(stub.deductions, models.Deduction{Slug: models.ChildSlug, Amount: 40_000})
+		stub.taxpayers = map[string]models.Taxpayer{"A": {NationalId: "A", Children: 1}}
+		service := NewTaxReturnService(stub)
+
+		got, err := service.SaveTaxReturn(context.Background(), models.TaxReturnRequest{
+			TaxpayerId: "A", TaxYear: 2567, Tax: models.TaxRequest{TotalIncome: 500_000, TaxpayerId: "A"},
+		})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 25_000.0, got.Response.Tax, expectTaxValueMsg(25_000, got.Response.Tax))
+		assertIsEqual(t, models.Deduction{Slug: models.ChildSlug, Amount: 40_000}, got.Deductions[0], "expect child allowance that is used")
+	})
+	t.Run("given taxpayer id only on tax return should apply family allowances of its profile", func(t *testing.T) {
+		stub := initTaxReturnStub(nil)
+		stub.taxpayers = map[string]models.Taxpayer{"A": {NationalId: "A", Children: 2}}
+		service := NewTaxReturnService(stub)
+
+		got, err := service.SaveTaxReturn(context.Background(), models.TaxReturnRequest{
+			TaxpayerId: "A", TaxYear: 2567, Tax: models.TaxRequest{TotalIncome: 500_000},
+		})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodCalledTime(t, "GetTaxpayer", 1)
+		// 500,000 - 60,000 personal - 60,000 for 2 children
+		assertIsEqual(t, 23_000.0, got.Response.Tax, expectTaxValueMsg(23_000, got.Response.Tax))
+		assertIsEqual(t, "A", got.Request.TaxpayerId, "expect saved request has taxpayer of tax return")
+		assertObjectIsEqual(t, &models.Taxpayer{NationalId: "A", Children: 2}, got.Taxpayer)
+		assertObjectIsEqual(t, &models.TaxRequest{TaxpayerId: "A", TotalIncome: 500_000}, got.RequestThb)
+	})
+	t.Run("given taxpayer without profile should not save tax return", func(t *testing.T) {
+		stub := initTaxReturnStub(nil)
+		service := NewTaxReturnService(stub)
+
+		_, err := service.SaveTaxReturn(context.Background(), models.TaxReturnRequest{
+			TaxpayerId: "B", TaxYear: 2567, Tax: models.TaxRequest{TotalIncome: 500_000},
+		})
+
+		assertIsEqual(t, utils.ErrTaxpayerNotFound, err, "expect taxpayer not found error")
+		if stub.expectToCall["CreateTaxReturn"] {
+			t.Error("expect CreateTaxReturn was not called")
+		}
+	})
+	t.Run("given saved return of taxpayer and tax year should reject it as exists", func(t *testing.T) {
+		stub := initTaxReturnStub(map[uint]models.TaxReturn{1: {Id: 1, TaxpayerId: "A", TaxYear: 2567, Revision: 1}})
+		service := NewTaxReturnService(stub)
+
+		_, err := service.SaveTaxReturn(context.Background(), models.TaxReturnRequest{
+			TaxpayerId: "A", TaxYear: 2567, Tax: models.TaxRequest{TotalIncome: 500_000},
+		})
+
+		assertIsEqual(t, utils.ErrTaxReturnExists, err, "expect tax return exists error")
+	})
+	t.Run("given get deduction error should not save tax return", func(t *testing.T) {
+		stub := initTaxReturnStub(nil)
+		stub.err = errors.New("error 'xxx' occured")
+		service := NewTaxReturnService(stub)
+
+		_, err := service.SaveTaxReturn(context.Background(), models.TaxReturnRequest{TaxpayerId: "1", TaxYear: 2567})
+
+		assertIsEqual(t, stub.err, err, "expect error from get deductions")
+		if stub.expectToCall["CreateTaxReturn"] {
+			t.Error("expect CreateTaxReturn was not called")
+		}
+	})
+}
+
+func TestGetTaxReturns(t *testing.T) {
+	stub := initTaxReturnStub(map[uint]models.TaxReturn{
+		1: {Id: 1, TaxpayerId: "A", TaxYear: 2566},
+		2: {Id: 2, TaxpayerId: "A", TaxYear: 2567},
+		3: {Id: 3, TaxpayerId: "B", TaxYear: 2567},
+	})
+	service := NewTaxReturnService(stub)
+
+	t.Run("given tax year should return only returns of that year", func(t *testing.T) {
+		got, err := service.GetTaxReturns(context.Background(), "A", 2567)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, []models.TaxReturn{{Id: 2, TaxpayerId: "A", TaxYear: 2567}}, got)
+	})
+	t.Run("given no return of taxpayer should return empty list", func(t *testing.T) {
+		got, err := service.GetTaxReturns(context.Background(), "C", 0)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertObjectIsEqual(t, []models.TaxReturn{}, got)
+	})
+}
+
+func TestGetTaxReturn(t *testing.T) {
+	t.Run("given not exist id should return not found error", func(t *testing.T) {
+		service := NewTaxReturnService(initTaxReturnStub(nil))
+
+		_, err := service.GetTaxReturn(context.Background(), 9)
+
+		assertIsEqual(t, utils.ErrTaxReturnNotFound, err, "expect tax return not found error")
+	})
+}
+
+func TestAmendTaxReturn(t *testing.T) {
+	t.Run("given exist return should save recalculated result as next revision and keep earlier one", func(t *testing.T) {
+		first := models.TaxReturn{Id: 1, TaxpayerId: "A", TaxYear: 2567, Revision: 1, Request: models.TaxRequest{TotalIncome: 500_000}}
+		stub := initTaxReturnStub(map[uint]models.TaxReturn{1: first})
+		service := NewTaxReturnService(stub)
+		tax := models.TaxRequest{TotalIncome: 500_000, Wht: 25_000}
+
+		got, err := service.AmendTaxReturn(context.Background(), 1, tax)
+
+		assertIsNil(t, err, expectNilErrMsg)
+		stub.assertMethodWasCalled(t, "CreateTaxReturn")
+		assertIsEqual(t, 2, got.Revision, "expect revision should be 2")
+		assertIsEqual(t, "A", got.TaxpayerId, "expect taxpayer should not change")
+		assertIsEqual(t, 2567, got.TaxYear, "expect tax year should not change")
+		tax.TaxpayerId = "A"
+		assertObjectIsEqual(t, tax, got.Request)
+		assertIsEqual(t, 4_000.0, got.Response.Tax, expectTaxValueMsg(4_000, got.Response.Tax))
+		assertObjectIsEqual(t, first, stub.taxReturns[1])
+	})
+	t.Run("given earlier revision should save revision after the latest one", func(t *testing.T) {
+		stub := initTaxReturnStub(map[uint]models.TaxReturn{
+			1: {Id: 1, TaxpayerId: "A", TaxYear: 2567, Revision: 1},
+			2: {Id: 2, TaxpayerId: "A", TaxYear: 2567, Revision: 2},
+			3: {Id: 3, TaxpayerId: "A", TaxYear: 2566, Revision: 5},
+		})
+		service := NewTaxReturnService(stub)
+
+		got, err := service.AmendTaxReturn(context.Background(), 1, models.TaxRequest{TotalIncome: 500_000})
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 3, got.Revision, "expect revision should be 3")
+	})
+	t.Run("given revision saved by other amendment at the same time should return exists error", func(t *testing.T) {
+		stub := initTaxReturnStub(map[uint]models.TaxReturn{1: {Id: 1, TaxpayerId: "A", TaxYear: 2567, Revision: 1}})
+		stub.taxReturnErr = utils.ErrTaxReturnExists
+		service := NewTaxReturnService(stub)
+
+		_, err := service.AmendTaxReturn(context.Background(), 1, models.TaxRequest{TotalIncome: 500_000})
+
+		assertIsEqual(t, utils.ErrTaxReturnExists, err, "expect tax return exists error from storer")
+	})
+	t.Run("given not exist id should return not found error", func(t *testing.T) {
+		stub := initTaxReturnStub(nil)
+		service := NewTaxReturnService(stub)
+
+		_, err := service.AmendTaxReturn(context.Background(), 1, models.TaxRequest{TotalIncome: 500_000})
+
+		assertIsEqual(t, utils.ErrTaxReturnNotFound, err, "expect tax return not found error")
+		if stub.expectToCall["CreateTaxReturn"] {
+			t.Error("expect CreateTaxReturn was not called")
+		}
+	})
+}
@@ -4,6 +4,7 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -11,9 +12,11 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
 )
 
 type TaxTestSuite struct {
@@ -27,16 +30,55 @@ type TaxTestSuite struct {
 type StubTaxStore struct {
 	deductions      []models.Deduction
 	err             error
+	penalties       []models.Penalty
+	penaltiesErr    error
+	installments    []models.InstallmentConfig
+	installmentsErr error
+	exchangeRates   map[string]models.ExchangeRate
+	exchangeRateErr error
+	taxpayers       map[string]models.Taxpayer
 	expectToCall    map[string]bool
 	expectCallTimes map[string]int
 }
 
-func (s *StubTaxStore) GetDeductions() ([]models.Deduction, error) {
+func (s *StubTaxStore) GetDeductions(ctx context.Context) ([]models.Deduction, error) {
 	s.expectToCall["GetDeductions"] = true
 	s.expectCallTimes["GetDeductions"]++
 	return s.deductions, s.err
 }
 
+func (s *StubTaxStore) GetPenalties(ctx context.Context) ([]models.Penalty, error) {
+	s.expectToCall["GetPenalties"] = true
+	s.expectCallTimes["GetPenalties"]++
+	return s.penalties, s.penaltiesErr
+}
+
+func (s *StubTaxStore) GetInstallmentConfigs(ctx context.Context) ([]models.InstallmentConfig, error) {
+	s.expectToCall["GetInstallmentConfigs"] = true
+	s.expectCallTimes["GetInstallmentConfigs"]++
+	return s.installments, s.installmentsErr
+}
+
+func (s *StubTaxStore) GetExchangeRate(ctx context.Context, currency string, date string) (models.ExchangeRate, error) {
+	s.expectToCall["GetExchangeRate"] = true
+	s.expectCallTimes["GetExchangeRate"]++
+	rate, ok := s.exchangeRates[currency]
+	if !ok && s.exchangeRateErr == nil {
+		return rate, sql.ErrNoRows
+	}
+	return rate, s.exchangeRateErr
+}
+
+func (s *StubTaxStore) GetTaxpayer(ctx context.Context, nationalId string) (models.Taxpayer, error) {
+	s.expectToCall["GetTaxpayer"] = true
+	s.expectCallTimes["GetTaxpayer"]++
+	taxpayer, ok := s.taxpayers[nationalId]
+	if !ok {
+		return taxpayer, sql.ErrNoRows
+	}
+	return taxpayer, nil
+}
+
 func (s *StubTaxStore) assertMethodWasCalled(t *testing.T, methodName string) {
 	t.Helper()
 	if !s.expectToCall[methodName] {
@@ -161,7 +203,7 @@ func TestTaxCalculate(t *testing.T) {
 			t.Run(tc.name, func(t *testing.T) {
 				service := setupTaxService(tc.stub)
 
-				result, err := service.TaxCalculate(tc.params)
+				result, err := service.TaxCalculate(context.Background(), tc.params)
 
 				tc.stub.assertMethodWasCalled(t, "GetDeductions")
 				tc.stub.assertMethodCalledTime(t, "GetDeductions", 1)
@@ -194,7 +236,7 @@ func TestTaxWithWHT(t *testing.T) {
 			t.Run(tc.name, func(t *testing.T) {
 				service := setupTaxService(tc.stub)
 
-				result, err := service.TaxCalculate(tc.params)
+				result, err := service.TaxCalculate(context.Background(), tc.params)
 
 				tc.stub.assertMethodWasCalled(t, "GetDeductions")
 				tc.stub.assertMethodCalledTime(t, "GetDeductions", 1)
@@ -264,7 +306,7 @@ func TestTaxWithAllowance(t *testing.T) {
 			t.Run(tc.name, func(t *testing.T) {
 				service := setupTaxService(tc.stub)
 
-				result, err := service.TaxCalculate(tc.params)
+				result, err := service.TaxCalculate(context.Background(), tc.params)
 
 				tc.stub.assertMethodWasCalled(t, "GetDeductions")
 				tc.stub.assertMethodCalledTime(t, "GetDeductions", 1)
@@ -339,7 +381,7 @@ func TestTaxWithAllowance(t *testing.T) {
 			t.Run(tc.name, func(t *testing.T) {
 				service := setupTaxService(tc.stub)
 
-				result, err := service.TaxCalculate(tc.params)
+				result, err := service.TaxCalculate(context.Background(), tc.params)
 
 				tc.stub.assertMethodWasCalled(t, "GetDeductions")
 				tc.stub.assertMethodCalledTime(t, "GetDeductions", 1)
@@ -449,7 +491,7 @@ func TestTaxWithAllowance(t *testing.T) {
 			t.Run(tc.name, func(t *testing.T) {
 				service := setupTaxService(tc.stub)
 
-				result, err := service.TaxCalculate(tc.params)
+				result, err := service.TaxCalculate(context.Background(), tc.params)
 
 				tc.stub.assertMethodWasCalled(t, "GetDeductions")
 				tc.stub.assertMethodCalledTime(t, "GetDeductions", 1)
@@ -484,7 +526,7 @@ func TestTaxLevel(t *testing.T) {
 		}
 		service := setupTaxService(stub)
 
-		result, err := service.TaxCalculate(params)
+		result, err := service.TaxCalculate(context.Background(), params)
 
 		stub.assertMethodWasCalled(t, "GetDeductions")
 		stub.assertMethodCalledTime(t, "GetDeductions", 1)
@@ -512,6 +554,11 @@ func TestTaxLevel(t *testing.T) {
 					Tax:   0.0,
 				},
 			},
+			Installments: []models.Installment{
+				{No: 1, DueDate: "2025-03-31", Amount: 6_333.33},
+				{No: 2, DueDate: "2025-04-30", Amount: 6_333.33},
+				{No: 3, DueDate: "2025-05-31", Amount: 6_333.34},
+			},
 		}
 		assertIsNil(t, err, expectNilErrMsg)
 		assertObjectIsEqual(t, want, result)
@@ -528,6 +575,13 @@ func TestFuncExtractCsv(t *testing.T) {
 		}
 		return fileData
 	}
+	t.Run("when csv is empty should return error 'missing required header field'", func(t *testing.T) {
+		s := setupTaxService(initStub(nil, nil))
+
+		_, err := s.ExtractCsv(strings.NewReader(""))
+
+		assertObjectIsEqual(t, utils.ErrCsvHeaderMissing, err)
+	})
 	// missing column -> missing required header field
 	t.Run("when csv is missing required colum should return error 'missing required header field'", func(t *testing.T) {
 		stub := initStub(nil, nil)
@@ -746,7 +800,7 @@ func TestFuncCalculateTaxCsv(t *testing.T) {
 			{TotalIncome: 750_000, Wht: 50_000, Donation: 15_000},
 		}
 
-		_, err := s.CalculateTaxCsv(input)
+		_, err := s.CalculateTaxCsv(context.Background(), input)
 
 		stub.assertMethodWasCalled(t, "GetDeductions")
 		stub.assertMethodCalledTime(t, "GetDeductions", 1)
@@ -772,7 +826,7 @@ func TestFuncCalculateTaxCsv(t *testing.T) {
 			{TotalIncome: 750_000, Wht: 50_000, Donation: 15_000},
 		}
 
-		result, err := s.CalculateTaxCsv(input)
+		result, err := s.CalculateTaxCsv(context.Background(), input)
 
 		assertIsNil(t, err, expectNilErrMsg)
 		stub.assertMethodWasCalled(t, "GetDeductions")
@@ -813,7 +867,7 @@ func TestFuncCalculateTaxCsv(t *testing.T) {
 			{TotalIncome: 500_000, Wht: 0, Donation: 100_000, KReceipt: 200_000},
 		}
 
-		result, err := s.CalculateTaxCsv(input)
+		result, err := s.CalculateTaxCsv(context.Background(), input)
 
 		assertIsNil(t, err, expectNilErrMsg)
 		stub.assertMethodWasCalled(t, "GetDeductions")
@@ -842,123 +896,93 @@ func TestFuncCalculateTaxCsv(t *testing.T) {
 	})
 }
 
-func TestGetDeductionConfig(t *testing.T) {
+func TestGetDeductionConfigs(t *testing.T) {
 	stub := initStub(nil, nil)
 	s := NewTaxService(&stub)
-	t.Run("given get no row error from database should return default value of each deduction", func(t *testing.T) {
+	defaults := DeductionConfigs(nil)
+	t.Run("given get no row error from database should return amount of active rule set for each deduction", func(t *testing.T) {
 		stub.err = sql.ErrNoRows
 		stub.deductions = nil
 
-		personal, donation, kReceipt, err := s.GetDeductionConfig()
+		got, err := s.GetDeductionConfigs(context.Background())
 
 		assertIsNil(t, err, expectNilErrMsg)
-		assertIsEqual(t, personal.Amount, DefaultPersonalDeduction, fmt.Sprintf("expect personal deduction is %.2f but got %.2f", DefaultPersonalDeduction, personal.Amount))
-		assertIsEqual(t, donation.Amount, DefaultDonationDeduction, fmt.Sprintf("expect donation deduction is %.2f but got %.2f", DefaultDonationDeduction, donation.Amount))
-		assertIsEqual(t, kReceipt.Amount, DefaultKReceiptDeduction, fmt.Sprintf("expect k-receipt deduction is %.2f but got %.2f", DefaultKReceiptDeduction, kReceipt.Amount))
+		for _, slug := range []string{models.PersonalSlug, models.DonationSlug, models.KReceiptSlug} {
+			if got[slug].Amount == 0 || got[slug].Amount != defaults[slug].Amount {
+				t.Errorf("expect %s deduction is %.2f but got %.2f", slug, defaults[slug].Amount, got[slug].Amount)
+			}
+		}
 	})
 	t.Run("given get error that is not 'no row' should return error", func(t *testing.T) {
 		stub.err = errors.New("error 'xxx' occured")
 		stub.deductions = nil
 
-		_, _, _, err := s.GetDeductionConfig()
+		_, err := s.GetDeductionConfigs(context.Background())
 
 		if err == nil {
 			t.Fatal("expect error should not be null")
 		}
 		assertIsEqual(t, stub.err, err, fmt.Sprintf("expect error %q but got %q", stub.err, err))
 	})
-	t.Run("given get some deduction from db should return value from db and default for other that no data", func(t *testing.T) {
+	t.Run("given get some deduction from db should return value from db and rule set amount for other that no data", func(t *testing.T) {
 		stub.err = nil
-		var personal, donation, kReceipt models.Deduction
-		var err error
-		// case 1 have personal
-		stub.deductions = []models.Deduction{
-			{Slug: models.PersonalSlug, Amount: 100},
-		}
-
-		personal, donation, kReceipt, err = s.GetDeductionConfig()
+		stub.deductions = []models.Deduction{{Slug: models.PersonalSlug, Amount: 100}}
 
-		assertIsNil(t, err, expectNilErrMsg)
-		assertIsEqual(t, personal.Amount, 100.0, fmt.Sprintf("expect personal deduction is %.2f but got %.2f", 100.0, personal.Amount))
-		assertIsEqual(t, donation.Amount, DefaultDonationDeduction, fmt.Sprintf("expect donation deduction is %.2f but got %.2f", DefaultDonationDeduction, donation.Amount))
-		assertIsEqual(t, kReceipt.Amount, DefaultKReceiptDeduction, fmt.Sprintf("expect k-receipt deduction is %.2f but got %.2f", DefaultKReceiptDeduction, kReceipt.Amount))
-
-		// case 2 have donation
-		stub.deductions = []models.Deduction{
-			{Slug: models.DonationSlug, Amount: 100},
-		}
-
-		personal, donation, kReceipt, err = s.GetDeductionConfig()
+		got, err := s.GetDeductionConfigs(context.Background())
 
 		assertIsNil(t, err, expectNilErrMsg)
-		assertIsEqual(t, personal.Amount, DefaultPersonalDeduction, fmt.Sprintf("expect personal deduction is %.2f but got %.2f", DefaultPersonalDeduction, personal.Amount))
-		assertIsEqual(t, donation.Amount, 100.0, fmt.Sprintf("expect donation deduction is %.2f but got %.2f", 100.0, donation.Amount))
-		assertIsEqual(t, kReceipt.Amount, DefaultKReceiptDeduction, fmt.Sprintf("expect k-receipt deduction is %.2f but got %.2f", DefaultKReceiptDeduction, kReceipt.Amount))
-
-		// case 3 have k-receipt
-		stub.deductions = []models.Deduction{
-			{Slug: models.KReceiptSlug, Amount: 100},
-		}
-
-		personal, donation, kReceipt, err = s.GetDeductionConfig()
-
-		assertIsNil(t, err, expectNilErrMsg)
-		assertIsEqual(t, personal.Amount, DefaultPersonalDeduction, fmt.Sprintf("expect personal deduction is %.2f but got %.2f", DefaultPersonalDeduction, personal.Amount))
-		assertIsEqual(t, donation.Amount, DefaultDonationDeduction, fmt.Sprintf("expect donation deduction is %.2f but got %.2f", DefaultDonationDeduction, donation.Amount))
-		assertIsEqual(t, kReceipt.Amount, 100.0, fmt.Sprintf("expect k-receipt deduction is %.2f but got %.2f", 100.0, kReceipt.Amount))
-
-		// case 4 have donation and k-receipt
-		stub.deductions = []models.Deduction{
-			{Slug: models.DonationSlug, Amount: 100},
-			{Slug: models.KReceiptSlug, Amount: 200},
+		assertIsEqual(t, 100.0, got[models.PersonalSlug].Amount, fmt.Sprintf("expect personal deduction is 100.00 but got %.2f", got[models.PersonalSlug].Amount))
+		for _, slug := range []string{models.DonationSlug, models.KReceiptSlug} {
+			if got[slug].Amount != defaults[slug].Amount {
+				t.Errorf("expect %s deduction is %.2f but got %.2f", slug, defaults[slug].Amount, got[slug].Amount)
+			}
 		}
+	})
+}
 
-		personal, donation, kReceipt, err = s.GetDeductionConfig()
-
-		assertIsNil(t, err, expectNilErrMsg)
-		assertIsEqual(t, personal.Amount, DefaultPersonalDeduction, fmt.Sprintf("expect personal deduction is %.2f but got %.2f", DefaultPersonalDeduction, personal.Amount))
-		assertIsEqual(t, donation.Amount, 100.0, fmt.Sprintf("expect donation deduction is %.2f but got %.2f", 100.0, donation.Amount))
-		assertIsEqual(t, kReceipt.Amount, 200.0, fmt.Sprintf("expect k-receipt deduction is %.2f but got %.2f", 200.0, kReceipt.Amount))
+func TestTaxWithPeriod(t *testing.T) {
+	testSuites := []TaxTestSuite{
+		{
+			name:   "when half-year period should deduct only half of personal allowance",
+			want:   models.TaxResponse{Tax: 5_000},
+			params: models.TaxRequest{TotalIncome: 230_000, Period: models.HalfYearPeriod, IncomeType: models.RentalIncome},
+			stub:   initStub([]models.Deduction{}, nil),
+		},
+		{
+			name:   "when full-year period with prepaid tax should subtract prepaid tax and wht from tax",
+			want:   models.TaxResponse{Tax: 19_000},
+			params: models.TaxRequest{TotalIncome: 500_000, Wht: 5_000, PrepaidTax: 5_000, Period: models.FullYearPeriod, IncomeType: models.RentalIncome},
+			stub:   initStub([]models.Deduction{}, nil),
+		},
+		{
+			name:   "when prepaid tax and wht more than tax should refund",
+			want:   models.TaxResponse{TaxRefund: 1_000},
+			params: models.TaxRequest{TotalIncome: 500_000, Wht: 25_000, PrepaidTax: 5_000},
+			stub:   initStub([]models.Deduction{}, nil),
+		},
+	}
 
-		// case 5 have donation and personal
-		stub.deductions = []models.Deduction{
-			{Slug: models.PersonalSlug, Amount: 100},
-			{Slug: models.DonationSlug, Amount: 200},
-		}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			service := setupTaxService(tc.stub)
 
-		personal, donation, kReceipt, err = s.GetDeductionConfig()
+			result, err := service.TaxCalculate(context.Background(), tc.params)
 
-		assertIsNil(t, err, expectNilErrMsg)
-		assertIsEqual(t, personal.Amount, 100.0, fmt.Sprintf("expect personal deduction is %.2f but got %.2f", 100.0, personal.Amount))
-		assertIsEqual(t, donation.Amount, 200.0, fmt.Sprintf("expect donation deduction is %.2f but got %.2f", 200.0, donation.Amount))
-		assertIsEqual(t, kReceipt.Amount, DefaultKReceiptDeduction, fmt.Sprintf("expect k-receipt deduction is %.2f but got %.2f", DefaultKReceiptDeduction, kReceipt.Amount))
-
-		// case 6 have personal and k-receipt
-		stub.deductions = []models.Deduction{
-			{Slug: models.PersonalSlug, Amount: 100},
-			{Slug: models.KReceiptSlug, Amount: 200},
-		}
+			assertIsNil(t, err, expectNilErrMsg)
+			assertIsEqual(t, tc.want.Tax, result.Tax, expectTaxValueMsg(tc.want.Tax, result.Tax))
+			assertIsEqual(t, tc.want.TaxRefund, result.TaxRefund, expectTaxRefundValueMsg(tc.want.TaxRefund, result.TaxRefund))
+		})
+	}
+	t.Run("when half-year period installments should start from half-year due date", func(t *testing.T) {
+		stub := initStub([]models.Deduction{}, nil)
+		service := setupTaxService(stub)
 
-		personal, donation, kReceipt, err = s.GetDeductionConfig()
+		result, err := service.TaxCalculate(context.Background(), models.TaxRequest{TotalIncome: 500_000, Period: models.HalfYearPeriod, IncomeType: models.BusinessIncome})
 
 		assertIsNil(t, err, expectNilErrMsg)
-		assertIsEqual(t, personal.Amount, 100.0, fmt.Sprintf("expect personal deduction is %.2f but got %.2f", 100.0, personal.Amount))
-		assertIsEqual(t, donation.Amount, DefaultDonationDeduction, fmt.Sprintf("expect donation deduction is %.2f but got %.2f", DefaultDonationDeduction, donation.Amount))
-		assertIsEqual(t, kReceipt.Amount, 200.0, fmt.Sprintf("expect k-receipt deduction is %.2f but got %.2f", 200.0, kReceipt.Amount))
-
-		// case 7 have all
-		stub.deductions = []models.Deduction{
-			{Slug: models.PersonalSlug, Amount: 100},
-			{Slug: models.DonationSlug, Amount: 200},
-			{Slug: models.KReceiptSlug, Amount: 300},
+		if len(result.Installments) == 0 {
+			t.Fatal("expect installments")
 		}
-
-		personal, donation, kReceipt, err = s.GetDeductionConfig()
-
-		assertIsNil(t, err, expectNilErrMsg)
-		assertIsEqual(t, personal.Amount, 100.0, fmt.Sprintf("expect personal deduction is %.2f but got %.2f", 100.0, personal.Amount))
-		assertIsEqual(t, donation.Amount, 200.0, fmt.Sprintf("expect donation deduction is %.2f but got %.2f", 200.0, donation.Amount))
-		assertIsEqual(t, kReceipt.Amount, 300.0, fmt.Sprintf("expect k-receipt deduction is %.2f but got %.2f", 300.0, kReceipt.Amount))
-
+		assertIsEqual(t, DefaultHalfYearDueDate, result.Installments[0].DueDate, "expect first installment due on half-year due date")
 	})
 }
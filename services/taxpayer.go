@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+type TaxpayerService struct {
+	Db TaxpayerStorer
+}
+
+type TaxpayerStorer interface {
+	CreateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error)
+	GetTaxpayer(ctx context.Context, nationalId string) (models.Taxpayer, error)
+	UpdateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error)
+	DeleteTaxpayer(ctx context.Context, nationalId string) error
+}
+
+func NewTaxpayerService(db TaxpayerStorer) *TaxpayerService {
+	return &TaxpayerService{
+		Db: db,
+	}
+}
+
+func taxpayerNotFound(err error) error {
+	if err == sql.ErrNoRows {
+		return utils.ErrTaxpayerNotFound
+	}
+	return err
+}
+
+func (tps *TaxpayerService) CreateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error) {
+	return tps.Db.CreateTaxpayer(ctx, taxpayer)
+}
+
+func (tps *TaxpayerService) GetTaxpayer(ctx context.Context, nationalId string) (models.Taxpayer, error) {
+	taxpayer, err := tps.Db.GetTaxpayer(ctx, nationalId)
+	return taxpayer, taxpayerNotFound(err)
+}
+
+func (tps *TaxpayerService) UpdateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error) {
+	taxpayer, err := tps.Db.UpdateTaxpayer(ctx, taxpayer)
+	return taxpayer, taxpayerNotFound(err)
+}
+
+func (tps *TaxpayerService) DeleteTaxpayer(ctx context.Context, nationalId string) error {
+	return taxpayerNotFound(tps.Db.DeleteTaxpayer(ctx, nationalId))
+}
+
+// FamilyMembers return number of each family member in taxpayer profile that family deduction of rule set
+// is given for. Spouse is counted only when taxpayer is married and spouse has no income.
+func FamilyMembers(taxpayer models.Taxpayer) map[string]int {
+	members := map[string]int{models.ChildMember: taxpayer.Children, models.ParentMember: taxpayer.Parents}
+	if taxpayer.MaritalStatus == models.MarriedStatus && !taxpayer.SpouseHasIncome {
+		members[models.SpouseMember] = 1
+	}
+	return members
+}
+
+// GetFamilyMembers return family members of taxpayer that referenced in tax request
+func (ts *TaxService) GetFamilyMembers(ctx context.Context, nationalId string) (map[string]int, error) {
+	taxpayer, err := ts.Db.GetTaxpayer(ctx, nationalId)
+	if err != nil {
+		return nil, taxpayerNotFound(err)
+	}
+	return FamilyMembers(taxpayer), nil
+}
@@ -0,0 +1,157 @@
+//go:build !integration
+// +build !integration
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+	"github.com/baronight/assessment-tax/utils"
+)
+
+type StubTaxpayerStore struct {
+	taxpayers    map[string]models.Taxpayer
+	err          error
+	expectToCall map[string]bool
+}
+
+func (s *StubTaxpayerStore) CreateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error) {
+	s.expectToCall["CreateTaxpayer"] = true
+	return taxpayer, s.err
+}
+
+func (s *StubTaxpayerStore) GetTaxpayer(ctx context.Context, nationalId string) (models.Taxpayer, error) {
+	s.expectToCall["GetTaxpayer"] = true
+	taxpayer, ok := s.taxpayers[nationalId]
+	if !ok {
+		return taxpayer, sql.ErrNoRows
+	}
+	return taxpayer, s.err
+}
+
+func (s *StubTaxpayerStore) UpdateTaxpayer(ctx context.Context, taxpayer models.Taxpayer) (models.Taxpayer, error) {
+	s.expectToCall["UpdateTaxpayer"] = true
+	if _, ok := s.taxpayers[taxpayer.NationalId]; !ok {
+		return models.Taxpayer{}, sql.ErrNoRows
+	}
+	return taxpayer, s.err
+}
+
+func (s *StubTaxpayerStore) DeleteTaxpayer(ctx context.Context, nationalId string) error {
+	s.expectToCall["DeleteTaxpayer"] = true
+	if _, ok := s.taxpayers[nationalId]; !ok {
+		return sql.ErrNoRows
+	}
+	return s.err
+}
+
+func TestTaxpayerService(t *testing.T) {
+	stub := &StubTaxpayerStore{
+		taxpayers:    map[string]models.Taxpayer{"1234567890121": {NationalId: "1234567890121", Name: "Somchai"}},
+		expectToCall: map[string]bool{},
+	}
+	service := NewTaxpayerService(stub)
+
+	t.Run("given exist national id should return taxpayer", func(t *testing.T) {
+		got, err := service.GetTaxpayer(context.Background(), "1234567890121")
+
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, "Somchai", got.Name, "expect taxpayer name should be Somchai")
+	})
+	t.Run("given not exist national id should return not found error", func(t *testing.T) {
+		_, err := service.GetTaxpayer(context.Background(), "3100600123450")
+		assertIsEqual(t, utils.ErrTaxpayerNotFound, err, "expect get taxpayer not found error")
+
+		_, err = service.UpdateTaxpayer(context.Background(), models.Taxpayer{NationalId: "3100600123450"})
+		assertIsEqual(t, utils.ErrTaxpayerNotFound, err, "expect update taxpayer not found error")
+
+		err = service.DeleteTaxpayer(context.Background(), "3100600123450")
+		assertIsEqual(t, utils.ErrTaxpayerNotFound, err, "expect delete taxpayer not found error")
+	})
+	t.Run("given error from db should return same error", func(t *testing.T) {
+		stub.err = errors.New("error 'xxx' occured")
+		defer func() { stub.err = nil }()
+
+		_, err := service.CreateTaxpayer(context.Background(), models.Taxpayer{NationalId: "3100600123450"})
+
+		assertIsEqual(t, stub.err, err, "expect error from db")
+	})
+}
+
+func TestFamilyMembers(t *testing.T) {
+	testSuites := []struct {
+		name     string
+		taxpayer models.Taxpayer
+		want     map[string]int
+	}{
+		{
+			name:     "given single taxpayer without dependant should get no member",
+			taxpayer: models.Taxpayer{MaritalStatus: models.SingleStatus},
+			want:     map[string]int{models.ChildMember: 0, models.ParentMember: 0},
+		},
+		{
+			name:     "given married taxpayer whose spouse has no income should count spouse",
+			taxpayer: models.Taxpayer{MaritalStatus: models.MarriedStatus, Children: 2},
+			want:     map[string]int{models.SpouseMember: 1, models.ChildMember: 2, models.ParentMember: 0},
+		},
+		{
+			name:     "given married taxpayer whose spouse has income should not count spouse",
+			taxpayer: models.Taxpayer{MaritalStatus: models.MarriedStatus, SpouseHasIncome: true, Children: 1, Parents: 2},
+			want:     map[string]int{models.ChildMember: 1, models.ParentMember: 2},
+		},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			assertObjectIsEqual(t, tc.want, FamilyMembers(tc.taxpayer))
+		})
+	}
+}
+
+func TestTaxCalculateWithTaxpayer(t *testing.T) {
+	t.Run("given taxpayer id should deduct family allowance from profile", func(t *testing.T) {
+		stub := initStub(nil, nil)
+		stub.taxpayers = map[string]models.Taxpayer{
+			"1234567890121": {NationalId: "1234567890121", MaritalStatus: models.MarriedStatus, Children: 2},
+		}
+		service := setupTaxService(stub)
+
+		got, err := service.TaxCalculate(context.Background(), models.TaxRequest{TaxpayerId: "1234567890121", TotalIncome: 500_000})
+
+		// 500,000 - 60,000 personal - 60,000 spouse - 60,000 children = 320,000
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 17_000.0, got.Tax, expectTaxValueMsg(17_000, got.Tax))
+	})
+	t.Run("given rule set with family allowances should deduct them by its amount and half-year flag", func(t *testing.T) {
+		restoreDefaultRuleSet(t)
+		ruleSet := ActiveRuleSet()
+		ruleSet.Deductions = []models.RuleDeduction{
+			{Slug: models.PersonalSlug, Type: models.FixedRuleType, Amount: 60_000, HalfOnHalfYear: true},
+			{Slug: "child-allowance", Type: models.FamilyRuleType, Member: models.ChildMember, Amount: 50_000},
+		}
+		ApplyRuleSet(ruleSet)
+		stub := initStub(nil, nil)
+		stub.taxpayers = map[string]models.Taxpayer{
+			"1234567890121": {NationalId: "1234567890121", MaritalStatus: models.MarriedStatus, Children: 2},
+		}
+		service := setupTaxService(stub)
+
+		got, err := service.TaxCalculate(context.Background(), models.TaxRequest{
+			TaxpayerId: "1234567890121", TotalIncome: 500_000, Period: models.HalfYearPeriod, IncomeType: models.RentalIncome,
+		})
+
+		// 500,000 - 30,000 half personal - 100,000 children that is not halved, rule set has no spouse allowance = 370,000
+		assertIsNil(t, err, expectNilErrMsg)
+		assertIsEqual(t, 22_000.0, got.Tax, expectTaxValueMsg(22_000, got.Tax))
+	})
+	t.Run("given not exist taxpayer id should return not found error", func(t *testing.T) {
+		service := setupTaxService(initStub(nil, nil))
+
+		_, err := service.TaxCalculate(context.Background(), models.TaxRequest{TaxpayerId: "1234567890121", TotalIncome: 500_000})
+
+		assertIsEqual(t, utils.ErrTaxpayerNotFound, err, "expect taxpayer not found error")
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+
+	"github.com/baronight/assessment-tax/cache"
+	"github.com/baronight/assessment-tax/config"
+	"github.com/baronight/assessment-tax/db"
+	"github.com/baronight/assessment-tax/handlers"
+	"github.com/baronight/assessment-tax/memory"
+	"github.com/baronight/assessment-tax/metrics"
+	"github.com/baronight/assessment-tax/services"
+	"github.com/lib/pq"
+)
+
+// storage is every storer that services need, it is implemented by database and memory backend
+type storage interface {
+	services.TaxReturnStorer
+	services.AdminStorer
+	services.TaxpayerStorer
+	services.RuleSetStorer
+	services.ExchangeRateStorer
+	services.HealthStorer
+}
+
+// backend is storage that is selected by STORAGE, cache and pool are nil when backend has no deduction cache
+// or database connection pool, watchRuleSets is nil when rule set can be activated only by this instance
+type backend struct {
+	store         storage
+	cache         handlers.CacheServicer
+	pool          handlers.PoolServicer
+	watchRuleSets func(loader cache.RuleSetLoader)
+	close         func() error
+}
+
+// openStorage select backend from storage config, it is memory for STORAGE=memory otherwise database of DATABASE_DRIVER
+func openStorage(cfg config.Config) (*backend, error) {
+	if cfg.Storage == config.StorageMemory {
+		return &backend{
+			store: memory.New(),
+			close: func() error { return nil },
+		}, nil
+	}
+
+	database, err := db.New(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	metrics.RegisterDB(database.Db, database.Driver)
+	store := cache.NewStore(database, cfg.DeductionCacheTTL)
+	if database.Driver == config.DriverSqlite {
+		// sqlite has no LISTEN/NOTIFY, cached deductions are expired by TTL only
+		return &backend{store: store, cache: store.Deductions, pool: database, close: database.Db.Close}, nil
+	}
+	listenCtx, stopListen := context.WithCancel(context.Background())
+	listeners := []*pq.Listener{cache.ListenDeductions(listenCtx, cfg.Database.URL, store.Deductions)}
+	return &backend{
+		store: store,
+		cache: store.Deductions,
+		pool:  database,
+		watchRuleSets: func(loader cache.RuleSetLoader) {
+			listeners = append(listeners, cache.ListenRuleSets(listenCtx, cfg.Database.URL, loader))
+		},
+		close: func() error {
+			// stop listening deduction and rule set changes before close db
+			stopListen()
+			for _, listener := range listeners {
+				listener.Close()
+			}
+			return database.Db.Close()
+		},
+	}, nil
+}
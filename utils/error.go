@@ -3,5 +3,21 @@ package utils
 import "errors"
 
 var (
-	ErrInternalServer = errors.New("internal server error")
+	ErrInternalServer           = errors.New("internal server error")
+	ErrExchangeRateNotFound     = errors.New("exchange rate not found")
+	ErrTaxReturnNotFound        = errors.New("tax return not found")
+	ErrTaxReturnSnapshotMissing = errors.New("tax return was saved without taxpayer profile snapshot, amend it to get its summary")
+	ErrTaxReturnExists          = errors.New("revision of tax return already exists, amend the latest revision instead")
+	ErrTaxpayerNotFound         = errors.New("taxpayer not found")
+	ErrTaxpayerExists           = errors.New("taxpayer already exists")
+	ErrTaxpayerAuthRequired     = errors.New("authentication is required to calculate with taxpayer profile")
+	ErrEFilingRecordInvalid     = errors.New("e-filing record missing required field")
+	ErrRuleSetNotFound          = errors.New("rule set not found")
+	ErrDeductionChangeNotFound  = errors.New("deduction change not found")
+	ErrDeductionChangeReviewed  = errors.New("deduction change was already reviewed")
+	ErrSelfReview               = errors.New("deduction change should be reviewed by other admin")
+	ErrVersionRequired          = errors.New("If-Match header with version is required")
+	ErrVersionMismatch          = errors.New("data was changed by other admin, get latest version and try again")
+	ErrCsvHeaderMissing         = errors.New("missing required header field")
+	ErrCsvValueEmpty            = errors.New("value should not be empty")
 )
@@ -0,0 +1,59 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+var (
+	ErrCertificatesRequired   = errors.New("certificates should not be empty")
+	ErrPayerTaxIdInvalid      = errors.New("payer tax id should be 13 digits with valid check digit")
+	ErrCertificateIncomeType  = errors.New("income type should be one of 'salary', 'rental', 'professional', 'contract', 'business'")
+	ErrAmountPaidInvalid      = errors.New("amount paid should be more than 0")
+	ErrTaxWithheldInvalid     = errors.New("tax withheld should be more than or equal 0")
+	ErrTaxWithheldMoreThanPay = errors.New("tax withheld should not more than amount paid")
+)
+
+func ValidateCertificate(certificate models.Certificate) error {
+	if ValidateNationalId(certificate.PayerTaxId) != nil {
+		return ErrPayerTaxIdInvalid
+	}
+	if certificate.IncomeType != models.SalaryIncome && !slices.Contains(models.HalfYearIncomeTypes, certificate.IncomeType) {
+		return ErrCertificateIncomeType
+	}
+	if certificate.AmountPaid <= 0 {
+		return ErrAmountPaidInvalid
+	}
+	if certificate.TaxWithheld < 0 {
+		return ErrTaxWithheldInvalid
+	}
+	if certificate.TaxWithheld > certificate.AmountPaid {
+		return ErrTaxWithheldMoreThanPay
+	}
+	return nil
+}
+
+func ValidateCertificateTaxRequest(req models.CertificateTaxRequest) error {
+	if req.TaxpayerId != "" {
+		if err := ValidateNationalId(req.TaxpayerId); err != nil {
+			return err
+		}
+	}
+	for _, v := range req.Allowances {
+		if err := ValidateAllowance(v); err != nil {
+			return err
+		}
+	}
+	if len(req.Certificates) == 0 {
+		return ErrCertificatesRequired
+	}
+	for i, v := range req.Certificates {
+		if err := ValidateCertificate(v); err != nil {
+			return fmt.Errorf("certificate %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+//go:build !integration
+// +build !integration
+
+package validators
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestValidateCertificate(t *testing.T) {
+	valid := models.Certificate{PayerTaxId: "0105556123453", IncomeType: models.SalaryIncome, AmountPaid: 500_000, TaxWithheld: 25_000}
+	testSuites := []struct {
+		name   string
+		modify func(c *models.Certificate)
+		want   error
+	}{
+		{
+			name:   "given invalid payer tax id should get error 'ErrPayerTaxIdInvalid'",
+			modify: func(c *models.Certificate) { c.PayerTaxId = "0105556123451" },
+			want:   ErrPayerTaxIdInvalid,
+		},
+		{
+			name:   "given unknown income type should get error 'ErrCertificateIncomeType'",
+			modify: func(c *models.Certificate) { c.IncomeType = "dividend" },
+			want:   ErrCertificateIncomeType,
+		},
+		{
+			name:   "given zero amount paid should get error 'ErrAmountPaidInvalid'",
+			modify: func(c *models.Certificate) { c.AmountPaid = 0 },
+			want:   ErrAmountPaidInvalid,
+		},
+		{
+			name:   "given negative tax withheld should get error 'ErrTaxWithheldInvalid'",
+			modify: func(c *models.Certificate) { c.TaxWithheld = -1 },
+			want:   ErrTaxWithheldInvalid,
+		},
+		{
+			name:   "given tax withheld more than amount paid should get error 'ErrTaxWithheldMoreThanPay'",
+			modify: func(c *models.Certificate) { c.TaxWithheld = 600_000 },
+			want:   ErrTaxWithheldMoreThanPay,
+		},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			certificate := valid
+			tc.modify(&certificate)
+			err := ValidateCertificate(certificate)
+			assertIsNotNil(t, err)
+			assertErrorMessage(t, tc.want, err)
+		})
+	}
+	t.Run("given valid certificate should not get error", func(t *testing.T) {
+		assertIsNil(t, ValidateCertificate(valid))
+	})
+}
+
+func TestValidateCertificateTaxRequest(t *testing.T) {
+	t.Run("given no certificate should get error 'ErrCertificatesRequired'", func(t *testing.T) {
+		err := ValidateCertificateTaxRequest(models.CertificateTaxRequest{})
+		assertIsNotNil(t, err)
+		assertErrorMessage(t, ErrCertificatesRequired, err)
+	})
+	t.Run("given invalid second certificate should get error with certificate number", func(t *testing.T) {
+		err := ValidateCertificateTaxRequest(models.CertificateTaxRequest{Certificates: []models.Certificate{
+			{PayerTaxId: "0105556123453", IncomeType: models.SalaryIncome, AmountPaid: 1},
+			{PayerTaxId: "0105556123453", IncomeType: models.SalaryIncome},
+		}})
+		if !errors.Is(err, ErrAmountPaidInvalid) || err.Error() != "certificate 2: "+ErrAmountPaidInvalid.Error() {
+			t.Errorf("expect amount paid error of certificate 2 but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+var (
+	ErrEFilingReturnsRequired = errors.New("returns should not be empty")
+	ErrEFilingFormatInvalid   = errors.New("format should be one of 'txt', 'xml'")
+	ErrEFilingHalfYear        = errors.New("e-filing export support only full-year return (PND 90/91)")
+	ErrEFilingFormTypeInvalid = errors.New("form type should be one of 'PND90', 'PND91'")
+	ErrEFilingNameEncoding    = errors.New("taxpayer name should have only Thai or Latin characters of TIS-620")
+)
+
+func ValidateEFilingFormat(format string) error {
+	if !slices.Contains([]string{models.EFilingFixedWidth, models.EFilingXml}, format) {
+		return ErrEFilingFormatInvalid
+	}
+	return nil
+}
+
+func ValidateEFilingRequest(req models.EFilingRequest) error {
+	if len(req.Returns) == 0 {
+		return ErrEFilingReturnsRequired
+	}
+	for i, v := range req.Returns {
+		if err := ValidateTaxReturnRequest(v); err != nil {
+			return fmt.Errorf("return %d: %w", i+1, err)
+		}
+		if v.Tax.Period == models.HalfYearPeriod {
+			return fmt.Errorf("return %d: %w", i+1, ErrEFilingHalfYear)
+		}
+	}
+	return nil
+}
+
+// ValidateEFilingRecord reject record that missing field which required by e-filing import
+func ValidateEFilingRecord(record models.EFilingRecord) error {
+	if !slices.Contains([]string{models.PND90Form, models.PND91Form}, record.FormType) {
+		return ErrEFilingFormTypeInvalid
+	}
+	if err := ValidateTaxYear(record.TaxYear); err != nil {
+		return err
+	}
+	if err := ValidateNationalId(record.NationalId); err != nil {
+		return err
+	}
+	if record.Name == "" {
+		return ErrTaxpayerNameRequired
+	}
+	if _, err := models.EFilingEncoding.NewEncoder().String(record.Name); err != nil {
+		return ErrEFilingNameEncoding
+	}
+	if err := ValidateTotalIncome(record.Income); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+//go:build !integration
+// +build !integration
+
+package validators
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestValidateEFilingRequest(t *testing.T) {
+	t.Run("given no return should get error 'ErrEFilingReturnsRequired'", func(t *testing.T) {
+		err := ValidateEFilingRequest(models.EFilingRequest{})
+		assertIsNotNil(t, err)
+		assertErrorMessage(t, ErrEFilingReturnsRequired, err)
+	})
+	t.Run("given half-year return should get error 'ErrEFilingHalfYear'", func(t *testing.T) {
+		err := ValidateEFilingRequest(models.EFilingRequest{Returns: []models.TaxReturnRequest{{
+			TaxpayerId: "1234567890121",
+			TaxYear:    2567,
+			Tax:        models.TaxRequest{Period: models.HalfYearPeriod, IncomeType: models.RentalIncome},
+		}}})
+		if !errors.Is(err, ErrEFilingHalfYear) {
+			t.Errorf("expect error is %v but got %v", ErrEFilingHalfYear, err)
+		}
+	})
+	t.Run("given return without taxpayer id should get error 'ErrTaxpayerIdRequired'", func(t *testing.T) {
+		err := ValidateEFilingRequest(models.EFilingRequest{Returns: []models.TaxReturnRequest{{TaxYear: 2567}}})
+		if !errors.Is(err, ErrTaxpayerIdRequired) {
+			t.Errorf("expect error is %v but got %v", ErrTaxpayerIdRequired, err)
+		}
+	})
+}
+
+func TestValidateEFilingRecord(t *testing.T) {
+	valid := models.EFilingRecord{FormType: models.PND91Form, TaxYear: 2567, NationalId: "1234567890121", Name: "Somchai"}
+	testSuites := []struct {
+		name   string
+		modify func(r *models.EFilingRecord)
+		want   error
+	}{
+		{
+			name:   "given unknown form type should get error 'ErrEFilingFormTypeInvalid'",
+			modify: func(r *models.EFilingRecord) { r.FormType = "PND94" },
+			want:   ErrEFilingFormTypeInvalid,
+		},
+		{
+			name:   "given no tax year should get error 'ErrTaxYearInvalid'",
+			modify: func(r *models.EFilingRecord) { r.TaxYear = 0 },
+			want:   ErrTaxYearInvalid,
+		},
+		{
+			name:   "given no national id should get error 'ErrNationalIdInvalid'",
+			modify: func(r *models.EFilingRecord) { r.NationalId = "" },
+			want:   ErrNationalIdInvalid,
+		},
+		{
+			name:   "given no name should get error 'ErrTaxpayerNameRequired'",
+			modify: func(r *models.EFilingRecord) { r.Name = "" },
+			want:   ErrTaxpayerNameRequired,
+		},
+		{
+			name:   "given name out of TIS-620 should get error 'ErrEFilingNameEncoding'",
+			modify: func(r *models.EFilingRecord) { r.Name = "王小明" },
+			want:   ErrEFilingNameEncoding,
+		},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			record := valid
+			tc.modify(&record)
+			err := ValidateEFilingRecord(record)
+			assertIsNotNil(t, err)
+			assertErrorMessage(t, tc.want, err)
+		})
+	}
+	t.Run("given complete record should not get error", func(t *testing.T) {
+		assertIsNil(t, ValidateEFilingRecord(valid))
+	})
+	t.Run("given unknown format should get error 'ErrEFilingFormatInvalid'", func(t *testing.T) {
+		err := ValidateEFilingFormat("pdf")
+		assertIsNotNil(t, err)
+		assertErrorMessage(t, ErrEFilingFormatInvalid, err)
+	})
+}
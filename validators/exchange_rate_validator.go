@@ -0,0 +1,65 @@
+package validators
+
+import (
+	"errors"
+	"time"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+var (
+	ErrCurrencyInvalid     = errors.New("currency should be 3 uppercase letters code e.g. 'USD'")
+	ErrExchangeRateInvalid = errors.New("exchange rate should be more than 0")
+	ErrThbExchangeRate     = errors.New("exchange rate of THB should not be set")
+)
+
+func ValidateCurrency(currency string) error {
+	if currency == "" {
+		return nil
+	}
+	if len(currency) != 3 {
+		return ErrCurrencyInvalid
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return ErrCurrencyInvalid
+		}
+	}
+	return nil
+}
+
+func ValidateTaxCurrency(tax models.TaxRequest) error {
+	if err := ValidateCurrency(tax.Currency); err != nil {
+		return err
+	}
+	for _, v := range tax.Allowances {
+		if err := ValidateCurrency(v.Currency); err != nil {
+			return err
+		}
+	}
+	if tax.RateDate != "" {
+		if _, err := time.Parse(models.DateLayout, tax.RateDate); err != nil {
+			return ErrDateInvalid
+		}
+	}
+	return nil
+}
+
+func ValidateExchangeRate(rate models.ExchangeRate) error {
+	if rate.Currency == "" {
+		return ErrCurrencyInvalid
+	}
+	if err := ValidateCurrency(rate.Currency); err != nil {
+		return err
+	}
+	if rate.Currency == models.ThbCurrency {
+		return ErrThbExchangeRate
+	}
+	if rate.Rate <= 0 {
+		return ErrExchangeRateInvalid
+	}
+	if _, err := time.Parse(models.DateLayout, rate.RateDate); err != nil {
+		return ErrDateInvalid
+	}
+	return nil
+}
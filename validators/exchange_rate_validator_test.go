@@ -0,0 +1,57 @@
+//go:build !integration
+// +build !integration
+
+package validators
+
+import (
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestValidateCurrency(t *testing.T) {
+	for _, v := range []string{"usd", "US", "USDT", "U$D"} {
+		t.Run("given currency '"+v+"' should get error 'ErrCurrencyInvalid'", func(t *testing.T) {
+			err := ValidateCurrency(v)
+			assertIsNotNil(t, err)
+			assertErrorMessage(t, ErrCurrencyInvalid, err)
+		})
+	}
+	t.Run("given empty or valid currency should not get error", func(t *testing.T) {
+		assertIsNil(t, ValidateCurrency(""))
+		assertIsNil(t, ValidateCurrency("USD"))
+	})
+	t.Run("given invalid allowance currency on tax request should get error 'ErrCurrencyInvalid'", func(t *testing.T) {
+		err := ValidateTaxRequest(models.TaxRequest{Allowances: []models.Allowance{{Type: models.DonationSlug, Currency: "usd"}}})
+		assertIsNotNil(t, err)
+		assertErrorMessage(t, ErrCurrencyInvalid, err)
+	})
+	t.Run("given invalid rate date on tax request should get error 'ErrDateInvalid'", func(t *testing.T) {
+		err := ValidateTaxRequest(models.TaxRequest{Currency: "USD", RateDate: "30/12/2024"})
+		assertIsNotNil(t, err)
+		assertErrorMessage(t, ErrDateInvalid, err)
+	})
+}
+
+func TestValidateExchangeRate(t *testing.T) {
+	testSuites := []struct {
+		name string
+		rate models.ExchangeRate
+		want error
+	}{
+		{"given empty currency should get error 'ErrCurrencyInvalid'", models.ExchangeRate{Rate: 1, RateDate: "2024-12-30"}, ErrCurrencyInvalid},
+		{"given THB should get error 'ErrThbExchangeRate'", models.ExchangeRate{Currency: "THB", Rate: 1, RateDate: "2024-12-30"}, ErrThbExchangeRate},
+		{"given zero rate should get error 'ErrExchangeRateInvalid'", models.ExchangeRate{Currency: "USD", RateDate: "2024-12-30"}, ErrExchangeRateInvalid},
+		{"given invalid date should get error 'ErrDateInvalid'", models.ExchangeRate{Currency: "USD", Rate: 35}, ErrDateInvalid},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateExchangeRate(tc.rate)
+			assertIsNotNil(t, err)
+			assertErrorMessage(t, tc.want, err)
+		})
+	}
+	t.Run("given valid rate should not get error", func(t *testing.T) {
+		assertIsNil(t, ValidateExchangeRate(models.ExchangeRate{Currency: "USD", Rate: 35, RateDate: "2024-12-30"}))
+	})
+}
@@ -0,0 +1,63 @@
+package validators
+
+import (
+	"errors"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+var (
+	ErrMonthlySalaryInvalid = errors.New("monthly salary should be more than or equal 0")
+	ErrPayrollMonthInvalid  = errors.New("month should be between 1 and 12")
+	ErrBonusMonthsInvalid   = errors.New("bonus months should be more than or equal 0")
+	ErrYtdIncomeInvalid     = errors.New("year-to-date income should be more than or equal 0")
+	ErrYtdWhtInvalid        = errors.New("year-to-date wht should be more than or equal 0")
+	ErrYtdWhtMoreThanIncome = errors.New("year-to-date wht should not more than year-to-date income")
+)
+
+func ValidatePayrollRequest(payroll models.PayrollRequest) error {
+	if err := ValidatePayroll(payroll.MonthlySalary, payroll.Month, payroll.BonusMonths, payroll.YtdIncome, payroll.YtdWht); err != nil {
+		return err
+	}
+	for _, v := range payroll.Allowances {
+		if err := ValidateAllowance(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ValidatePayrollCsv(csv models.PayrollCsv) error {
+	if err := ValidatePayroll(csv.MonthlySalary, csv.Month, csv.BonusMonths, csv.YtdIncome, csv.YtdWht); err != nil {
+		return err
+	}
+	if err := ValidateDeduction(models.DonationSlug, csv.Donation); err != nil {
+		return err
+	}
+	if err := ValidateDeduction(models.KReceiptSlug, csv.KReceipt); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ValidatePayroll(monthlySalary float64, month int, bonusMonths, ytdIncome, ytdWht float64) error {
+	if monthlySalary < 0 {
+		return ErrMonthlySalaryInvalid
+	}
+	if month < 1 || month > 12 {
+		return ErrPayrollMonthInvalid
+	}
+	if bonusMonths < 0 {
+		return ErrBonusMonthsInvalid
+	}
+	if ytdIncome < 0 {
+		return ErrYtdIncomeInvalid
+	}
+	if ytdWht < 0 {
+		return ErrYtdWhtInvalid
+	}
+	if ytdWht > ytdIncome {
+		return ErrYtdWhtMoreThanIncome
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+//go:build !integration
+// +build !integration
+
+package validators
+
+import (
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestValidatePayrollRequest(t *testing.T) {
+	testSuites := []struct {
+		name    string
+		payroll models.PayrollRequest
+		want    error
+	}{
+		{"given negative salary should get error 'ErrMonthlySalaryInvalid'", models.PayrollRequest{MonthlySalary: -1, Month: 1}, ErrMonthlySalaryInvalid},
+		{"given month 0 should get error 'ErrPayrollMonthInvalid'", models.PayrollRequest{Month: 0}, ErrPayrollMonthInvalid},
+		{"given month 13 should get error 'ErrPayrollMonthInvalid'", models.PayrollRequest{Month: 13}, ErrPayrollMonthInvalid},
+		{"given negative bonus months should get error 'ErrBonusMonthsInvalid'", models.PayrollRequest{Month: 1, BonusMonths: -1}, ErrBonusMonthsInvalid},
+		{"given negative ytd income should get error 'ErrYtdIncomeInvalid'", models.PayrollRequest{Month: 2, YtdIncome: -1}, ErrYtdIncomeInvalid},
+		{"given negative ytd wht should get error 'ErrYtdWhtInvalid'", models.PayrollRequest{Month: 2, YtdWht: -1}, ErrYtdWhtInvalid},
+		{"given ytd wht more than ytd income should get error 'ErrYtdWhtMoreThanIncome'", models.PayrollRequest{Month: 2, YtdIncome: 10, YtdWht: 11}, ErrYtdWhtMoreThanIncome},
+		{"given invalid allowance should get error 'ErrAllowanceTypeInvalid'", models.PayrollRequest{Month: 1, Allowances: []models.Allowance{{Type: "kReceipt"}}}, ErrAllowanceTypeInvalid},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidatePayrollRequest(tc.payroll)
+
+			assertIsNotNil(t, err)
+			assertErrorMessage(t, tc.want, err)
+		})
+	}
+	t.Run("given valid payroll request should not get error", func(t *testing.T) {
+		err := ValidatePayrollRequest(models.PayrollRequest{
+			MonthlySalary: 50_000,
+			Month:         6,
+			BonusMonths:   1.5,
+			YtdIncome:     250_000,
+			YtdWht:        10_000,
+			Allowances:    []models.Allowance{{Type: models.DonationSlug, Amount: 1_000}},
+		})
+		assertIsNil(t, err)
+	})
+}
+
+func TestValidatePayrollCsv(t *testing.T) {
+	t.Run("given invalid donation should get error 'donation amount should be more than or equal 0'", func(t *testing.T) {
+		err := ValidatePayrollCsv(models.PayrollCsv{Month: 1, Donation: -1})
+
+		assertIsNotNil(t, err)
+		if err.Error() != "donation amount should be more than or equal 0" {
+			t.Errorf("unexpected error %q", err)
+		}
+	})
+	t.Run("given valid payroll csv should not get error", func(t *testing.T) {
+		err := ValidatePayrollCsv(models.PayrollCsv{EmployeeId: "E001", MonthlySalary: 50_000, Month: 1})
+		assertIsNil(t, err)
+	})
+}
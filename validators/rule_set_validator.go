@@ -0,0 +1,108 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+var (
+	ErrRuleSetNameRequired      = errors.New("rule set name is required")
+	ErrRuleSetFormatInvalid     = errors.New("rule set format should be json or yaml")
+	ErrRuleSetBracketsRequired  = errors.New("rule set should have at least one bracket")
+	ErrRuleSetBracketStart      = errors.New("first bracket should start at 0")
+	ErrRuleSetBracketGap        = errors.New("bracket should start at max income of previous bracket")
+	ErrRuleSetBracketRange      = errors.New("bracket max income should be more than min income, only last bracket can be 0")
+	ErrRuleSetBracketRate       = errors.New("bracket rate should be between 0 and 1")
+	ErrRuleDeductionSlug        = errors.New("deduction slug is required")
+	ErrRuleDeductionDuplicate   = errors.New("deduction slug should not be duplicated")
+	ErrRuleDeductionType        = errors.New("deduction type should be fixed, allowance or family")
+	ErrRuleDeductionAmount      = errors.New("deduction amount should not be negative")
+	ErrRuleDeductionPercent     = errors.New("deduction percent limit should be between 0 and 100")
+	ErrRuleDeductionPeriod      = errors.New("deduction period should be full-year or half-year")
+	ErrRuleDeductionFixedAmount = errors.New("fixed and family deduction should have amount")
+	ErrRuleDeductionMember      = errors.New("family deduction member should be spouse, child or parent")
+)
+
+func ValidateRuleSetFormat(format string) error {
+	if format != models.JsonRuleSetFormat && format != models.YamlRuleSetFormat {
+		return ErrRuleSetFormatInvalid
+	}
+	return nil
+}
+
+func ValidateRuleSet(ruleSet models.RuleSet) error {
+	if ruleSet.Name == "" {
+		return ErrRuleSetNameRequired
+	}
+	if err := ValidateTaxYear(ruleSet.TaxYear); err != nil {
+		return err
+	}
+	if len(ruleSet.Brackets) == 0 {
+		return ErrRuleSetBracketsRequired
+	}
+	for i, v := range ruleSet.Brackets {
+		if err := validateBracket(ruleSet.Brackets, i); err != nil {
+			return fmt.Errorf("bracket %d: %w", i+1, err)
+		}
+		if v.Rate < 0 || v.Rate > 1 {
+			return fmt.Errorf("bracket %d: %w", i+1, ErrRuleSetBracketRate)
+		}
+	}
+	slugs := []string{}
+	for i, v := range ruleSet.Deductions {
+		if slices.Contains(slugs, v.Slug) {
+			return fmt.Errorf("deduction %d: %w", i+1, ErrRuleDeductionDuplicate)
+		}
+		if err := ValidateRuleDeduction(v); err != nil {
+			return fmt.Errorf("deduction %d: %w", i+1, err)
+		}
+		slugs = append(slugs, v.Slug)
+	}
+	return nil
+}
+
+func validateBracket(brackets []models.TaxStep, i int) error {
+	v := brackets[i]
+	if i == 0 && v.MinIncome != 0 {
+		return ErrRuleSetBracketStart
+	}
+	if i > 0 && v.MinIncome != brackets[i-1].MaxIncome {
+		return ErrRuleSetBracketGap
+	}
+	last := i == len(brackets)-1
+	if (v.MaxIncome == 0 && !last) || (v.MaxIncome != 0 && v.MaxIncome <= v.MinIncome) {
+		return ErrRuleSetBracketRange
+	}
+	return nil
+}
+
+func ValidateRuleDeduction(deduction models.RuleDeduction) error {
+	if deduction.Slug == "" {
+		return ErrRuleDeductionSlug
+	}
+	if !slices.Contains([]string{models.FixedRuleType, models.AllowanceRuleType, models.FamilyRuleType}, deduction.Type) {
+		return ErrRuleDeductionType
+	}
+	if deduction.Amount < 0 {
+		return ErrRuleDeductionAmount
+	}
+	if deduction.Type != models.AllowanceRuleType && deduction.Amount == 0 {
+		return ErrRuleDeductionFixedAmount
+	}
+	isMember := slices.Contains([]string{models.SpouseMember, models.ChildMember, models.ParentMember}, deduction.Member)
+	if (deduction.Type == models.FamilyRuleType) != isMember {
+		return ErrRuleDeductionMember
+	}
+	if deduction.PercentLimit < 0 || deduction.PercentLimit > 100 {
+		return ErrRuleDeductionPercent
+	}
+	for _, v := range deduction.Periods {
+		if v != models.FullYearPeriod && v != models.HalfYearPeriod {
+			return ErrRuleDeductionPeriod
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+//go:build !integration
+// +build !integration
+
+package validators
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func validRuleSet() models.RuleSet {
+	return models.RuleSet{
+		Name:    "default",
+		TaxYear: 2567,
+		Brackets: []models.TaxStep{
+			{MinIncome: 0, MaxIncome: 150_000, Rate: 0},
+			{MinIncome: 150_000, MaxIncome: 0, Rate: 0.1},
+		},
+		Deductions: []models.RuleDeduction{
+			{Slug: models.PersonalSlug, Type: models.FixedRuleType, Amount: 60_000},
+			{Slug: models.DonationSlug, Type: models.AllowanceRuleType, PercentLimit: 10, Periods: []string{models.FullYearPeriod}},
+		},
+	}
+}
+
+func TestValidateRuleSet(t *testing.T) {
+	testSuites := []struct {
+		name   string
+		modify func(rs *models.RuleSet)
+		want   error
+	}{
+		{
+			name:   "given empty name should get error 'ErrRuleSetNameRequired'",
+			modify: func(rs *models.RuleSet) { rs.Name = "" },
+			want:   ErrRuleSetNameRequired,
+		},
+		{
+			name:   "given tax year in Christian Era should get error 'ErrTaxYearInvalid'",
+			modify: func(rs *models.RuleSet) { rs.TaxYear = 2024 },
+			want:   ErrTaxYearInvalid,
+		},
+		{
+			name:   "given no bracket should get error 'ErrRuleSetBracketsRequired'",
+			modify: func(rs *models.RuleSet) { rs.Brackets = nil },
+			want:   ErrRuleSetBracketsRequired,
+		},
+		{
+			name:   "given first bracket not start at 0 should get error 'ErrRuleSetBracketStart'",
+			modify: func(rs *models.RuleSet) { rs.Brackets[0].MinIncome = 1 },
+			want:   ErrRuleSetBracketStart,
+		},
+		{
+			name:   "given gap between brackets should get error 'ErrRuleSetBracketGap'",
+			modify: func(rs *models.RuleSet) { rs.Brackets[1].MinIncome = 200_000 },
+			want:   ErrRuleSetBracketGap,
+		},
+		{
+			name:   "given no ceiling bracket that is not last should get error 'ErrRuleSetBracketRange'",
+			modify: func(rs *models.RuleSet) { rs.Brackets[0].MaxIncome = 0 },
+			want:   ErrRuleSetBracketRange,
+		},
+		{
+			name:   "given rate more than 1 should get error 'ErrRuleSetBracketRate'",
+			modify: func(rs *models.RuleSet) { rs.Brackets[1].Rate = 35 },
+			want:   ErrRuleSetBracketRate,
+		},
+		{
+			name:   "given duplicate deduction slug should get error 'ErrRuleDeductionDuplicate'",
+			modify: func(rs *models.RuleSet) { rs.Deductions[1].Slug = models.PersonalSlug },
+			want:   ErrRuleDeductionDuplicate,
+		},
+		{
+			name:   "given unknown deduction type should get error 'ErrRuleDeductionType'",
+			modify: func(rs *models.RuleSet) { rs.Deductions[1].Type = "percent" },
+			want:   ErrRuleDeductionType,
+		},
+		{
+			name:   "given fixed deduction without amount should get error 'ErrRuleDeductionFixedAmount'",
+			modify: func(rs *models.RuleSet) { rs.Deductions[0].Amount = 0 },
+			want:   ErrRuleDeductionFixedAmount,
+		},
+		{
+			name: "given family deduction without member should get error 'ErrRuleDeductionMember'",
+			modify: func(rs *models.RuleSet) {
+				rs.Deductions = append(rs.Deductions, models.RuleDeduction{Slug: models.ChildSlug, Type: models.FamilyRuleType, Amount: 30_000})
+			},
+			want: ErrRuleDeductionMember,
+		},
+		{
+			name: "given family deduction without amount should get error 'ErrRuleDeductionFixedAmount'",
+			modify: func(rs *models.RuleSet) {
+				rs.Deductions = append(rs.Deductions, models.RuleDeduction{Slug: models.ChildSlug, Type: models.FamilyRuleType, Member: models.ChildMember})
+			},
+			want: ErrRuleDeductionFixedAmount,
+		},
+		{
+			name:   "given member on deduction that is not family should get error 'ErrRuleDeductionMember'",
+			modify: func(rs *models.RuleSet) { rs.Deductions[1].Member = models.ChildMember },
+			want:   ErrRuleDeductionMember,
+		},
+		{
+			name:   "given percent limit more than 100 should get error 'ErrRuleDeductionPercent'",
+			modify: func(rs *models.RuleSet) { rs.Deductions[1].PercentLimit = 150 },
+			want:   ErrRuleDeductionPercent,
+		},
+		{
+			name:   "given unknown period should get error 'ErrRuleDeductionPeriod'",
+			modify: func(rs *models.RuleSet) { rs.Deductions[1].Periods = []string{"quarter"} },
+			want:   ErrRuleDeductionPeriod,
+		},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := validRuleSet()
+			tc.modify(&rs)
+
+			err := ValidateRuleSet(rs)
+
+			assertIsNotNil(t, err)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("expect error %v but got %v", tc.want, err)
+			}
+		})
+	}
+	t.Run("given valid rule set should not get error", func(t *testing.T) {
+		assertIsNil(t, ValidateRuleSet(validRuleSet()))
+	})
+	t.Run("given format other than json or yaml should get error 'ErrRuleSetFormatInvalid'", func(t *testing.T) {
+		assertErrorMessage(t, ErrRuleSetFormatInvalid, ValidateRuleSetFormat("xml"))
+	})
+}
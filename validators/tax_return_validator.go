@@ -0,0 +1,36 @@
+package validators
+
+import (
+	"errors"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+var (
+	ErrTaxpayerIdRequired = errors.New("taxpayer id is required")
+	ErrTaxYearInvalid     = errors.New("tax year should be in Buddhist Era e.g. 2567")
+	ErrTaxpayerIdMismatch = errors.New("taxpayer id of tax should be same as tax return")
+)
+
+func ValidateTaxReturnRequest(taxReturn models.TaxReturnRequest) error {
+	if taxReturn.TaxpayerId == "" {
+		return ErrTaxpayerIdRequired
+	}
+	if err := ValidateNationalId(taxReturn.TaxpayerId); err != nil {
+		return err
+	}
+	if taxReturn.Tax.TaxpayerId != "" && taxReturn.Tax.TaxpayerId != taxReturn.TaxpayerId {
+		return ErrTaxpayerIdMismatch
+	}
+	if err := ValidateTaxYear(taxReturn.TaxYear); err != nil {
+		return err
+	}
+	return ValidateTaxRequest(taxReturn.Tax)
+}
+
+func ValidateTaxYear(taxYear int) error {
+	if taxYear < 2500 || taxYear > 2700 {
+		return ErrTaxYearInvalid
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+//go:build !integration
+// +build !integration
+
+package validators
+
+import (
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestValidateTaxReturnRequest(t *testing.T) {
+	testSuites := []struct {
+		name string
+		req  models.TaxReturnRequest
+		want error
+	}{
+		{
+			name: "given empty taxpayer id should get error 'ErrTaxpayerIdRequired'",
+			req:  models.TaxReturnRequest{TaxYear: 2567},
+			want: ErrTaxpayerIdRequired,
+		},
+		{
+			name: "given invalid national id should get error 'ErrNationalIdInvalid'",
+			req:  models.TaxReturnRequest{TaxpayerId: "1234567890123", TaxYear: 2567},
+			want: ErrNationalIdInvalid,
+		},
+		{
+			name: "given different taxpayer id in tax should get error 'ErrTaxpayerIdMismatch'",
+			req:  models.TaxReturnRequest{TaxpayerId: "1234567890121", TaxYear: 2567, Tax: models.TaxRequest{TaxpayerId: "3100600123456"}},
+			want: ErrTaxpayerIdMismatch,
+		},
+		{
+			name: "given tax year in Christian Era should get error 'ErrTaxYearInvalid'",
+			req:  models.TaxReturnRequest{TaxpayerId: "1234567890121", TaxYear: 2024},
+			want: ErrTaxYearInvalid,
+		},
+		{
+			name: "given invalid tax should get error 'ErrTotalIncomeInvalid'",
+			req:  models.TaxReturnRequest{TaxpayerId: "1234567890121", TaxYear: 2567, Tax: models.TaxRequest{TotalIncome: -1}},
+			want: ErrTotalIncomeInvalid,
+		},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTaxReturnRequest(tc.req)
+			assertIsNotNil(t, err)
+			assertErrorMessage(t, tc.want, err)
+		})
+	}
+	t.Run("given valid request should not get error", func(t *testing.T) {
+		assertIsNil(t, ValidateTaxReturnRequest(models.TaxReturnRequest{TaxpayerId: "1234567890121", TaxYear: 2567, Tax: models.TaxRequest{TotalIncome: 500_000}}))
+	})
+}
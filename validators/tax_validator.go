@@ -3,6 +3,9 @@ package validators
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/baronight/assessment-tax/models"
 )
@@ -15,9 +18,25 @@ var (
 	ErrWhtMoreThanIncome      = errors.New("wht should not more than income")
 	ErrAllowanceTypeInvalid   = errors.New("allowance type should be one of 'donation', 'k-receipt'")
 	ErrAllowanceAmountInvalid = errors.New("allowance amount should be more than or equal 0")
+	ErrDateInvalid            = errors.New("date should be in format 'YYYY-MM-DD'")
+	ErrFilingDateRequired     = errors.New("filing date and due date should be sent together")
+	ErrRefundDateRequired     = errors.New("refund date should be sent with filing date and due date")
+	ErrRefundBeforeFiling     = errors.New("refund date should not before filing date")
+	ErrPeriodInvalid          = errors.New("period should be one of 'half-year', 'full-year'")
+	ErrIncomeTypeInvalid      = errors.New("income type should be one of 'salary', 'rental', 'professional', 'contract', 'business'")
+	ErrHalfYearIncomeType     = errors.New("half-year period support only income type 'rental', 'professional', 'contract', 'business'")
+	ErrHalfYearAllowance      = errors.New("half-year period not allow k-receipt allowance")
+	ErrHalfYearPrepaidTax     = errors.New("half-year period should not have prepaid tax")
+	ErrPrepaidTaxInvalid      = errors.New("prepaid tax should be more than or equal 0")
+	ErrSpousePeriodMismatch   = errors.New("taxpayer and spouse should file in the same period")
 )
 
 func ValidateTaxRequest(tax models.TaxRequest) error {
+	if tax.TaxpayerId != "" {
+		if err := ValidateNationalId(tax.TaxpayerId); err != nil {
+			return err
+		}
+	}
 	if err := ValidateTotalIncome(tax.TotalIncome); err != nil {
 		return err
 	}
@@ -29,6 +48,15 @@ func ValidateTaxRequest(tax models.TaxRequest) error {
 			return err
 		}
 	}
+	if err := ValidateFilingDates(tax.FilingDate, tax.DueDate, tax.RefundDate); err != nil {
+		return err
+	}
+	if err := ValidatePeriod(tax); err != nil {
+		return err
+	}
+	if err := ValidateTaxCurrency(tax); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -65,9 +93,26 @@ func ValidateWht(wht, totalIncome float64) error {
 	return nil
 }
 
+// AllowanceTypes return allowance types that can be claimed, services set it to slugs of deduction rules
+// in registry of active rule set
+var AllowanceTypes = func() []string {
+	return []string{models.DonationSlug, models.KReceiptSlug}
+}
+
+// allowanceTypeError list allowance types that can be claimed, and match ErrAllowanceTypeInvalid
+type allowanceTypeError struct {
+	types []string
+}
+
+func (e allowanceTypeError) Error() string {
+	return fmt.Sprintf("allowance type should be one of '%s'", strings.Join(e.types, "', '"))
+}
+
+func (e allowanceTypeError) Is(target error) bool { return target == ErrAllowanceTypeInvalid }
+
 func ValidateAllowance(allowance models.Allowance) error {
-	if allowance.Type != models.DonationSlug && allowance.Type != models.KReceiptSlug {
-		return ErrAllowanceTypeInvalid
+	if types := AllowanceTypes(); !slices.Contains(types, allowance.Type) {
+		return allowanceTypeError{types}
 	}
 	if allowance.Amount < 0 {
 		return ErrAllowanceAmountInvalid
@@ -81,3 +126,92 @@ func ValidateDeduction(deduction string, amount float64) error {
 	}
 	return nil
 }
+
+func ValidateFilingDates(filingDate, dueDate, refundDate string) error {
+	if filingDate == "" && dueDate == "" {
+		if refundDate != "" {
+			return ErrRefundDateRequired
+		}
+		return nil
+	}
+	if filingDate == "" || dueDate == "" {
+		return ErrFilingDateRequired
+	}
+	filing, err := time.Parse(models.DateLayout, filingDate)
+	if err != nil {
+		return ErrDateInvalid
+	}
+	if _, err := time.Parse(models.DateLayout, dueDate); err != nil {
+		return ErrDateInvalid
+	}
+	if refundDate == "" {
+		return nil
+	}
+	refund, err := time.Parse(models.DateLayout, refundDate)
+	if err != nil {
+		return ErrDateInvalid
+	}
+	if refund.Before(filing) {
+		return ErrRefundBeforeFiling
+	}
+	return nil
+}
+
+func ValidateSpouseTaxRequest(tax models.SpouseTaxRequest) error {
+	if err := ValidateTaxRequest(tax.Taxpayer); err != nil {
+		return err
+	}
+	if err := ValidateTaxRequest(tax.Spouse); err != nil {
+		return fmt.Errorf("spouse %w", err)
+	}
+	// joint filing combine both incomes into one return, so both should be the same period
+	if periodOrFullYear(tax.Taxpayer.Period) != periodOrFullYear(tax.Spouse.Period) {
+		return ErrSpousePeriodMismatch
+	}
+	return nil
+}
+
+// periodOrFullYear return full-year period when period is not sent
+func periodOrFullYear(period string) string {
+	if period == "" {
+		return models.FullYearPeriod
+	}
+	return period
+}
+
+func ValidateSpouseTaxCsv(csv models.SpouseTaxCsv) error {
+	if err := ValidateTaxCsv(csv.Taxpayer); err != nil {
+		return err
+	}
+	if err := ValidateTaxCsv(csv.Spouse); err != nil {
+		return fmt.Errorf("spouse %w", err)
+	}
+	return nil
+}
+
+func ValidatePeriod(tax models.TaxRequest) error {
+	if tax.Period != "" && tax.Period != models.HalfYearPeriod && tax.Period != models.FullYearPeriod {
+		return ErrPeriodInvalid
+	}
+	if tax.IncomeType != "" && tax.IncomeType != models.SalaryIncome && !slices.Contains(models.HalfYearIncomeTypes, tax.IncomeType) {
+		return ErrIncomeTypeInvalid
+	}
+	if tax.PrepaidTax < 0 {
+		return ErrPrepaidTaxInvalid
+	}
+	if tax.Period != models.HalfYearPeriod {
+		return nil
+	}
+	if !slices.Contains(models.HalfYearIncomeTypes, tax.IncomeType) {
+		return ErrHalfYearIncomeType
+	}
+	if tax.PrepaidTax > 0 {
+		return ErrHalfYearPrepaidTax
+	}
+	for _, v := range tax.Allowances {
+		if v.Type == models.KReceiptSlug {
+			return ErrHalfYearAllowance
+		}
+	}
+	return nil
+}
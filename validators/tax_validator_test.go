@@ -98,6 +98,20 @@ func TestValidateTaxRequest(t *testing.T) {
 		assertIsNotNil(t, err)
 		assertErrorMessage(t, ErrAllowanceTypeInvalid, err)
 	})
+	t.Run("given allowance type of registered rule should accept it and list types in error", func(t *testing.T) {
+		original := AllowanceTypes
+		AllowanceTypes = func() []string { return []string{models.DonationSlug, "provident-fund"} }
+		t.Cleanup(func() { AllowanceTypes = original })
+
+		err := ValidateTaxRequest(models.TaxRequest{Allowances: []models.Allowance{{Type: "provident-fund", Amount: 1000}}})
+		assertIsNil(t, err)
+
+		err = ValidateTaxRequest(models.TaxRequest{Allowances: []models.Allowance{{Type: models.KReceiptSlug, Amount: 1000}}})
+		if !errors.Is(err, ErrAllowanceTypeInvalid) {
+			t.Errorf("expect error %q but got %v", ErrAllowanceTypeInvalid, err)
+		}
+		assertErrorMessage(t, errors.New("allowance type should be one of 'donation', 'provident-fund'"), err)
+	})
 	t.Run("given allowance amount is invalid should get error 'ErrAllowanceAmountInvalid'", func(t *testing.T) {
 		// case 1 donation type
 		err := ValidateTaxRequest(models.TaxRequest{
@@ -191,3 +205,112 @@ func TestValidateTaxCsv(t *testing.T) {
 		assertIsNil(t, err)
 	})
 }
+
+func TestValidateFilingDates(t *testing.T) {
+	testSuites := []struct {
+		name       string
+		filingDate string
+		dueDate    string
+		refundDate string
+		want       error
+	}{
+		{"given only filing date should get error 'ErrFilingDateRequired'", "2025-04-30", "", "", ErrFilingDateRequired},
+		{"given only due date should get error 'ErrFilingDateRequired'", "", "2025-03-31", "", ErrFilingDateRequired},
+		{"given only refund date should get error 'ErrRefundDateRequired'", "", "", "2025-09-30", ErrRefundDateRequired},
+		{"given invalid filing date format should get error 'ErrDateInvalid'", "30/04/2025", "2025-03-31", "", ErrDateInvalid},
+		{"given invalid due date format should get error 'ErrDateInvalid'", "2025-04-30", "2025-02-30", "", ErrDateInvalid},
+		{"given invalid refund date format should get error 'ErrDateInvalid'", "2025-04-30", "2025-03-31", "2025-9-30", ErrDateInvalid},
+		{"given refund date before filing date should get error 'ErrRefundBeforeFiling'", "2025-04-30", "2025-03-31", "2025-04-29", ErrRefundBeforeFiling},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateFilingDates(tc.filingDate, tc.dueDate, tc.refundDate)
+
+			assertIsNotNil(t, err)
+			assertErrorMessage(t, tc.want, err)
+		})
+	}
+	t.Run("given no dates should not get error", func(t *testing.T) {
+		assertIsNil(t, ValidateFilingDates("", "", ""))
+	})
+	t.Run("given valid dates should not get error", func(t *testing.T) {
+		assertIsNil(t, ValidateFilingDates("2025-04-30", "2025-03-31", "2025-09-30"))
+	})
+}
+
+func TestValidateSpouseTaxRequest(t *testing.T) {
+	t.Run("given invalid taxpayer income should get error 'ErrTotalIncomeInvalid'", func(t *testing.T) {
+		err := ValidateSpouseTaxRequest(models.SpouseTaxRequest{Taxpayer: models.TaxRequest{TotalIncome: -1}})
+		assertIsNotNil(t, err)
+		assertErrorMessage(t, ErrTotalIncomeInvalid, err)
+	})
+	t.Run("given invalid spouse wht should get spouse error", func(t *testing.T) {
+		err := ValidateSpouseTaxRequest(models.SpouseTaxRequest{Spouse: models.TaxRequest{Wht: -1}})
+		assertIsNotNil(t, err)
+		assertErrorMessage(t, errors.New("spouse "+ErrWhtInvalid.Error()), err)
+		if !errors.Is(err, ErrWhtInvalid) {
+			t.Error("expect error wrap ErrWhtInvalid")
+		}
+	})
+	t.Run("given invalid spouse csv should get spouse error", func(t *testing.T) {
+		err := ValidateSpouseTaxCsv(models.SpouseTaxCsv{Spouse: models.TaxCsv{Donation: -1}})
+		assertIsNotNil(t, err)
+		assertErrorMessage(t, errors.New("spouse donation amount should be more than or equal 0"), err)
+	})
+	t.Run("given taxpayer and spouse in different period should get error 'ErrSpousePeriodMismatch'", func(t *testing.T) {
+		err := ValidateSpouseTaxRequest(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 500_000, Period: models.HalfYearPeriod, IncomeType: models.RentalIncome},
+			Spouse:   models.TaxRequest{TotalIncome: 300_000},
+		})
+		assertIsNotNil(t, err)
+		assertErrorMessage(t, ErrSpousePeriodMismatch, err)
+	})
+	t.Run("given valid spouse tax request should not get error", func(t *testing.T) {
+		err := ValidateSpouseTaxRequest(models.SpouseTaxRequest{
+			Taxpayer: models.TaxRequest{TotalIncome: 500_000},
+			Spouse:   models.TaxRequest{TotalIncome: 300_000, Wht: 1_000},
+		})
+		assertIsNil(t, err)
+	})
+}
+
+func TestValidatePeriod(t *testing.T) {
+	testSuites := []struct {
+		name string
+		tax  models.TaxRequest
+		want error
+	}{
+		{"given unknown period should get error 'ErrPeriodInvalid'", models.TaxRequest{Period: "quarter"}, ErrPeriodInvalid},
+		{"given unknown income type should get error 'ErrIncomeTypeInvalid'", models.TaxRequest{IncomeType: "lottery"}, ErrIncomeTypeInvalid},
+		{"given negative prepaid tax should get error 'ErrPrepaidTaxInvalid'", models.TaxRequest{PrepaidTax: -1}, ErrPrepaidTaxInvalid},
+		{"given half-year with salary should get error 'ErrHalfYearIncomeType'", models.TaxRequest{Period: models.HalfYearPeriod, IncomeType: models.SalaryIncome}, ErrHalfYearIncomeType},
+		{"given half-year without income type should get error 'ErrHalfYearIncomeType'", models.TaxRequest{Period: models.HalfYearPeriod}, ErrHalfYearIncomeType},
+		{"given half-year with prepaid tax should get error 'ErrHalfYearPrepaidTax'", models.TaxRequest{Period: models.HalfYearPeriod, IncomeType: models.RentalIncome, PrepaidTax: 1}, ErrHalfYearPrepaidTax},
+		{
+			"given half-year with k-receipt should get error 'ErrHalfYearAllowance'",
+			models.TaxRequest{Period: models.HalfYearPeriod, IncomeType: models.RentalIncome, Allowances: []models.Allowance{{Type: models.KReceiptSlug}}},
+			ErrHalfYearAllowance,
+		},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTaxRequest(tc.tax)
+
+			assertIsNotNil(t, err)
+			assertErrorMessage(t, tc.want, err)
+		})
+	}
+	t.Run("given valid half-year request should not get error", func(t *testing.T) {
+		err := ValidateTaxRequest(models.TaxRequest{
+			TotalIncome: 300_000,
+			Period:      models.HalfYearPeriod,
+			IncomeType:  models.BusinessIncome,
+			Allowances:  []models.Allowance{{Type: models.DonationSlug, Amount: 1_000}},
+		})
+		assertIsNil(t, err)
+	})
+	t.Run("given valid full-year request with prepaid tax should not get error", func(t *testing.T) {
+		err := ValidateTaxRequest(models.TaxRequest{TotalIncome: 300_000, Period: models.FullYearPeriod, PrepaidTax: 2_000})
+		assertIsNil(t, err)
+	})
+}
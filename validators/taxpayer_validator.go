@@ -0,0 +1,70 @@
+package validators
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+var (
+	ErrNationalIdInvalid    = errors.New("national id should be 13 digits with valid check digit")
+	ErrTaxpayerNameRequired = errors.New("taxpayer name is required")
+	ErrMaritalStatusInvalid = errors.New("marital status should be one of 'single', 'married', 'divorced', 'widowed'")
+	ErrSpouseIncomeStatus   = errors.New("spouse has income is allowed only for married status")
+	ErrChildrenInvalid      = errors.New("children should be more than or equal 0")
+	ErrParentsInvalid       = errors.New("parents should be between 0 and 2, or 4 with parents of spouse who has no income")
+)
+
+// ValidateNationalId check Thai national id, the last digit is check digit of first 12 digits
+// that weighted from 13 down to 2: (11 - sum mod 11) mod 10.
+func ValidateNationalId(id string) error {
+	if len(id) != 13 {
+		return ErrNationalIdInvalid
+	}
+	sum := 0
+	for i, c := range id {
+		if c < '0' || c > '9' {
+			return ErrNationalIdInvalid
+		}
+		if i < 12 {
+			sum += int(c-'0') * (13 - i)
+		}
+	}
+	if int(id[12]-'0') != (11-sum%11)%10 {
+		return ErrNationalIdInvalid
+	}
+	return nil
+}
+
+func ValidateTaxpayer(taxpayer models.Taxpayer) error {
+	if err := ValidateNationalId(taxpayer.NationalId); err != nil {
+		return err
+	}
+	if taxpayer.Name == "" {
+		return ErrTaxpayerNameRequired
+	}
+	statuses := []string{models.SingleStatus, models.MarriedStatus, models.DivorcedStatus, models.WidowedStatus}
+	if !slices.Contains(statuses, taxpayer.MaritalStatus) {
+		return ErrMaritalStatusInvalid
+	}
+	if taxpayer.SpouseHasIncome && taxpayer.MaritalStatus != models.MarriedStatus {
+		return ErrSpouseIncomeStatus
+	}
+	if taxpayer.Children < 0 {
+		return ErrChildrenInvalid
+	}
+	if taxpayer.Parents < 0 || taxpayer.Parents > MaxParents(taxpayer) {
+		return ErrParentsInvalid
+	}
+	return nil
+}
+
+// MaxParents return number of parents that taxpayer can claim allowance,
+// married taxpayer whose spouse has no income can also claim parents of spouse
+func MaxParents(taxpayer models.Taxpayer) int {
+	if taxpayer.MaritalStatus == models.MarriedStatus && !taxpayer.SpouseHasIncome {
+		return 4
+	}
+	return 2
+}
@@ -0,0 +1,106 @@
+//go:build !integration
+// +build !integration
+
+package validators
+
+import (
+	"testing"
+
+	"github.com/baronight/assessment-tax/models"
+)
+
+func TestValidateNationalId(t *testing.T) {
+	for _, v := range []string{"", "123456789012", "12345678901210", "123456789012a", "1234567890123"} {
+		t.Run("given national id '"+v+"' should get error 'ErrNationalIdInvalid'", func(t *testing.T) {
+			err := ValidateNationalId(v)
+			assertIsNotNil(t, err)
+			assertErrorMessage(t, ErrNationalIdInvalid, err)
+		})
+	}
+	for _, v := range []string{"1234567890121", "3100600123450", "1101700203000"} {
+		t.Run("given valid national id '"+v+"' should not get error", func(t *testing.T) {
+			assertIsNil(t, ValidateNationalId(v))
+		})
+	}
+}
+
+func TestValidateTaxpayer(t *testing.T) {
+	valid := models.Taxpayer{NationalId: "1234567890121", Name: "Somchai", MaritalStatus: models.MarriedStatus, Children: 2, Parents: 1}
+	testSuites := []struct {
+		name   string
+		modify func(tp *models.Taxpayer)
+		want   error
+	}{
+		{
+			name:   "given invalid national id should get error 'ErrNationalIdInvalid'",
+			modify: func(tp *models.Taxpayer) { tp.NationalId = "1234567890123" },
+			want:   ErrNationalIdInvalid,
+		},
+		{
+			name:   "given empty name should get error 'ErrTaxpayerNameRequired'",
+			modify: func(tp *models.Taxpayer) { tp.Name = "" },
+			want:   ErrTaxpayerNameRequired,
+		},
+		{
+			name:   "given unknown marital status should get error 'ErrMaritalStatusInvalid'",
+			modify: func(tp *models.Taxpayer) { tp.MaritalStatus = "engaged" },
+			want:   ErrMaritalStatusInvalid,
+		},
+		{
+			name: "given spouse has income on single status should get error 'ErrSpouseIncomeStatus'",
+			modify: func(tp *models.Taxpayer) {
+				tp.MaritalStatus = models.SingleStatus
+				tp.SpouseHasIncome = true
+			},
+			want: ErrSpouseIncomeStatus,
+		},
+		{
+			name:   "given negative children should get error 'ErrChildrenInvalid'",
+			modify: func(tp *models.Taxpayer) { tp.Children = -1 },
+			want:   ErrChildrenInvalid,
+		},
+		{
+			name: "given more than 2 parents on single status should get error 'ErrParentsInvalid'",
+			modify: func(tp *models.Taxpayer) {
+				tp.MaritalStatus = models.SingleStatus
+				tp.Parents = 3
+			},
+			want: ErrParentsInvalid,
+		},
+		{
+			name: "given more than 2 parents when spouse has income should get error 'ErrParentsInvalid'",
+			modify: func(tp *models.Taxpayer) {
+				tp.SpouseHasIncome = true
+				tp.Parents = 3
+			},
+			want: ErrParentsInvalid,
+		},
+		{
+			name:   "given more than 4 parents on married status should get error 'ErrParentsInvalid'",
+			modify: func(tp *models.Taxpayer) { tp.Parents = 5 },
+			want:   ErrParentsInvalid,
+		},
+	}
+	for _, tc := range testSuites {
+		t.Run(tc.name, func(t *testing.T) {
+			taxpayer := valid
+			tc.modify(&taxpayer)
+			err := ValidateTaxpayer(taxpayer)
+			assertIsNotNil(t, err)
+			assertErrorMessage(t, tc.want, err)
+		})
+	}
+	t.Run("given valid taxpayer should not get error", func(t *testing.T) {
+		assertIsNil(t, ValidateTaxpayer(valid))
+	})
+	t.Run("given 4 parents when spouse has no income should not get error", func(t *testing.T) {
+		taxpayer := valid
+		taxpayer.Parents = 4
+		assertIsNil(t, ValidateTaxpayer(taxpayer))
+	})
+	t.Run("given invalid taxpayer id on tax request should get error 'ErrNationalIdInvalid'", func(t *testing.T) {
+		err := ValidateTaxRequest(models.TaxRequest{TaxpayerId: "123"})
+		assertIsNotNil(t, err)
+		assertErrorMessage(t, ErrNationalIdInvalid, err)
+	})
+}